@@ -0,0 +1,159 @@
+package compiler
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/emit"
+	"bicep-go/registry"
+	"bicep-go/semantics"
+	"bicep-go/syntax"
+	"bicep-go/types"
+	"bicep-go/util"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+)
+
+// Compilation type checks a file together with the modules and imported files it references.
+// Semantic models are created the first time they are requested.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/Compilation.cs
+type Compilation struct {
+	fsys     fs.FS
+	grouping *SourceFileGrouping
+	provider types.ResourceTypeProvider
+	models   map[*SourceFile]*semantics.SemanticModel
+}
+
+// NewCompilation loads the entry point and the files it references from fsys, and registry modules
+// from the cache of modules. Registry modules must be restored beforehand.
+func NewCompilation(fsys fs.FS, entryPath string, provider types.ResourceTypeProvider, modules *registry.OciModuleRegistry) (*Compilation, error) {
+	grouping, err := BuildSourceFileGrouping(fsys, entryPath, modules)
+	if err != nil {
+		return nil, err
+	}
+	return &Compilation{
+		fsys:     fsys,
+		grouping: grouping,
+		provider: provider,
+		models:   map[*SourceFile]*semantics.SemanticModel{},
+	}, nil
+}
+
+func (c *Compilation) GetSourceFileGrouping() *SourceFileGrouping {
+	return c.grouping
+}
+
+func (c *Compilation) GetEntrypointSemanticModel() *semantics.SemanticModel {
+	return c.GetSemanticModel(c.grouping.EntryPoint)
+}
+
+// GetSemanticModel returns nil for ARM JSON templates.
+func (c *Compilation) GetSemanticModel(file *SourceFile) *semantics.SemanticModel {
+	if file.Template != nil {
+		return nil
+	}
+	if model, ok := c.models[file]; ok {
+		return model
+	}
+	model := semantics.NewSemanticModel(file.Program, c.provider, c)
+	c.models[file] = model
+	return model
+}
+
+// TryGetModuleModel implements semantics.ModuleLookup. Files with errors cannot be used as modules.
+func (c *Compilation) TryGetModuleModel(declaration *syntax.ModuleDeclarationSyntax) (semantics.ModuleModel, *diagnostics.Diagnostic) {
+	file, diagnostic := c.grouping.TryGetModuleFile(declaration)
+	if file == nil {
+		return nil, diagnostic
+	}
+	if file.Template != nil {
+		return file.Template, nil
+	}
+	if diagnostics.HasErrors(c.GetDiagnostics(file)) {
+		return nil, referencedModuleHasErrors(declaration.Path.GetSpan())
+	}
+	return c.GetSemanticModel(file), nil
+}
+
+// TryGetImportedModel implements semantics.ModuleLookup. Files with errors cannot be imported from.
+func (c *Compilation) TryGetImportedModel(declaration *syntax.CompileTimeImportDeclarationSyntax) (semantics.ModuleModel, *diagnostics.Diagnostic) {
+	file, diagnostic := c.grouping.TryGetImportedFile(declaration)
+	if file == nil {
+		return nil, diagnostic
+	}
+	if file.Template != nil {
+		return file.Template, nil
+	}
+	if diagnostics.HasErrors(c.GetDiagnostics(file)) {
+		return nil, referencedModuleHasErrors(declaration.TryGetPathSyntax().GetSpan())
+	}
+	return c.GetSemanticModel(file), nil
+}
+
+// TryReadFile implements semantics.ModuleLookup. Files are read from the file system of the compilation, relative to
+// the file declaring program.
+func (c *Compilation) TryReadFile(program *syntax.ProgramSyntax, span *util.TextSpan, filePath string) ([]byte, *diagnostics.Diagnostic) {
+	parentPath := c.grouping.EntryPoint.Path
+	for _, file := range c.grouping.Files {
+		if file.Program == program {
+			parentPath = file.Path
+			break
+		}
+	}
+	resolved, diagnostic := resolveRelativePath(c.fsys, span, parentPath, filePath)
+	if diagnostic != nil {
+		return nil, diagnostic
+	}
+	data, err := fs.ReadFile(c.fsys, resolved)
+	if err != nil {
+		return nil, errorOccurredReadingFile(span, err)
+	}
+	return data, nil
+}
+
+// GetDiagnostics returns the parse and semantic diagnostics of a file, sorted by position.
+// ARM JSON templates have no diagnostics.
+func (c *Compilation) GetDiagnostics(file *SourceFile) []*diagnostics.Diagnostic {
+	if file.Template != nil {
+		return nil
+	}
+	var all []*diagnostics.Diagnostic
+	all = append(all, file.ParseDiagnostics...)
+	all = append(all, c.GetSemanticModel(file).GetDiagnostics()...)
+	diagnostics.Sort(all)
+	return all
+}
+
+// GetAllDiagnostics maps the paths of all files of the compilation to their diagnostics.
+func (c *Compilation) GetAllDiagnostics() map[string][]*diagnostics.Diagnostic {
+	all := map[string][]*diagnostics.Diagnostic{}
+	for filePath, file := range c.grouping.Files {
+		all[filePath] = c.GetDiagnostics(file)
+	}
+	return all
+}
+
+// Emit writes the ARM template of the entry point with the given options. Files with errors cannot be emitted.
+func (c *Compilation) Emit(out io.Writer, options emit.EmitterOptions) error {
+	if err := c.checkErrors(); err != nil {
+		return err
+	}
+	return emit.NewTemplateWriter(c.GetEntrypointSemanticModel(), options).Write(out)
+}
+
+// checkErrors fails if any file of the compilation has errors, naming the first file in path order by its display
+// path.
+func (c *Compilation) checkErrors() error {
+	all := c.GetAllDiagnostics()
+	filePaths := make([]string, 0, len(all))
+	for filePath := range all {
+		filePaths = append(filePaths, filePath)
+	}
+	sort.Strings(filePaths)
+	for _, filePath := range filePaths {
+		if diagnostics.HasErrors(all[filePath]) {
+			return fmt.Errorf("the file %s has errors", util.GetDisplayPath(c.fsys, filePath))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,549 @@
+package compiler
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/emit"
+	"bicep-go/registry"
+	"bicep-go/registry/registrytest"
+	"bicep-go/types"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFS(files map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for filePath, text := range files {
+		fsys[filePath] = &fstest.MapFile{Data: []byte(text)}
+	}
+	return fsys
+}
+
+func newTestRegistry(t *testing.T, httpClient *http.Client) *registry.OciModuleRegistry {
+	return registry.NewOciModuleRegistry(nil, registry.NewCache(t.TempDir()), registry.NewOciClient(httpClient))
+}
+
+func formatDiagnostics(diagnostics []*diagnostics.Diagnostic) []string {
+	var formatted []string
+	for _, diagnostic := range diagnostics {
+		formatted = append(formatted, diagnostic.ToString())
+	}
+	return formatted
+}
+
+const storageModule = "param name string\nparam size int = 1\noutput id string = name\n"
+
+const storageTemplate = `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "parameters": {
+    "name": { "type": "string" },
+    "sku": { "type": "string", "defaultValue": "Standard_LRS", "allowedValues": ["Standard_LRS", "Premium_LRS"] }
+  },
+  "outputs": {
+    "id": { "type": "string" }
+  }
+}`
+
+func TestCompilationModules(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []string
+	}{
+		{"valid module", map[string]string{
+			"main.bicep":        "module m './sub/storage.bicep' = {\n  name: 'm'\n  params: {\n    name: 'a'\n  }\n}\noutput id string = m.outputs.id\n",
+			"sub/storage.bicep": storageModule,
+		}, nil},
+		{"relative to referencing file", map[string]string{
+			"main.bicep":            "module m './sub/main.bicep' = {\n  name: 'm'\n}\n",
+			"sub/main.bicep":        "module s '../lib/storage.bicep' = {\n  name: 's'\n  params: {\n    name: 'a'\n  }\n}\n",
+			"lib/storage.bicep":     storageModule,
+			"sub/lib/storage.bicep": "param other string\n",
+		}, nil},
+		{"param type mismatch", map[string]string{
+			"main.bicep":        "module m './sub/storage.bicep' = {\n  name: 'm'\n  params: {\n    name: 1\n  }\n}\n",
+			"sub/storage.bicep": storageModule,
+		}, []string{"[69:70] Error BCP036: The property \"name\" expected a value of type \"string\" but the provided value is of type \"1\"."}},
+		{"missing params", map[string]string{
+			"main.bicep":        "module m './sub/storage.bicep' = {\n  name: 'm'\n}\n",
+			"sub/storage.bicep": storageModule,
+		}, []string{"[33:34] Error BCP035: The specified \"module\" declaration is missing the following required properties: \"params\"."}},
+		{"output type", map[string]string{
+			"main.bicep":        "module m './sub/storage.bicep' = {\n  name: 'm'\n  params: {\n    name: 'a'\n  }\n}\noutput id int = m.outputs.id\n",
+			"sub/storage.bicep": storageModule,
+		}, []string{"[95:107] Error BCP026: The output expects a value of type \"int\" but the provided value is of type \"string\"."}},
+		{"missing file", map[string]string{
+			"main.bicep": "module m './missing.bicep' = {\n  name: 'm'\n}\n",
+		}, []string{"[9:26] Error BCP091: An error occurred reading file. open missing.bicep: file does not exist"}},
+		{"outside of root", map[string]string{
+			"main.bicep": "module m '../storage.bicep' = {\n  name: 'm'\n}\n",
+		}, []string{"[9:27] Error BCP093: File path \"../storage.bicep\" could not be resolved relative to \"main.bicep\"."}},
+		{"absolute path", map[string]string{
+			"main.bicep": "module m '/storage.bicep' = {\n  name: 'm'\n}\n",
+		}, []string{"[9:25] Error BCP051: The specified path begins with \"/\". Files must be referenced using relative paths."}},
+		{"back slash", map[string]string{
+			"main.bicep": "module m 'sub\\\\storage.bicep' = {\n  name: 'm'\n}\n",
+		}, []string{"[9:29] Error BCP098: The specified file path contains a \"\\\" character. Use \"/\" instead as the directory separator character."}},
+		{"interpolated path", map[string]string{
+			"main.bicep": "var name = 'storage'\nmodule m './${name}.bicep' = {\n  name: 'm'\n}\n",
+		}, []string{"[30:47] Error BCP092: String interpolation is not supported in file paths."}},
+		{"module with errors", map[string]string{
+			"main.bicep": "module m './bad.bicep' = {\n  name: 'm'\n}\n",
+			"bad.bicep":  "param p string = 1\n",
+		}, []string{"[9:22] Error BCP104: The referenced module has errors."}},
+		{"module with parse errors", map[string]string{
+			"main.bicep": "module m './bad.bicep' = {\n  name: 'm'\n}\n",
+			"bad.bicep":  "param\n",
+		}, []string{"[9:22] Error BCP104: The referenced module has errors."}},
+		{"self reference", map[string]string{
+			"main.bicep": "module m './main.bicep' = {\n  name: 'm'\n}\n",
+		}, []string{"[9:23] Error BCP094: This module references itself, which is not allowed."}},
+		{"cycle", map[string]string{
+			"main.bicep": "module a './a.bicep' = {\n  name: 'a'\n}\n",
+			"a.bicep":    "module b './b.bicep' = {\n  name: 'b'\n}\n",
+			"b.bicep":    "module a './a.bicep' = {\n  name: 'a'\n}\n",
+		}, []string{"[9:20] Error BCP104: The referenced module has errors."}},
+		{"template module", map[string]string{
+			"main.bicep":   "module m './storage.json' = {\n  name: 'm'\n  params: {\n    name: 'a'\n    sku: 'Basic'\n  }\n}\noutput id int = m.outputs.id\n",
+			"storage.json": storageTemplate,
+		}, []string{
+			"[77:84] Error BCP036: The property \"sku\" expected a value of type \"'Standard_LRS' | 'Premium_LRS'\" but the provided value is of type \"'Basic'\".",
+			"[107:119] Error BCP026: The output expects a value of type \"int\" but the provided value is of type \"string\".",
+		}},
+		{"invalid template module", map[string]string{
+			"main.bicep":   "module m './storage.json' = {\n  name: 'm'\n}\n",
+			"storage.json": "{",
+		}, []string{"[9:25] Error BCP091: An error occurred reading file. invalid ARM template: unexpected end of JSON input"}},
+		{"invalid registry reference", map[string]string{
+			"main.bicep": "module m 'br:example.azurecr.io/storage' = {\n  name: 'm'\n}\n",
+		}, []string{"[9:40] Error BCP196: The module tag or digest is missing."}},
+		{"module target scope", map[string]string{
+			"main.bicep": "module m './sub.bicep' = {\n  name: 'm'\n}\n",
+			"sub.bicep":  "targetScope = 'subscription'\n",
+		}, []string{"[9:22] Error BCP134: Scope \"resourceGroup\" is not valid for this module. Permitted scopes: \"subscription\"."}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compilation, err := NewCompilation(newTestFS(test.files), "main.bicep", types.NewGenericResourceTypeProvider(), newTestRegistry(t, http.DefaultClient))
+			require.NoError(t, err)
+			diagnostics := compilation.GetDiagnostics(compilation.GetSourceFileGrouping().EntryPoint)
+			require.Equal(t, test.expected, formatDiagnostics(diagnostics))
+		})
+	}
+}
+
+func TestCompilationCycleDiagnostics(t *testing.T) {
+	compilation, err := NewCompilation(newTestFS(map[string]string{
+		"main.bicep": "module a './a.bicep' = {\n  name: 'a'\n}\n",
+		"a.bicep":    "module b './b.bicep' = {\n  name: 'b'\n}\n",
+		"b.bicep":    "module a './a.bicep' = {\n  name: 'a'\n}\n",
+	}), "main.bicep", types.NewGenericResourceTypeProvider(), newTestRegistry(t, http.DefaultClient))
+	require.NoError(t, err)
+
+	all := compilation.GetAllDiagnostics()
+	require.Len(t, all, 3)
+	require.Equal(t, []string{"[9:20] Error BCP095: The file is involved in a cycle (\"a.bicep\" -> \"b.bicep\")."}, formatDiagnostics(all["a.bicep"]))
+	require.Equal(t, []string{"[9:20] Error BCP095: The file is involved in a cycle (\"b.bicep\" -> \"a.bicep\")."}, formatDiagnostics(all["b.bicep"]))
+}
+
+type displayPathFS struct {
+	fstest.MapFS
+}
+
+func (f displayPathFS) DisplayPath(name string) string {
+	return "root/" + name
+}
+
+func TestCompilationDisplayPaths(t *testing.T) {
+	compilation, err := NewCompilation(displayPathFS{newTestFS(map[string]string{
+		"main.bicep": "module a './a.bicep' = {\n  name: 'a'\n}\n",
+		"a.bicep":    "module b './b.bicep' = {\n  name: 'b'\n}\n",
+		"b.bicep":    "module a './a.bicep' = {\n  name: 'a'\n}\n",
+	})}, "main.bicep", types.NewGenericResourceTypeProvider(), newTestRegistry(t, http.DefaultClient))
+	require.NoError(t, err)
+
+	all := compilation.GetAllDiagnostics()
+	require.Equal(t, []string{"[9:20] Error BCP095: The file is involved in a cycle (\"root/a.bicep\" -> \"root/b.bicep\")."}, formatDiagnostics(all["a.bicep"]))
+	require.EqualError(t, compilation.Emit(&bytes.Buffer{}, emit.EmitterOptions{}), "the file root/a.bicep has errors")
+}
+
+func TestCompilationDependencyDiagnostics(t *testing.T) {
+	compilation, err := NewCompilation(newTestFS(map[string]string{
+		"main.bicep":        "module a './sub/storage.bicep' = {\n  name: 'a'\n  params: {\n    name: 'a'\n  }\n}\nmodule b './sub/storage.bicep' = {\n  name: 'b'\n  params: {\n    name: a.outputs.id\n  }\n  dependsOn: [\n    a\n  ]\n}\n",
+		"sub/storage.bicep": storageModule,
+	}), "main.bicep", types.NewGenericResourceTypeProvider(), newTestRegistry(t, http.DefaultClient))
+	require.NoError(t, err)
+
+	diagnostics := compilation.GetDiagnostics(compilation.GetSourceFileGrouping().EntryPoint)
+	require.Equal(t, []string{"[184:185] Warning no-unnecessary-dependson: Remove unnecessary dependsOn entry 'a'."}, formatDiagnostics(diagnostics))
+}
+
+func TestCompilationModuleType(t *testing.T) {
+	compilation, err := NewCompilation(newTestFS(map[string]string{
+		"main.bicep":        "module m './sub/storage.bicep' = {\n  name: 'm'\n  params: {\n    name: 'a'\n  }\n}\n",
+		"sub/storage.bicep": storageModule,
+	}), "main.bicep", types.NewGenericResourceTypeProvider(), newTestRegistry(t, http.DefaultClient))
+	require.NoError(t, err)
+
+	file := compilation.GetSourceFileGrouping().Files["sub/storage.bicep"]
+	require.Equal(t, []int{0, 18, 37, 61}, file.LineStarts)
+	module := compilation.GetSemanticModel(file)
+	params := module.GetParametersType().Properties
+	require.Len(t, params, 2)
+	require.Equal(t, "name", params[0].Name)
+	require.True(t, params[0].IsRequired())
+	require.Equal(t, "size", params[1].Name)
+	require.False(t, params[1].IsRequired())
+
+	outputs := module.GetOutputsType().Properties
+	require.Len(t, outputs, 1)
+	require.Equal(t, "id", outputs[0].Name)
+	require.Equal(t, types.String, outputs[0].Type)
+	require.Equal(t, types.ResourceScopeResourceGroup, module.GetTargetScope())
+}
+
+func TestCompilationEmit(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{"valid", "param name string\noutput upper string = toUpper(name)\n", ""},
+		{"errors", "output upper string = toUpper(1)\n", "the file main.bicep has errors"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compilation, err := NewCompilation(newTestFS(map[string]string{"main.bicep": tt.text}), "main.bicep", types.NewGenericResourceTypeProvider(), newTestRegistry(t, http.DefaultClient))
+			require.NoError(t, err)
+
+			var template bytes.Buffer
+			err = compilation.Emit(&template, emit.EmitterOptions{})
+			if tt.expected != "" {
+				require.EqualError(t, err, tt.expected)
+				return
+			}
+			require.NoError(t, err)
+			require.Contains(t, template.String(), `"value": "[toUpper(parameters('name'))]"`)
+		})
+	}
+}
+
+func TestCompilationMissingEntryPoint(t *testing.T) {
+	_, err := NewCompilation(newTestFS(nil), "main.bicep", types.NewGenericResourceTypeProvider(), newTestRegistry(t, http.DefaultClient))
+	require.Error(t, err)
+}
+
+func TestCompilationRegistryModules(t *testing.T) {
+	server := registrytest.NewServer()
+	defer server.Close()
+	server.PushModule("bicep/storage", "v1", []byte(storageTemplate))
+
+	reference := "br:" + server.Registry() + "/bicep/storage:v1"
+	fsys := newTestFS(map[string]string{
+		"main.bicep": "module m '" + reference + "' = {\n  name: 'm'\n  params: {\n    name: 1\n  }\n}\nmodule n 'br:" + server.Registry() + "/bicep/missing:v1' = {\n  name: 'n'\n}\n",
+	})
+	modules := newTestRegistry(t, server.Client())
+
+	compilation, err := NewCompilation(fsys, "main.bicep", types.NewGenericResourceTypeProvider(), modules)
+	require.NoError(t, err)
+	diagnostics := compilation.GetDiagnostics(compilation.GetSourceFileGrouping().EntryPoint)
+	require.Len(t, diagnostics, 2)
+	require.Equal(t, "BCP190", diagnostics[0].Code)
+	require.Equal(t, "The artifact with reference \""+reference+"\" has not been restored.", diagnostics[0].Message)
+	require.Equal(t, "BCP190", diagnostics[1].Code)
+
+	require.NoError(t, Restore(context.Background(), fsys, "main.bicep", modules))
+	compilation, err = NewCompilation(fsys, "main.bicep", types.NewGenericResourceTypeProvider(), modules)
+	require.NoError(t, err)
+	diagnostics = compilation.GetDiagnostics(compilation.GetSourceFileGrouping().EntryPoint)
+	require.Len(t, diagnostics, 2)
+	require.Equal(t, "BCP036", diagnostics[0].Code)
+	require.Equal(t, "BCP192", diagnostics[1].Code)
+}
+
+func TestCompilationRegistryAliases(t *testing.T) {
+	server := registrytest.NewServer()
+	defer server.Close()
+	server.PushModule("bicep/modules/storage", "v1", []byte(storageTemplate))
+
+	fsys := newTestFS(map[string]string{
+		"bicepconfig.json": `{"moduleAliases": {"br": {"shared": {"registry": "` + server.Registry() + `", "modulePath": "bicep/modules"}}}}`,
+		"app/main.bicep":   "module m 'br/shared:storage:v1' = {\n  name: 'm'\n  params: {\n    name: 'a'\n  }\n}\nmodule p 'br/public:avm/storage:v1' = {\n  name: 'p'\n}\n",
+	})
+	configuration, err := registry.FindConfiguration(fsys, "app/main.bicep")
+	require.NoError(t, err)
+	modules := registry.NewOciModuleRegistry(configuration, registry.NewCache(t.TempDir()), registry.NewOciClient(server.Client()))
+
+	// the public alias is built in, and resolves to the public registry
+	compilation, err := NewCompilation(fsys, "app/main.bicep", types.NewGenericResourceTypeProvider(), modules)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"[9:31] Error BCP190: The artifact with reference \"br:" + server.Registry() + "/bicep/modules/storage:v1\" has not been restored.",
+		"[89:115] Error BCP190: The artifact with reference \"br:mcr.microsoft.com/bicep/avm/storage:v1\" has not been restored.",
+	}, formatDiagnostics(compilation.GetDiagnostics(compilation.GetSourceFileGrouping().EntryPoint)))
+
+	modules.Restore(context.Background(), compilation.GetSourceFileGrouping().ArtifactReferences[:1])
+	compilation, err = NewCompilation(fsys, "app/main.bicep", types.NewGenericResourceTypeProvider(), modules)
+	require.NoError(t, err)
+	diagnostics := compilation.GetDiagnostics(compilation.GetSourceFileGrouping().EntryPoint)
+	require.Len(t, diagnostics, 1)
+	require.Equal(t, "BCP190", diagnostics[0].Code)
+}
+
+const exportsModule = "@export()\ntype name = string\n\n@export()\n@description('The default location.')\nvar location = 'westus'\n\n@export()\nfunc greet(name string) string => 'Hello ${name}'\n"
+
+const exportsTemplate = `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "metadata": {
+    "__bicep_exported_variables!": [{ "name": "location" }]
+  },
+  "definitions": {
+    "name": { "type": "string", "metadata": { "__bicep_export!": true } },
+    "internal": { "type": "int" }
+  },
+  "variables": {
+    "location": "westus"
+  },
+  "resources": []
+}`
+
+func TestCompilationImports(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []string
+	}{
+		{"named imports", map[string]string{
+			"main.bicep":    "import {name, location, greet} from './exports.bicep'\nparam n name = location\noutput greeting string = greet(n)\n",
+			"exports.bicep": exportsModule,
+		}, nil},
+		{"aliased imports", map[string]string{
+			"main.bicep":    "import {name as resourceName, greet as hello} from './exports.bicep'\nparam n resourceName\noutput greeting string = hello(n)\n",
+			"exports.bicep": exportsModule,
+		}, nil},
+		{"wildcard import", map[string]string{
+			"main.bicep":    "import * as lib from './exports.bicep'\nparam n lib.name = lib.location\noutput greeting string = lib.greet(n)\n",
+			"exports.bicep": exportsModule,
+		}, nil},
+		{"imported type mismatch", map[string]string{
+			"main.bicep":    "import {name, greet} from './exports.bicep'\nparam n name = 1\noutput greeting int = greet(n)\n",
+			"exports.bicep": exportsModule,
+		}, []string{"[59:60] Error BCP027: The parameter expects a default value of type \"string\" but provided value is of type \"1\".", "[83:91] Error BCP026: The output expects a value of type \"int\" but the provided value is of type \"string\"."}},
+		{"wildcard function not found", map[string]string{
+			"main.bicep":    "import * as lib from './exports.bicep'\noutput greeting string = lib.missing()\n",
+			"exports.bicep": exportsModule,
+		}, []string{"[68:75] Error BCP107: The function \"missing\" does not exist in namespace \"lib\"."}},
+		{"template exports", map[string]string{
+			"main.bicep":   "import {name, location} from './exports.json'\nparam n name = location\n",
+			"exports.json": exportsTemplate,
+		}, nil},
+		{"template definition not exported", map[string]string{
+			"main.bicep":   "import {internal} from './exports.json'\n",
+			"exports.json": exportsTemplate,
+		}, []string{"[8:16] Error BCP360: The \"internal\" symbol was not found in (or was not exported by) the imported template."}},
+		{"symbol not exported", map[string]string{
+			"main.bicep":    "import {missing} from './exports.bicep'\n",
+			"exports.bicep": exportsModule,
+		}, []string{"[8:15] Error BCP360: The \"missing\" symbol was not found in (or was not exported by) the imported template."}},
+		{"imported multiple times", map[string]string{
+			"main.bicep":    "import {name, name as other} from './exports.bicep'\n",
+			"exports.bicep": exportsModule,
+		}, []string{"[8:12] Error BCP362: This symbol is imported multiple times under the names \"name\", \"other\".", "[14:27] Error BCP362: This symbol is imported multiple times under the names \"name\", \"other\"."}},
+		{"alias collision", map[string]string{
+			"main.bicep":    "import {name} from './exports.bicep'\nparam name string\n",
+			"exports.bicep": exportsModule,
+		}, []string{"[8:12] Error BCP028: Identifier \"name\" is declared multiple times. Remove or rename the duplicates.", "[43:47] Error BCP028: Identifier \"name\" is declared multiple times. Remove or rename the duplicates."}},
+		{"value used as type", map[string]string{
+			"main.bicep":    "import {location} from './exports.bicep'\nparam n location\n",
+			"exports.bicep": exportsModule,
+		}, []string{"[49:57] Error BCP287: 'location' refers to a value but is being used as a type here."}},
+		{"transitive import", map[string]string{
+			"main.bicep":    "import {fullName} from './types.bicep'\nparam n fullName\n",
+			"types.bicep":   "import {name} from './exports.bicep'\n\n@export()\ntype fullName = {\n  first: name\n  last: name\n}\n",
+			"exports.bicep": exportsModule,
+		}, nil},
+		{"ambiguous template exports", map[string]string{
+			"main.bicep":   "import * as lib from './exports.json'\n",
+			"exports.json": `{"metadata": {"__bicep_exported_variables!": [{"name": "name"}]}, "definitions": {"name": {"type": "string", "metadata": {"__bicep_export!": true}}}, "variables": {"name": "a"}}`,
+		}, []string{"[7:15] Error BCP374: The imported model cannot be loaded with a wildcard because it contains the following duplicated exports: \"name\"."}},
+		{"imported file has errors", map[string]string{
+			"main.bicep":    "import {name} from './exports.bicep'\n",
+			"exports.bicep": "@export()\ntype name = string\nvar x = \n",
+		}, []string{"[19:36] Error BCP104: The referenced module has errors."}},
+		{"import cycle", map[string]string{
+			"main.bicep": "import {other} from './main.bicep'\n\n@export()\ntype name = string\n",
+		}, []string{"[20:34] Error BCP094: This module references itself, which is not allowed."}},
+		{"unexportable variable", map[string]string{
+			"main.bicep": "param prefix string\n\nvar name = '${prefix}-name'\n\n@export()\nvar fullName = name\n",
+		}, []string{"[51:59] Error BCP372: The \"@export()\" decorator may not be applied to variables that refer to parameters, modules, or resource, either directly or indirectly. The target of this decorator contains direct or transitive references to the following unexportable symbols: \"prefix\"."}},
+		{"export not allowed", map[string]string{
+			"main.bicep": "@export()\nparam name string\n",
+		}, []string{"[1:9] Error BCP125: Function \"export\" cannot be used as a parameter decorator."}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compilation, err := NewCompilation(newTestFS(test.files), "main.bicep", types.NewGenericResourceTypeProvider(), newTestRegistry(t, http.DefaultClient))
+			require.NoError(t, err)
+			diagnostics := compilation.GetDiagnostics(compilation.GetSourceFileGrouping().EntryPoint)
+			require.Equal(t, test.expected, formatDiagnostics(diagnostics))
+		})
+	}
+}
+
+func TestCompilationRegistryImports(t *testing.T) {
+	server := registrytest.NewServer()
+	defer server.Close()
+	server.PushModule("bicep/exports", "v1", []byte(exportsTemplate))
+
+	fsys := newTestFS(map[string]string{
+		"main.bicep": "import * as lib from 'br:" + server.Registry() + "/bicep/exports:v1'\nparam n lib.name = lib.location\n",
+	})
+	modules := newTestRegistry(t, server.Client())
+	require.NoError(t, Restore(context.Background(), fsys, "main.bicep", modules))
+
+	compilation, err := NewCompilation(fsys, "main.bicep", types.NewGenericResourceTypeProvider(), modules)
+	require.NoError(t, err)
+	require.Empty(t, compilation.GetDiagnostics(compilation.GetSourceFileGrouping().EntryPoint))
+}
+
+func TestCompilationEmitImports(t *testing.T) {
+	compilation, err := NewCompilation(newTestFS(map[string]string{
+		"main.bicep":       "import { tier, qualify } from './lib/names.bicep'\nparam level tier\noutput name string = qualify(level)\n",
+		"lib/names.bicep":  "import * as shared from 'shared.bicep'\n@export()\ntype tier = shared.level\n@export()\nfunc qualify(name string) string => '${shared.prefix()}-${name}'\n",
+		"lib/shared.bicep": "@export()\ntype level = 'basic' | 'premium'\n@export()\nfunc prefix() string => 'app'\n",
+	}), "main.bicep", types.NewGenericResourceTypeProvider(), newTestRegistry(t, http.DefaultClient))
+	require.NoError(t, err)
+
+	var template bytes.Buffer
+	require.NoError(t, compilation.Emit(&template, emit.EmitterOptions{}))
+	require.Contains(t, template.String(), `"$ref": "#/definitions/_1.level"`)
+	require.Contains(t, template.String(), `"value": "[format('{0}-{1}', __bicep._1.prefix(), parameters('name'))]"`)
+	require.Contains(t, template.String(), `"value": "[__bicep.qualify(parameters('level'))]"`)
+}
+
+func TestCompilationFileLoads(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		expected []string
+	}{
+		{"loaded files", map[string]string{
+			"main.bicep":   "var a = loadTextContent('files/a.txt')\nvar b = loadJsonContent('files/b.json', '$.items[0]')\nvar c = loadYamlContent('files/c.yaml', 'name')\n",
+			"files/a.txt":  "text",
+			"files/b.json": `{"items": [1]}`,
+			"files/c.yaml": "name: c\n",
+		}, nil},
+		{"missing file", map[string]string{
+			"main.bicep": "var a = loadTextContent('a.txt')\n",
+		}, []string{"[24:31] Error BCP091: An error occurred reading file. open a.txt: file does not exist"}},
+		{"absolute path", map[string]string{
+			"main.bicep": "var a = loadFileAsBase64('/a.txt')\n",
+		}, []string{"[25:33] Error BCP051: The specified path begins with \"/\". Files must be referenced using relative paths."}},
+		{"invalid json", map[string]string{
+			"main.bicep": "var a = loadJsonContent('a.json')\n",
+			"a.json":     "{",
+		}, []string{"[24:32] Error BCP186: Unable to parse literal JSON value. Please ensure that it is well-formed."}},
+		{"invalid yaml", map[string]string{
+			"main.bicep": "var a = loadYamlContent('a.yaml')\n",
+			"a.yaml":     "a: [",
+		}, []string{"[24:32] Error BCP340: Unable to parse literal YAML value. Please ensure that it is well-formed."}},
+		{"json path not found", map[string]string{
+			"main.bicep": "var a = loadJsonContent('a.json', '$.missing')\n",
+			"a.json":     "{}",
+		}, []string{"[34:45] Error BCP235: Specified JSONPath does not exist in the given file or is invalid."}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			compilation, err := NewCompilation(newTestFS(test.files), "main.bicep", types.NewGenericResourceTypeProvider(), newTestRegistry(t, http.DefaultClient))
+			require.NoError(t, err)
+			diagnostics := compilation.GetDiagnostics(compilation.GetSourceFileGrouping().EntryPoint)
+			require.Equal(t, test.expected, formatDiagnostics(diagnostics))
+		})
+	}
+}
+
+func TestCompilationEmitFileLoads(t *testing.T) {
+	compilation, err := NewCompilation(newTestFS(map[string]string{
+		"main.bicep":         "import { settings } from 'lib/settings.bicep'\nvar script = loadTextContent('script.sh')\nvar config = loadJsonContent('config.json')\noutput names array = loadYamlContent('names.yaml')\noutput tier string = settings.tier\n",
+		"script.sh":          "echo '[done]'\n",
+		"config.json":        `{"b": "[x]", "a": 1.5}`,
+		"names.yaml":         "- a\n- b\n",
+		"lib/settings.bicep": "@export()\nvar settings = loadJsonContent('settings.json')\n",
+		"lib/settings.json":  `{"tier": "basic"}`,
+	}), "main.bicep", types.NewGenericResourceTypeProvider(), newTestRegistry(t, http.DefaultClient))
+	require.NoError(t, err)
+
+	var template bytes.Buffer
+	require.NoError(t, compilation.Emit(&template, emit.EmitterOptions{}))
+	require.Contains(t, template.String(), `"variables": {
+    "$fxv#0": "echo '[done]'\n",
+    "$fxv#1": [
+      "a",
+      "b"
+    ],
+    "script": "[variables('$fxv#0')]",
+    "config": {
+      "b": "[[x]",
+      "a": 1.5
+    },
+    "settings": {
+      "tier": "basic"
+    }
+  }`)
+	require.Contains(t, template.String(), `"value": "[variables('$fxv#1')]"`)
+}
+
+func TestCompilationSourceMap(t *testing.T) {
+	files := map[string]string{
+		"main.bicep":        "module m './sub/storage.bicep' = {\n  name: 'm'\n  params: {\n    name: 'a'\n  }\n}\noutput id string = m.outputs.id\n",
+		"sub/storage.bicep": storageModule,
+	}
+	compilation, err := NewCompilation(newTestFS(files), "main.bicep", types.NewGenericResourceTypeProvider(), newTestRegistry(t, http.DefaultClient))
+	require.NoError(t, err)
+	var template bytes.Buffer
+	emitted, err := compilation.EmitWithSourceMap(&template, emit.EmitterOptions{})
+	require.NoError(t, err)
+	// lookups read the source map written alongside the template
+	data, err := json.Marshal(emitted)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"span":{"position":`)
+	var sourceMap emit.SourceMap
+	require.NoError(t, json.Unmarshal(data, &sourceMap))
+
+	tests := []struct {
+		target   string
+		file     string
+		expected string
+	}{
+		{"resources[0].properties.parameters.name.value", "main.bicep", "name: 'a'"},
+		{"$.resources[0].properties.template.outputs.id", "sub/storage.bicep", "output id string = name"},
+		{"resources[0]['properties'].template.parameters.size.defaultValue", "sub/storage.bicep", "param size int = 1"},
+		{"resources[0].properties.mode", "main.bicep", "module m './sub/storage.bicep' = {"},
+		{"outputs.id", "main.bicep", "output id string = m.outputs.id"},
+		{"23", "main.bicep", "name: 'a'"},
+		{"15", "main.bicep", "name: 'm'"},
+		{"contentVersion", "", "the path \"contentVersion\" is not in the source map"},
+		{"1", "", "the line 1 is not in the source map"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			entry, err := sourceMap.Lookup(tt.target)
+			if tt.file == "" {
+				require.EqualError(t, err, tt.expected)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.file, entry.File)
+			text := files[entry.File][entry.Span.Position : entry.Span.Position+entry.Span.Length]
+			require.Equal(t, tt.expected, strings.SplitN(text, "\n", 2)[0])
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package compiler
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/util"
+	"fmt"
+	"strings"
+)
+
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Diagnostics/DiagnosticBuilder.cs
+func filePathIsEmpty(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP050", "The specified path is empty.")
+}
+
+func filePathBeginsWithForwardSlash(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP051", "The specified path begins with \"/\". Files must be referenced using relative paths.")
+}
+
+func errorOccurredReadingFile(span *util.TextSpan, err error) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP091", fmt.Sprintf("An error occurred reading file. %s", err))
+}
+
+func filePathInterpolationUnsupported(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP092", "String interpolation is not supported in file paths.")
+}
+
+func filePathCouldNotBeResolved(span *util.TextSpan, filePath string, parentPath string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP093", fmt.Sprintf("File path \"%s\" could not be resolved relative to \"%s\".", filePath, parentPath))
+}
+
+func cyclicModuleSelfReference(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP094", "This module references itself, which is not allowed.")
+}
+
+func cyclicFile(span *util.TextSpan, cycle []string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP095", fmt.Sprintf("The file is involved in a cycle (\"%s\").", strings.Join(cycle, "\" -> \"")))
+}
+
+func filePathContainsBackSlash(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP098", "The specified file path contains a \"\\\" character. Use \"/\" instead as the directory separator character.")
+}
+
+func referencedModuleHasErrors(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP104", "The referenced module has errors.")
+}
@@ -0,0 +1,20 @@
+package compiler
+
+import (
+	"bicep-go/registry"
+	"context"
+	"io/fs"
+)
+
+// Restore downloads the registry modules referenced by the entry point and the files it references into the
+// cache of modules. Only failing to read the entry point is an error; modules that cannot be restored are
+// reported by the compilation.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Registry/ModuleDispatcher.cs
+func Restore(ctx context.Context, fsys fs.FS, entryPath string, modules *registry.OciModuleRegistry) error {
+	grouping, err := BuildSourceFileGrouping(fsys, entryPath, modules)
+	if err != nil {
+		return err
+	}
+	modules.Restore(ctx, grouping.ArtifactReferences)
+	return nil
+}
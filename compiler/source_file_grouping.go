@@ -0,0 +1,285 @@
+package compiler
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/parser"
+	"bicep-go/registry"
+	"bicep-go/semantics"
+	"bicep-go/syntax"
+	"bicep-go/util"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+const ARM_TEMPLATE_EXTENSION = ".json"
+
+// SourceFile is a parsed Bicep file or, if Template is set, a compiled ARM JSON template. Path is slash-separated
+// and relative to the root of the file system, or the fully qualified reference of modules restored from registries.
+type SourceFile struct {
+	Path             string
+	Program          *syntax.ProgramSyntax
+	ParseDiagnostics []*diagnostics.Diagnostic
+	// LineStarts are the offsets of the lines of a Bicep file, which positions of diagnostics are shown with.
+	LineStarts []int
+	Template   *semantics.ArmTemplateSemanticModel
+}
+
+// SourceFileGrouping is the set of files reachable from an entry point through module declarations and
+// import statements, which are both called artifact declarations here.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Workspaces/SourceFileGrouping.cs
+type SourceFileGrouping struct {
+	EntryPoint *SourceFile
+	// Files maps paths to the files of the grouping, including the entry point.
+	Files map[string]*SourceFile
+	// artifactFiles maps artifact declarations to the files they reference.
+	artifactFiles map[syntax.SyntaxBase]*SourceFile
+	// artifactFailures maps artifact declarations to the reason their file cannot be used.
+	artifactFailures map[syntax.SyntaxBase]*diagnostics.Diagnostic
+	// parents maps artifact declarations to the files declaring them.
+	parents map[syntax.SyntaxBase]*SourceFile
+	// ArtifactReferences lists the registry artifacts referenced by the files, once each.
+	ArtifactReferences []*registry.OciArtifactReference
+	fsys               fs.FS
+	modules            *registry.OciModuleRegistry
+}
+
+// BuildSourceFileGrouping reads and parses the entry point and every file it references, recursively.
+// Registry modules are read from the cache of modules. Only failing to read the entry point is an error;
+// problems with referenced files are reported on the artifact declarations.
+func BuildSourceFileGrouping(fsys fs.FS, entryPath string, modules *registry.OciModuleRegistry) (*SourceFileGrouping, error) {
+	g := &SourceFileGrouping{
+		fsys:             fsys,
+		modules:          modules,
+		Files:            map[string]*SourceFile{},
+		artifactFiles:    map[syntax.SyntaxBase]*SourceFile{},
+		artifactFailures: map[syntax.SyntaxBase]*diagnostics.Diagnostic{},
+		parents:          map[syntax.SyntaxBase]*SourceFile{},
+	}
+
+	entryPoint, err := readSourceFile(fsys, path.Clean(entryPath))
+	if err != nil {
+		return nil, err
+	}
+	g.EntryPoint = entryPoint
+	g.Files[entryPoint.Path] = entryPoint
+
+	pending := []*SourceFile{entryPoint}
+	for len(pending) > 0 {
+		file := pending[0]
+		pending = pending[1:]
+		for _, declaration := range getArtifactDeclarations(file.Program) {
+			g.parents[declaration] = file
+			target, diagnostic := g.resolveArtifactDeclaration(fsys, file, declaration)
+			if target == nil {
+				if diagnostic != nil {
+					g.artifactFailures[declaration] = diagnostic
+				}
+				continue
+			}
+			if _, ok := g.Files[target.Path]; !ok {
+				g.Files[target.Path] = target
+				pending = append(pending, target)
+			}
+			g.artifactFiles[declaration] = g.Files[target.Path]
+		}
+	}
+
+	g.detectCycles()
+	return g, nil
+}
+
+// TryGetModuleFile returns the file referenced by a module declaration, or the diagnostic explaining why it
+// cannot be used. Both are nil if the declaration has no path, which the parser reports.
+func (g *SourceFileGrouping) TryGetModuleFile(declaration *syntax.ModuleDeclarationSyntax) (*SourceFile, *diagnostics.Diagnostic) {
+	return g.tryGetArtifactFile(declaration)
+}
+
+// TryGetImportedFile is the equivalent of TryGetModuleFile for import statements.
+func (g *SourceFileGrouping) TryGetImportedFile(declaration *syntax.CompileTimeImportDeclarationSyntax) (*SourceFile, *diagnostics.Diagnostic) {
+	return g.tryGetArtifactFile(declaration)
+}
+
+func (g *SourceFileGrouping) tryGetArtifactFile(declaration syntax.SyntaxBase) (*SourceFile, *diagnostics.Diagnostic) {
+	if diagnostic, ok := g.artifactFailures[declaration]; ok {
+		return nil, diagnostic
+	}
+	return g.artifactFiles[declaration], nil
+}
+
+func (g *SourceFileGrouping) resolveArtifactDeclaration(fsys fs.FS, parent *SourceFile, declaration syntax.SyntaxBase) (*SourceFile, *diagnostics.Diagnostic) {
+	pathSyntax, ok := getArtifactPath(declaration).(*syntax.StringSyntax)
+	if !ok {
+		return nil, nil
+	}
+	span := pathSyntax.GetSpan()
+	modulePath, ok := pathSyntax.TryGetLiteralValue()
+	if !ok {
+		return nil, filePathInterpolationUnsupported(span)
+	}
+
+	if registry.IsOciArtifactReference(modulePath) {
+		return g.resolveArtifact(span, modulePath)
+	}
+
+	filePath, diagnostic := resolveRelativePath(fsys, span, parent.Path, modulePath)
+	if diagnostic != nil {
+		return nil, diagnostic
+	}
+	if file, ok := g.Files[filePath]; ok {
+		return file, nil
+	}
+	file, err := readSourceFile(fsys, filePath)
+	if err != nil {
+		return nil, errorOccurredReadingFile(span, err)
+	}
+	return file, nil
+}
+
+func (g *SourceFileGrouping) resolveArtifact(span *util.TextSpan, modulePath string) (*SourceFile, *diagnostics.Diagnostic) {
+	reference, diagnostic := g.modules.ParseReference(modulePath)
+	if diagnostic != nil {
+		diagnostic.Span = span
+		return nil, diagnostic
+	}
+	name := reference.FormatName()
+	if file, ok := g.Files[name]; ok {
+		return file, nil
+	}
+	if !g.hasArtifactReference(name) {
+		g.ArtifactReferences = append(g.ArtifactReferences, reference)
+	}
+
+	data, diagnostic := g.modules.TryReadTemplate(reference)
+	if diagnostic != nil {
+		diagnostic.Span = span
+		return nil, diagnostic
+	}
+	template, err := semantics.NewArmTemplateSemanticModel(data)
+	if err != nil {
+		return nil, errorOccurredReadingFile(span, err)
+	}
+	return &SourceFile{Path: name, Template: template}, nil
+}
+
+func (g *SourceFileGrouping) hasArtifactReference(name string) bool {
+	for _, reference := range g.ArtifactReferences {
+		if reference.FormatName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveRelativePath resolves the path of a file referenced from the parent file.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Modules/LocalModuleReference.cs
+func resolveRelativePath(fsys fs.FS, span *util.TextSpan, parentPath string, filePath string) (string, *diagnostics.Diagnostic) {
+	switch {
+	case filePath == "":
+		return "", filePathIsEmpty(span)
+	case strings.HasPrefix(filePath, "/"):
+		return "", filePathBeginsWithForwardSlash(span)
+	case strings.Contains(filePath, "\\"):
+		return "", filePathContainsBackSlash(span)
+	}
+
+	resolved := path.Join(path.Dir(parentPath), filePath)
+	if !fs.ValidPath(resolved) {
+		return "", filePathCouldNotBeResolved(span, filePath, util.GetDisplayPath(fsys, parentPath))
+	}
+	return resolved, nil
+}
+
+// detectCycles replaces the files of artifact declarations that lead back to the declaring file with a diagnostic.
+func (g *SourceFileGrouping) detectCycles() {
+	for declaration, target := range g.artifactFiles {
+		parent := g.parents[declaration]
+		span := getArtifactPath(declaration).GetSpan()
+		if target == parent {
+			g.artifactFailures[declaration] = cyclicModuleSelfReference(span)
+			continue
+		}
+		if cycle := g.findPath(target, parent, map[*SourceFile]bool{}); cycle != nil {
+			// the path ends at the parent, which is already the start of the cycle
+			names := []string{util.GetDisplayPath(g.fsys, parent.Path)}
+			for _, file := range cycle[:len(cycle)-1] {
+				names = append(names, util.GetDisplayPath(g.fsys, file.Path))
+			}
+			g.artifactFailures[declaration] = cyclicFile(span, names)
+		}
+	}
+}
+
+// findPath returns the files from source to target through artifact declarations, or nil if target is unreachable.
+func (g *SourceFileGrouping) findPath(source *SourceFile, target *SourceFile, visited map[*SourceFile]bool) []*SourceFile {
+	if source == target {
+		return []*SourceFile{source}
+	}
+	if visited[source] {
+		return nil
+	}
+	visited[source] = true
+
+	for _, declaration := range getArtifactDeclarations(source.Program) {
+		next, ok := g.artifactFiles[declaration]
+		if !ok {
+			continue
+		}
+		if rest := g.findPath(next, target, visited); rest != nil {
+			return append([]*SourceFile{source}, rest...)
+		}
+	}
+	return nil
+}
+
+// readSourceFile parses Bicep files, and files with a .json extension as ARM JSON templates.
+func readSourceFile(fsys fs.FS, filePath string) (*SourceFile, error) {
+	data, err := fs.ReadFile(fsys, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if path.Ext(filePath) == ARM_TEMPLATE_EXTENSION {
+		template, err := semantics.NewArmTemplateSemanticModel(data)
+		if err != nil {
+			return nil, err
+		}
+		return &SourceFile{Path: filePath, Template: template}, nil
+	}
+
+	text := string(data)
+	p := parser.New(text)
+	program := p.Program()
+	return &SourceFile{
+		Path:             filePath,
+		Program:          program,
+		ParseDiagnostics: p.GetDiagnostics(),
+		LineStarts:       util.ComputeLineStarts(text),
+	}, nil
+}
+
+// getArtifactDeclarations returns the module declarations and import statements of a program.
+func getArtifactDeclarations(program *syntax.ProgramSyntax) []syntax.SyntaxBase {
+	var declarations []syntax.SyntaxBase
+	if program == nil {
+		return nil
+	}
+	for _, child := range program.Children {
+		switch child.(type) {
+		case *syntax.ModuleDeclarationSyntax, *syntax.CompileTimeImportDeclarationSyntax:
+			declarations = append(declarations, child)
+		}
+	}
+	return declarations
+}
+
+// getArtifactPath returns the path syntax of a module declaration or import statement, which is nil for
+// import statements without a path.
+func getArtifactPath(declaration syntax.SyntaxBase) syntax.SyntaxBase {
+	switch declaration := declaration.(type) {
+	case *syntax.ModuleDeclarationSyntax:
+		return declaration.Path
+	case *syntax.CompileTimeImportDeclarationSyntax:
+		return declaration.TryGetPathSyntax()
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package compiler
+
+import (
+	"bicep-go/emit"
+	"bicep-go/semantics"
+	"io"
+)
+
+// EmitWithSourceMap writes the ARM template of the entry point like Emit, and returns its source map, with the
+// paths of the files the values were compiled from.
+func (c *Compilation) EmitWithSourceMap(out io.Writer, options emit.EmitterOptions) (*emit.SourceMap, error) {
+	if err := c.checkErrors(); err != nil {
+		return nil, err
+	}
+	sourceMap, err := emit.NewTemplateWriter(c.GetEntrypointSemanticModel(), options).WriteWithSourceMap(out)
+	if err != nil {
+		return nil, err
+	}
+
+	filePaths := map[*semantics.SemanticModel]string{}
+	for filePath, file := range c.grouping.Files {
+		if model, ok := c.models[file]; ok {
+			filePaths[model] = filePath
+		}
+	}
+	for _, entry := range sourceMap.Entries {
+		entry.File = filePaths[entry.Model]
+	}
+	return sourceMap, nil
+}
@@ -0,0 +1,77 @@
+package diagnostics
+
+import (
+	"bicep-go/util"
+	"fmt"
+	"sort"
+)
+
+type DiagnosticLevel int
+
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Diagnostics/DiagnosticLevel.cs
+const (
+	DiagnosticLevelOff DiagnosticLevel = iota
+	DiagnosticLevelInfo
+	DiagnosticLevelWarning
+	DiagnosticLevelError
+)
+
+var diagnosticLevelToText = map[DiagnosticLevel]string{
+	DiagnosticLevelOff:     "Off",
+	DiagnosticLevelInfo:    "Info",
+	DiagnosticLevelWarning: "Warning",
+	DiagnosticLevelError:   "Error",
+}
+
+func (l DiagnosticLevel) ToString() string {
+	return diagnosticLevelToText[l]
+}
+
+type Diagnostic struct {
+	Span    *util.TextSpan
+	Level   DiagnosticLevel
+	Code    string
+	Message string
+}
+
+func NewDiagnostic(span *util.TextSpan, level DiagnosticLevel, code string, message string) *Diagnostic {
+	return &Diagnostic{
+		Span:    span,
+		Level:   level,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func NewError(span *util.TextSpan, code string, message string) *Diagnostic {
+	return NewDiagnostic(span, DiagnosticLevelError, code, message)
+}
+
+func NewWarning(span *util.TextSpan, code string, message string) *Diagnostic {
+	return NewDiagnostic(span, DiagnosticLevelWarning, code, message)
+}
+
+func (d *Diagnostic) IsError() bool {
+	return d.Level == DiagnosticLevelError
+}
+
+func (d *Diagnostic) ToString() string {
+	return fmt.Sprintf("%s %s %s: %s", d.Span.ToString(), d.Level.ToString(), d.Code, d.Message)
+}
+
+// HasErrors reports whether any of the diagnostics is an error.
+func HasErrors(diagnostics []*Diagnostic) bool {
+	for _, diagnostic := range diagnostics {
+		if diagnostic.IsError() {
+			return true
+		}
+	}
+	return false
+}
+
+// Sort orders diagnostics by position, keeping the relative order of diagnostics on the same span.
+func Sort(diagnostics []*Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Span.Position < diagnostics[j].Span.Position
+	})
+}
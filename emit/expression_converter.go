@@ -0,0 +1,612 @@
+package emit
+
+import (
+	"bicep-go/namespaces"
+	"bicep-go/semantics"
+	"bicep-go/syntax"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// armExpression is an expression of the ARM template language, e.g. concat(parameters('a'), 'b').
+type armExpression interface {
+	writeTo(builder *strings.Builder)
+}
+
+// armString is a string literal, written with single quotes.
+type armString string
+
+// armInt is an integer literal.
+type armInt int64
+
+// armFunction is a function call, followed by property and index accesses on its result.
+type armFunction struct {
+	name      string
+	arguments []armExpression
+	// properties are the accessed property names (armString) and indexes, in order.
+	properties []armExpression
+}
+
+func newArmFunction(name string, arguments ...armExpression) *armFunction {
+	return &armFunction{name: name, arguments: arguments}
+}
+
+func (e armString) writeTo(builder *strings.Builder) {
+	builder.WriteString("'")
+	builder.WriteString(strings.ReplaceAll(string(e), "'", "''"))
+	builder.WriteString("'")
+}
+
+func (e armInt) writeTo(builder *strings.Builder) {
+	builder.WriteString(strconv.FormatInt(int64(e), 10))
+}
+
+var armIdentifierPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+func (e *armFunction) writeTo(builder *strings.Builder) {
+	builder.WriteString(e.name)
+	builder.WriteString("(")
+	for i, argument := range e.arguments {
+		if i > 0 {
+			builder.WriteString(", ")
+		}
+		argument.writeTo(builder)
+	}
+	builder.WriteString(")")
+
+	for _, property := range e.properties {
+		if name, ok := property.(armString); ok && armIdentifierPattern.MatchString(string(name)) {
+			builder.WriteString(".")
+			builder.WriteString(string(name))
+			continue
+		}
+		builder.WriteString("[")
+		property.writeTo(builder)
+		builder.WriteString("]")
+	}
+}
+
+// serializeExpression writes an expression as a template string value, e.g. [parameters('a')].
+func serializeExpression(expression armExpression) string {
+	var builder strings.Builder
+	builder.WriteString("[")
+	expression.writeTo(&builder)
+	builder.WriteString("]")
+	return builder.String()
+}
+
+// armExpressionToJson returns the JSON value of an expression, which is a plain value for literals.
+func armExpressionToJson(expression armExpression) any {
+	switch expression := expression.(type) {
+	case armString:
+		return escapeJsonString(string(expression))
+	case armInt:
+		return int64(expression)
+	}
+	return serializeExpression(expression)
+}
+
+// escapeJsonString doubles a leading '[' of a string value, as ARM would evaluate the string as an expression.
+func escapeJsonString(value string) string {
+	if strings.HasPrefix(value, "[") {
+		return "[" + value
+	}
+	return value
+}
+
+// ExpressionConverter converts Bicep expressions to ARM template values and expressions.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Emit/ExpressionConverter.cs
+type ExpressionConverter struct {
+	model *semantics.SemanticModel
+	// locals replaces the local variables of loops, e.g. the item variable of a resource loop by
+	// parameters('names')[copyIndex()].
+	locals map[*semantics.LocalVariableSymbol]armExpression
+	// symbolicNames refers to resources by their symbolic names, as in language version 2.0 templates.
+	symbolicNames bool
+	// instances are the indexes of the resource and module loops whose single instances are converted, e.g. the
+	// instance of storage[i] in storage[i].id.
+	instances map[semantics.DeclaredSymbol]armExpression
+	// imports names the declarations of imported files, which are inlined into the template.
+	imports *importClosure
+	// fileLoads names the variables holding the contents of files loaded at compile time.
+	fileLoads *fileLoads
+}
+
+func NewExpressionConverter(model *semantics.SemanticModel) *ExpressionConverter {
+	imports := newImportClosure(model)
+	return &ExpressionConverter{
+		model:     model,
+		locals:    map[*semantics.LocalVariableSymbol]armExpression{},
+		imports:   imports,
+		fileLoads: newFileLoads(model, imports),
+	}
+}
+
+// withModel returns a converter for the declarations of an imported file, which share the import closure of the
+// file being emitted.
+func (c *ExpressionConverter) withModel(model *semantics.SemanticModel) *ExpressionConverter {
+	return &ExpressionConverter{model: model, locals: map[*semantics.LocalVariableSymbol]armExpression{}, symbolicNames: c.symbolicNames, imports: c.imports, fileLoads: c.fileLoads}
+}
+
+// withLocals returns a converter that replaces the given local variables, in addition to those already replaced.
+func (c *ExpressionConverter) withLocals(locals map[*semantics.LocalVariableSymbol]armExpression) *ExpressionConverter {
+	combined := map[*semantics.LocalVariableSymbol]armExpression{}
+	for symbol, replacement := range c.locals {
+		combined[symbol] = replacement
+	}
+	for symbol, replacement := range locals {
+		combined[symbol] = replacement
+	}
+	return &ExpressionConverter{model: c.model, locals: combined, symbolicNames: c.symbolicNames, instances: c.instances, imports: c.imports, fileLoads: c.fileLoads}
+}
+
+// ConvertToJson converts an expression to a template value. Literals, arrays and objects are written as JSON,
+// other expressions as template expression strings. Properties whose value is a loop are written as entries of
+// the copy property of their object, which comes first.
+func (c *ExpressionConverter) ConvertToJson(expression syntax.SyntaxBase) (any, error) {
+	switch expression := expression.(type) {
+	case *syntax.ObjectSyntax:
+		var copies []any
+		object := newJsonObject()
+		for _, property := range expression.Properties() {
+			if loop, ok := property.Value.(*syntax.ForSyntax); ok {
+				name, ok := property.TryGetKeyText()
+				if !ok {
+					return nil, unsupportedExpression(property.Key, "loops in properties with interpolated names")
+				}
+				entry, err := c.convertPropertyCopy(name, loop)
+				if err != nil {
+					return nil, err
+				}
+				copies = append(copies, entry)
+				continue
+			}
+
+			name, err := c.convertPropertyKey(property)
+			if err != nil {
+				return nil, err
+			}
+			value, err := c.ConvertToJson(property.Value)
+			if err != nil {
+				return nil, err
+			}
+			object.set(name, value)
+		}
+		return prependCopies(copies, object), nil
+
+	case *syntax.ArraySyntax:
+		items := make([]any, 0, len(expression.Items))
+		for _, item := range expression.Items {
+			value, err := c.ConvertToJson(item.Value)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+
+	case *syntax.BooleanLiteralSyntax:
+		return expression.Value, nil
+
+	case *syntax.NullLiteralSyntax:
+		return nil, nil
+
+	case *syntax.ParenthesizedExpressionSyntax:
+		return c.ConvertToJson(expression.Expression)
+	}
+
+	if value, ok := getLoadedFileValue(c.model, expression); ok {
+		if _, ok := c.fileLoads.names[expression]; !ok {
+			return convertLoadedFileValue(value), nil
+		}
+	}
+
+	converted, err := c.ConvertExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	return armExpressionToJson(converted), nil
+}
+
+// convertPropertyKey returns the JSON name of an object property. Interpolated keys are expressions.
+func (c *ExpressionConverter) convertPropertyKey(property *syntax.ObjectPropertySyntax) (string, error) {
+	if name, ok := property.TryGetKeyText(); ok {
+		return escapeJsonString(name), nil
+	}
+	converted, err := c.ConvertExpression(property.Key)
+	if err != nil {
+		return "", err
+	}
+	return serializeExpression(converted), nil
+}
+
+// ConvertExpression converts an expression to an ARM template language expression.
+func (c *ExpressionConverter) ConvertExpression(expression syntax.SyntaxBase) (armExpression, error) {
+	switch expression := expression.(type) {
+	case *syntax.StringSyntax:
+		return c.convertString(expression)
+
+	case *syntax.IntegerLiteralSyntax:
+		if expression.Value > math.MaxInt64 {
+			return nil, fmt.Errorf("%s the integer %d is outside the range of int64", expression.GetSpan().ToString(), expression.Value)
+		}
+		return armInt(expression.Value), nil
+
+	case *syntax.BooleanLiteralSyntax:
+		return newArmFunction(strconv.FormatBool(expression.Value)), nil
+
+	case *syntax.NullLiteralSyntax:
+		return newArmFunction("null"), nil
+
+	case *syntax.ArraySyntax:
+		items := make([]armExpression, 0, len(expression.Items))
+		for _, item := range expression.Items {
+			value, err := c.ConvertExpression(item.Value)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return newArmFunction("createArray", items...), nil
+
+	case *syntax.ObjectSyntax:
+		var arguments []armExpression
+		for _, property := range expression.Properties() {
+			var key armExpression
+			if name, ok := property.TryGetKeyText(); ok {
+				key = armString(name)
+			} else {
+				converted, err := c.ConvertExpression(property.Key)
+				if err != nil {
+					return nil, err
+				}
+				key = converted
+			}
+			value, err := c.ConvertExpression(property.Value)
+			if err != nil {
+				return nil, err
+			}
+			arguments = append(arguments, key, value)
+		}
+		return newArmFunction("createObject", arguments...), nil
+
+	case *syntax.ParenthesizedExpressionSyntax:
+		return c.ConvertExpression(expression.Expression)
+
+	case *syntax.FunctionArgumentSyntax:
+		return c.ConvertExpression(expression.Expression)
+
+	case *syntax.NonNullAssertionSyntax:
+		return c.ConvertExpression(expression.BaseExpression)
+
+	case *syntax.VariableAccessSyntax:
+		return c.convertVariableAccess(expression)
+
+	case *syntax.ResourceAccessSyntax:
+		symbol, converter, err := c.tryGetDeclarationReference(expression)
+		if err != nil {
+			return nil, err
+		}
+		if resource, ok := symbol.(*semantics.ResourceSymbol); ok {
+			return converter.getReference(expression, resource, true)
+		}
+		return nil, unsupportedExpression(expression, "references to this symbol")
+
+	case *syntax.PropertyAccessSyntax:
+		if name, ok := c.imports.tryGetImportedName(c.model, expression); ok {
+			return newArmFunction("variables", armString(name)), nil
+		}
+		if c.isModuleOutputs(expression.BaseExpression) {
+			return c.convertModuleOutputAccess(expression.BaseExpression, armString(expression.PropertyName.IdentifierName()), expression.IsSafeAccess())
+		}
+		if !expression.IsSafeAccess() {
+			symbol, converter, err := c.tryGetDeclarationReference(expression.BaseExpression)
+			if err != nil {
+				return nil, err
+			}
+			switch symbol := symbol.(type) {
+			case *semantics.ResourceSymbol:
+				return converter.convertResourcePropertyAccess(expression, symbol)
+			case *semantics.ModuleSymbol:
+				return converter.convertModulePropertyAccess(expression, symbol)
+			}
+		}
+		return c.convertAccess(expression, expression.BaseExpression, armString(expression.PropertyName.IdentifierName()), expression.IsSafeAccess())
+
+	case *syntax.ArrayAccessSyntax:
+		if !expression.IsSafeAccess() {
+			symbol, converter, err := c.tryGetDeclarationReference(expression)
+			if err != nil {
+				return nil, err
+			}
+			switch symbol := symbol.(type) {
+			case *semantics.ResourceSymbol:
+				return converter.getReference(expression, symbol, true)
+			case *semantics.ModuleSymbol:
+				return nil, unsupportedExpression(expression, "references to modules")
+			}
+		}
+		index, err := c.ConvertExpression(expression.IndexExpression)
+		if err != nil {
+			return nil, err
+		}
+		if c.isModuleOutputs(expression.BaseExpression) {
+			return c.convertModuleOutputAccess(expression.BaseExpression, index, expression.IsSafeAccess())
+		}
+		return c.convertAccess(expression, expression.BaseExpression, index, expression.IsSafeAccess())
+
+	case *syntax.UnaryOperationSyntax:
+		return c.convertUnaryOperation(expression)
+
+	case *syntax.BinaryOperationSyntax:
+		return c.convertBinaryOperation(expression)
+
+	case *syntax.TernaryOperationSyntax:
+		return c.convertFunction("if", expression.ConditionExpression, expression.TrueExpression, expression.FalseExpression)
+
+	case *syntax.FunctionCallSyntax:
+		return c.convertFunctionCall(expression, expression.Arguments)
+
+	case *syntax.InstanceFunctionCallSyntax:
+		if name, ok := c.imports.tryGetImportedName(c.model, expression); ok {
+			return c.convertUserDefinedFunctionCall(name, expression.Arguments)
+		}
+		symbol, converter, err := c.tryGetDeclarationReference(expression.BaseExpression)
+		if err != nil {
+			return nil, err
+		}
+		if resource, ok := symbol.(*semantics.ResourceSymbol); ok {
+			return converter.convertResourceFunctionCall(expression, resource)
+		}
+		return c.convertFunctionCall(expression, expression.Arguments)
+
+	case *syntax.LambdaSyntax:
+		return c.convertLambda(expression)
+	}
+	return nil, unsupportedExpression(expression, "these expressions")
+}
+
+// convertString converts an interpolated string to a format() call, e.g. format('{0}-app', parameters('a')).
+func (c *ExpressionConverter) convertString(expression *syntax.StringSyntax) (armExpression, error) {
+	if value, ok := expression.TryGetLiteralValue(); ok {
+		return armString(value), nil
+	}
+
+	var format strings.Builder
+	arguments := []armExpression{nil}
+	for i, segment := range expression.SegmentValues {
+		format.WriteString(strings.NewReplacer("{", "{{", "}", "}}").Replace(segment))
+		if i >= len(expression.Expressions) {
+			continue
+		}
+		converted, err := c.ConvertExpression(expression.Expressions[i])
+		if err != nil {
+			return nil, err
+		}
+		format.WriteString("{" + strconv.Itoa(i) + "}")
+		arguments = append(arguments, converted)
+	}
+	arguments[0] = armString(format.String())
+	return newArmFunction("format", arguments...), nil
+}
+
+func (c *ExpressionConverter) convertVariableAccess(expression *syntax.VariableAccessSyntax) (armExpression, error) {
+	switch symbol := c.model.GetSymbol(expression).(type) {
+	case *semantics.ParameterSymbol:
+		return newArmFunction("parameters", armString(symbol.GetName())), nil
+	case *semantics.VariableSymbol:
+		return newArmFunction("variables", armString(c.imports.getName(symbol))), nil
+	case *semantics.ImportedSymbol:
+		if name, ok := c.imports.tryGetImportedName(c.model, expression); ok {
+			return newArmFunction("variables", armString(name)), nil
+		}
+		return nil, unsupportedExpression(expression, "references to symbols imported from ARM templates")
+	case *semantics.LocalVariableSymbol:
+		if replacement, ok := c.locals[symbol]; ok {
+			return replacement, nil
+		}
+		return nil, unsupportedExpression(expression, "references to local variables")
+	case *semantics.ResourceSymbol:
+		return c.getReference(expression, symbol, true)
+	case *semantics.ModuleSymbol:
+		return nil, unsupportedExpression(expression, "references to modules")
+	}
+	return nil, unsupportedExpression(expression, "references to this symbol")
+}
+
+// convertAccess appends a property name or index to the converted base expression. Safe accesses are converted
+// to tryGet() calls, e.g. tryGet(variables('a'), 'b'). Accesses chained after a safe access are added to the same
+// call, as the whole chain is null when a property is missing: a.?b.c is tryGet(variables('a'), 'b', 'c').
+func (c *ExpressionConverter) convertAccess(expression syntax.SyntaxBase, baseExpression syntax.SyntaxBase, property armExpression, safe bool) (armExpression, error) {
+	base, err := c.ConvertExpression(baseExpression)
+	if err != nil {
+		return nil, err
+	}
+	if isSafeAccessChain(baseExpression) {
+		function := *base.(*armFunction)
+		function.arguments = append(append([]armExpression{}, function.arguments...), property)
+		return &function, nil
+	}
+	if safe {
+		return newArmFunction("tryGet", base, property), nil
+	}
+	accessed, ok := appendProperty(base, property)
+	if !ok {
+		return nil, unsupportedExpression(expression, "accesses into literals")
+	}
+	return accessed, nil
+}
+
+// isSafeAccessChain reports whether an expression is a safe access, or an access chained after one.
+func isSafeAccessChain(expression syntax.SyntaxBase) bool {
+	switch expression := expression.(type) {
+	case *syntax.PropertyAccessSyntax:
+		return expression.IsSafeAccess() || isSafeAccessChain(expression.BaseExpression)
+	case *syntax.ArrayAccessSyntax:
+		return expression.IsSafeAccess() || isSafeAccessChain(expression.BaseExpression)
+	}
+	return false
+}
+
+// appendProperty returns an access of a property name or index on the result of a function call.
+func appendProperty(base armExpression, property armExpression) (armExpression, bool) {
+	function, ok := base.(*armFunction)
+	if !ok {
+		return nil, false
+	}
+	accessed := *function
+	accessed.properties = append(append([]armExpression{}, function.properties...), property)
+	return &accessed, true
+}
+
+func (c *ExpressionConverter) convertUnaryOperation(expression *syntax.UnaryOperationSyntax) (armExpression, error) {
+	if expression.Operator == syntax.UnaryOperatorMinus {
+		if literal, ok := expression.Expression.(*syntax.IntegerLiteralSyntax); ok {
+			if literal.Value == -math.MinInt64 {
+				return armInt(math.MinInt64), nil
+			}
+			if literal.Value <= math.MaxInt64 {
+				return armInt(-int64(literal.Value)), nil
+			}
+		}
+	}
+	operand, err := c.ConvertExpression(expression.Expression)
+	if err != nil {
+		return nil, err
+	}
+	if expression.Operator == syntax.UnaryOperatorMinus {
+		return newArmFunction("sub", armInt(0), operand), nil
+	}
+	return newArmFunction("not", operand), nil
+}
+
+var binaryOperatorFunctions = map[syntax.BinaryOperator]string{
+	syntax.BinaryOperatorLogicalOr:          "or",
+	syntax.BinaryOperatorLogicalAnd:         "and",
+	syntax.BinaryOperatorEquals:             "equals",
+	syntax.BinaryOperatorLessThan:           "less",
+	syntax.BinaryOperatorLessThanOrEqual:    "lessOrEquals",
+	syntax.BinaryOperatorGreaterThan:        "greater",
+	syntax.BinaryOperatorGreaterThanOrEqual: "greaterOrEquals",
+	syntax.BinaryOperatorAdd:                "add",
+	syntax.BinaryOperatorSubtract:           "sub",
+	syntax.BinaryOperatorMultiply:           "mul",
+	syntax.BinaryOperatorDivide:             "div",
+	syntax.BinaryOperatorModulo:             "mod",
+}
+
+func (c *ExpressionConverter) convertBinaryOperation(expression *syntax.BinaryOperationSyntax) (armExpression, error) {
+	if expression.Operator == syntax.BinaryOperatorCoalesce {
+		return c.convertCoalesce(expression)
+	}
+
+	left, err := c.ConvertExpression(expression.LeftExpression)
+	if err != nil {
+		return nil, err
+	}
+	right, err := c.ConvertExpression(expression.RightExpression)
+	if err != nil {
+		return nil, err
+	}
+
+	switch expression.Operator {
+	case syntax.BinaryOperatorNotEquals:
+		return newArmFunction("not", newArmFunction("equals", left, right)), nil
+	case syntax.BinaryOperatorEqualsInsensitive:
+		return newArmFunction("equals", newArmFunction("toLower", left), newArmFunction("toLower", right)), nil
+	case syntax.BinaryOperatorNotEqualsInsensitive:
+		return newArmFunction("not", newArmFunction("equals", newArmFunction("toLower", left), newArmFunction("toLower", right))), nil
+	}
+	return newArmFunction(binaryOperatorFunctions[expression.Operator], left, right), nil
+}
+
+// convertCoalesce converts a chain of ?? operators to a single coalesce() call.
+func (c *ExpressionConverter) convertCoalesce(expression *syntax.BinaryOperationSyntax) (armExpression, error) {
+	var operands []syntax.SyntaxBase
+	var collect func(operand syntax.SyntaxBase)
+	collect = func(operand syntax.SyntaxBase) {
+		if binary, ok := operand.(*syntax.BinaryOperationSyntax); ok && binary.Operator == syntax.BinaryOperatorCoalesce {
+			collect(binary.LeftExpression)
+			collect(binary.RightExpression)
+			return
+		}
+		operands = append(operands, operand)
+	}
+	collect(expression)
+	return c.convertFunction("coalesce", operands...)
+}
+
+func (c *ExpressionConverter) convertFunction(name string, arguments ...syntax.SyntaxBase) (armExpression, error) {
+	converted := make([]armExpression, 0, len(arguments))
+	for _, argument := range arguments {
+		value, err := c.ConvertExpression(argument)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, value)
+	}
+	return newArmFunction(name, converted...), nil
+}
+
+// convertFunctionCall converts a call to a function of the sys or az namespace, or to a user-defined function.
+// Namespace qualifiers of built-in functions are dropped, as the functions are built into ARM.
+func (c *ExpressionConverter) convertFunctionCall(call syntax.SyntaxBase, arguments []*syntax.FunctionArgumentSyntax) (armExpression, error) {
+	switch symbol := c.model.GetSymbol(call).(type) {
+	case *semantics.DeclaredFunctionSymbol:
+		return c.convertUserDefinedFunctionCall(c.imports.getName(symbol), arguments)
+	case *semantics.ImportedSymbol:
+		if name, ok := c.imports.tryGetImportedName(c.model, call); ok {
+			return c.convertUserDefinedFunctionCall(name, arguments)
+		}
+		return nil, unsupportedExpression(call, "calls to functions imported from ARM templates")
+	case *semantics.FunctionSymbol:
+		if symbol.Function.Overloads[0].HasFlag(namespaces.FunctionFlagsFileLoad) {
+			return c.convertFileLoad(call)
+		}
+		if symbol.Namespace.GetName() == namespaces.NAMESPACE_SYS && symbol.Function.Name == "any" && len(arguments) == 1 {
+			return c.ConvertExpression(arguments[0].Expression)
+		}
+		return c.convertFunction(symbol.GetName(), getArgumentExpressions(arguments)...)
+	}
+	return nil, unsupportedExpression(call, "calls to this function")
+}
+
+// convertUserDefinedFunctionCall converts a call to a user-defined function, which is called in its template
+// namespace, e.g. __bicep.name().
+func (c *ExpressionConverter) convertUserDefinedFunctionCall(name string, arguments []*syntax.FunctionArgumentSyntax) (armExpression, error) {
+	return c.convertFunction(USER_DEFINED_FUNCTIONS_NAMESPACE+"."+name, getArgumentExpressions(arguments)...)
+}
+
+func getArgumentExpressions(arguments []*syntax.FunctionArgumentSyntax) []syntax.SyntaxBase {
+	expressions := make([]syntax.SyntaxBase, 0, len(arguments))
+	for _, argument := range arguments {
+		expressions = append(expressions, argument.Expression)
+	}
+	return expressions
+}
+
+// convertLambda converts a lambda to a lambda() call, replacing its variables by lambdaVariables() calls, e.g.
+// x => x + 1 is lambda('x', add(lambdaVariables('x'), 1)).
+func (c *ExpressionConverter) convertLambda(expression *syntax.LambdaSyntax) (armExpression, error) {
+	var arguments []armExpression
+	locals := map[*semantics.LocalVariableSymbol]armExpression{}
+	for _, variable := range expression.GetLocalVariables() {
+		name := armString(variable.Name.IdentifierName())
+		arguments = append(arguments, name)
+		if symbol, ok := c.model.GetSymbol(variable).(*semantics.LocalVariableSymbol); ok {
+			locals[symbol] = newArmFunction("lambdaVariables", name)
+		}
+	}
+	body, err := c.withLocals(locals).ConvertExpression(expression.Body)
+	if err != nil {
+		return nil, err
+	}
+	return newArmFunction("lambda", append(arguments, body)...), nil
+}
+
+func unsupportedExpression(expression syntax.SyntaxBase, description string) error {
+	return fmt.Errorf("%s %s cannot be emitted yet", expression.GetSpan().ToString(), description)
+}
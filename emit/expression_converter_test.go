@@ -0,0 +1,156 @@
+package emit
+
+import (
+	"bicep-go/parser"
+	"bicep-go/semantics"
+	"bicep-go/types"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestModel checks a file that must not have errors.
+func newTestModel(t *testing.T, text string) *semantics.SemanticModel {
+	p := parser.New(text)
+	program := p.Program()
+	require.Empty(t, p.GetDiagnostics())
+	model := semantics.NewSemanticModel(program, types.NewGenericResourceTypeProvider(), nil)
+	for _, diagnostic := range model.GetDiagnostics() {
+		require.False(t, diagnostic.IsError(), diagnostic.ToString())
+	}
+	return model
+}
+
+func TestExpressionConverter(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"'abc'", `"abc"`},
+		{"'[abc]'", `"[[abc]"`},
+		{"'it\\'s'", `"it's"`},
+		{"42", `42`},
+		{"-1", `-1`},
+		{"-9223372036854775808", `-9223372036854775808`},
+		{"true", `true`},
+		{"null", `null`},
+		{"[]", `[]`},
+		{"{}", `{}`},
+		{"name", `"[parameters('name')]"`},
+		{"settings", `"[variables('settings')]"`},
+		{"'${name}-{app}'", `"[format('{0}-{{app}}', parameters('name'))]"`},
+		{"'it\\'s ${name}'", `"[format('it''s {0}', parameters('name'))]"`},
+		{"settings.size", `"[variables('settings').size]"`},
+		{"settings['odd-key']", `"[variables('settings')['odd-key']]"`},
+		{"sizes[count]", `"[variables('sizes')[parameters('count')]]"`},
+		{"!enabled", `"[not(parameters('enabled'))]"`},
+		{"-count", `"[sub(0, parameters('count'))]"`},
+		{"count + 1 * 2", `"[add(parameters('count'), mul(1, 2))]"`},
+		{"count / 2 - count % 2", `"[sub(div(parameters('count'), 2), mod(parameters('count'), 2))]"`},
+		{"name == 'a' && count >= 1", `"[and(equals(parameters('name'), 'a'), greaterOrEquals(parameters('count'), 1))]"`},
+		{"name != 'a' || count < 1", `"[or(not(equals(parameters('name'), 'a')), less(parameters('count'), 1))]"`},
+		{"name =~ 'A'", `"[equals(toLower(parameters('name')), toLower('A'))]"`},
+		{"name !~ 'A'", `"[not(equals(toLower(parameters('name')), toLower('A')))]"`},
+		{"count > 1 ? 'many' : 'one'", `"[if(greater(parameters('count'), 1), 'many', 'one')]"`},
+		{"enabled ? true : null", `"[if(parameters('enabled'), true(), null())]"`},
+		{"optional ?? name ?? 'x'", `"[coalesce(parameters('optional'), parameters('name'), 'x')]"`},
+		{"toUpper(name)", `"[toUpper(parameters('name'))]"`},
+		{"sys.length(sizes)", `"[length(variables('sizes'))]"`},
+		{"resourceGroup().location", `"[resourceGroup().location]"`},
+		{"az.subscription().subscriptionId", `"[subscription().subscriptionId]"`},
+		{"any(count)", `"[parameters('count')]"`},
+		{"concat([1], [count])", `"[concat(createArray(1), createArray(parameters('count')))]"`},
+		{"union(settings, { key: name })", `"[union(variables('settings'), createObject('key', parameters('name')))]"`},
+		{"(count)", `"[parameters('count')]"`},
+		{"optional!", `"[parameters('optional')]"`},
+		{"settings.?size", `"[tryGet(variables('settings'), 'size')]"`},
+		{"sizes[?count]", `"[tryGet(variables('sizes'), parameters('count'))]"`},
+		{"config.?a.b[0]", `"[tryGet(parameters('config'), 'a', 'b', 0)]"`},
+		{"(config.?a).b", `"[tryGet(parameters('config'), 'a').b]"`},
+		{"settings.?size ?? 0", `"[coalesce(tryGet(variables('settings'), 'size'), 0)]"`},
+		{"map(sizes, x => x * count)", `"[map(variables('sizes'), lambda('x', mul(lambdaVariables('x'), parameters('count'))))]"`},
+		{"reduce(sizes, 0, (sum, x) => sum + x)", `"[reduce(variables('sizes'), 0, lambda('sum', 'x', add(lambdaVariables('sum'), lambdaVariables('x'))))]"`},
+		{"double(count)", `"[__bicep.double(parameters('count'))]"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			text := "func double(x int) int => x * 2\nparam name string\nparam count int\nparam enabled bool\nparam optional string?\nparam config object\nvar settings = {\n  size: 1\n  'odd-key': 2\n}\nvar sizes = [1, 2]\nvar v = " + tt.expression + "\n"
+			model := newTestModel(t, text)
+
+			variables := model.Binder.GetFileSymbol().Variables
+			value, err := NewExpressionConverter(model).ConvertToJson(variables[len(variables)-1].Declaration.Value)
+			require.NoError(t, err)
+
+			var buffer bytes.Buffer
+			writeJson(&buffer, value, 0)
+			require.Equal(t, tt.expected, buffer.String())
+		})
+	}
+}
+
+func TestExpressionConverterUnsupported(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		expected   string
+	}{
+		{"unread file load", "loadTextContent('a.txt')", "[56:80] calls loading unread files cannot be emitted yet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := newTestModel(t, "var settings = {\n  size: 1\n}\nvar sizes = [1, 2]\nvar v = "+tt.expression+"\n")
+
+			variables := model.Binder.GetFileSymbol().Variables
+			_, err := NewExpressionConverter(model).ConvertToJson(variables[len(variables)-1].Declaration.Value)
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestExpressionConverterResourceReferences(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+		symbolic   string
+	}{
+		{"stg.id", `"[resourceId('Microsoft.Storage/storageAccounts', parameters('name'))]"`, `"[resourceId('Microsoft.Storage/storageAccounts', parameters('name'))]"`},
+		{"stg::blob.id", `"[resourceId('Microsoft.Storage/storageAccounts/blobServices', parameters('name'), 'default')]"`, `"[resourceId('Microsoft.Storage/storageAccounts/blobServices', parameters('name'), 'default')]"`},
+		{"stg.name", `"[parameters('name')]"`, `"[parameters('name')]"`},
+		{"stg::blob.name", `"[format('{0}/{1}', parameters('name'), 'default')]"`, `"[format('{0}/{1}', parameters('name'), 'default')]"`},
+		{"stg.type", `"Microsoft.Storage/storageAccounts"`, `"Microsoft.Storage/storageAccounts"`},
+		{"stg.apiVersion", `"2023-01-01"`, `"2023-01-01"`},
+		{"stg.properties.primaryEndpoints.blob", `"[reference(resourceId('Microsoft.Storage/storageAccounts', parameters('name')), '2023-01-01').primaryEndpoints.blob]"`, `"[reference('stg').primaryEndpoints.blob]"`},
+		{"stg.properties.?accessTier", `"[tryGet(reference(resourceId('Microsoft.Storage/storageAccounts', parameters('name')), '2023-01-01'), 'accessTier')]"`, `"[tryGet(reference('stg'), 'accessTier')]"`},
+		{"stg.location", `"[reference(resourceId('Microsoft.Storage/storageAccounts', parameters('name')), '2023-01-01', 'full').location]"`, `"[reference('stg', '2023-01-01', 'full').location]"`},
+		{"stg::blob.properties", `"[reference(resourceId('Microsoft.Storage/storageAccounts/blobServices', parameters('name'), 'default'), '2023-01-01')]"`, `"[reference('stg::blob')]"`},
+		{"stg", `"[reference(resourceId('Microsoft.Storage/storageAccounts', parameters('name')), '2023-01-01', 'full')]"`, `"[reference('stg', '2023-01-01', 'full')]"`},
+		{"lock.id", `"[extensionResourceId(resourceId('Microsoft.Storage/storageAccounts', parameters('name')), 'Microsoft.Authorization/locks', 'lock')]"`, `"[extensionResourceId(resourceId('Microsoft.Storage/storageAccounts', parameters('name')), 'Microsoft.Authorization/locks', 'lock')]"`},
+		{"stg.listKeys().keys[0].value", `"[listKeys(resourceId('Microsoft.Storage/storageAccounts', parameters('name')), '2023-01-01').keys[0].value]"`, `"[listKeys(resourceId('Microsoft.Storage/storageAccounts', parameters('name')), '2023-01-01').keys[0].value]"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			text := "param name string\nresource stg 'Microsoft.Storage/storageAccounts@2023-01-01' = {\n  name: name\n  resource blob 'blobServices' = {\n    name: 'default'\n  }\n}\nresource lock 'Microsoft.Authorization/locks@2020-05-01' = {\n  name: 'lock'\n  scope: stg\n}\nvar v = " + tt.expression + "\n"
+			model := newTestModel(t, text)
+			expression := model.Binder.GetFileSymbol().Variables[0].Declaration.Value
+
+			for _, symbolicNames := range []bool{false, true} {
+				converter := NewExpressionConverter(model)
+				converter.symbolicNames = symbolicNames
+				value, err := converter.ConvertToJson(expression)
+				require.NoError(t, err)
+
+				var buffer bytes.Buffer
+				writeJson(&buffer, value, 0)
+				if symbolicNames {
+					require.Equal(t, tt.symbolic, buffer.String())
+				} else {
+					require.Equal(t, tt.expected, buffer.String())
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package emit
+
+import (
+	"bicep-go/namespaces"
+	"bicep-go/semantics"
+	"bicep-go/syntax"
+	"bytes"
+	"encoding/json"
+	"strconv"
+)
+
+// FILE_LOAD_VARIABLE_PREFIX starts the names of the variables holding the contents of files loaded at compile time.
+const FILE_LOAD_VARIABLE_PREFIX = "$fxv#"
+
+// fileLoads holds the variables that the contents of files loaded at compile time are written to, so that a file is
+// written once however often it is referenced. The JSON and YAML files loaded as the whole value of a variable are
+// inlined into that variable instead.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Emit/EmitterContext.cs
+type fileLoads struct {
+	// names maps the calls loading files to the variables holding their values.
+	names map[syntax.SyntaxBase]string
+	// variables are the names and values of the variables, in order.
+	variables []*fileLoadVariable
+}
+
+type fileLoadVariable struct {
+	name  string
+	value any
+}
+
+// newFileLoads names the calls loading files in the variables, resources, modules and outputs of the file being
+// emitted and in the imported variables. Functions cannot refer to variables, so their calls are inlined.
+func newFileLoads(model *semantics.SemanticModel, imports *importClosure) *fileLoads {
+	l := &fileLoads{names: map[syntax.SyntaxBase]string{}}
+	for _, statement := range model.Binder.GetFileSymbol().Program.Children {
+		if _, ok := statement.(*syntax.FunctionDeclarationSyntax); !ok {
+			l.addCalls(model, statement)
+		}
+	}
+	for _, imported := range imports.declarations {
+		if variable, ok := imported.symbol.(*semantics.VariableSymbol); ok {
+			l.addCalls(imported.model, variable.Declaration)
+		}
+	}
+	return l
+}
+
+func (l *fileLoads) addCalls(model *semantics.SemanticModel, node syntax.SyntaxBase) {
+	var inlined syntax.SyntaxBase
+	if variable, ok := node.(*syntax.VariableDeclarationSyntax); ok {
+		inlined = variable.Value
+	}
+	syntax.Inspect(node, func(node syntax.SyntaxBase) bool {
+		if node == nil {
+			return false
+		}
+		value, ok := getLoadedFileValue(model, node)
+		if !ok {
+			return true
+		}
+		if _, ok := value.(json.RawMessage); ok && node == inlined {
+			return false
+		}
+		name := FILE_LOAD_VARIABLE_PREFIX + strconv.Itoa(len(l.variables))
+		l.names[node] = name
+		l.variables = append(l.variables, &fileLoadVariable{name: name, value: convertLoadedFileValue(value)})
+		return false
+	})
+}
+
+// getLoadedFileValue returns the value of a call loading a file, or false if node is not such a call.
+func getLoadedFileValue(model *semantics.SemanticModel, node syntax.SyntaxBase) (any, bool) {
+	switch node.(type) {
+	case *syntax.FunctionCallSyntax, *syntax.InstanceFunctionCallSyntax:
+	default:
+		return nil, false
+	}
+	symbol, ok := model.GetSymbol(node).(*semantics.FunctionSymbol)
+	if !ok || !symbol.Function.Overloads[0].HasFlag(namespaces.FunctionFlagsFileLoad) {
+		return nil, false
+	}
+	return model.GetLoadedFileValue(node)
+}
+
+// convertLoadedFileValue converts the value of a call loading a file to a template value, keeping the order of the
+// properties of JSON objects.
+func convertLoadedFileValue(value any) any {
+	data, ok := value.(json.RawMessage)
+	if !ok {
+		return escapeJsonString(value.(string))
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	converted, _ := decodeJsonValue(decoder)
+	return converted
+}
+
+func decodeJsonValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token := token.(type) {
+	case json.Delim:
+		if token == '[' {
+			items := []any{}
+			for decoder.More() {
+				item, err := decodeJsonValue(decoder)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			_, err := decoder.Token()
+			return items, err
+		}
+		object := newJsonObject()
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJsonValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.set(key.(string), value)
+		}
+		_, err := decoder.Token()
+		return object, err
+	case json.Number:
+		if value, err := token.Int64(); err == nil {
+			return value, nil
+		}
+		return json.RawMessage(token), nil
+	case string:
+		return escapeJsonString(token), nil
+	}
+	return token, nil
+}
+
+// convertFileLoad converts a call loading a file to a reference to the variable holding its value, or to the value
+// itself in functions.
+func (c *ExpressionConverter) convertFileLoad(call syntax.SyntaxBase) (armExpression, error) {
+	if name, ok := c.fileLoads.names[call]; ok {
+		return newArmFunction("variables", armString(name)), nil
+	}
+	value, ok := c.model.GetLoadedFileValue(call)
+	if !ok {
+		return nil, unsupportedExpression(call, "calls loading unread files")
+	}
+	if data, ok := value.(json.RawMessage); ok {
+		return newArmFunction("json", armString(data)), nil
+	}
+	return armString(value.(string)), nil
+}
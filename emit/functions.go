@@ -0,0 +1,94 @@
+package emit
+
+import (
+	"bicep-go/semantics"
+	"bicep-go/syntax"
+	"bicep-go/types"
+)
+
+// USER_DEFINED_FUNCTIONS_NAMESPACE is the template namespace of user-defined functions, which are called as
+// __bicep.name(...).
+const USER_DEFINED_FUNCTIONS_NAMESPACE = "__bicep"
+
+// buildFunctions creates the functions of the template: a single namespace holding the user-defined functions
+// of the file and of imported files, or nil if there are none.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Emit/TemplateWriter.cs
+func (w *TemplateWriter) buildFunctions(declarations []*inlinedDeclaration) ([]any, error) {
+	members := newJsonObject()
+	for _, declaration := range declarations {
+		function, ok := declaration.symbol.(*semantics.DeclaredFunctionSymbol)
+		if !ok {
+			continue
+		}
+		value, err := declaration.writer.buildFunction(function.Declaration)
+		if err != nil {
+			return nil, err
+		}
+		members.set(declaration.name, newSourcedValue(declaration.writer.model, function.Declaration, value))
+	}
+	if members.isEmpty() {
+		return nil, nil
+	}
+
+	namespace := newJsonObject()
+	namespace.set("namespace", USER_DEFINED_FUNCTIONS_NAMESPACE)
+	namespace.set("members", members)
+	return []any{namespace}, nil
+}
+
+// buildFunction creates a template function from the typed lambda of a user-defined function. The parameters
+// of the lambda are referenced with parameters() calls in its body.
+func (w *TemplateWriter) buildFunction(declaration *syntax.FunctionDeclarationSyntax) (*jsonObject, error) {
+	lambda := declaration.TryGetLambda()
+	if lambda == nil {
+		return nil, unsupportedExpression(declaration.Lambda, "functions without a body")
+	}
+
+	parameters := []any{}
+	locals := map[*semantics.LocalVariableSymbol]armExpression{}
+	for _, variable := range lambda.GetLocalVariables() {
+		name := variable.Name.IdentifierName()
+		parameter, err := w.buildFunctionValueType(variable.Type)
+		if err != nil {
+			return nil, err
+		}
+		parameter.set("name", name)
+		parameters = append(parameters, parameter)
+		if symbol, ok := w.model.GetSymbol(variable).(*semantics.LocalVariableSymbol); ok {
+			locals[symbol] = newArmFunction("parameters", armString(name))
+		}
+	}
+
+	output, err := w.buildFunctionValueType(lambda.ReturnType)
+	if err != nil {
+		return nil, err
+	}
+	value, err := w.converter.withLocals(locals).ConvertToJson(lambda.Body)
+	if err != nil {
+		return nil, err
+	}
+	output.set("value", value)
+
+	object := newJsonObject()
+	object.set("parameters", parameters)
+	object.set("output", output)
+	if err := w.addDecorators(declaration, object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+// buildFunctionValueType creates the type of a function parameter or output: its type schema in language
+// version 2.0 templates, and its template type otherwise, like parameters.
+func (w *TemplateWriter) buildFunctionValueType(typeSyntax syntax.SyntaxBase) (*jsonObject, error) {
+	if w.symbolicNames {
+		return w.buildTypeSchema(typeSyntax)
+	}
+	declaredType := w.model.GetDeclaredType(typeSyntax)
+	object := newJsonObject()
+	object.set("type", getArmTypeName(declaredType, false))
+	if types.IsNullable(declaredType) {
+		object.set("nullable", true)
+	}
+	return object, nil
+}
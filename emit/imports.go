@@ -0,0 +1,203 @@
+package emit
+
+import (
+	"bicep-go/semantics"
+	"bicep-go/syntax"
+	"strconv"
+)
+
+// importClosure holds the declarations of other files that the imports of a file refer to, and the declarations
+// those refer to in turn, all of which are inlined into the template of the file. Declarations imported by name
+// keep the names they are imported with; the others are named after the index of their file, e.g. _1.settings.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Emit/CompileTimeImports/ImportClosureInfo.cs
+type importClosure struct {
+	// model is the file being emitted.
+	model *semantics.SemanticModel
+	// declarations are the imported declarations in the order they were found.
+	declarations []*importedDeclaration
+	names        map[semantics.DeclaredSymbol]string
+	// sources are the indexes of the imported files.
+	sources map[*semantics.SemanticModel]int
+}
+
+// importedDeclaration is a type alias, variable or function declared in an imported file.
+type importedDeclaration struct {
+	model  *semantics.SemanticModel
+	symbol semantics.DeclaredSymbol
+	name   string
+}
+
+func newImportClosure(model *semantics.SemanticModel) *importClosure {
+	c := &importClosure{
+		model:   model,
+		names:   map[semantics.DeclaredSymbol]string{},
+		sources: map[*semantics.SemanticModel]int{},
+	}
+	for _, imported := range model.Binder.GetFileSymbol().Imports {
+		if source, symbol := resolveImport(model, imported.Item); symbol != nil {
+			c.add(source, symbol, imported.GetName())
+		}
+	}
+
+	c.addReferences(model, model.Binder.GetFileSymbol().Program)
+	for i := 0; i < len(c.declarations); i++ {
+		declaration := c.declarations[i]
+		c.addReferences(declaration.model, declaration.symbol.GetDeclaringSyntax())
+	}
+	return c
+}
+
+// addReferences adds the imported declarations a node refers to. References to declarations of imported files
+// add those declarations too.
+func (c *importClosure) addReferences(model *semantics.SemanticModel, node syntax.SyntaxBase) {
+	syntax.Inspect(node, func(node syntax.SyntaxBase) bool {
+		if node == nil {
+			return false
+		}
+		if source, symbol := resolveImport(model, node); symbol != nil {
+			c.add(source, symbol, "")
+			return true
+		}
+		if model == c.model {
+			return true
+		}
+		switch symbol := model.GetSymbol(node).(type) {
+		case *semantics.TypeAliasSymbol, *semantics.VariableSymbol, *semantics.DeclaredFunctionSymbol:
+			c.add(model, symbol.(semantics.DeclaredSymbol), "")
+		}
+		return true
+	})
+}
+
+// add adds a declaration of an imported file, unless it is already part of the closure. Without a name, the
+// declaration is named after the index of its file.
+func (c *importClosure) add(model *semantics.SemanticModel, symbol semantics.DeclaredSymbol, name string) {
+	if _, ok := c.names[symbol]; ok {
+		return
+	}
+	if name == "" {
+		index, ok := c.sources[model]
+		if !ok {
+			index = len(c.sources) + 1
+			c.sources[model] = index
+		}
+		name = "_" + strconv.Itoa(index) + "." + symbol.GetName()
+	}
+	c.names[symbol] = name
+	c.declarations = append(c.declarations, &importedDeclaration{model: model, symbol: symbol, name: name})
+}
+
+// getName returns the name a type alias, variable or function of the file being emitted or of an imported file
+// is emitted with.
+func (c *importClosure) getName(symbol semantics.DeclaredSymbol) string {
+	if name, ok := c.names[symbol]; ok {
+		return name
+	}
+	return symbol.GetName()
+}
+
+// tryGetImportedName returns the name of the imported declaration a node refers to, or false if the node is not a
+// reference to an imported symbol or a member of a wildcard import.
+func (c *importClosure) tryGetImportedName(model *semantics.SemanticModel, node syntax.SyntaxBase) (string, bool) {
+	_, symbol := resolveImport(model, node)
+	if symbol == nil {
+		return "", false
+	}
+	name, ok := c.names[symbol]
+	return name, ok
+}
+
+// isImported reports whether a model is an imported file rather than the file being emitted.
+func (c *importClosure) isImported(model *semantics.SemanticModel) bool {
+	return model != c.model
+}
+
+// resolveImport returns the declaration referenced by a symbol imported by name, e.g. settings or its import
+// item, or by an access to a member of a wildcard import, e.g. lib.settings, lib.format() or the type lib.config.
+// The declaration is nil for other nodes, and for imports of ARM JSON templates, which cannot be inlined.
+func resolveImport(model *semantics.SemanticModel, node syntax.SyntaxBase) (*semantics.SemanticModel, semantics.DeclaredSymbol) {
+	var declaration *syntax.CompileTimeImportDeclarationSyntax
+	var name string
+	switch node := node.(type) {
+	case *syntax.PropertyAccessSyntax:
+		declaration, name = tryGetWildcardMember(model, node.BaseExpression, node.PropertyName)
+	case *syntax.TypePropertyAccessSyntax:
+		declaration, name = tryGetWildcardMember(model, node.BaseExpression, node.PropertyName)
+	case *syntax.InstanceFunctionCallSyntax:
+		declaration, name = tryGetWildcardMember(model, node.BaseExpression, node.Name)
+	default:
+		if imported, ok := model.GetSymbol(node).(*semantics.ImportedSymbol); ok {
+			declaration, name = imported.Declaration, imported.GetOriginalSymbolName()
+		}
+	}
+	if declaration == nil {
+		return nil, nil
+	}
+
+	source, ok := model.GetImportedModel(declaration).(*semantics.SemanticModel)
+	if !ok {
+		return nil, nil
+	}
+	for _, symbol := range source.Binder.GetFileSymbol().Declarations {
+		switch symbol.(type) {
+		case *semantics.TypeAliasSymbol, *semantics.VariableSymbol, *semantics.DeclaredFunctionSymbol:
+			if symbol.GetName() == name {
+				return source, symbol
+			}
+		}
+	}
+	return nil, nil
+}
+
+// tryGetWildcardMember returns the import statement and the member name of an access to a member of a wildcard
+// import, e.g. lib.settings.
+func tryGetWildcardMember(model *semantics.SemanticModel, baseExpression syntax.SyntaxBase, member *syntax.IdentifierSyntax) (*syntax.CompileTimeImportDeclarationSyntax, string) {
+	wildcard, ok := model.GetSymbol(baseExpression).(*semantics.WildcardImportSymbol)
+	if !ok || !member.IsValid() {
+		return nil, ""
+	}
+	return wildcard.Declaration, member.IdentifierName()
+}
+
+// inlinedDeclaration is a type alias, variable or function written into the template, with the writer of the
+// file declaring it and the name it is written with.
+type inlinedDeclaration struct {
+	writer *TemplateWriter
+	symbol semantics.DeclaredSymbol
+	name   string
+}
+
+// getInlinedDeclarations returns the type aliases, variables and functions of the file, followed by those of
+// imported files.
+func (w *TemplateWriter) getInlinedDeclarations() []*inlinedDeclaration {
+	var declarations []*inlinedDeclaration
+	for _, symbol := range w.model.Binder.GetFileSymbol().Declarations {
+		switch symbol.(type) {
+		case *semantics.TypeAliasSymbol, *semantics.VariableSymbol, *semantics.DeclaredFunctionSymbol:
+			declarations = append(declarations, &inlinedDeclaration{writer: w, symbol: symbol, name: symbol.GetName()})
+		}
+	}
+
+	writers := map[*semantics.SemanticModel]*TemplateWriter{}
+	for _, imported := range w.converter.imports.declarations {
+		writer, ok := writers[imported.model]
+		if !ok {
+			writer = w.withModel(imported.model)
+			writers[imported.model] = writer
+		}
+		declarations = append(declarations, &inlinedDeclaration{writer: writer, symbol: imported.symbol, name: imported.name})
+	}
+	return declarations
+}
+
+// withModel returns a writer for the declarations of an imported file, which share the import closure of the
+// file being emitted.
+func (w *TemplateWriter) withModel(model *semantics.SemanticModel) *TemplateWriter {
+	return &TemplateWriter{
+		model:         model,
+		converter:     w.converter.withModel(model),
+		dependencies:  model.GetDependencyGraph(),
+		symbolicNames: w.symbolicNames,
+		options:       w.options,
+	}
+}
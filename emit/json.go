@@ -0,0 +1,198 @@
+package emit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// jsonObject is a JSON object that keeps its properties in the order they were set, as templates list
+// declarations in source order. Values are nil, bool, int64, string, []any, *jsonObject, json.RawMessage, which is
+// written as it is, reindented, and *sourcedValue.
+type jsonObject struct {
+	names  []string
+	values map[string]any
+}
+
+func newJsonObject() *jsonObject {
+	return &jsonObject{values: map[string]any{}}
+}
+
+// set adds a property, or replaces the value of an existing property in place.
+func (o *jsonObject) set(name string, value any) {
+	if _, ok := o.values[name]; !ok {
+		o.names = append(o.names, name)
+	}
+	o.values[name] = value
+}
+
+func (o *jsonObject) get(name string) any {
+	return o.values[name]
+}
+
+func (o *jsonObject) isEmpty() bool {
+	return len(o.names) == 0
+}
+
+// writeJson formats a value with two-space indentation, writing empty objects and arrays on one line.
+func writeJson(buffer *bytes.Buffer, value any, indent int) {
+	writer := &jsonWriter{buffer: buffer, line: 1}
+	writer.write(value, indent, "")
+}
+
+// writeMinifiedJson formats a value without whitespace, like the template hash is computed from.
+func writeMinifiedJson(buffer *bytes.Buffer, value any) {
+	writer := &jsonWriter{buffer: buffer, line: 1, minified: true}
+	writer.write(value, 0, "")
+}
+
+// jsonWriter writes JSON values, recording the JSON paths and lines of the values emitted from Bicep syntax in
+// the source map if there is one.
+type jsonWriter struct {
+	buffer    *bytes.Buffer
+	sourceMap *SourceMap
+	// line is the one-based line being written.
+	line     int
+	minified bool
+}
+
+func (w *jsonWriter) write(value any, indent int, path string) {
+	switch value := value.(type) {
+	case nil:
+		w.buffer.WriteString("null")
+	case bool:
+		w.buffer.WriteString(strconv.FormatBool(value))
+	case int64:
+		w.buffer.WriteString(strconv.FormatInt(value, 10))
+	case string:
+		writeJsonString(w.buffer, value)
+	case []any:
+		if len(value) == 0 {
+			w.buffer.WriteString("[]")
+			return
+		}
+		w.buffer.WriteString("[")
+		for i, item := range value {
+			if i > 0 {
+				w.buffer.WriteString(",")
+			}
+			w.writeNewLine(indent + 1)
+			w.write(item, indent+1, path+"["+strconv.Itoa(i)+"]")
+		}
+		w.writeNewLine(indent)
+		w.buffer.WriteString("]")
+	case json.RawMessage:
+		if w.minified {
+			var compacted bytes.Buffer
+			if err := json.Compact(&compacted, value); err != nil {
+				compacted.Reset()
+				compacted.Write(value)
+			}
+			w.buffer.Write(compacted.Bytes())
+			return
+		}
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, bytes.TrimSpace(value), strings.Repeat("  ", indent), "  "); err != nil {
+			indented.Reset()
+			indented.Write(value)
+		}
+		w.buffer.Write(indented.Bytes())
+		w.line += bytes.Count(indented.Bytes(), []byte("\n"))
+	case *jsonObject:
+		if value.isEmpty() {
+			w.buffer.WriteString("{}")
+			return
+		}
+		w.buffer.WriteString("{")
+		for i, name := range value.names {
+			if i > 0 {
+				w.buffer.WriteString(",")
+			}
+			w.writeNewLine(indent + 1)
+			writeJsonString(w.buffer, name)
+			if w.minified {
+				w.buffer.WriteString(":")
+			} else {
+				w.buffer.WriteString(": ")
+			}
+			w.write(value.values[name], indent+1, appendJsonPath(path, name))
+		}
+		w.writeNewLine(indent)
+		w.buffer.WriteString("}")
+	case *sourcedValue:
+		startLine := w.line
+		w.write(value.value, indent, path)
+		if w.sourceMap != nil {
+			w.sourceMap.Entries = append(w.sourceMap.Entries, &SourceMapEntry{
+				Path:      path,
+				StartLine: startLine,
+				EndLine:   w.line,
+				Model:     value.model,
+				Span:      value.span,
+			})
+		}
+	default:
+		panic(fmt.Sprintf("unexpected JSON value of type %T", value))
+	}
+}
+
+func (w *jsonWriter) writeNewLine(indent int) {
+	if w.minified {
+		return
+	}
+	w.buffer.WriteString("\n")
+	for i := 0; i < indent; i++ {
+		w.buffer.WriteString("  ")
+	}
+	w.line++
+}
+
+// appendJsonPath returns the path of a property of the value at path, e.g. resources[0].properties, quoting
+// names that are not identifiers, e.g. resources['vnet::subnet'].
+func appendJsonPath(path string, name string) string {
+	if !armIdentifierPattern.MatchString(name) {
+		return path + "['" + strings.ReplaceAll(name, "'", "''") + "']"
+	}
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// writeJsonString quotes a string like Json.NET, which ARM and the Bicep CLI use: control characters, quotes,
+// backslashes and the Unicode line terminators are escaped, and everything else, including HTML characters, is
+// written as it is.
+func writeJsonString(buffer *bytes.Buffer, value string) {
+	const hex = "0123456789abcdef"
+	buffer.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"':
+			buffer.WriteString(`\"`)
+		case '\\':
+			buffer.WriteString(`\\`)
+		case '\n':
+			buffer.WriteString(`\n`)
+		case '\r':
+			buffer.WriteString(`\r`)
+		case '\t':
+			buffer.WriteString(`\t`)
+		case '\b':
+			buffer.WriteString(`\b`)
+		case '\f':
+			buffer.WriteString(`\f`)
+		default:
+			if r < 0x20 || r == '\u0085' || r == '\u2028' || r == '\u2029' {
+				buffer.WriteString(`\u`)
+				for shift := 12; shift >= 0; shift -= 4 {
+					buffer.WriteByte(hex[r>>shift&0xf])
+				}
+				continue
+			}
+			buffer.WriteRune(r)
+		}
+	}
+	buffer.WriteByte('"')
+}
@@ -0,0 +1,158 @@
+package emit
+
+import (
+	"bicep-go/semantics"
+	"bicep-go/syntax"
+)
+
+// copyIndex returns the index of the current iteration of a copy loop. Resource, module and output loops are
+// unnamed; property and variable loops are named after their property.
+func copyIndex(name string) armExpression {
+	if name == "" {
+		return newArmFunction("copyIndex")
+	}
+	return newArmFunction("copyIndex", armString(name))
+}
+
+// convertLoop converts the items of a loop to its number of iterations, e.g. length(parameters('names')), and
+// returns a converter replacing the item and index variables of the loop by their values at the given index, e.g.
+// parameters('names')[copyIndex()]. Loops over range() are converted like any other array, e.g. to
+// range(0, 3)[copyIndex()].
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Emit/ExpressionConverter.cs
+func (c *ExpressionConverter) convertLoop(loop *syntax.ForSyntax, index armExpression) (armExpression, *ExpressionConverter, error) {
+	items, err := c.ConvertExpression(loop.Expression)
+	if err != nil {
+		return nil, nil, err
+	}
+	item, ok := appendProperty(items, index)
+	if !ok {
+		return nil, nil, unsupportedExpression(loop.Expression, "loops over literals")
+	}
+
+	locals := map[*semantics.LocalVariableSymbol]armExpression{}
+	if variable := loop.ItemVariable(); variable != nil {
+		if symbol, ok := c.model.GetSymbol(variable).(*semantics.LocalVariableSymbol); ok {
+			locals[symbol] = item
+		}
+	}
+	if variable := loop.IndexVariable(); variable != nil {
+		if symbol, ok := c.model.GetSymbol(variable).(*semantics.LocalVariableSymbol); ok {
+			locals[symbol] = index
+		}
+	}
+	return newArmFunction("length", items), c.withLocals(locals), nil
+}
+
+// convertPropertyCopy converts a loop producing the value of a property or variable to an entry of a copy
+// property, e.g. {"name": "subnets", "count": "[length(parameters('subnets'))]", "input": {...}}.
+func (c *ExpressionConverter) convertPropertyCopy(name string, loop *syntax.ForSyntax) (*jsonObject, error) {
+	if _, ok := loop.Body.(*syntax.IfConditionSyntax); ok {
+		return nil, unsupportedExpression(loop, "filtered property loops")
+	}
+	count, converter, err := c.convertLoop(loop, copyIndex(name))
+	if err != nil {
+		return nil, err
+	}
+	input, err := converter.ConvertToJson(loop.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := newJsonObject()
+	entry.set("name", name)
+	entry.set("count", armExpressionToJson(count))
+	entry.set("input", input)
+	return entry, nil
+}
+
+// prependCopies returns the object with a copy property listing the given entries first, or the object itself if
+// there are none.
+func prependCopies(copies []any, object *jsonObject) *jsonObject {
+	if len(copies) == 0 {
+		return object
+	}
+	withCopies := newJsonObject()
+	withCopies.set(syntax.LOOP_IDENTIFIER_COPY, copies)
+	for _, name := range object.names {
+		withCopies.set(name, object.get(name))
+	}
+	return withCopies
+}
+
+// withLoopInstance returns a converter for a single instance of a resource or module loop, e.g. storage[i], which
+// replaces the variables of the loop by their values at the given index. The instances of resources nested in a
+// resource loop are those of the loop.
+func (c *ExpressionConverter) withLoopInstance(symbol semantics.DeclaredSymbol, index armExpression) (*ExpressionConverter, error) {
+	owner := getLoopOwner(symbol)
+	_, converter, err := c.convertLoop(getDeclarationLoop(owner), index)
+	if err != nil {
+		return nil, err
+	}
+	converter.instances = c.withInstance(owner, index)
+	return converter, nil
+}
+
+// withInstance returns the instances of the converter with the instance of the given loop replaced.
+func (c *ExpressionConverter) withInstance(owner semantics.DeclaredSymbol, index armExpression) map[semantics.DeclaredSymbol]armExpression {
+	instances := map[semantics.DeclaredSymbol]armExpression{owner: index}
+	for other, otherIndex := range c.instances {
+		if other != owner {
+			instances[other] = otherIndex
+		}
+	}
+	return instances
+}
+
+// isLoopInstance reports whether the converter converts a single instance of a resource or module loop, or of the
+// resource loop a resource is nested in.
+func (c *ExpressionConverter) isLoopInstance(symbol semantics.DeclaredSymbol) bool {
+	_, ok := c.instances[getLoopOwner(symbol)]
+	return ok
+}
+
+// getDeclarationLoop returns the loop of a resource or module declared with one, or nil.
+func getDeclarationLoop(symbol semantics.DeclaredSymbol) *syntax.ForSyntax {
+	var value syntax.SyntaxBase
+	switch symbol := symbol.(type) {
+	case *semantics.ResourceSymbol:
+		value = symbol.Declaration.Value
+	case *semantics.ModuleSymbol:
+		value = symbol.Declaration.Value
+	}
+	loop, _ := value.(*syntax.ForSyntax)
+	return loop
+}
+
+// getLoopOwner returns the resource or module whose loop deploys the instances of a declaration: the declaration
+// itself if it has a loop, or the closest resource loop a resource is nested in. It returns nil for declarations
+// deployed once.
+func getLoopOwner(symbol semantics.DeclaredSymbol) semantics.DeclaredSymbol {
+	if getDeclarationLoop(symbol) != nil {
+		return symbol
+	}
+	if resource, ok := symbol.(*semantics.ResourceSymbol); ok && resource.Parent != nil {
+		return getLoopOwner(resource.Parent)
+	}
+	return nil
+}
+
+// isDeclarationLoop reports whether a declaration is deployed by a loop, either its own or that of the resource
+// loop it is nested in.
+func isDeclarationLoop(symbol semantics.DeclaredSymbol) bool {
+	return getLoopOwner(symbol) != nil
+}
+
+// canConvert reports whether every local variable an expression references is replaced by the converter, e.g.
+// whether the index of a loop instance only uses the variables of the loop of its declaration.
+func (c *ExpressionConverter) canConvert(expression syntax.SyntaxBase) bool {
+	bound := true
+	syntax.Inspect(expression, func(node syntax.SyntaxBase) bool {
+		if symbol, ok := c.model.GetSymbol(node).(*semantics.LocalVariableSymbol); ok {
+			if _, ok := c.locals[symbol]; !ok {
+				bound = false
+			}
+		}
+		return bound
+	})
+	return bound
+}
@@ -0,0 +1,366 @@
+package emit
+
+import (
+	"bicep-go/namespaces"
+	"bicep-go/semantics"
+	"bicep-go/syntax"
+	"bicep-go/types"
+	"fmt"
+)
+
+const (
+	MODULE_DEPLOYMENT_TYPE        = "Microsoft.Resources/deployments"
+	MODULE_DEPLOYMENT_API_VERSION = "2022-09-01"
+	MANAGEMENT_GROUP_TYPE         = namespaces.MANAGEMENT_GROUP_RESOURCE_TYPE
+)
+
+// moduleScope is the scope a module is deployed at, with the arguments of the scope function selecting it, e.g.
+// the subscription ID and resource group name of resourceGroup('sub', 'rg'). Arguments that are not given are nil.
+type moduleScope struct {
+	scope           types.ResourceScope
+	subscriptionId  armExpression
+	resourceGroup   armExpression
+	managementGroup armExpression
+}
+
+// getModuleScope returns the scope of a module from its scope property. Modules without one are deployed at the
+// target scope of the file.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Emit/ScopeHelper.cs
+func (c *ExpressionConverter) getModuleScope(module *semantics.ModuleSymbol) (*moduleScope, error) {
+	body := module.Declaration.TryGetBody()
+	if body == nil {
+		return &moduleScope{scope: c.model.GetTargetScope()}, nil
+	}
+	property := body.TryGetProperty(syntax.RESOURCE_PROPERTY_SCOPE)
+	if property == nil {
+		return &moduleScope{scope: c.model.GetTargetScope()}, nil
+	}
+	return c.getScope(property.Value)
+}
+
+// getScope returns the scope a scope function call or a resource group, subscription or management group resource
+// refers to.
+func (c *ExpressionConverter) getScope(expression syntax.SyntaxBase) (*moduleScope, error) {
+	symbol, converter, err := c.tryGetDeclarationReference(expression)
+	if err != nil {
+		return nil, err
+	}
+	if resource, ok := symbol.(*semantics.ResourceSymbol); ok {
+		return converter.getResourceScope(expression, resource)
+	}
+
+	var arguments []*syntax.FunctionArgumentSyntax
+	switch call := expression.(type) {
+	case *syntax.FunctionCallSyntax:
+		arguments = call.Arguments
+	case *syntax.InstanceFunctionCallSyntax:
+		arguments = call.Arguments
+	default:
+		return nil, unsupportedExpression(expression, "module scopes other than scope function calls and scope resources")
+	}
+	var converted []armExpression
+	for _, argument := range arguments {
+		value, err := c.ConvertExpression(argument.Expression)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, value)
+	}
+
+	result := &moduleScope{scope: namespaces.TryGetScopeReference(c.model.GetType(expression))}
+	switch {
+	case result.scope == types.ResourceScopeResourceGroup && len(converted) == 1:
+		result.resourceGroup = converted[0]
+	case result.scope == types.ResourceScopeResourceGroup && len(converted) == 2:
+		result.subscriptionId = converted[0]
+		result.resourceGroup = converted[1]
+	case result.scope == types.ResourceScopeSubscription && len(converted) == 1:
+		result.subscriptionId = converted[0]
+	case result.scope == types.ResourceScopeManagementGroup && len(converted) == 1:
+		result.managementGroup = converted[0]
+	case len(converted) > 0 || result.scope == types.ResourceScopeNone:
+		return nil, unsupportedExpression(expression, "these module scopes")
+	}
+	return result, nil
+}
+
+// getResourceScope returns the scope a resource group, subscription or management group resource refers to, named
+// after the resource. A resource group resource declared with the scope of another subscription is in that
+// subscription.
+func (c *ExpressionConverter) getResourceScope(expression syntax.SyntaxBase, resource *semantics.ResourceSymbol) (*moduleScope, error) {
+	result := &moduleScope{scope: namespaces.TryGetScopeReference(c.model.GetType(expression))}
+	if result.scope == types.ResourceScopeNone {
+		return nil, unsupportedExpression(expression, "module scopes other than scope function calls and scope resources")
+	}
+	segments, err := c.getNameSegments(resource)
+	if err != nil {
+		return nil, err
+	}
+	name := segments[len(segments)-1]
+
+	switch result.scope {
+	case types.ResourceScopeResourceGroup:
+		result.resourceGroup = name
+		if property := resource.Declaration.TryGetBody().TryGetProperty(syntax.RESOURCE_PROPERTY_SCOPE); property != nil {
+			subscription, err := c.getScope(property.Value)
+			if err != nil {
+				return nil, err
+			}
+			result.subscriptionId = subscription.subscriptionId
+		}
+	case types.ResourceScopeSubscription:
+		result.subscriptionId = name
+	case types.ResourceScopeManagementGroup:
+		result.managementGroup = name
+	}
+	return result, nil
+}
+
+// getModuleName returns the name of the deployment of a module. The name of a module loop is converted with the
+// local variables of the loop replaced.
+func (c *ExpressionConverter) getModuleName(module *semantics.ModuleSymbol) (armExpression, error) {
+	body := module.Declaration.TryGetBody()
+	if body == nil {
+		return nil, fmt.Errorf("%s the module %q is not valid", module.Declaration.GetSpan().ToString(), module.GetName())
+	}
+	property := body.TryGetProperty(syntax.MODULE_PROPERTY_NAME)
+	if property == nil {
+		return nil, unsupportedExpression(module.Declaration.GetName(), "modules without names")
+	}
+	return c.ConvertExpression(property.Value)
+}
+
+// getModuleId returns the expression of the fully-qualified identifier of the deployment of a module, using the
+// resource identifier function of the scope the module is deployed at.
+func (c *ExpressionConverter) getModuleId(module *semantics.ModuleSymbol) (armExpression, error) {
+	if isDeclarationLoop(module) && !c.isLoopInstance(module) {
+		return nil, unsupportedExpression(module.Declaration.GetName(), "identifiers of whole module loops")
+	}
+	scope, err := c.getModuleScope(module)
+	if err != nil {
+		return nil, err
+	}
+	name, err := c.getModuleName(module)
+	if err != nil {
+		return nil, err
+	}
+	arguments := []armExpression{armString(MODULE_DEPLOYMENT_TYPE), name}
+
+	switch scope.scope {
+	case types.ResourceScopeSubscription:
+		if scope.subscriptionId != nil {
+			arguments = append([]armExpression{scope.subscriptionId}, arguments...)
+		}
+		return newArmFunction("subscriptionResourceId", arguments...), nil
+	case types.ResourceScopeManagementGroup:
+		managementGroupId := armExpression(&armFunction{name: "managementGroup", properties: []armExpression{armString("id")}})
+		if scope.managementGroup != nil {
+			managementGroupId = newArmFunction("tenantResourceId", armString(MANAGEMENT_GROUP_TYPE), scope.managementGroup)
+		}
+		return newArmFunction("extensionResourceId", append([]armExpression{managementGroupId}, arguments...)...), nil
+	case types.ResourceScopeTenant:
+		return newArmFunction("tenantResourceId", arguments...), nil
+	}
+	if scope.resourceGroup != nil {
+		arguments = append([]armExpression{scope.resourceGroup}, arguments...)
+	}
+	if scope.subscriptionId != nil {
+		arguments = append([]armExpression{scope.subscriptionId}, arguments...)
+	}
+	return newArmFunction("resourceId", arguments...), nil
+}
+
+// getDeclarationId returns the identifier of a resource, or of the deployment of a module.
+func (c *ExpressionConverter) getDeclarationId(symbol semantics.DeclaredSymbol) (armExpression, error) {
+	if module, ok := symbol.(*semantics.ModuleSymbol); ok {
+		return c.getModuleId(module)
+	}
+	return c.getResourceId(symbol.(*semantics.ResourceSymbol))
+}
+
+// convertModulePropertyAccess converts an access of a property of a module: the name of its deployment, or the
+// outputs read from the deployment with reference().
+func (c *ExpressionConverter) convertModulePropertyAccess(expression *syntax.PropertyAccessSyntax, module *semantics.ModuleSymbol) (armExpression, error) {
+	if isDeclarationLoop(module) && !c.isLoopInstance(module) {
+		return nil, unsupportedExpression(expression.BaseExpression, "references to module loops")
+	}
+
+	switch expression.PropertyName.IdentifierName() {
+	case syntax.MODULE_PROPERTY_NAME:
+		return c.getModuleName(module)
+	case syntax.MODULE_PROPERTY_OUTPUTS:
+		var reference armExpression
+		if c.symbolicNames {
+			reference = newArmFunction("reference", c.getSymbolicReference(module))
+		} else {
+			moduleId, err := c.getModuleId(module)
+			if err != nil {
+				return nil, err
+			}
+			reference = newArmFunction("reference", moduleId, armString(MODULE_DEPLOYMENT_API_VERSION))
+		}
+		accessed, _ := appendProperty(reference, armString(syntax.MODULE_PROPERTY_OUTPUTS))
+		return accessed, nil
+	}
+	return nil, unsupportedExpression(expression, "references to this module property")
+}
+
+// isModuleOutputs reports whether an expression reads the outputs of a module, e.g. app.outputs.
+func (c *ExpressionConverter) isModuleOutputs(expression syntax.SyntaxBase) bool {
+	access, ok := expression.(*syntax.PropertyAccessSyntax)
+	if !ok || access.IsSafeAccess() || access.PropertyName.IdentifierName() != syntax.MODULE_PROPERTY_OUTPUTS {
+		return false
+	}
+	switch base := access.BaseExpression.(type) {
+	case *syntax.ArrayAccessSyntax:
+		_, ok = c.model.GetSymbol(base.BaseExpression).(*semantics.ModuleSymbol)
+		return ok && !base.IsSafeAccess()
+	default:
+		_, ok = c.model.GetSymbol(base).(*semantics.ModuleSymbol)
+		return ok
+	}
+}
+
+// convertModuleOutputAccess converts an access of a single output of a module. Deployments return each output
+// as an object holding its value, e.g. reference(...).outputs.endpoint.value.
+func (c *ExpressionConverter) convertModuleOutputAccess(outputs syntax.SyntaxBase, name armExpression, safe bool) (armExpression, error) {
+	base, err := c.ConvertExpression(outputs)
+	if err != nil {
+		return nil, err
+	}
+	if safe {
+		return newArmFunction("tryGet", base, name, armString("value")), nil
+	}
+	accessed, _ := appendProperty(base, name)
+	accessed, _ = appendProperty(accessed, armString("value"))
+	return accessed, nil
+}
+
+// buildModule creates the nested deployment of a module, which inlines the template compiled from the module
+// file and passes the module parameters as deployment parameters. Expressions in the nested template are
+// evaluated in its own scope.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Emit/TemplateWriter.cs
+func (w *TemplateWriter) buildModule(module *semantics.ModuleSymbol) (*jsonObject, error) {
+	declaration := module.Declaration
+	moduleModel := w.model.GetModuleModel(declaration)
+	body := declaration.TryGetBody()
+	if moduleModel == nil || body == nil {
+		return nil, fmt.Errorf("%s the module %q is not valid", declaration.GetSpan().ToString(), module.GetName())
+	}
+
+	object := newJsonObject()
+	converter, err := w.addCopyAndCondition(object, module, declaration, declaration.Value)
+	if err != nil {
+		return nil, err
+	}
+	object.set("type", MODULE_DEPLOYMENT_TYPE)
+	object.set("apiVersion", MODULE_DEPLOYMENT_API_VERSION)
+
+	name, err := converter.getModuleName(module)
+	if err != nil {
+		return nil, err
+	}
+	object.set("name", newSourcedValue(w.model, body.TryGetProperty(syntax.MODULE_PROPERTY_NAME), armExpressionToJson(name)))
+
+	scope, err := converter.getModuleScope(module)
+	if err != nil {
+		return nil, err
+	}
+	targetScope := w.model.GetTargetScope()
+	if scope.scope == types.ResourceScopeSubscription && scope.subscriptionId == nil && targetScope == types.ResourceScopeResourceGroup {
+		scope.subscriptionId = &armFunction{name: "subscription", properties: []armExpression{armString("subscriptionId")}}
+	}
+	if scope.subscriptionId != nil {
+		object.set("subscriptionId", armExpressionToJson(scope.subscriptionId))
+	}
+	if scope.resourceGroup != nil {
+		object.set("resourceGroup", armExpressionToJson(scope.resourceGroup))
+	}
+	if scope.managementGroup != nil {
+		object.set("scope", serializeExpression(newArmFunction("format", armString(MANAGEMENT_GROUP_TYPE+"/{0}"), scope.managementGroup)))
+	} else if scope.scope == types.ResourceScopeTenant && targetScope != types.ResourceScopeTenant {
+		object.set("scope", "/")
+	}
+	if scope.scope != types.ResourceScopeResourceGroup {
+		// deployments at resource group scope have no location of their own
+		if targetScope == types.ResourceScopeResourceGroup {
+			object.set(syntax.RESOURCE_PROPERTY_LOCATION, "[resourceGroup().location]")
+		} else {
+			object.set(syntax.RESOURCE_PROPERTY_LOCATION, "[deployment().location]")
+		}
+	}
+
+	evaluationOptions := newJsonObject()
+	evaluationOptions.set("scope", "inner")
+	properties := newJsonObject()
+	properties.set("expressionEvaluationOptions", evaluationOptions)
+	properties.set("mode", "Incremental")
+	parameters, err := converter.buildModuleParameters(body)
+	if err != nil {
+		return nil, err
+	}
+	properties.set("parameters", parameters)
+	template, err := w.buildModuleTemplate(moduleModel)
+	if err != nil {
+		return nil, fmt.Errorf("%s the module %q cannot be emitted: %w", declaration.Path.GetSpan().ToString(), module.GetName(), err)
+	}
+	properties.set("template", template)
+	object.set(syntax.RESOURCE_PROPERTY_PROPERTIES, properties)
+
+	dependsOn, err := w.buildDependsOn(module, converter)
+	if err != nil {
+		return nil, err
+	}
+	if len(dependsOn) > 0 {
+		object.set(syntax.RESOURCE_PROPERTY_DEPENDS_ON, dependsOn)
+	}
+	return object, nil
+}
+
+// buildModuleParameters converts the params property of a module to deployment parameters, e.g.
+// {"size": {"value": 1}}. Parameters given by a loop are written as a copy of their value.
+func (c *ExpressionConverter) buildModuleParameters(body *syntax.ObjectSyntax) (*jsonObject, error) {
+	parameters := newJsonObject()
+	property := body.TryGetProperty(syntax.MODULE_PROPERTY_PARAMS)
+	if property == nil {
+		return parameters, nil
+	}
+	object, ok := property.Value.(*syntax.ObjectSyntax)
+	if !ok {
+		return nil, unsupportedExpression(property.Value, "module parameters other than object literals")
+	}
+
+	for _, parameter := range object.Properties() {
+		name, ok := parameter.TryGetKeyText()
+		if !ok {
+			return nil, unsupportedExpression(parameter.Key, "interpolated parameter names")
+		}
+		value := newJsonObject()
+		if loop, ok := parameter.Value.(*syntax.ForSyntax); ok {
+			entry, err := c.convertPropertyCopy("value", loop)
+			if err != nil {
+				return nil, err
+			}
+			value = prependCopies([]any{entry}, value)
+		} else {
+			converted, err := c.ConvertToJson(parameter.Value)
+			if err != nil {
+				return nil, err
+			}
+			value.set("value", converted)
+		}
+		parameters.set(name, newSourcedValue(c.model, parameter, value))
+	}
+	return parameters, nil
+}
+
+// buildModuleTemplate compiles the file referenced by a module. ARM templates are inlined as they are.
+func (w *TemplateWriter) buildModuleTemplate(moduleModel semantics.ModuleModel) (any, error) {
+	switch moduleModel := moduleModel.(type) {
+	case *semantics.SemanticModel:
+		return NewTemplateWriter(moduleModel, w.options).buildHashedTemplate()
+	case *semantics.ArmTemplateSemanticModel:
+		return moduleModel.GetContent(), nil
+	}
+	return nil, fmt.Errorf("modules of this kind cannot be emitted yet")
+}
@@ -0,0 +1,287 @@
+package emit
+
+import (
+	"bicep-go/semantics"
+	"bicep-go/syntax"
+	"bicep-go/types"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// getParent returns the parent of a resource, declared either by nesting or by the parent property.
+func (c *ExpressionConverter) getParent(resource *semantics.ResourceSymbol) *semantics.ResourceSymbol {
+	if resource.Parent != nil {
+		return resource.Parent
+	}
+	if body := resource.Declaration.TryGetBody(); body != nil {
+		if property := body.TryGetProperty(syntax.RESOURCE_PROPERTY_PARENT); property != nil {
+			parent, _ := c.model.GetSymbol(property.Value).(*semantics.ResourceSymbol)
+			return parent
+		}
+	}
+	return nil
+}
+
+// getScopeResource returns the resource an extension resource, or its outermost parent, is deployed onto.
+func (c *ExpressionConverter) getScopeResource(resource *semantics.ResourceSymbol) *semantics.ResourceSymbol {
+	for parent := c.getParent(resource); parent != nil; parent = c.getParent(parent) {
+		resource = parent
+	}
+	if body := resource.Declaration.TryGetBody(); body != nil {
+		if property := body.TryGetProperty(syntax.RESOURCE_PROPERTY_SCOPE); property != nil {
+			scope, _ := c.model.GetSymbol(property.Value).(*semantics.ResourceSymbol)
+			return scope
+		}
+	}
+	return nil
+}
+
+// getNameSegments returns the names of a resource and its parents, outermost first, e.g. the names of the
+// virtual network and the subnet for a subnet. The name of a resource loop is converted with the local
+// variables of the loop replaced.
+func (c *ExpressionConverter) getNameSegments(resource *semantics.ResourceSymbol) ([]armExpression, error) {
+	var segments []armExpression
+	if parent := c.getParent(resource); parent != nil {
+		if isDeclarationLoop(parent) && !c.isLoopInstance(parent) {
+			return nil, unsupportedExpression(resource.Declaration.GetName(), "resource loops nested in resource loops")
+		}
+		parentSegments, err := c.getNameSegments(parent)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, parentSegments...)
+	}
+
+	property := resource.Declaration.TryGetBody().TryGetProperty(syntax.MODULE_PROPERTY_NAME)
+	if property == nil {
+		return nil, fmt.Errorf("%s the resource %q has no name", resource.Declaration.GetSpan().ToString(), resource.GetName())
+	}
+	name, err := c.ConvertExpression(property.Value)
+	if err != nil {
+		return nil, err
+	}
+	return append(segments, name), nil
+}
+
+// formatNameSegments joins name segments with slashes, e.g. format('{0}/{1}', 'vnet', 'subnet').
+func formatNameSegments(segments []armExpression) armExpression {
+	placeholders := make([]string, 0, len(segments))
+	for i := range segments {
+		placeholders = append(placeholders, "{"+strconv.Itoa(i)+"}")
+	}
+	arguments := append([]armExpression{armString(strings.Join(placeholders, "/"))}, segments...)
+	return newArmFunction("format", arguments...)
+}
+
+// getScopeString returns the relative identifier of the resource an extension resource is deployed onto, e.g.
+// format('Microsoft.Storage/storageAccounts/{0}', parameters('name')).
+func (c *ExpressionConverter) getScopeString(scope *semantics.ResourceSymbol) (string, error) {
+	resourceType := c.model.GetResourceType(scope)
+	if resourceType == nil {
+		return "", fmt.Errorf("%s the resource %q is not valid", scope.Declaration.GetSpan().ToString(), scope.GetName())
+	}
+	if isDeclarationLoop(scope) {
+		return "", unsupportedExpression(scope.Declaration.GetName(), "extension resources of resource loops")
+	}
+	segments, err := c.getNameSegments(scope)
+	if err != nil {
+		return "", err
+	}
+
+	typeSegments := strings.Split(resourceType.TypeReference.Type, "/")
+	var format strings.Builder
+	format.WriteString(typeSegments[0])
+	for i, typeSegment := range typeSegments[1:] {
+		format.WriteString("/" + typeSegment + "/{" + strconv.Itoa(i) + "}")
+	}
+	arguments := append([]armExpression{armString(format.String())}, segments...)
+	return serializeExpression(newArmFunction("format", arguments...)), nil
+}
+
+// getResourceId returns the expression of the fully-qualified identifier of a resource, using the resource
+// identifier function of the target scope of the file.
+func (c *ExpressionConverter) getResourceId(resource *semantics.ResourceSymbol) (armExpression, error) {
+	resourceType := c.model.GetResourceType(resource)
+	if resourceType == nil {
+		return nil, fmt.Errorf("%s the resource %q is not valid", resource.Declaration.GetSpan().ToString(), resource.GetName())
+	}
+	if isDeclarationLoop(resource) && !c.isLoopInstance(resource) {
+		return nil, unsupportedExpression(resource.Declaration.GetName(), "identifiers of whole resource loops")
+	}
+	segments, err := c.getNameSegments(resource)
+	if err != nil {
+		return nil, err
+	}
+	arguments := append([]armExpression{armString(resourceType.TypeReference.Type)}, segments...)
+
+	if scope := c.getScopeResource(resource); scope != nil {
+		scopeId, err := c.getResourceId(scope)
+		if err != nil {
+			return nil, err
+		}
+		return newArmFunction("extensionResourceId", append([]armExpression{scopeId}, arguments...)...), nil
+	}
+
+	switch c.model.GetTargetScope() {
+	case types.ResourceScopeSubscription:
+		return newArmFunction("subscriptionResourceId", arguments...), nil
+	case types.ResourceScopeManagementGroup:
+		managementGroupId := &armFunction{name: "managementGroup", properties: []armExpression{armString("id")}}
+		return newArmFunction("extensionResourceId", append([]armExpression{managementGroupId}, arguments...)...), nil
+	case types.ResourceScopeTenant:
+		return newArmFunction("tenantResourceId", arguments...), nil
+	}
+	return newArmFunction("resourceId", arguments...), nil
+}
+
+// convertResourcePropertyAccess converts an access of a property of a resource. The identifier, name, type and
+// API version are known from the declaration; the other properties are read from the runtime state of the
+// resource with reference().
+func (c *ExpressionConverter) convertResourcePropertyAccess(expression *syntax.PropertyAccessSyntax, resource *semantics.ResourceSymbol) (armExpression, error) {
+	resourceType := c.model.GetResourceType(resource)
+	if resourceType == nil {
+		return nil, fmt.Errorf("%s the resource %q is not valid", resource.Declaration.GetSpan().ToString(), resource.GetName())
+	}
+	if isDeclarationLoop(resource) && !c.isLoopInstance(resource) {
+		return nil, unsupportedExpression(expression.BaseExpression, "references to resource loops")
+	}
+
+	switch name := expression.PropertyName.IdentifierName(); name {
+	case syntax.RESOURCE_PROPERTY_ID:
+		return c.getResourceId(resource)
+	case syntax.MODULE_PROPERTY_NAME:
+		segments, err := c.getNameSegments(resource)
+		if err != nil {
+			return nil, err
+		}
+		if len(segments) == 1 {
+			return segments[0], nil
+		}
+		return formatNameSegments(segments), nil
+	case syntax.RESOURCE_PROPERTY_TYPE:
+		return armString(resourceType.TypeReference.Type), nil
+	case syntax.RESOURCE_PROPERTY_API_VERSION:
+		return armString(resourceType.TypeReference.ApiVersion), nil
+	case syntax.RESOURCE_PROPERTY_PROPERTIES:
+		return c.getReference(expression, resource, false)
+	default:
+		reference, err := c.getReference(expression, resource, true)
+		if err != nil {
+			return nil, err
+		}
+		accessed, _ := appendProperty(reference, armString(name))
+		return accessed, nil
+	}
+}
+
+// getReference returns the reference() call reading the runtime state of a resource: its properties, or the
+// whole resource if full is set. Language version 2.0 templates refer to the resource by its symbolic name.
+func (c *ExpressionConverter) getReference(expression syntax.SyntaxBase, resource *semantics.ResourceSymbol, full bool) (armExpression, error) {
+	resourceType := c.model.GetResourceType(resource)
+	if resourceType == nil {
+		return nil, fmt.Errorf("%s the resource %q is not valid", resource.Declaration.GetSpan().ToString(), resource.GetName())
+	}
+	if isDeclarationLoop(resource) && !c.isLoopInstance(resource) {
+		return nil, unsupportedExpression(expression, "references to resource loops")
+	}
+
+	var arguments []armExpression
+	if c.symbolicNames {
+		if !full {
+			return newArmFunction("reference", c.getSymbolicReference(resource)), nil
+		}
+		arguments = append(arguments, c.getSymbolicReference(resource))
+	} else {
+		resourceId, err := c.getResourceId(resource)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, resourceId)
+	}
+	arguments = append(arguments, armString(resourceType.TypeReference.ApiVersion))
+	if full {
+		arguments = append(arguments, armString("full"))
+	}
+	return newArmFunction("reference", arguments...), nil
+}
+
+// convertResourceFunctionCall converts a call of a resource function, e.g. stg.listKeys(), to a call taking the
+// identifier and API version of the resource, e.g. listKeys(resourceId(...), '2023-01-01').
+func (c *ExpressionConverter) convertResourceFunctionCall(call *syntax.InstanceFunctionCallSyntax, resource *semantics.ResourceSymbol) (armExpression, error) {
+	resourceType := c.model.GetResourceType(resource)
+	if resourceType == nil {
+		return nil, fmt.Errorf("%s the resource %q is not valid", resource.Declaration.GetSpan().ToString(), resource.GetName())
+	}
+	if isDeclarationLoop(resource) && !c.isLoopInstance(resource) {
+		return nil, unsupportedExpression(call.BaseExpression, "references to resource loops")
+	}
+	resourceId, err := c.getResourceId(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	arguments := []armExpression{resourceId, armString(resourceType.TypeReference.ApiVersion)}
+	for _, argument := range call.Arguments {
+		converted, err := c.ConvertExpression(argument.Expression)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, converted)
+	}
+	return newArmFunction(call.Name.IdentifierName(), arguments...), nil
+}
+
+// tryGetDeclarationReference returns the resource or module referenced by an expression, and the converter for
+// its name. A single instance of a resource or module loop, e.g. storage[i], is converted with the variables of the
+// loop replaced. It returns a nil symbol for expressions that are not resource or module references.
+func (c *ExpressionConverter) tryGetDeclarationReference(expression syntax.SyntaxBase) (semantics.DeclaredSymbol, *ExpressionConverter, error) {
+	if access, ok := expression.(*syntax.ResourceAccessSyntax); ok {
+		// a resource nested in an instance of a resource loop, e.g. vnets[i]::subnet, is an instance of that loop
+		nested, ok := c.model.GetSymbol(access).(*semantics.ResourceSymbol)
+		if !ok {
+			return nil, nil, nil
+		}
+		_, converter, err := c.tryGetDeclarationReference(access.BaseExpression)
+		if err != nil {
+			return nil, nil, err
+		}
+		if converter == nil {
+			converter = c
+		}
+		return nested, converter, nil
+	}
+	switch symbol := c.model.GetSymbol(expression).(type) {
+	case *semantics.ResourceSymbol:
+		return symbol, c, nil
+	case *semantics.ModuleSymbol:
+		return symbol, c, nil
+	}
+	access, ok := expression.(*syntax.ArrayAccessSyntax)
+	if !ok || access.IsSafeAccess() {
+		return nil, nil, nil
+	}
+	symbol, ok := c.model.GetSymbol(access.BaseExpression).(semantics.DeclaredSymbol)
+	if !ok || !isDeclarationLoop(symbol) {
+		return nil, nil, nil
+	}
+	index, err := c.ConvertExpression(access.IndexExpression)
+	if err != nil {
+		return nil, nil, err
+	}
+	instance, err := c.withLoopInstance(symbol, index)
+	if err != nil {
+		return nil, nil, err
+	}
+	return symbol, instance, nil
+}
+
+// getSymbolicReference returns the symbolic name of a resource or module in language version 2.0 templates, or the
+// name of a single instance of a loop, e.g. format('storage[{0}]', 1).
+func (c *ExpressionConverter) getSymbolicReference(symbol semantics.DeclaredSymbol) armExpression {
+	name := getSymbolicName(symbol)
+	if index, ok := c.instances[getLoopOwner(symbol)]; ok {
+		return newArmFunction("format", armString(name+"[{0}]"), index)
+	}
+	return armString(name)
+}
@@ -0,0 +1,145 @@
+package emit
+
+import (
+	"bicep-go/semantics"
+	"bicep-go/syntax"
+	"bicep-go/util"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SourceMap maps the values of a template to the Bicep syntax they were emitted from, including the values of
+// the templates of modules, which are emitted from the module files. It is written as JSON alongside the template.
+type SourceMap struct {
+	// Entries are ordered by the end of their values, so that nested values come before the values enclosing them.
+	Entries []*SourceMapEntry `json:"entries"`
+}
+
+type SourceMapEntry struct {
+	// Path is the JSON path of the value in the template, e.g. resources[0].properties.sku.
+	Path string `json:"path"`
+	// StartLine and EndLine are the one-based lines of the template the value spans.
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine"`
+	// Model is the file the value was emitted from, and Span the syntax in that file. File is the path of the
+	// model, which only compilations know.
+	Model *semantics.SemanticModel `json:"-"`
+	File  string                   `json:"file"`
+	Span  *util.TextSpan           `json:"span"`
+}
+
+// sourcedValue is a JSON value emitted from a Bicep node. It is written as the value itself, and recorded in
+// source maps.
+type sourcedValue struct {
+	value any
+	model *semantics.SemanticModel
+	span  *util.TextSpan
+}
+
+func newSourcedValue(model *semantics.SemanticModel, node syntax.SyntaxBase, value any) *sourcedValue {
+	return &sourcedValue{value: value, model: model, span: node.GetSpan()}
+}
+
+// Lookup translates the target of a deployment error back to the Bicep source of the innermost value it refers
+// to. The target is either a line of the template or a JSON path, e.g. resources[1].properties.template.outputs.id,
+// optionally starting with $.
+func (m *SourceMap) Lookup(target string) (*SourceMapEntry, error) {
+	target = strings.TrimSpace(target)
+	if line, err := strconv.Atoi(target); err == nil {
+		return m.lookupLine(line)
+	}
+
+	segments, err := parseJsonPath(strings.TrimPrefix(target, "$"))
+	if err != nil {
+		return nil, err
+	}
+	var found *SourceMapEntry
+	foundLength := -1
+	for _, entry := range m.Entries {
+		entrySegments, err := parseJsonPath(entry.Path)
+		if err != nil || len(entrySegments) <= foundLength || !hasPathPrefix(segments, entrySegments) {
+			continue
+		}
+		found = entry
+		foundLength = len(entrySegments)
+	}
+	if found == nil {
+		return nil, fmt.Errorf("the path %q is not in the source map", target)
+	}
+	return found, nil
+}
+
+// lookupLine returns the entry of the innermost value spanning a line of the template.
+func (m *SourceMap) lookupLine(line int) (*SourceMapEntry, error) {
+	var found *SourceMapEntry
+	for _, entry := range m.Entries {
+		if entry.StartLine > line || line > entry.EndLine {
+			continue
+		}
+		if found == nil || entry.EndLine-entry.StartLine < found.EndLine-found.StartLine {
+			found = entry
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("the line %d is not in the source map", line)
+	}
+	return found, nil
+}
+
+// parseJsonPath splits a JSON path into its property names and indexes, so that resources.stg and
+// resources['stg'] are the same path.
+func parseJsonPath(path string) ([]string, error) {
+	var segments []string
+	for rest := strings.TrimPrefix(path, "."); rest != ""; {
+		switch {
+		case strings.HasPrefix(rest, "['"):
+			var name strings.Builder
+			i := 2
+			for ; i < len(rest); i++ {
+				if rest[i] == '\'' {
+					if i+1 < len(rest) && rest[i+1] == '\'' {
+						name.WriteByte('\'')
+						i++
+						continue
+					}
+					break
+				}
+				name.WriteByte(rest[i])
+			}
+			if !strings.HasPrefix(rest[i:], "']") {
+				return nil, fmt.Errorf("the path %q is not valid", path)
+			}
+			segments = append(segments, name.String())
+			rest = rest[i+2:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("the path %q is not valid", path)
+			}
+			segments = append(segments, rest[1:end])
+			rest = rest[end+1:]
+		default:
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			segments = append(segments, rest[:end])
+			rest = rest[end:]
+		}
+		rest = strings.TrimPrefix(rest, ".")
+	}
+	return segments, nil
+}
+
+func hasPathPrefix(segments []string, prefix []string) bool {
+	if len(prefix) > len(segments) {
+		return false
+	}
+	for i, segment := range prefix {
+		if segments[i] != segment {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,678 @@
+package emit
+
+import (
+	"bicep-go/namespaces"
+	"bicep-go/semantics"
+	"bicep-go/syntax"
+	"bicep-go/token"
+	"bicep-go/types"
+	"bicep-go/util"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+const (
+	TEMPLATE_SCHEMA_RESOURCE_GROUP   = "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#"
+	TEMPLATE_SCHEMA_SUBSCRIPTION     = "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#"
+	TEMPLATE_SCHEMA_MANAGEMENT_GROUP = "https://schema.management.azure.com/schemas/2019-08-01/managementGroupDeploymentTemplate.json#"
+	TEMPLATE_SCHEMA_TENANT           = "https://schema.management.azure.com/schemas/2019-08-01/tenantDeploymentTemplate.json#"
+	TEMPLATE_CONTENT_VERSION         = "1.0.0.0"
+	TEMPLATE_LANGUAGE_VERSION        = "2.0"
+
+	GENERATOR_NAME = "bicep"
+	// DEFAULT_GENERATOR_VERSION is the generator version of templates written without one in the options.
+	DEFAULT_GENERATOR_VERSION = "0.1.0"
+)
+
+// EmitterOptions configures the templates written by the TemplateWriter.
+type EmitterOptions struct {
+	// SymbolicNames writes language version 2.0 templates, whose resources are keyed by their symbolic names,
+	// even if the file could be written with the original template language.
+	SymbolicNames bool
+	// GeneratorVersion is the version of Bicep recorded in the generator metadata of the templates, which is part
+	// of their hash. It defaults to DEFAULT_GENERATOR_VERSION.
+	GeneratorVersion string
+}
+
+// TemplateWriter compiles a Bicep file without errors into an ARM deployment template.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Emit/TemplateWriter.cs
+type TemplateWriter struct {
+	model        *semantics.SemanticModel
+	converter    *ExpressionConverter
+	dependencies *semantics.DependencyGraph
+	// symbolicNames is set for language version 2.0 templates.
+	symbolicNames bool
+	options       EmitterOptions
+}
+
+func NewTemplateWriter(model *semantics.SemanticModel, options EmitterOptions) *TemplateWriter {
+	converter := NewExpressionConverter(model)
+	symbolicNames := options.SymbolicNames || requiresSymbolicNames(model.Binder.GetFileSymbol(), converter.imports)
+	converter.symbolicNames = symbolicNames
+	return &TemplateWriter{
+		model:         model,
+		converter:     converter,
+		dependencies:  model.GetDependencyGraph(),
+		symbolicNames: symbolicNames,
+		options:       options,
+	}
+}
+
+// requiresSymbolicNames reports whether a file uses features only language version 2.0 templates can
+// express: type definitions, including imported ones, and existing resources, which are declared alongside
+// deployed resources.
+func requiresSymbolicNames(file *semantics.FileSymbol, imports *importClosure) bool {
+	if len(file.TypeAliases) > 0 {
+		return true
+	}
+	for _, imported := range imports.declarations {
+		if _, ok := imported.symbol.(*semantics.TypeAliasSymbol); ok {
+			return true
+		}
+	}
+	for _, resource := range file.AllResources() {
+		if resource.Declaration.IsExistingResource() {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes the template as indented JSON. It fails for constructs the emitter does not support.
+func (w *TemplateWriter) Write(out io.Writer) error {
+	_, err := w.WriteWithSourceMap(out)
+	return err
+}
+
+// WriteWithSourceMap writes the template like Write, and returns the map from the values of the template back
+// to the Bicep files they were emitted from.
+func (w *TemplateWriter) WriteWithSourceMap(out io.Writer) (*SourceMap, error) {
+	template, err := w.buildHashedTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	writer := &jsonWriter{buffer: &buffer, sourceMap: &SourceMap{}, line: 1}
+	writer.write(template, 0, "")
+	if _, err := out.Write(buffer.Bytes()); err != nil {
+		return nil, err
+	}
+	return writer.sourceMap, nil
+}
+
+// buildHashedTemplate builds the template and sets its hash in the generator metadata.
+func (w *TemplateWriter) buildHashedTemplate() (*jsonObject, error) {
+	template, err := w.buildTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	generator := template.get("metadata").(*jsonObject).get("_generator").(*jsonObject)
+	generator.set("templateHash", computeTemplateHash(template))
+	return template, nil
+}
+
+// computeTemplateHash hashes the template written without its hash as minified JSON, like ARM hashes the
+// templates it deploys, so that changes to the template are visible in its metadata.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Emit/TemplateWriter.cs
+func computeTemplateHash(template *jsonObject) string {
+	var buffer bytes.Buffer
+	writeMinifiedJson(&buffer, template)
+	return strconv.FormatUint(util.ComputeMurmurHash64(buffer.Bytes()), 10)
+}
+
+func (w *TemplateWriter) buildTemplate() (*jsonObject, error) {
+	file := w.model.Binder.GetFileSymbol()
+
+	schema, err := getTemplateSchema(file.TargetScope)
+	if err != nil {
+		return nil, err
+	}
+
+	template := newJsonObject()
+	template.set("$schema", schema)
+	if w.symbolicNames {
+		template.set("languageVersion", TEMPLATE_LANGUAGE_VERSION)
+	}
+	template.set("contentVersion", TEMPLATE_CONTENT_VERSION)
+
+	metadata, err := w.buildMetadata(file.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	template.set("metadata", metadata)
+
+	declarations := w.getInlinedDeclarations()
+	definitions := newJsonObject()
+	for _, declaration := range declarations {
+		if alias, ok := declaration.symbol.(*semantics.TypeAliasSymbol); ok {
+			value, err := declaration.writer.buildDecoratedSchema(alias.Declaration, alias.Declaration.Value)
+			if err != nil {
+				return nil, err
+			}
+			definitions.set(declaration.name, newSourcedValue(declaration.writer.model, alias.Declaration, value))
+		}
+	}
+	if !definitions.isEmpty() {
+		template.set("definitions", definitions)
+	}
+
+	functions, err := w.buildFunctions(declarations)
+	if err != nil {
+		return nil, err
+	}
+	if functions != nil {
+		template.set("functions", functions)
+	}
+
+	if len(file.Parameters) > 0 {
+		parameters := newJsonObject()
+		for _, parameter := range file.Parameters {
+			value, err := w.buildParameter(parameter)
+			if err != nil {
+				return nil, err
+			}
+			parameters.set(parameter.GetName(), newSourcedValue(w.model, parameter.Declaration, value))
+		}
+		template.set("parameters", parameters)
+	}
+
+	var copies []any
+	variables := newJsonObject()
+	for _, variable := range w.converter.fileLoads.variables {
+		variables.set(variable.name, variable.value)
+	}
+	for _, declaration := range declarations {
+		variable, ok := declaration.symbol.(*semantics.VariableSymbol)
+		if !ok {
+			continue
+		}
+		model, converter := declaration.writer.model, declaration.writer.converter
+		if loop, ok := variable.Declaration.Value.(*syntax.ForSyntax); ok {
+			entry, err := converter.convertPropertyCopy(declaration.name, loop)
+			if err != nil {
+				return nil, err
+			}
+			copies = append(copies, newSourcedValue(model, variable.Declaration, entry))
+			continue
+		}
+		value, err := converter.ConvertToJson(variable.Declaration.Value)
+		if err != nil {
+			return nil, err
+		}
+		variables.set(declaration.name, newSourcedValue(model, variable.Declaration, value))
+	}
+	if len(copies) > 0 || !variables.isEmpty() {
+		template.set("variables", prependCopies(copies, variables))
+	}
+
+	resources, err := w.buildResources(file)
+	if err != nil {
+		return nil, err
+	}
+	template.set("resources", resources)
+
+	if len(file.Outputs) > 0 {
+		outputs := newJsonObject()
+		for _, output := range file.Outputs {
+			value, err := w.buildOutput(output)
+			if err != nil {
+				return nil, err
+			}
+			outputs.set(output.GetName(), newSourcedValue(w.model, output.Declaration, value))
+		}
+		template.set("outputs", outputs)
+	}
+
+	return template, nil
+}
+
+func getTemplateSchema(targetScope types.ResourceScope) (string, error) {
+	switch targetScope {
+	case types.ResourceScopeResourceGroup:
+		return TEMPLATE_SCHEMA_RESOURCE_GROUP, nil
+	case types.ResourceScopeSubscription:
+		return TEMPLATE_SCHEMA_SUBSCRIPTION, nil
+	case types.ResourceScopeManagementGroup:
+		return TEMPLATE_SCHEMA_MANAGEMENT_GROUP, nil
+	case types.ResourceScopeTenant:
+		return TEMPLATE_SCHEMA_TENANT, nil
+	}
+	return "", fmt.Errorf("files targeting the %s scope are not deployed with ARM templates", syntax.TARGET_SCOPE_TYPE_DESIRED_STATE_CONFIGURATION)
+}
+
+func (w *TemplateWriter) getGeneratorVersion() string {
+	if w.options.GeneratorVersion != "" {
+		return w.options.GeneratorVersion
+	}
+	return DEFAULT_GENERATOR_VERSION
+}
+
+// buildMetadata creates the template metadata: the generator, followed by the metadata declarations of the file.
+func (w *TemplateWriter) buildMetadata(declarations []*semantics.MetadataSymbol) (*jsonObject, error) {
+	generator := newJsonObject()
+	generator.set("name", GENERATOR_NAME)
+	generator.set("version", w.getGeneratorVersion())
+
+	metadata := newJsonObject()
+	metadata.set("_generator", generator)
+	for _, declaration := range declarations {
+		value, err := w.converter.ConvertToJson(declaration.Declaration.Value)
+		if err != nil {
+			return nil, err
+		}
+		metadata.set(declaration.GetName(), newSourcedValue(w.model, declaration.Declaration, value))
+	}
+	return metadata, nil
+}
+
+func (w *TemplateWriter) buildParameter(parameter *semantics.ParameterSymbol) (*jsonObject, error) {
+	declaration := parameter.Declaration
+	declaredType := w.model.GetDeclaredType(declaration)
+
+	var object *jsonObject
+	if w.symbolicNames {
+		schema, err := w.buildDeclarationSchema(declaration, declaration.Type)
+		if err != nil {
+			return nil, err
+		}
+		object = schema
+	} else {
+		object = newJsonObject()
+		object.set("type", getArmTypeName(declaredType, w.model.Binder.TryGetDecorator(declaration, namespaces.DECORATOR_SECURE) != nil))
+		if types.IsNullable(declaredType) {
+			object.set("nullable", true)
+		}
+	}
+	if defaultValue := declaration.DefaultValue(); defaultValue != nil {
+		value, err := w.converter.ConvertToJson(defaultValue)
+		if err != nil {
+			return nil, err
+		}
+		object.set("defaultValue", value)
+	}
+	if allowedValues := getAllowedValues(declaredType); allowedValues != nil && !w.symbolicNames {
+		object.set("allowedValues", allowedValues)
+	}
+	if err := w.addDecorators(declaration, object); err != nil {
+		return nil, err
+	}
+	return object, nil
+}
+
+func (w *TemplateWriter) buildOutput(output *semantics.OutputSymbol) (*jsonObject, error) {
+	declaration := output.Declaration
+	var object *jsonObject
+	if w.symbolicNames {
+		schema, err := w.buildDeclarationSchema(declaration, declaration.Type)
+		if err != nil {
+			return nil, err
+		}
+		object = schema
+	} else {
+		object = newJsonObject()
+		object.set("type", getArmTypeName(w.model.GetDeclaredType(declaration), w.model.Binder.TryGetDecorator(declaration, namespaces.DECORATOR_SECURE) != nil))
+	}
+	if err := w.addDecorators(declaration, object); err != nil {
+		return nil, err
+	}
+
+	if loop, ok := declaration.Value.(*syntax.ForSyntax); ok {
+		count, converter, err := w.converter.convertLoop(loop, copyIndex(""))
+		if err != nil {
+			return nil, err
+		}
+		input, err := converter.ConvertToJson(loop.Body)
+		if err != nil {
+			return nil, err
+		}
+		loopCopy := newJsonObject()
+		loopCopy.set("count", armExpressionToJson(count))
+		loopCopy.set("input", input)
+		object.set(syntax.LOOP_IDENTIFIER_COPY, loopCopy)
+		return object, nil
+	}
+
+	value, err := w.converter.ConvertToJson(declaration.Value)
+	if err != nil {
+		return nil, err
+	}
+	object.set("value", value)
+	return object, nil
+}
+
+// decoratorProperties are the template properties of decorators whose argument is copied as is.
+var decoratorProperties = map[string]string{
+	namespaces.DECORATOR_ALLOWED:    "allowedValues",
+	namespaces.DECORATOR_MIN_VALUE:  "minValue",
+	namespaces.DECORATOR_MAX_VALUE:  "maxValue",
+	namespaces.DECORATOR_MIN_LENGTH: "minLength",
+	namespaces.DECORATOR_MAX_LENGTH: "maxLength",
+}
+
+// addDecorators adds the template properties of the sys decorators of a declaration in source order. The
+// description, metadata and export decorators all add to the metadata property.
+func (w *TemplateWriter) addDecorators(declaration syntax.DecorableSyntax, object *jsonObject) error {
+	for _, decorator := range declaration.Decorators() {
+		symbol, ok := w.model.GetSymbol(decorator.Expression).(*semantics.DecoratorSymbol)
+		if !ok || symbol.Namespace.GetName() != namespaces.NAMESPACE_SYS {
+			continue
+		}
+		if symbol.Decorator.Name == namespaces.DECORATOR_EXPORT {
+			// imported declarations are not exported again
+			if !w.converter.imports.isImported(w.model) {
+				getMetadata(object).set(semantics.ARM_METADATA_EXPORT, true)
+			}
+			continue
+		}
+		arguments := decorator.Arguments()
+		if len(arguments) != 1 {
+			continue
+		}
+
+		value, err := w.converter.ConvertToJson(arguments[0].Expression)
+		if err != nil {
+			return err
+		}
+		if name, ok := decoratorProperties[symbol.Decorator.Name]; ok {
+			object.set(name, value)
+			continue
+		}
+
+		switch symbol.Decorator.Name {
+		case namespaces.DECORATOR_DESCRIPTION:
+			getMetadata(object).set("description", value)
+		case namespaces.DECORATOR_METADATA:
+			if properties, ok := value.(*jsonObject); ok {
+				metadata := getMetadata(object)
+				for _, name := range properties.names {
+					metadata.set(name, properties.get(name))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// getMetadata returns the metadata property of an object, adding it if it is missing.
+func getMetadata(object *jsonObject) *jsonObject {
+	if metadata, ok := object.get("metadata").(*jsonObject); ok {
+		return metadata
+	}
+	metadata := newJsonObject()
+	object.set("metadata", metadata)
+	return metadata
+}
+
+// getArmTypeName returns the template type of a parameter or output: string, int, bool, array or object, or
+// securestring and secureObject for secure values.
+func getArmTypeName(typeSymbol types.TypeSymbol, secure bool) string {
+	name := "object"
+	switch typeSymbol := types.RemoveNullability(typeSymbol).(type) {
+	case *types.PrimitiveType:
+		name = typeSymbol.Name
+	case *types.StringLiteralType:
+		name = syntax.TYPE_NAME_STRING
+	case *types.IntegerLiteralType:
+		name = syntax.TYPE_NAME_INT
+	case *types.BooleanLiteralType:
+		name = syntax.TYPE_NAME_BOOL
+	case *types.ArrayType, *types.TupleType:
+		name = syntax.TYPE_ARRAY
+	case *types.UnionType:
+		return getArmTypeName(typeSymbol.Members[0], secure)
+	}
+
+	if secure {
+		switch name {
+		case syntax.TYPE_NAME_STRING:
+			return "securestring"
+		case syntax.TYPE_OBJECT:
+			return "secureObject"
+		}
+	}
+	return name
+}
+
+// getAllowedValues returns the values of a literal type or a union of literal types, e.g. 'a' | 'b', or nil for
+// other types.
+func getAllowedValues(typeSymbol types.TypeSymbol) []any {
+	typeSymbol = types.RemoveNullability(typeSymbol)
+	members := []types.TypeSymbol{typeSymbol}
+	if union, ok := typeSymbol.(*types.UnionType); ok {
+		members = union.Members
+	}
+	var values []any
+	for _, member := range members {
+		switch member := member.(type) {
+		case *types.StringLiteralType:
+			values = append(values, escapeJsonString(member.Value))
+		case *types.IntegerLiteralType:
+			values = append(values, member.Value)
+		case *types.BooleanLiteralType:
+			values = append(values, member.Value)
+		case *types.NullType:
+		default:
+			return nil
+		}
+	}
+	return values
+}
+
+// resourceBodyProperties are emitted from the symbols they reference rather than copied from the body.
+var resourceBodyProperties = map[string]bool{
+	syntax.MODULE_PROPERTY_NAME:         true,
+	syntax.RESOURCE_PROPERTY_PARENT:     true,
+	syntax.RESOURCE_PROPERTY_SCOPE:      true,
+	syntax.RESOURCE_PROPERTY_DEPENDS_ON: true,
+}
+
+// buildResources lists the resources of the file followed by the deployments of its modules, or keys them by
+// their symbolic names in language version 2.0 templates.
+func (w *TemplateWriter) buildResources(file *semantics.FileSymbol) (any, error) {
+	list := []any{}
+	object := newJsonObject()
+	for _, resource := range file.AllResources() {
+		built, err := w.buildResource(resource)
+		if err != nil {
+			return nil, err
+		}
+		value := newSourcedValue(w.model, resource.Declaration, built)
+		list = append(list, value)
+		object.set(getSymbolicName(resource), value)
+	}
+	for _, module := range file.Modules {
+		built, err := w.buildModule(module)
+		if err != nil {
+			return nil, err
+		}
+		value := newSourcedValue(w.model, module.Declaration, built)
+		list = append(list, value)
+		object.set(getSymbolicName(module), value)
+	}
+	if w.symbolicNames {
+		return object, nil
+	}
+	return list, nil
+}
+
+// getSymbolicName returns the name of a resource or module in language version 2.0 templates. Nested resources
+// are qualified by their parents, e.g. vnet::subnet.
+func getSymbolicName(symbol semantics.DeclaredSymbol) string {
+	if resource, ok := symbol.(*semantics.ResourceSymbol); ok && resource.Parent != nil {
+		return getSymbolicName(resource.Parent) + token.GetTokenText(token.TokenTypeDoubleColon) + resource.GetName()
+	}
+	return symbol.GetName()
+}
+
+func (w *TemplateWriter) buildResource(resource *semantics.ResourceSymbol) (*jsonObject, error) {
+	declaration := resource.Declaration
+	resourceType := w.model.GetResourceType(resource)
+	body := declaration.TryGetBody()
+	if resourceType == nil || body == nil {
+		return nil, fmt.Errorf("%s the resource %q is not valid", declaration.GetSpan().ToString(), resource.GetName())
+	}
+
+	object := newJsonObject()
+	if declaration.IsExistingResource() {
+		object.set("existing", true)
+	}
+	converter, err := w.addCopyAndCondition(object, resource, declaration, declaration.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	object.set("type", resourceType.TypeReference.Type)
+	object.set("apiVersion", resourceType.TypeReference.ApiVersion)
+
+	if scope := w.converter.getScopeResource(resource); scope != nil {
+		value, err := w.converter.getScopeString(scope)
+		if err != nil {
+			return nil, err
+		}
+		object.set("scope", value)
+	}
+
+	segments, err := converter.getNameSegments(resource)
+	if err != nil {
+		return nil, err
+	}
+	nameProperty := body.TryGetProperty(syntax.MODULE_PROPERTY_NAME)
+	if len(segments) == 1 {
+		object.set("name", newSourcedValue(w.model, nameProperty, armExpressionToJson(segments[0])))
+	} else {
+		object.set("name", newSourcedValue(w.model, nameProperty, serializeExpression(formatNameSegments(segments))))
+	}
+
+	for _, property := range body.Properties() {
+		name, ok := property.TryGetKeyText()
+		if ok && resourceBodyProperties[name] {
+			continue
+		}
+		key, err := converter.convertPropertyKey(property)
+		if err != nil {
+			return nil, err
+		}
+		value, err := converter.ConvertToJson(property.Value)
+		if err != nil {
+			return nil, err
+		}
+		object.set(key, newSourcedValue(w.model, property, value))
+	}
+
+	dependsOn, err := w.buildDependsOn(resource, converter)
+	if err != nil {
+		return nil, err
+	}
+	if len(dependsOn) > 0 {
+		object.set(syntax.RESOURCE_PROPERTY_DEPENDS_ON, dependsOn)
+	}
+	return object, nil
+}
+
+// addCopyAndCondition sets the copy property of a resource or module loop and the condition of a conditional
+// declaration, and returns the converter for the body of the declaration. Resources nested in a resource loop are
+// deployed with a copy of its loop.
+func (w *TemplateWriter) addCopyAndCondition(object *jsonObject, symbol semantics.DeclaredSymbol, declaration syntax.DecorableSyntax, value syntax.SyntaxBase) (*ExpressionConverter, error) {
+	converter := w.converter
+	if owner := getLoopOwner(symbol); owner != nil {
+		loopCopy, loopConverter, err := w.buildCopy(getSymbolicName(symbol), declaration, owner)
+		if err != nil {
+			return nil, err
+		}
+		object.set(syntax.LOOP_IDENTIFIER_COPY, loopCopy)
+		converter = loopConverter
+	}
+	if loop, ok := value.(*syntax.ForSyntax); ok {
+		value = loop.Body
+	}
+	if condition, ok := value.(*syntax.IfConditionSyntax); ok {
+		value, err := converter.ConvertToJson(condition.ConditionExpression)
+		if err != nil {
+			return nil, err
+		}
+		object.set("condition", value)
+	}
+	return converter, nil
+}
+
+// buildCopy creates the copy property of the loop of owner. The returned converter replaces the item variable of
+// the loop by the current item, and the index variable by copyIndex(). Loops decorated with @batchSize deploy
+// their instances serially, in batches of the given size.
+func (w *TemplateWriter) buildCopy(name string, declaration syntax.DecorableSyntax, owner semantics.DeclaredSymbol) (*jsonObject, *ExpressionConverter, error) {
+	count, converter, err := w.converter.convertLoop(getDeclarationLoop(owner), copyIndex(""))
+	if err != nil {
+		return nil, nil, err
+	}
+	converter.instances = w.converter.withInstance(owner, copyIndex(""))
+
+	loopCopy := newJsonObject()
+	loopCopy.set("name", name)
+	loopCopy.set("count", armExpressionToJson(count))
+	if decorator := w.model.Binder.TryGetDecorator(declaration, namespaces.DECORATOR_BATCH_SIZE); decorator != nil && len(decorator.Arguments()) == 1 {
+		batchSize, err := w.converter.ConvertToJson(decorator.Arguments()[0].Expression)
+		if err != nil {
+			return nil, nil, err
+		}
+		loopCopy.set("mode", "serial")
+		loopCopy.set("batchSize", batchSize)
+	}
+	return loopCopy, converter, nil
+}
+
+// buildDependsOn lists the resources and modules a resource or module depends on. Dependencies on every
+// instance of a loop are written as the name of the loop, and language version 2.0 templates refer to every
+// dependency by its symbolic name. The indexes of dependencies on single loop instances are converted with the
+// converter of the dependent declaration, as they may use its loop variables; indexes using the variables of
+// property loops, which are not known at the declaration, depend on every instance.
+func (w *TemplateWriter) buildDependsOn(symbol semantics.DeclaredSymbol, converter *ExpressionConverter) ([]any, error) {
+	var dependsOn []any
+	for _, dependency := range w.dependencies.GetDependencies(symbol) {
+		if dependency.IndexExpression == nil && converter.isLoopInstance(dependency.Resource) {
+			// the parent of a resource nested in a resource loop is the instance of the current iteration
+			if w.symbolicNames {
+				dependsOn = append(dependsOn, armExpressionToJson(converter.getSymbolicReference(dependency.Resource)))
+				continue
+			}
+			id, err := converter.getDeclarationId(dependency.Resource)
+			if err != nil {
+				return nil, err
+			}
+			dependsOn = append(dependsOn, serializeExpression(id))
+			continue
+		}
+		if isDeclarationLoop(dependency.Resource) && dependency.IndexExpression != nil && converter.canConvert(dependency.IndexExpression) {
+			index, err := converter.ConvertExpression(dependency.IndexExpression)
+			if err != nil {
+				return nil, err
+			}
+			instance, err := converter.withLoopInstance(dependency.Resource, index)
+			if err != nil {
+				return nil, err
+			}
+			if w.symbolicNames {
+				dependsOn = append(dependsOn, armExpressionToJson(instance.getSymbolicReference(dependency.Resource)))
+				continue
+			}
+			id, err := instance.getDeclarationId(dependency.Resource)
+			if err != nil {
+				return nil, err
+			}
+			dependsOn = append(dependsOn, serializeExpression(id))
+			continue
+		}
+		if w.symbolicNames || isDeclarationLoop(dependency.Resource) {
+			dependsOn = append(dependsOn, getSymbolicName(dependency.Resource))
+			continue
+		}
+		id, err := w.converter.getDeclarationId(dependency.Resource)
+		if err != nil {
+			return nil, err
+		}
+		dependsOn = append(dependsOn, serializeExpression(id))
+	}
+	return dependsOn, nil
+}
@@ -0,0 +1,1773 @@
+package emit
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/parser"
+	"bicep-go/semantics"
+	"bicep-go/syntax"
+	"bicep-go/types"
+	"bicep-go/util"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var templateHashPattern = regexp.MustCompile(`"templateHash": "\d+"`)
+
+// writeTemplate emits a file without errors, blanking the template hash, which TestTemplateWriterCorpus checks.
+func writeTemplate(t *testing.T, text string, options EmitterOptions) (string, error) {
+	var buffer bytes.Buffer
+	err := NewTemplateWriter(newTestModel(t, text), options).Write(&buffer)
+	return templateHashPattern.ReplaceAllString(buffer.String(), `"templateHash": ""`), err
+}
+
+// TestTemplateWriterCorpus compares the templates of the Bicep files in testdata byte for byte, hashes included, with
+// the templates next to them, written with the generator version they record.
+func TestTemplateWriterCorpus(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.bicep"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".bicep"), func(t *testing.T) {
+			text, err := os.ReadFile(file)
+			require.NoError(t, err)
+			expected, err := os.ReadFile(strings.TrimSuffix(file, ".bicep") + ".json")
+			require.NoError(t, err)
+			var template struct {
+				Metadata struct {
+					Generator struct {
+						Version string `json:"version"`
+					} `json:"_generator"`
+				} `json:"metadata"`
+			}
+			require.NoError(t, json.Unmarshal(expected, &template))
+
+			var buffer bytes.Buffer
+			options := EmitterOptions{GeneratorVersion: template.Metadata.Generator.Version}
+			require.NoError(t, NewTemplateWriter(newTestModel(t, string(text)), options).Write(&buffer))
+			require.Equal(t, string(expected), buffer.String())
+		})
+	}
+}
+
+func TestTemplateWriterGeneratorVersion(t *testing.T) {
+	model := newTestModel(t, "output name string = 'a'\n")
+	var defaultVersion, otherVersion bytes.Buffer
+	require.NoError(t, NewTemplateWriter(model, EmitterOptions{}).Write(&defaultVersion))
+	require.NoError(t, NewTemplateWriter(model, EmitterOptions{GeneratorVersion: "0.30.23.60470"}).Write(&otherVersion))
+
+	require.Contains(t, defaultVersion.String(), `"version": "`+DEFAULT_GENERATOR_VERSION+`"`)
+	require.Contains(t, otherVersion.String(), `"version": "0.30.23.60470"`)
+	require.NotEqual(t, templateHashPattern.FindString(defaultVersion.String()), templateHashPattern.FindString(otherVersion.String()))
+}
+
+func TestTemplateWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"empty", "", `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "resources": []
+}`},
+		{"parameters, variables and outputs", "metadata owner = 'team'\n@minLength(3)\n@description('The name')\nparam name string = 'stg${uniqueString(resourceGroup().id)}'\nparam sku 'Standard_LRS' | 'Premium_LRS' = 'Standard_LRS'\n@secure()\nparam password string\nparam tag string?\n@allowed([\n  1\n  2\n])\n@metadata({\n  unit: 'GB'\n})\nparam size int = 1\nvar tags = {\n  env: 'dev'\n  '[x]': name\n}\n@description('The names')\noutput names array = [name, '${name}-{x}']\noutput large bool = size > 1\n", `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    },
+    "owner": "team"
+  },
+  "parameters": {
+    "name": {
+      "type": "string",
+      "defaultValue": "[format('stg{0}', uniqueString(resourceGroup().id))]",
+      "minLength": 3,
+      "metadata": {
+        "description": "The name"
+      }
+    },
+    "sku": {
+      "type": "string",
+      "defaultValue": "Standard_LRS",
+      "allowedValues": [
+        "Standard_LRS",
+        "Premium_LRS"
+      ]
+    },
+    "password": {
+      "type": "securestring"
+    },
+    "tag": {
+      "type": "string",
+      "nullable": true
+    },
+    "size": {
+      "type": "int",
+      "defaultValue": 1,
+      "allowedValues": [
+        1,
+        2
+      ],
+      "metadata": {
+        "unit": "GB"
+      }
+    }
+  },
+  "variables": {
+    "tags": {
+      "env": "dev",
+      "[[x]": "[parameters('name')]"
+    }
+  },
+  "resources": [],
+  "outputs": {
+    "names": {
+      "type": "array",
+      "metadata": {
+        "description": "The names"
+      },
+      "value": [
+        "[parameters('name')]",
+        "[format('{0}-{{x}}', parameters('name'))]"
+      ]
+    },
+    "large": {
+      "type": "bool",
+      "value": "[greater(parameters('size'), 1)]"
+    }
+  }
+}`},
+		{"resources", "param name string\nresource stg 'Microsoft.Storage/storageAccounts@2023-01-01' = {\n  name: name\n  location: resourceGroup().location\n  kind: 'StorageV2'\n  resource blob 'blobServices' = {\n    name: 'default'\n  }\n}\nresource container 'Microsoft.Storage/storageAccounts/blobServices/containers@2023-01-01' = {\n  parent: stg::blob\n  name: 'logs'\n}\nresource lock 'Microsoft.Authorization/locks@2020-05-01' = if (name != 'test') {\n  name: 'lock'\n  scope: stg\n  properties: {\n    level: 'CanNotDelete'\n  }\n}\n", `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "parameters": {
+    "name": {
+      "type": "string"
+    }
+  },
+  "resources": [
+    {
+      "type": "Microsoft.Storage/storageAccounts",
+      "apiVersion": "2023-01-01",
+      "name": "[parameters('name')]",
+      "location": "[resourceGroup().location]",
+      "kind": "StorageV2"
+    },
+    {
+      "type": "Microsoft.Storage/storageAccounts/blobServices",
+      "apiVersion": "2023-01-01",
+      "name": "[format('{0}/{1}', parameters('name'), 'default')]",
+      "dependsOn": [
+        "[resourceId('Microsoft.Storage/storageAccounts', parameters('name'))]"
+      ]
+    },
+    {
+      "type": "Microsoft.Storage/storageAccounts/blobServices/containers",
+      "apiVersion": "2023-01-01",
+      "name": "[format('{0}/{1}/{2}', parameters('name'), 'default', 'logs')]",
+      "dependsOn": [
+        "[resourceId('Microsoft.Storage/storageAccounts/blobServices', parameters('name'), 'default')]"
+      ]
+    },
+    {
+      "condition": "[not(equals(parameters('name'), 'test'))]",
+      "type": "Microsoft.Authorization/locks",
+      "apiVersion": "2020-05-01",
+      "scope": "[format('Microsoft.Storage/storageAccounts/{0}', parameters('name'))]",
+      "name": "lock",
+      "properties": {
+        "level": "CanNotDelete"
+      },
+      "dependsOn": [
+        "[resourceId('Microsoft.Storage/storageAccounts', parameters('name'))]"
+      ]
+    }
+  ]
+}`},
+		{"resource loop", "param names array\nresource stg 'Microsoft.Storage/storageAccounts@2023-01-01' = [for (name, i) in names: {\n  name: name\n  properties: {\n    index: i\n  }\n}]\nresource lock 'Microsoft.Authorization/locks@2020-05-01' = {\n  name: 'lock'\n  dependsOn: [\n    stg\n  ]\n}\n", `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "parameters": {
+    "names": {
+      "type": "array"
+    }
+  },
+  "resources": [
+    {
+      "copy": {
+        "name": "stg",
+        "count": "[length(parameters('names'))]"
+      },
+      "type": "Microsoft.Storage/storageAccounts",
+      "apiVersion": "2023-01-01",
+      "name": "[parameters('names')[copyIndex()]]",
+      "properties": {
+        "index": "[copyIndex()]"
+      }
+    },
+    {
+      "type": "Microsoft.Authorization/locks",
+      "apiVersion": "2020-05-01",
+      "name": "lock",
+      "dependsOn": [
+        "stg"
+      ]
+    }
+  ]
+}`},
+		{"subscription scope", "targetScope = 'subscription'\nresource rg 'Microsoft.Resources/resourceGroups@2022-09-01' = {\n  name: 'rg'\n  location: 'westus'\n}\nresource lock 'Microsoft.Authorization/locks@2020-05-01' = {\n  name: 'lock'\n  dependsOn: [\n    rg\n  ]\n}\n", `{
+  "$schema": "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "resources": [
+    {
+      "type": "Microsoft.Resources/resourceGroups",
+      "apiVersion": "2022-09-01",
+      "name": "rg",
+      "location": "westus"
+    },
+    {
+      "type": "Microsoft.Authorization/locks",
+      "apiVersion": "2020-05-01",
+      "name": "lock",
+      "dependsOn": [
+        "[subscriptionResourceId('Microsoft.Resources/resourceGroups', 'rg')]"
+      ]
+    }
+  ]
+}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := writeTemplate(t, tt.input, EmitterOptions{})
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, template)
+		})
+	}
+}
+
+func TestTemplateWriterUnsupported(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"resource loop nested in resource loop", "resource vnets 'Microsoft.Network/virtualNetworks@2020-06-01' = [for name in ['a']: {\n  name: name\n  resource subnets 'subnets' = [for i in range(0, 2): {\n    name: string(i)\n  }]\n}]\n", "[110:117] resource loops nested in resource loops cannot be emitted yet"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := writeTemplate(t, tt.input, EmitterOptions{})
+			require.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestTemplateWriterSymbolicNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  EmitterOptions
+		expected string
+	}{
+		{"forced", "param name string\nresource stg 'Microsoft.Storage/storageAccounts@2023-01-01' = {\n  name: name\n  resource blob 'blobServices' = {\n    name: 'default'\n  }\n}\nresource lock 'Microsoft.Authorization/locks@2020-05-01' = {\n  name: 'lock'\n  scope: stg\n  dependsOn: [\n    stg::blob\n  ]\n}\n", EmitterOptions{SymbolicNames: true}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "languageVersion": "2.0",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "parameters": {
+    "name": {
+      "type": "string"
+    }
+  },
+  "resources": {
+    "stg": {
+      "type": "Microsoft.Storage/storageAccounts",
+      "apiVersion": "2023-01-01",
+      "name": "[parameters('name')]"
+    },
+    "stg::blob": {
+      "type": "Microsoft.Storage/storageAccounts/blobServices",
+      "apiVersion": "2023-01-01",
+      "name": "[format('{0}/{1}', parameters('name'), 'default')]",
+      "dependsOn": [
+        "stg"
+      ]
+    },
+    "lock": {
+      "type": "Microsoft.Authorization/locks",
+      "apiVersion": "2020-05-01",
+      "scope": "[format('Microsoft.Storage/storageAccounts/{0}', parameters('name'))]",
+      "name": "lock",
+      "dependsOn": [
+        "stg::blob"
+      ]
+    }
+  }
+}`},
+		{"existing resource", "resource other 'Microsoft.Storage/storageAccounts@2023-01-01' existing = {\n  name: 'other'\n}\nresource lock 'Microsoft.Authorization/locks@2020-05-01' = {\n  name: 'lock'\n  scope: other\n}\n", EmitterOptions{}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "languageVersion": "2.0",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "resources": {
+    "other": {
+      "existing": true,
+      "type": "Microsoft.Storage/storageAccounts",
+      "apiVersion": "2023-01-01",
+      "name": "other"
+    },
+    "lock": {
+      "type": "Microsoft.Authorization/locks",
+      "apiVersion": "2020-05-01",
+      "scope": "[format('Microsoft.Storage/storageAccounts/{0}', 'other')]",
+      "name": "lock"
+    }
+  }
+}`},
+		{"types", "@export()\n@description('A size')\n@minValue(1)\ntype size = int\ntype settings = {\n  name: string\n  tier: 'Basic' | 'Premium'\n  size: size?\n  tags: {\n    *: string\n  }\n}\n@sealed()\ntype point = {\n  x: int\n  y: int\n}\ntype pair = [string, @maxLength(3) string]\n@discriminator('kind')\ntype shape = {\n  kind: 'circle'\n  radius: int\n} | {\n  kind: 'square'\n  side: int\n}\nparam config settings\nparam tier settings.tier = 'Basic'\nparam shapes shape[]\n@secure()\nparam password string\noutput optional string? = null\n", EmitterOptions{}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "languageVersion": "2.0",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "definitions": {
+    "size": {
+      "type": "int",
+      "metadata": {
+        "__bicep_export!": true,
+        "description": "A size"
+      },
+      "minValue": 1
+    },
+    "settings": {
+      "type": "object",
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "tier": {
+          "type": "string",
+          "allowedValues": [
+            "Basic",
+            "Premium"
+          ]
+        },
+        "size": {
+          "$ref": "#/definitions/size",
+          "nullable": true
+        },
+        "tags": {
+          "type": "object",
+          "properties": {},
+          "additionalProperties": {
+            "type": "string"
+          }
+        }
+      }
+    },
+    "point": {
+      "type": "object",
+      "properties": {
+        "x": {
+          "type": "int"
+        },
+        "y": {
+          "type": "int"
+        }
+      },
+      "additionalProperties": false
+    },
+    "pair": {
+      "type": "array",
+      "prefixItems": [
+        {
+          "type": "string"
+        },
+        {
+          "type": "string",
+          "maxLength": 3
+        }
+      ],
+      "items": false
+    },
+    "shape": {
+      "type": "object",
+      "discriminator": {
+        "propertyName": "kind",
+        "mapping": {
+          "circle": {
+            "type": "object",
+            "properties": {
+              "kind": {
+                "type": "string",
+                "allowedValues": [
+                  "circle"
+                ]
+              },
+              "radius": {
+                "type": "int"
+              }
+            }
+          },
+          "square": {
+            "type": "object",
+            "properties": {
+              "kind": {
+                "type": "string",
+                "allowedValues": [
+                  "square"
+                ]
+              },
+              "side": {
+                "type": "int"
+              }
+            }
+          }
+        }
+      }
+    }
+  },
+  "parameters": {
+    "config": {
+      "$ref": "#/definitions/settings"
+    },
+    "tier": {
+      "$ref": "#/definitions/settings/properties/tier",
+      "defaultValue": "Basic"
+    },
+    "shapes": {
+      "type": "array",
+      "items": {
+        "$ref": "#/definitions/shape"
+      }
+    },
+    "password": {
+      "type": "securestring"
+    }
+  },
+  "resources": {},
+  "outputs": {
+    "optional": {
+      "type": "string",
+      "nullable": true,
+      "value": null
+    }
+  }
+}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := writeTemplate(t, tt.input, tt.options)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, template)
+		})
+	}
+}
+
+func TestTemplateWriterLoops(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  EmitterOptions
+		expected string
+	}{
+		{"resource loops", "param names array\n@batchSize(2)\nresource stg 'Microsoft.Storage/storageAccounts@2023-01-01' = [for (name, i) in names: if (i > 0) {\n  name: name\n  properties: {\n    index: i\n  }\n}]\nresource locks 'Microsoft.Authorization/locks@2020-05-01' = [for i in range(1, 3): {\n  name: 'lock${i}'\n  dependsOn: [\n    stg[i]\n  ]\n}]\noutput firstId string = stg[0].id\noutput tier string = stg[1].properties.accessTier\n", EmitterOptions{}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "parameters": {
+    "names": {
+      "type": "array"
+    }
+  },
+  "resources": [
+    {
+      "copy": {
+        "name": "stg",
+        "count": "[length(parameters('names'))]",
+        "mode": "serial",
+        "batchSize": 2
+      },
+      "condition": "[greater(copyIndex(), 0)]",
+      "type": "Microsoft.Storage/storageAccounts",
+      "apiVersion": "2023-01-01",
+      "name": "[parameters('names')[copyIndex()]]",
+      "properties": {
+        "index": "[copyIndex()]"
+      }
+    },
+    {
+      "copy": {
+        "name": "locks",
+        "count": "[length(range(1, 3))]"
+      },
+      "type": "Microsoft.Authorization/locks",
+      "apiVersion": "2020-05-01",
+      "name": "[format('lock{0}', range(1, 3)[copyIndex()])]",
+      "dependsOn": [
+        "[resourceId('Microsoft.Storage/storageAccounts', parameters('names')[range(1, 3)[copyIndex()]])]"
+      ]
+    }
+  ],
+  "outputs": {
+    "firstId": {
+      "type": "string",
+      "value": "[resourceId('Microsoft.Storage/storageAccounts', parameters('names')[0])]"
+    },
+    "tier": {
+      "type": "string",
+      "value": "[reference(resourceId('Microsoft.Storage/storageAccounts', parameters('names')[1]), '2023-01-01').accessTier]"
+    }
+  }
+}`},
+		{"property, variable and output loops", "param subnets array\nvar names = [for (subnet, i) in subnets: '${subnet.name}-${i}']\nvar doubled = [for i in range(0, 3): i * 2]\nresource vnet 'Microsoft.Network/virtualNetworks@2023-04-01' = {\n  name: 'vnet'\n  properties: {\n    subnets: [for subnet in subnets: {\n      name: subnet.name\n      properties: {\n        addressPrefix: subnet.prefix\n      }\n    }]\n  }\n}\noutput prefixes array = [for subnet in subnets: subnet.prefix]\n", EmitterOptions{}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "parameters": {
+    "subnets": {
+      "type": "array"
+    }
+  },
+  "variables": {
+    "copy": [
+      {
+        "name": "names",
+        "count": "[length(parameters('subnets'))]",
+        "input": "[format('{0}-{1}', parameters('subnets')[copyIndex('names')].name, copyIndex('names'))]"
+      },
+      {
+        "name": "doubled",
+        "count": "[length(range(0, 3))]",
+        "input": "[mul(range(0, 3)[copyIndex('doubled')], 2)]"
+      }
+    ]
+  },
+  "resources": [
+    {
+      "type": "Microsoft.Network/virtualNetworks",
+      "apiVersion": "2023-04-01",
+      "name": "vnet",
+      "properties": {
+        "copy": [
+          {
+            "name": "subnets",
+            "count": "[length(parameters('subnets'))]",
+            "input": {
+              "name": "[parameters('subnets')[copyIndex('subnets')].name]",
+              "properties": {
+                "addressPrefix": "[parameters('subnets')[copyIndex('subnets')].prefix]"
+              }
+            }
+          }
+        ]
+      }
+    }
+  ],
+  "outputs": {
+    "prefixes": {
+      "type": "array",
+      "copy": {
+        "count": "[length(parameters('subnets'))]",
+        "input": "[parameters('subnets')[copyIndex()].prefix]"
+      }
+    }
+  }
+}`},
+		{"symbolic names", "resource stg 'Microsoft.Storage/storageAccounts@2023-01-01' = [for i in range(0, 2): {\n  name: 'stg${i}'\n}]\nresource lock 'Microsoft.Authorization/locks@2020-05-01' = {\n  name: 'lock'\n  dependsOn: [\n    stg[0]\n  ]\n}\noutput tier string = stg[1].properties.accessTier\n", EmitterOptions{SymbolicNames: true}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "languageVersion": "2.0",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "resources": {
+    "stg": {
+      "copy": {
+        "name": "stg",
+        "count": "[length(range(0, 2))]"
+      },
+      "type": "Microsoft.Storage/storageAccounts",
+      "apiVersion": "2023-01-01",
+      "name": "[format('stg{0}', range(0, 2)[copyIndex()])]"
+    },
+    "lock": {
+      "type": "Microsoft.Authorization/locks",
+      "apiVersion": "2020-05-01",
+      "name": "lock",
+      "dependsOn": [
+        "[format('stg[{0}]', 0)]"
+      ]
+    }
+  },
+  "outputs": {
+    "tier": {
+      "type": "string",
+      "value": "[reference(format('stg[{0}]', 1)).accessTier]"
+    }
+  }
+}`},
+		{"resources nested in resource loops", "param names array\nresource vnets 'Microsoft.Network/virtualNetworks@2020-06-01' = [for name in names: {\n  name: name\n  resource subnet 'subnets' = {\n    name: 'default'\n  }\n  resource other 'subnets' = {\n    name: 'other'\n    properties: {\n      id: subnet.id\n    }\n  }\n}]\noutput subnetId string = vnets[0]::subnet.id\n", EmitterOptions{}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "parameters": {
+    "names": {
+      "type": "array"
+    }
+  },
+  "resources": [
+    {
+      "copy": {
+        "name": "vnets",
+        "count": "[length(parameters('names'))]"
+      },
+      "type": "Microsoft.Network/virtualNetworks",
+      "apiVersion": "2020-06-01",
+      "name": "[parameters('names')[copyIndex()]]"
+    },
+    {
+      "copy": {
+        "name": "vnets::subnet",
+        "count": "[length(parameters('names'))]"
+      },
+      "type": "Microsoft.Network/virtualNetworks/subnets",
+      "apiVersion": "2020-06-01",
+      "name": "[format('{0}/{1}', parameters('names')[copyIndex()], 'default')]",
+      "dependsOn": [
+        "[resourceId('Microsoft.Network/virtualNetworks', parameters('names')[copyIndex()])]"
+      ]
+    },
+    {
+      "copy": {
+        "name": "vnets::other",
+        "count": "[length(parameters('names'))]"
+      },
+      "type": "Microsoft.Network/virtualNetworks/subnets",
+      "apiVersion": "2020-06-01",
+      "name": "[format('{0}/{1}', parameters('names')[copyIndex()], 'other')]",
+      "properties": {
+        "id": "[resourceId('Microsoft.Network/virtualNetworks/subnets', parameters('names')[copyIndex()], 'default')]"
+      },
+      "dependsOn": [
+        "[resourceId('Microsoft.Network/virtualNetworks/subnets', parameters('names')[copyIndex()], 'default')]"
+      ]
+    }
+  ],
+  "outputs": {
+    "subnetId": {
+      "type": "string",
+      "value": "[resourceId('Microsoft.Network/virtualNetworks/subnets', parameters('names')[0], 'default')]"
+    }
+  }
+}`},
+		{"resources nested in resource loops with symbolic names", "param names array\nresource vnets 'Microsoft.Network/virtualNetworks@2020-06-01' = [for name in names: {\n  name: name\n  resource subnet 'subnets' = {\n    name: 'default'\n  }\n  resource other 'subnets' = {\n    name: 'other'\n    properties: {\n      id: subnet.id\n    }\n  }\n}]\noutput subnetId string = vnets[0]::subnet.id\n", EmitterOptions{SymbolicNames: true}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "languageVersion": "2.0",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "parameters": {
+    "names": {
+      "type": "array"
+    }
+  },
+  "resources": {
+    "vnets": {
+      "copy": {
+        "name": "vnets",
+        "count": "[length(parameters('names'))]"
+      },
+      "type": "Microsoft.Network/virtualNetworks",
+      "apiVersion": "2020-06-01",
+      "name": "[parameters('names')[copyIndex()]]"
+    },
+    "vnets::subnet": {
+      "copy": {
+        "name": "vnets::subnet",
+        "count": "[length(parameters('names'))]"
+      },
+      "type": "Microsoft.Network/virtualNetworks/subnets",
+      "apiVersion": "2020-06-01",
+      "name": "[format('{0}/{1}', parameters('names')[copyIndex()], 'default')]",
+      "dependsOn": [
+        "[format('vnets[{0}]', copyIndex())]"
+      ]
+    },
+    "vnets::other": {
+      "copy": {
+        "name": "vnets::other",
+        "count": "[length(parameters('names'))]"
+      },
+      "type": "Microsoft.Network/virtualNetworks/subnets",
+      "apiVersion": "2020-06-01",
+      "name": "[format('{0}/{1}', parameters('names')[copyIndex()], 'other')]",
+      "properties": {
+        "id": "[resourceId('Microsoft.Network/virtualNetworks/subnets', parameters('names')[copyIndex()], 'default')]"
+      },
+      "dependsOn": [
+        "[format('vnets::subnet[{0}]', copyIndex())]"
+      ]
+    }
+  },
+  "outputs": {
+    "subnetId": {
+      "type": "string",
+      "value": "[resourceId('Microsoft.Network/virtualNetworks/subnets', parameters('names')[0], 'default')]"
+    }
+  }
+}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := writeTemplate(t, tt.input, tt.options)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, template)
+		})
+	}
+}
+
+// testModuleLookup resolves module and import paths to the models of files without errors.
+type testModuleLookup map[string]semantics.ModuleModel
+
+func (l testModuleLookup) TryGetModuleModel(declaration *syntax.ModuleDeclarationSyntax) (semantics.ModuleModel, *diagnostics.Diagnostic) {
+	path, _ := declaration.TryGetPath()
+	return l[path], nil
+}
+
+func (l testModuleLookup) TryGetImportedModel(declaration *syntax.CompileTimeImportDeclarationSyntax) (semantics.ModuleModel, *diagnostics.Diagnostic) {
+	path, _ := declaration.TryGetPath()
+	return l[path], nil
+}
+
+func (l testModuleLookup) TryReadFile(program *syntax.ProgramSyntax, span *util.TextSpan, filePath string) ([]byte, *diagnostics.Diagnostic) {
+	return nil, diagnostics.NewError(span, "BCP091", "An error occurred reading file. open "+filePath+": file does not exist")
+}
+
+// writeModuleTemplate emits a file referencing the given Bicep and ARM JSON module files, which may reference each
+// other, blanking the template hashes.
+func writeModuleTemplate(t *testing.T, text string, files map[string]string, options EmitterOptions) (string, error) {
+	modules := testModuleLookup{}
+	for path, content := range files {
+		if strings.HasSuffix(path, ".json") {
+			model, err := semantics.NewArmTemplateSemanticModel([]byte(content))
+			require.NoError(t, err)
+			modules[path] = model
+			continue
+		}
+		p := parser.New(content)
+		program := p.Program()
+		require.Empty(t, p.GetDiagnostics())
+		modules[path] = semantics.NewSemanticModel(program, types.NewGenericResourceTypeProvider(), modules)
+	}
+	for _, module := range modules {
+		if model, ok := module.(*semantics.SemanticModel); ok {
+			for _, diagnostic := range model.GetDiagnostics() {
+				require.False(t, diagnostic.IsError(), diagnostic.ToString())
+			}
+		}
+	}
+
+	p := parser.New(text)
+	program := p.Program()
+	require.Empty(t, p.GetDiagnostics())
+	model := semantics.NewSemanticModel(program, types.NewGenericResourceTypeProvider(), modules)
+	for _, diagnostic := range model.GetDiagnostics() {
+		require.False(t, diagnostic.IsError(), diagnostic.ToString())
+	}
+
+	var buffer bytes.Buffer
+	err := NewTemplateWriter(model, options).Write(&buffer)
+	return templateHashPattern.ReplaceAllString(buffer.String(), `"templateHash": ""`), err
+}
+
+func TestTemplateWriterModules(t *testing.T) {
+	files := map[string]string{
+		"app.bicep":   "param size int\nparam account string\noutput endpoint string = '${account}-${size}'\n",
+		"group.bicep": "targetScope = 'subscription'\nparam names array\noutput count int = length(names)\n",
+		"plan.json":   "{\n  \"$schema\": \"https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#\",\n  \"contentVersion\": \"1.0.0.0\",\n  \"parameters\": {\n    \"sku\": {\n      \"type\": \"string\"\n    }\n  },\n  \"resources\": [],\n  \"outputs\": {\n    \"id\": {\n      \"type\": \"string\",\n      \"value\": \"[parameters('sku')]\"\n    }\n  }\n}\n",
+	}
+	tests := []struct {
+		name     string
+		input    string
+		options  EmitterOptions
+		expected string
+	}{
+		{"module", "param prefix string\nresource stg 'Microsoft.Storage/storageAccounts@2023-01-01' = {\n  name: prefix\n}\nmodule app 'app.bicep' = {\n  name: '${prefix}-app'\n  params: {\n    size: 2\n    account: stg.properties.primaryEndpoints.blob\n  }\n}\noutput endpoint string = app.outputs.endpoint\noutput deployment string = app.name\n", EmitterOptions{}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "parameters": {
+    "prefix": {
+      "type": "string"
+    }
+  },
+  "resources": [
+    {
+      "type": "Microsoft.Storage/storageAccounts",
+      "apiVersion": "2023-01-01",
+      "name": "[parameters('prefix')]"
+    },
+    {
+      "type": "Microsoft.Resources/deployments",
+      "apiVersion": "2022-09-01",
+      "name": "[format('{0}-app', parameters('prefix'))]",
+      "properties": {
+        "expressionEvaluationOptions": {
+          "scope": "inner"
+        },
+        "mode": "Incremental",
+        "parameters": {
+          "size": {
+            "value": 2
+          },
+          "account": {
+            "value": "[reference(resourceId('Microsoft.Storage/storageAccounts', parameters('prefix')), '2023-01-01').primaryEndpoints.blob]"
+          }
+        },
+        "template": {
+          "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+          "contentVersion": "1.0.0.0",
+          "metadata": {
+            "_generator": {
+              "name": "bicep",
+              "version": "0.1.0",
+              "templateHash": ""
+            }
+          },
+          "parameters": {
+            "size": {
+              "type": "int"
+            },
+            "account": {
+              "type": "string"
+            }
+          },
+          "resources": [],
+          "outputs": {
+            "endpoint": {
+              "type": "string",
+              "value": "[format('{0}-{1}', parameters('account'), parameters('size'))]"
+            }
+          }
+        }
+      },
+      "dependsOn": [
+        "[resourceId('Microsoft.Storage/storageAccounts', parameters('prefix'))]"
+      ]
+    }
+  ],
+  "outputs": {
+    "endpoint": {
+      "type": "string",
+      "value": "[reference(resourceId('Microsoft.Resources/deployments', format('{0}-app', parameters('prefix'))), '2022-09-01').outputs.endpoint.value]"
+    },
+    "deployment": {
+      "type": "string",
+      "value": "[format('{0}-app', parameters('prefix'))]"
+    }
+  }
+}`},
+		{"scopes", "targetScope = 'subscription'\nmodule rg 'app.bicep' = {\n  name: 'rg'\n  scope: resourceGroup('other', 'rg')\n  params: {\n    size: 1\n    account: 'a'\n  }\n}\nmodule sub 'group.bicep' = {\n  name: 'sub'\n  scope: subscription('other')\n  params: {\n    names: [rg.outputs.endpoint]\n  }\n}\noutput count int = sub.outputs.count\n", EmitterOptions{}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "resources": [
+    {
+      "type": "Microsoft.Resources/deployments",
+      "apiVersion": "2022-09-01",
+      "name": "rg",
+      "subscriptionId": "other",
+      "resourceGroup": "rg",
+      "properties": {
+        "expressionEvaluationOptions": {
+          "scope": "inner"
+        },
+        "mode": "Incremental",
+        "parameters": {
+          "size": {
+            "value": 1
+          },
+          "account": {
+            "value": "a"
+          }
+        },
+        "template": {
+          "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+          "contentVersion": "1.0.0.0",
+          "metadata": {
+            "_generator": {
+              "name": "bicep",
+              "version": "0.1.0",
+              "templateHash": ""
+            }
+          },
+          "parameters": {
+            "size": {
+              "type": "int"
+            },
+            "account": {
+              "type": "string"
+            }
+          },
+          "resources": [],
+          "outputs": {
+            "endpoint": {
+              "type": "string",
+              "value": "[format('{0}-{1}', parameters('account'), parameters('size'))]"
+            }
+          }
+        }
+      }
+    },
+    {
+      "type": "Microsoft.Resources/deployments",
+      "apiVersion": "2022-09-01",
+      "name": "sub",
+      "subscriptionId": "other",
+      "location": "[deployment().location]",
+      "properties": {
+        "expressionEvaluationOptions": {
+          "scope": "inner"
+        },
+        "mode": "Incremental",
+        "parameters": {
+          "names": {
+            "value": [
+              "[reference(resourceId('other', 'rg', 'Microsoft.Resources/deployments', 'rg'), '2022-09-01').outputs.endpoint.value]"
+            ]
+          }
+        },
+        "template": {
+          "$schema": "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
+          "contentVersion": "1.0.0.0",
+          "metadata": {
+            "_generator": {
+              "name": "bicep",
+              "version": "0.1.0",
+              "templateHash": ""
+            }
+          },
+          "parameters": {
+            "names": {
+              "type": "array"
+            }
+          },
+          "resources": [],
+          "outputs": {
+            "count": {
+              "type": "int",
+              "value": "[length(parameters('names'))]"
+            }
+          }
+        }
+      },
+      "dependsOn": [
+        "[resourceId('other', 'rg', 'Microsoft.Resources/deployments', 'rg')]"
+      ]
+    }
+  ],
+  "outputs": {
+    "count": {
+      "type": "int",
+      "value": "[reference(subscriptionResourceId('other', 'Microsoft.Resources/deployments', 'sub'), '2022-09-01').outputs.count.value]"
+    }
+  }
+}`},
+		{"resource scopes", "targetScope = 'subscription'\nresource rg 'Microsoft.Resources/resourceGroups@2022-09-01' = {\n  name: 'rg'\n  location: 'westus'\n}\nresource other 'Microsoft.Resources/resourceGroups@2022-09-01' existing = {\n  name: 'other'\n  scope: subscription('sub')\n}\nmodule app 'app.bicep' = {\n  name: 'app'\n  scope: rg\n  params: {\n    size: 1\n    account: 'a'\n  }\n}\nmodule otherApp 'app.bicep' = {\n  name: 'otherApp'\n  scope: other\n  params: {\n    size: 2\n    account: app.outputs.endpoint\n  }\n}\n", EmitterOptions{}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
+  "languageVersion": "2.0",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "resources": {
+    "rg": {
+      "type": "Microsoft.Resources/resourceGroups",
+      "apiVersion": "2022-09-01",
+      "name": "rg",
+      "location": "westus"
+    },
+    "other": {
+      "existing": true,
+      "type": "Microsoft.Resources/resourceGroups",
+      "apiVersion": "2022-09-01",
+      "name": "other"
+    },
+    "app": {
+      "type": "Microsoft.Resources/deployments",
+      "apiVersion": "2022-09-01",
+      "name": "app",
+      "resourceGroup": "rg",
+      "properties": {
+        "expressionEvaluationOptions": {
+          "scope": "inner"
+        },
+        "mode": "Incremental",
+        "parameters": {
+          "size": {
+            "value": 1
+          },
+          "account": {
+            "value": "a"
+          }
+        },
+        "template": {
+          "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+          "contentVersion": "1.0.0.0",
+          "metadata": {
+            "_generator": {
+              "name": "bicep",
+              "version": "0.1.0",
+              "templateHash": ""
+            }
+          },
+          "parameters": {
+            "size": {
+              "type": "int"
+            },
+            "account": {
+              "type": "string"
+            }
+          },
+          "resources": [],
+          "outputs": {
+            "endpoint": {
+              "type": "string",
+              "value": "[format('{0}-{1}', parameters('account'), parameters('size'))]"
+            }
+          }
+        }
+      },
+      "dependsOn": [
+        "rg"
+      ]
+    },
+    "otherApp": {
+      "type": "Microsoft.Resources/deployments",
+      "apiVersion": "2022-09-01",
+      "name": "otherApp",
+      "subscriptionId": "sub",
+      "resourceGroup": "other",
+      "properties": {
+        "expressionEvaluationOptions": {
+          "scope": "inner"
+        },
+        "mode": "Incremental",
+        "parameters": {
+          "size": {
+            "value": 2
+          },
+          "account": {
+            "value": "[reference('app').outputs.endpoint.value]"
+          }
+        },
+        "template": {
+          "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+          "contentVersion": "1.0.0.0",
+          "metadata": {
+            "_generator": {
+              "name": "bicep",
+              "version": "0.1.0",
+              "templateHash": ""
+            }
+          },
+          "parameters": {
+            "size": {
+              "type": "int"
+            },
+            "account": {
+              "type": "string"
+            }
+          },
+          "resources": [],
+          "outputs": {
+            "endpoint": {
+              "type": "string",
+              "value": "[format('{0}-{1}', parameters('account'), parameters('size'))]"
+            }
+          }
+        }
+      },
+      "dependsOn": [
+        "app"
+      ]
+    }
+  }
+}`},
+		{"loops and ARM templates", "@batchSize(1)\nmodule plans 'plan.json' = [for sku in ['S1', 'P1']: {\n  name: 'plan-${sku}'\n  params: {\n    sku: sku\n  }\n}]\nmodule app 'app.bicep' = {\n  name: 'app'\n  params: {\n    size: 1\n    account: plans[1].outputs.id\n  }\n}\nmodule group 'group.bicep' = {\n  name: 'group'\n  scope: subscription()\n  params: {\n    names: [for i in range(0, 2): plans[i].outputs.?id]\n  }\n}\n", EmitterOptions{}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "resources": [
+    {
+      "copy": {
+        "name": "plans",
+        "count": "[length(createArray('S1', 'P1'))]",
+        "mode": "serial",
+        "batchSize": 1
+      },
+      "type": "Microsoft.Resources/deployments",
+      "apiVersion": "2022-09-01",
+      "name": "[format('plan-{0}', createArray('S1', 'P1')[copyIndex()])]",
+      "properties": {
+        "expressionEvaluationOptions": {
+          "scope": "inner"
+        },
+        "mode": "Incremental",
+        "parameters": {
+          "sku": {
+            "value": "[createArray('S1', 'P1')[copyIndex()]]"
+          }
+        },
+        "template": {
+          "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+          "contentVersion": "1.0.0.0",
+          "parameters": {
+            "sku": {
+              "type": "string"
+            }
+          },
+          "resources": [],
+          "outputs": {
+            "id": {
+              "type": "string",
+              "value": "[parameters('sku')]"
+            }
+          }
+        }
+      }
+    },
+    {
+      "type": "Microsoft.Resources/deployments",
+      "apiVersion": "2022-09-01",
+      "name": "app",
+      "properties": {
+        "expressionEvaluationOptions": {
+          "scope": "inner"
+        },
+        "mode": "Incremental",
+        "parameters": {
+          "size": {
+            "value": 1
+          },
+          "account": {
+            "value": "[reference(resourceId('Microsoft.Resources/deployments', format('plan-{0}', createArray('S1', 'P1')[1])), '2022-09-01').outputs.id.value]"
+          }
+        },
+        "template": {
+          "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+          "contentVersion": "1.0.0.0",
+          "metadata": {
+            "_generator": {
+              "name": "bicep",
+              "version": "0.1.0",
+              "templateHash": ""
+            }
+          },
+          "parameters": {
+            "size": {
+              "type": "int"
+            },
+            "account": {
+              "type": "string"
+            }
+          },
+          "resources": [],
+          "outputs": {
+            "endpoint": {
+              "type": "string",
+              "value": "[format('{0}-{1}', parameters('account'), parameters('size'))]"
+            }
+          }
+        }
+      },
+      "dependsOn": [
+        "[resourceId('Microsoft.Resources/deployments', format('plan-{0}', createArray('S1', 'P1')[1]))]"
+      ]
+    },
+    {
+      "type": "Microsoft.Resources/deployments",
+      "apiVersion": "2022-09-01",
+      "name": "group",
+      "subscriptionId": "[subscription().subscriptionId]",
+      "location": "[resourceGroup().location]",
+      "properties": {
+        "expressionEvaluationOptions": {
+          "scope": "inner"
+        },
+        "mode": "Incremental",
+        "parameters": {
+          "names": {
+            "copy": [
+              {
+                "name": "value",
+                "count": "[length(range(0, 2))]",
+                "input": "[tryGet(reference(resourceId('Microsoft.Resources/deployments', format('plan-{0}', createArray('S1', 'P1')[range(0, 2)[copyIndex('value')]])), '2022-09-01').outputs, 'id', 'value')]"
+              }
+            ]
+          }
+        },
+        "template": {
+          "$schema": "https://schema.management.azure.com/schemas/2018-05-01/subscriptionDeploymentTemplate.json#",
+          "contentVersion": "1.0.0.0",
+          "metadata": {
+            "_generator": {
+              "name": "bicep",
+              "version": "0.1.0",
+              "templateHash": ""
+            }
+          },
+          "parameters": {
+            "names": {
+              "type": "array"
+            }
+          },
+          "resources": [],
+          "outputs": {
+            "count": {
+              "type": "int",
+              "value": "[length(parameters('names'))]"
+            }
+          }
+        }
+      },
+      "dependsOn": [
+        "plans"
+      ]
+    }
+  ]
+}`},
+		{"symbolic names", "module plans 'plan.json' = [for sku in ['S1', 'P1']: {\n  name: 'plan-${sku}'\n  params: {\n    sku: sku\n  }\n}]\nmodule app 'app.bicep' = {\n  name: 'app'\n  params: {\n    size: 1\n    account: plans[1].outputs.id\n  }\n}\noutput endpoint string = app.outputs.endpoint\n", EmitterOptions{SymbolicNames: true}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "languageVersion": "2.0",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "resources": {
+    "plans": {
+      "copy": {
+        "name": "plans",
+        "count": "[length(createArray('S1', 'P1'))]"
+      },
+      "type": "Microsoft.Resources/deployments",
+      "apiVersion": "2022-09-01",
+      "name": "[format('plan-{0}', createArray('S1', 'P1')[copyIndex()])]",
+      "properties": {
+        "expressionEvaluationOptions": {
+          "scope": "inner"
+        },
+        "mode": "Incremental",
+        "parameters": {
+          "sku": {
+            "value": "[createArray('S1', 'P1')[copyIndex()]]"
+          }
+        },
+        "template": {
+          "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+          "contentVersion": "1.0.0.0",
+          "parameters": {
+            "sku": {
+              "type": "string"
+            }
+          },
+          "resources": [],
+          "outputs": {
+            "id": {
+              "type": "string",
+              "value": "[parameters('sku')]"
+            }
+          }
+        }
+      }
+    },
+    "app": {
+      "type": "Microsoft.Resources/deployments",
+      "apiVersion": "2022-09-01",
+      "name": "app",
+      "properties": {
+        "expressionEvaluationOptions": {
+          "scope": "inner"
+        },
+        "mode": "Incremental",
+        "parameters": {
+          "size": {
+            "value": 1
+          },
+          "account": {
+            "value": "[reference(format('plans[{0}]', 1)).outputs.id.value]"
+          }
+        },
+        "template": {
+          "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+          "languageVersion": "2.0",
+          "contentVersion": "1.0.0.0",
+          "metadata": {
+            "_generator": {
+              "name": "bicep",
+              "version": "0.1.0",
+              "templateHash": ""
+            }
+          },
+          "parameters": {
+            "size": {
+              "type": "int"
+            },
+            "account": {
+              "type": "string"
+            }
+          },
+          "resources": {},
+          "outputs": {
+            "endpoint": {
+              "type": "string",
+              "value": "[format('{0}-{1}', parameters('account'), parameters('size'))]"
+            }
+          }
+        }
+      },
+      "dependsOn": [
+        "[format('plans[{0}]', 1)]"
+      ]
+    }
+  },
+  "outputs": {
+    "endpoint": {
+      "type": "string",
+      "value": "[reference('app').outputs.endpoint.value]"
+    }
+  }
+}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := writeModuleTemplate(t, tt.input, files, tt.options)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, template)
+		})
+	}
+}
+
+func TestTemplateWriterFunctions(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		options  EmitterOptions
+		expected string
+	}{
+		{"functions", "@description('Builds a URL.')\nfunc buildUrl(https bool, host string, path string?) string => '${https ? 'https' : 'http'}://${host}/${path ?? ''}'\nfunc secureUrl(host string) string => buildUrl(true, host, null)\nparam host string\noutput url string = secureUrl(host)\n", EmitterOptions{}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "functions": [
+    {
+      "namespace": "__bicep",
+      "members": {
+        "buildUrl": {
+          "parameters": [
+            {
+              "type": "bool",
+              "name": "https"
+            },
+            {
+              "type": "string",
+              "name": "host"
+            },
+            {
+              "type": "string",
+              "nullable": true,
+              "name": "path"
+            }
+          ],
+          "output": {
+            "type": "string",
+            "value": "[format('{0}://{1}/{2}', if(parameters('https'), 'https', 'http'), parameters('host'), coalesce(parameters('path'), ''))]"
+          },
+          "metadata": {
+            "description": "Builds a URL."
+          }
+        },
+        "secureUrl": {
+          "parameters": [
+            {
+              "type": "string",
+              "name": "host"
+            }
+          ],
+          "output": {
+            "type": "string",
+            "value": "[__bicep.buildUrl(true(), parameters('host'), null())]"
+          }
+        }
+      }
+    }
+  ],
+  "parameters": {
+    "host": {
+      "type": "string"
+    }
+  },
+  "resources": [],
+  "outputs": {
+    "url": {
+      "type": "string",
+      "value": "[__bicep.secureUrl(parameters('host'))]"
+    }
+  }
+}`},
+		{"typed functions", "type names = string[]\nfunc first(values names) string => values[0]\noutput name string = first(['a'])\n", EmitterOptions{}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "languageVersion": "2.0",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "definitions": {
+    "names": {
+      "type": "array",
+      "items": {
+        "type": "string"
+      }
+    }
+  },
+  "functions": [
+    {
+      "namespace": "__bicep",
+      "members": {
+        "first": {
+          "parameters": [
+            {
+              "$ref": "#/definitions/names",
+              "name": "values"
+            }
+          ],
+          "output": {
+            "type": "string",
+            "value": "[parameters('values')[0]]"
+          }
+        }
+      }
+    }
+  ],
+  "resources": {},
+  "outputs": {
+    "name": {
+      "type": "string",
+      "value": "[__bicep.first(createArray('a'))]"
+    }
+  }
+}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := writeTemplate(t, tt.input, tt.options)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, template)
+		})
+	}
+}
+
+func TestTemplateWriterImports(t *testing.T) {
+	files := map[string]string{
+		"types.bicep": "@export()\n@description('The size of an account.')\ntype size = 'small' | 'large'\n@export()\ntype account = {\n  name: string\n  size: size\n}\nvar separator = '-'\n@export()\nvar prefix = 'app${separator}'\n@export()\nfunc qualify(name string) string => 'app-${name}'\n",
+		"names.bicep": "import { qualify } from 'types.bicep'\n@export()\nfunc storageName(name string) string => toLower(qualify(name))\n",
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		options  EmitterOptions
+		expected string
+	}{
+		{"by name", "import { account, prefix as namePrefix } from 'types.bicep'\nparam primary account\noutput name string = '${namePrefix}${primary.name}'\n", EmitterOptions{}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "languageVersion": "2.0",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "definitions": {
+    "account": {
+      "type": "object",
+      "properties": {
+        "name": {
+          "type": "string"
+        },
+        "size": {
+          "$ref": "#/definitions/_1.size"
+        }
+      }
+    },
+    "_1.size": {
+      "type": "string",
+      "allowedValues": [
+        "small",
+        "large"
+      ],
+      "metadata": {
+        "description": "The size of an account."
+      }
+    }
+  },
+  "parameters": {
+    "primary": {
+      "$ref": "#/definitions/account"
+    }
+  },
+  "variables": {
+    "namePrefix": "[format('app{0}', variables('_1.separator'))]",
+    "_1.separator": "-"
+  },
+  "resources": {},
+  "outputs": {
+    "name": {
+      "type": "string",
+      "value": "[format('{0}{1}', variables('namePrefix'), parameters('primary').name)]"
+    }
+  }
+}`},
+		{"wildcard", "import * as types from 'types.bicep'\nimport { storageName } from 'names.bicep'\nparam size types.size\noutput name string = types.qualify(storageName(size))\n", EmitterOptions{}, `{
+  "$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#",
+  "languageVersion": "2.0",
+  "contentVersion": "1.0.0.0",
+  "metadata": {
+    "_generator": {
+      "name": "bicep",
+      "version": "0.1.0",
+      "templateHash": ""
+    }
+  },
+  "definitions": {
+    "_1.size": {
+      "type": "string",
+      "allowedValues": [
+        "small",
+        "large"
+      ],
+      "metadata": {
+        "description": "The size of an account."
+      }
+    }
+  },
+  "functions": [
+    {
+      "namespace": "__bicep",
+      "members": {
+        "storageName": {
+          "parameters": [
+            {
+              "type": "string",
+              "name": "name"
+            }
+          ],
+          "output": {
+            "type": "string",
+            "value": "[toLower(__bicep._1.qualify(parameters('name')))]"
+          }
+        },
+        "_1.qualify": {
+          "parameters": [
+            {
+              "type": "string",
+              "name": "name"
+            }
+          ],
+          "output": {
+            "type": "string",
+            "value": "[format('app-{0}', parameters('name'))]"
+          }
+        }
+      }
+    }
+  ],
+  "parameters": {
+    "size": {
+      "$ref": "#/definitions/_1.size"
+    }
+  },
+  "resources": {},
+  "outputs": {
+    "name": {
+      "type": "string",
+      "value": "[__bicep._1.qualify(__bicep.storageName(parameters('size')))]"
+    }
+  }
+}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			template, err := writeModuleTemplate(t, tt.input, files, tt.options)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, template)
+		})
+	}
+}
+
+func TestTemplateWriterSourceMap(t *testing.T) {
+	text := "param prefix string\nvar names = [for i in range(0, 2): '${prefix}${i}']\nresource stg 'Microsoft.Storage/storageAccounts@2023-01-01' = {\n  name: prefix\n  location: 'westus'\n}\nmodule app 'app.bicep' = {\n  name: 'app'\n  params: {\n    size: 1\n    account: stg.name\n  }\n}\noutput endpoint string = app.outputs.endpoint\n"
+	appText := "param size int\nparam account string\noutput endpoint string = '${account}-${size}'\n"
+	app := newTestModel(t, appText)
+	model := semantics.NewSemanticModel(parser.New(text).Program(), types.NewGenericResourceTypeProvider(), testModuleLookup{"app.bicep": app})
+
+	var buffer bytes.Buffer
+	sourceMap, err := NewTemplateWriter(model, EmitterOptions{}).WriteWithSourceMap(&buffer)
+	require.NoError(t, err)
+
+	var entries []string
+	for _, entry := range sourceMap.Entries {
+		source := text
+		if entry.Model == app {
+			source = appText
+		}
+		syntaxText := source[entry.Span.Position : entry.Span.Position+entry.Span.Length]
+		entries = append(entries, fmt.Sprintf("%s %d-%d %s", entry.Path, entry.StartLine, entry.EndLine, strings.SplitN(syntaxText, "\n", 2)[0]))
+	}
+	require.Equal(t, []string{
+		"parameters.prefix 12-14 param prefix string",
+		"variables.copy[0] 18-22 var names = [for i in range(0, 2): '${prefix}${i}']",
+		"resources[0].name 29-29 name: prefix",
+		"resources[0].location 30-30 location: 'westus'",
+		"resources[0] 26-31 resource stg 'Microsoft.Storage/storageAccounts@2023-01-01' = {",
+		"resources[1].name 35-35 name: 'app'",
+		"resources[1].properties.parameters.size 42-44 size: 1",
+		"resources[1].properties.parameters.account 45-47 account: stg.name",
+		"resources[1].properties.template.parameters.size 60-62 param size int",
+		"resources[1].properties.template.parameters.account 63-65 param account string",
+		"resources[1].properties.template.outputs.endpoint 69-72 output endpoint string = '${account}-${size}'",
+		"resources[1] 32-79 module app 'app.bicep' = {",
+		"outputs.endpoint 82-85 output endpoint string = app.outputs.endpoint",
+	}, entries)
+}
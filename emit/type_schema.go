@@ -0,0 +1,213 @@
+package emit
+
+import (
+	"bicep-go/namespaces"
+	"bicep-go/semantics"
+	"bicep-go/syntax"
+	"bicep-go/types"
+	"fmt"
+)
+
+const TEMPLATE_DEFINITIONS_PATH = "#/definitions/"
+
+// buildDeclarationSchema creates the type schema of a declaration or type member from its type syntax. The
+// @discriminator, @sealed and @secure decorators change the schema itself; the other decorators are added
+// separately, as parameters list their default value first.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Emit/TemplateWriter.cs
+func (w *TemplateWriter) buildDeclarationSchema(declaration syntax.DecorableSyntax, typeSyntax syntax.SyntaxBase) (*jsonObject, error) {
+	var schema *jsonObject
+	var err error
+	if decorator := w.model.Binder.TryGetDecorator(declaration, namespaces.DECORATOR_DISCRIMINATOR); decorator != nil {
+		schema, err = w.buildDiscriminatedSchema(decorator, typeSyntax)
+	} else {
+		schema, err = w.buildTypeSchema(typeSyntax)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if w.model.Binder.TryGetDecorator(declaration, namespaces.DECORATOR_SECURE) != nil {
+		switch schema.get("type") {
+		case syntax.TYPE_NAME_STRING:
+			schema.set("type", "securestring")
+		case syntax.TYPE_OBJECT:
+			schema.set("type", "secureObject")
+		}
+	}
+	if w.model.Binder.TryGetDecorator(declaration, namespaces.DECORATOR_SEALED) != nil {
+		schema.set("additionalProperties", false)
+	}
+	return schema, nil
+}
+
+// buildTypeSchema creates the type schema of a type expression. References to type aliases are written as
+// references to their definitions rather than inlined.
+func (w *TemplateWriter) buildTypeSchema(typeSyntax syntax.SyntaxBase) (*jsonObject, error) {
+	schema := newJsonObject()
+	switch node := typeSyntax.(type) {
+	case *syntax.ParenthesizedExpressionSyntax:
+		return w.buildTypeSchema(node.Expression)
+	case *syntax.TypeVariableAccessSyntax, *syntax.TypePropertyAccessSyntax:
+		if path, ok := w.tryGetDefinitionPath(node); ok {
+			schema.set("$ref", path)
+			return schema, nil
+		}
+		if _, ok := w.model.GetSymbol(node).(*semantics.AmbientTypeSymbol); !ok {
+			return nil, unsupportedExpression(node, "references to types imported from ARM templates")
+		}
+		schema.set("type", getArmTypeName(w.model.GetDeclaredType(node), false))
+	case *syntax.NullableTypeSyntax:
+		schema, err := w.buildTypeSchema(node.Base)
+		if err != nil {
+			return nil, err
+		}
+		schema.set("nullable", true)
+		return schema, nil
+	case *syntax.ArrayTypeSyntax:
+		items, err := w.buildTypeSchema(node.Item)
+		if err != nil {
+			return nil, err
+		}
+		schema.set("type", syntax.TYPE_ARRAY)
+		schema.set("items", items)
+	case *syntax.TupleTypeSyntax:
+		prefixItems := []any{}
+		for _, item := range node.Items {
+			itemSchema, err := w.buildDecoratedSchema(item, item.Value)
+			if err != nil {
+				return nil, err
+			}
+			prefixItems = append(prefixItems, itemSchema)
+		}
+		schema.set("type", syntax.TYPE_ARRAY)
+		schema.set("prefixItems", prefixItems)
+		schema.set("items", false)
+	case *syntax.ObjectTypeSyntax:
+		return w.buildObjectSchema(node)
+	case *syntax.UnionTypeSyntax, *syntax.StringSyntax, *syntax.IntegerLiteralSyntax, *syntax.UnaryOperationSyntax, *syntax.BooleanLiteralSyntax:
+		declaredType := w.model.GetDeclaredType(node)
+		allowedValues := getAllowedValues(declaredType)
+		if allowedValues == nil {
+			return nil, unsupportedExpression(node, "unions of non-literal types")
+		}
+		schema.set("type", getArmTypeName(declaredType, false))
+		schema.set("allowedValues", allowedValues)
+		if types.IsNullable(declaredType) {
+			schema.set("nullable", true)
+		}
+	default:
+		return nil, unsupportedExpression(node, "these types")
+	}
+	return schema, nil
+}
+
+// buildDecoratedSchema creates the type schema of an object property, tuple item or additional properties,
+// including their decorators.
+func (w *TemplateWriter) buildDecoratedSchema(declaration syntax.DecorableSyntax, typeSyntax syntax.SyntaxBase) (*jsonObject, error) {
+	schema, err := w.buildDeclarationSchema(declaration, typeSyntax)
+	if err != nil {
+		return nil, err
+	}
+	if err := w.addDecorators(declaration, schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+// buildObjectSchema creates the schema of an object type. Optional properties are nullable.
+func (w *TemplateWriter) buildObjectSchema(node *syntax.ObjectTypeSyntax) (*jsonObject, error) {
+	properties := newJsonObject()
+	for _, property := range node.Properties() {
+		name, ok := property.TryGetKeyText()
+		if !ok {
+			return nil, unsupportedExpression(property.Key, "interpolated property names")
+		}
+		propertySchema, err := w.buildDecoratedSchema(property, property.Value)
+		if err != nil {
+			return nil, err
+		}
+		if property.IsOptional() {
+			propertySchema.set("nullable", true)
+		}
+		properties.set(name, propertySchema)
+	}
+
+	schema := newJsonObject()
+	schema.set("type", syntax.TYPE_OBJECT)
+	schema.set("properties", properties)
+	if additionalProperties := node.AdditionalProperties(); additionalProperties != nil {
+		additionalSchema, err := w.buildDecoratedSchema(additionalProperties, additionalProperties.Value)
+		if err != nil {
+			return nil, err
+		}
+		schema.set("additionalProperties", additionalSchema)
+	}
+	return schema, nil
+}
+
+// buildDiscriminatedSchema creates the schema of a union of object types tagged by the property named by the
+// @discriminator decorator, mapping each value of the property to the schema of its variant.
+func (w *TemplateWriter) buildDiscriminatedSchema(decorator *syntax.DecoratorSyntax, typeSyntax syntax.SyntaxBase) (*jsonObject, error) {
+	switch node := typeSyntax.(type) {
+	case *syntax.ParenthesizedExpressionSyntax:
+		return w.buildDiscriminatedSchema(decorator, node.Expression)
+	case *syntax.NullableTypeSyntax:
+		schema, err := w.buildDiscriminatedSchema(decorator, node.Base)
+		if err != nil {
+			return nil, err
+		}
+		schema.set("nullable", true)
+		return schema, nil
+	}
+
+	union, ok := typeSyntax.(*syntax.UnionTypeSyntax)
+	if !ok {
+		return nil, unsupportedExpression(typeSyntax, "discriminated unions declared elsewhere")
+	}
+	key, ok := w.model.GetType(decorator.Arguments()[0].Expression).(*types.StringLiteralType)
+	if !ok {
+		return nil, fmt.Errorf("%s the discriminator is not valid", decorator.GetSpan().ToString())
+	}
+
+	mapping := newJsonObject()
+	for _, member := range union.Members {
+		variant, ok := w.model.GetDeclaredType(member).(*types.ObjectType)
+		if !ok {
+			return nil, unsupportedExpression(member, "nested discriminated unions")
+		}
+		value := variant.TryGetProperty(key.Value).Type.(*types.StringLiteralType)
+		variantSchema, err := w.buildTypeSchema(member)
+		if err != nil {
+			return nil, err
+		}
+		mapping.set(value.Value, variantSchema)
+	}
+
+	discriminator := newJsonObject()
+	discriminator.set("propertyName", key.Value)
+	discriminator.set("mapping", mapping)
+
+	schema := newJsonObject()
+	schema.set("type", syntax.TYPE_OBJECT)
+	schema.set("discriminator", discriminator)
+	return schema, nil
+}
+
+// tryGetDefinitionPath returns the reference to the definition of a type alias, including imported ones, or to a
+// property of one, e.g. #/definitions/settings/properties/size.
+func (w *TemplateWriter) tryGetDefinitionPath(typeSyntax syntax.SyntaxBase) (string, bool) {
+	if name, ok := w.converter.imports.tryGetImportedName(w.model, typeSyntax); ok {
+		return TEMPLATE_DEFINITIONS_PATH + name, true
+	}
+	switch node := typeSyntax.(type) {
+	case *syntax.TypeVariableAccessSyntax:
+		if alias, ok := w.model.GetSymbol(node).(*semantics.TypeAliasSymbol); ok {
+			return TEMPLATE_DEFINITIONS_PATH + w.converter.imports.getName(alias), true
+		}
+	case *syntax.TypePropertyAccessSyntax:
+		if path, ok := w.tryGetDefinitionPath(node.BaseExpression); ok {
+			return path + "/properties/" + node.PropertyName.IdentifierName(), true
+		}
+	}
+	return "", false
+}
@@ -4,7 +4,10 @@ import (
 	"bicep-go/syntax"
 	"bicep-go/token"
 	"bicep-go/util"
+	"fmt"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 var SingleCharacterEscapes = map[byte]byte{
@@ -34,10 +37,24 @@ func New(input string) *Lexer {
 	}
 }
 
+// NewAt creates a lexer that starts scanning input at the given offset.
+// The offset must not be inside a string, e.g. the end of a new line token.
+func NewAt(input string, offset int) *Lexer {
+	l := New(input)
+	l.textWindow.AdvanceTo(offset)
+	l.textWindow.Reset()
+	return l
+}
+
 func (l *Lexer) GetTokens() []*token.Token {
 	return l.tokens
 }
 
+// GetPosition returns the offset at which the next token will be scanned.
+func (l *Lexer) GetPosition() int {
+	return l.textWindow.GetAbsolutePosition()
+}
+
 func (l *Lexer) Lex() {
 	for !l.textWindow.IsAtEnd() {
 		l.LexToken()
@@ -56,12 +73,13 @@ func (l *Lexer) LexToken() {
 	l.textWindow.Reset()
 	tokenType := l.scanToken()
 	tokenText := l.textWindow.GetText()
+	tokenSpan := l.textWindow.GetSpan()
 
 	l.textWindow.Reset()
 	includeComments := syntax.GetCommentStickiness(tokenType) >= syntax.COMMENT_STICKINESS_TRAILING
 	trailingTrivia := l.scanTrailingTrivia(includeComments)
 
-	token := token.NewToken(tokenType, tokenText, leadingTrivia, trailingTrivia)
+	token := token.NewToken(tokenType, tokenText, tokenSpan, leadingTrivia, trailingTrivia)
 	l.tokens = append(l.tokens, token)
 }
 
@@ -97,6 +115,7 @@ func (l *Lexer) scanToken() token.TokenType {
 	switch nextChar {
 	case '{':
 		if l.templateStack.Any() {
+			// keep track of object braces nested in an interpolation hole
 			l.templateStack.Push(token.TokenTypeLeftBrace)
 		}
 		return token.TokenTypeLeftBrace
@@ -104,12 +123,16 @@ func (l *Lexer) scanToken() token.TokenType {
 		if l.templateStack.Any() {
 			prevTemplateToken, _ := l.templateStack.Peek()
 			if prevTemplateToken == token.TokenTypeLeftBrace {
-				stringToken := l.scanStringSegment(false)
-				if stringToken == token.TokenTypeRightBrace {
-					l.templateStack.Pop()
-				}
-				return stringToken
+				l.templateStack.Pop()
+				return token.TokenTypeRightBrace
+			}
+
+			// closing an interpolation hole
+			stringToken := l.scanStringSegment(false)
+			if stringToken == token.TokenTypeStringRightPiece {
+				l.templateStack.Pop()
 			}
+			return stringToken
 		}
 		return token.TokenTypeRightBrace
 	case '?':
@@ -260,7 +283,7 @@ func (l *Lexer) scanStringSegment(isAtStartOfString bool) token.TokenType {
 			}
 		}
 
-		if nextChar == '&' && !l.textWindow.IsAtEnd() && l.textWindow.Peek() == '{' {
+		if nextChar == '$' && !l.textWindow.IsAtEnd() && l.textWindow.Peek() == '{' {
 			l.textWindow.Advance()
 			if isAtStartOfString {
 				return token.TokenTypeStringLeftPiece
@@ -504,8 +527,14 @@ func scanHexNumber(textWindow *TextWindow) string {
 }
 
 func parseCodePoint(codePointText string) (string, error) {
-	// TODO: implement this
-	return "", nil
+	codePoint, err := strconv.ParseUint(codePointText, 16, 32)
+	if err != nil {
+		return "", err
+	}
+	if codePoint > unicode.MaxRune {
+		return "", fmt.Errorf("code point %s is out of range", codePointText)
+	}
+	return string(rune(codePoint)), nil
 }
 
 func isIdentifierStart(ch byte) bool {
@@ -17,18 +17,18 @@ func TestNextToken(t *testing.T) {
 		expectedType    token.TokenType
 		expectedLiteral string
 	}{
-		{token.IDENTIFIER, "resource"},
-		{token.IDENTIFIER, "test"},
-		{token.STRING_COMPLETE, "'Provider/ResourceType@version'"},
-		{token.ASSIGNMENT, "="},
-		{token.LEFT_BRACE, "{"},
-		{token.NEW_LINE, "\\n"},
-		{token.IDENTIFIER, "name"},
-		{token.COLON, ":"},
-		{token.STRING_COMPLETE, "'test'"},
-		{token.NEW_LINE, "\\n"},
-		{token.RIGHT_BRACE, "}"},
-		{token.NEW_LINE, "\\n"},
+		{token.TokenTypeIdentifier, "resource"},
+		{token.TokenTypeIdentifier, "test"},
+		{token.TokenTypeStringComplete, "'Provider/ResourceType@version'"},
+		{token.TokenTypeAssignment, "="},
+		{token.TokenTypeLeftBrace, "{"},
+		{token.TokenTypeNewLine, "\n"},
+		{token.TokenTypeIdentifier, "name"},
+		{token.TokenTypeColon, ":"},
+		{token.TokenTypeStringComplete, "'test'"},
+		{token.TokenTypeNewLine, "\n"},
+		{token.TokenTypeRightBrace, "}"},
+		{token.TokenTypeEndOfFile, ""},
 	}
 
 	lexer := New(input)
@@ -37,7 +37,62 @@ func TestNextToken(t *testing.T) {
 
 	assert.Equal(t, len(tests), len(tokens))
 	for i, tt := range tests {
-		require.Equal(t, tokens[i].Type, tt.expectedType)
-		require.Equal(t, tokens[i].Literal, tt.expectedLiteral)
+		require.Equal(t, tt.expectedType, tokens[i].Type)
+		require.Equal(t, tt.expectedLiteral, tokens[i].Literal)
+	}
+}
+
+func TestStringInterpolation(t *testing.T) {
+	input := `'a${b}c${{ d: 'e' }.d}f'`
+
+	expected := []token.TokenType{
+		token.TokenTypeStringLeftPiece,
+		token.TokenTypeIdentifier,
+		token.TokenTypeStringMiddlePiece,
+		token.TokenTypeLeftBrace,
+		token.TokenTypeIdentifier,
+		token.TokenTypeColon,
+		token.TokenTypeStringComplete,
+		token.TokenTypeRightBrace,
+		token.TokenTypeDot,
+		token.TokenTypeIdentifier,
+		token.TokenTypeStringRightPiece,
+		token.TokenTypeEndOfFile,
+	}
+
+	lexer := New(input)
+	lexer.Lex()
+	tokens := lexer.GetTokens()
+
+	require.Equal(t, len(expected), len(tokens))
+	for i, tokenType := range expected {
+		require.Equal(t, tokenType, tokens[i].Type, "token %d", i)
+	}
+	require.Equal(t, 0, tokens[0].Span.Position)
+	require.Equal(t, len(input)-3, tokens[10].Span.Position)
+
+	segments, ok := TryGetRawStringSegments([]*token.Token{tokens[0], tokens[2], tokens[10]})
+	require.True(t, ok)
+	require.Equal(t, []string{"a", "c", "f"}, segments)
+}
+
+func TestStringValue(t *testing.T) {
+	for _, tc := range []struct {
+		input    string
+		expected string
+		ok       bool
+	}{
+		{`'abc'`, "abc", true},
+		{`'it\'s'`, "it's", true},
+		{`'a\nb\t\\\$'`, "a\nb\t\\$", true},
+		{`'\u{1F600}'`, "\U0001F600", true},
+		{`'\q'`, "", false},
+		{`'abc`, "", false},
+	} {
+		lexer := New(tc.input)
+		lexer.Lex()
+		value, ok := TryGetStringValue(lexer.GetTokens()[0])
+		require.Equal(t, tc.ok, ok, tc.input)
+		require.Equal(t, tc.expected, value, tc.input)
 	}
 }
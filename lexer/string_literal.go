@@ -0,0 +1,125 @@
+package lexer
+
+import (
+	"bicep-go/token"
+	"strings"
+)
+
+const (
+	multilineStringQuotes = "'''"
+)
+
+// TryGetStringValue returns the unescaped value of a complete (non-interpolated) string token.
+func TryGetStringValue(tok *token.Token) (string, bool) {
+	if tok.Type != token.TokenTypeStringComplete {
+		return "", false
+	}
+
+	segments, ok := TryGetRawStringSegments([]*token.Token{tok})
+	if !ok {
+		return "", false
+	}
+	return segments[0], true
+}
+
+// TryGetRawStringSegments returns the unescaped text between the interpolation holes of a string.
+// The number of returned segments always equals the number of tokens.
+func TryGetRawStringSegments(tokens []*token.Token) ([]string, bool) {
+	segments := make([]string, 0, len(tokens))
+	for i, tok := range tokens {
+		text := tok.Literal
+
+		var prefix, suffix string
+		switch {
+		case len(tokens) == 1:
+			prefix, suffix = "'", "'"
+		case i == 0:
+			prefix, suffix = "'", "${"
+		case i == len(tokens)-1:
+			prefix, suffix = "}", "'"
+		default:
+			prefix, suffix = "}", "${"
+		}
+
+		if len(text) < len(prefix)+len(suffix) ||
+			!strings.HasPrefix(text, prefix) ||
+			!strings.HasSuffix(text, suffix) {
+			// unterminated string or segment
+			return nil, false
+		}
+
+		value, ok := unescapeString(text[len(prefix) : len(text)-len(suffix)])
+		if !ok {
+			return nil, false
+		}
+		segments = append(segments, value)
+	}
+
+	return segments, true
+}
+
+// TryGetMultilineStringValue returns the value of a multi-line string token.
+// Escapes are not processed and a single leading line break is dropped.
+func TryGetMultilineStringValue(tok *token.Token) (string, bool) {
+	text := tok.Literal
+	if tok.Type != token.TokenTypeMultilineString ||
+		len(text) < 2*len(multilineStringQuotes) ||
+		!strings.HasSuffix(text, multilineStringQuotes) {
+		return "", false
+	}
+
+	text = text[len(multilineStringQuotes) : len(text)-len(multilineStringQuotes)]
+	if strings.HasPrefix(text, "\r\n") {
+		text = text[2:]
+	} else if strings.HasPrefix(text, "\n") {
+		text = text[1:]
+	}
+
+	return text, true
+}
+
+func unescapeString(text string) (string, bool) {
+	var builder strings.Builder
+
+	window := NewTextWindow(text)
+	for !window.IsAtEnd() {
+		nextChar := window.Peek()
+		window.Advance()
+
+		if nextChar != '\\' {
+			builder.WriteByte(nextChar)
+			continue
+		}
+
+		if window.IsAtEnd() {
+			return "", false
+		}
+
+		nextChar = window.Peek()
+		window.Advance()
+
+		if escaped, ok := SingleCharacterEscapes[nextChar]; ok {
+			builder.WriteByte(escaped)
+			continue
+		}
+
+		if nextChar != 'u' || window.Peek() != '{' {
+			return "", false
+		}
+
+		window.Advance()
+		codePointText := scanHexNumber(window)
+		if len(codePointText) == 0 || window.Peek() != '}' {
+			return "", false
+		}
+		window.Advance()
+
+		codePoint, err := parseCodePoint(codePointText)
+		if err != nil {
+			return "", false
+		}
+		builder.WriteString(codePoint)
+	}
+
+	return builder.String(), true
+}
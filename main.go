@@ -1,13 +1,42 @@
 package main
 
 import (
+	"bicep-go/compiler"
+	"bicep-go/emit"
+	"bicep-go/registry"
 	"bicep-go/repl"
+	"bicep-go/types"
+	"bicep-go/util"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io/fs"
+	"net/http"
 	"os"
 	"os/user"
+	"path/filepath"
+	"sort"
+	"strings"
 )
 
+const USAGE = `usage:
+  bicep-go                                    start the REPL
+  bicep-go build [-sourcemap] [-symbolic-names] [-generator-version <version>] <file.bicep>
+                                              compile a file to <file>.json, and its source map to <file>.json.map
+  bicep-go lookup <file.json.map> <target>    find the Bicep source of a template line or JSON path`
+
 func main() {
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
@@ -17,3 +46,163 @@ func main() {
 	fmt.Printf("Feel free to type in commands\n")
 	repl.Start(os.Stdin, os.Stdout)
 }
+
+func runCommand(command string, args []string) error {
+	switch command {
+	case "build":
+		return build(args)
+	case "lookup":
+		return lookup(args)
+	}
+	return fmt.Errorf("unknown command %q\n%s", command, USAGE)
+}
+
+// build compiles a file and the modules it references, restoring registry modules into the user cache first.
+func build(args []string) error {
+	flags := flag.NewFlagSet("build", flag.ContinueOnError)
+	sourceMap := flags.Bool("sourcemap", false, "write the source map of the template")
+	symbolicNames := flags.Bool("symbolic-names", false, "write a language version 2.0 template, whose resources are keyed by their symbolic names")
+	generatorVersion := flags.String("generator-version", emit.DEFAULT_GENERATOR_VERSION, "the Bicep version recorded in the template metadata")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("%s", USAGE)
+	}
+
+	filePath := flags.Arg(0)
+	cacheDirectory, err := os.UserCacheDir()
+	if err != nil {
+		return err
+	}
+	// the file system is rooted at the volume, so that modules and imports can refer to parent directories
+	absolutePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return err
+	}
+	root := filepath.VolumeName(absolutePath) + string(filepath.Separator)
+	entryPath, err := filepath.Rel(root, absolutePath)
+	if err != nil {
+		return err
+	}
+	entryPath = filepath.ToSlash(entryPath)
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	fsys := &volumeFS{FS: os.DirFS(root), root: root, workingDirectory: workingDirectory}
+	configuration, err := registry.FindConfiguration(fsys, entryPath)
+	if err != nil {
+		return err
+	}
+	modules := registry.NewOciModuleRegistry(configuration, registry.NewCache(filepath.Join(cacheDirectory, "bicep")), registry.NewOciClient(http.DefaultClient))
+	if err := compiler.Restore(context.Background(), fsys, entryPath, modules); err != nil {
+		return err
+	}
+	compilation, err := compiler.NewCompilation(fsys, entryPath, types.NewGenericResourceTypeProvider(), modules)
+	if err != nil {
+		return err
+	}
+	all := compilation.GetAllDiagnostics()
+	paths := make([]string, 0, len(all))
+	for path := range all {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		displayPath := fsys.DisplayPath(path)
+		lineStarts := compilation.GetSourceFileGrouping().Files[path].LineStarts
+		for _, diagnostic := range all[path] {
+			line, column := util.GetPosition(lineStarts, diagnostic.Span.Position)
+			fmt.Fprintf(os.Stderr, "%s:%d:%d: %s %s: %s\n", displayPath, line+1, column+1, diagnostic.Level.ToString(), diagnostic.Code, diagnostic.Message)
+		}
+	}
+
+	var template bytes.Buffer
+	options := emit.EmitterOptions{SymbolicNames: *symbolicNames, GeneratorVersion: *generatorVersion}
+	templatePath := strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".json"
+	if !*sourceMap {
+		if err := compilation.Emit(&template, options); err != nil {
+			return err
+		}
+		return os.WriteFile(templatePath, template.Bytes(), 0644)
+	}
+
+	emitted, err := compilation.EmitWithSourceMap(&template, options)
+	if err != nil {
+		return err
+	}
+	// the files of the source map are relative to it, rather than to the root of the file system
+	for _, entry := range emitted.Entries {
+		relativePath, err := filepath.Rel(filepath.Dir(absolutePath), filepath.Join(root, filepath.FromSlash(entry.File)))
+		if err != nil {
+			return err
+		}
+		entry.File = filepath.ToSlash(relativePath)
+	}
+	data, err := json.MarshalIndent(emitted, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(templatePath, template.Bytes(), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(templatePath+".map", data, 0644)
+}
+
+// volumeFS is the file system rooted at the volume of the file being built, whose files are shown with the paths of
+// the operating system relative to the working directory, as they are given on the command line.
+type volumeFS struct {
+	fs.FS
+	root             string
+	workingDirectory string
+}
+
+// DisplayPath implements util.DisplayPathFS.
+func (f *volumeFS) DisplayPath(name string) string {
+	displayPath := filepath.Join(f.root, filepath.FromSlash(name))
+	if relativePath, err := filepath.Rel(f.workingDirectory, displayPath); err == nil {
+		return relativePath
+	}
+	return displayPath
+}
+
+// Open reports the files that cannot be opened by their display paths.
+func (f *volumeFS) Open(name string) (fs.File, error) {
+	file, err := f.FS.Open(name)
+	var pathError *fs.PathError
+	if errors.As(err, &pathError) {
+		return nil, &fs.PathError{Op: pathError.Op, Path: f.DisplayPath(pathError.Path), Err: pathError.Err}
+	}
+	return file, err
+}
+
+// lookup translates the target of a deployment error, a line of the template or a JSON path, back to the Bicep
+// source it was compiled from.
+func lookup(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("%s", USAGE)
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	var sourceMap emit.SourceMap
+	if err := json.Unmarshal(data, &sourceMap); err != nil {
+		return err
+	}
+	entry, err := sourceMap.Lookup(args[1])
+	if err != nil {
+		return err
+	}
+
+	// the files of the source map are relative to it
+	sourcePath := filepath.Join(filepath.Dir(args[0]), filepath.FromSlash(entry.File))
+	text, err := os.ReadFile(sourcePath)
+	if err != nil {
+		return err
+	}
+	line, column := util.GetPosition(util.ComputeLineStarts(string(text)), entry.Span.Position)
+	fmt.Printf("%s:%d:%d\n", sourcePath, line+1, column+1)
+	return nil
+}
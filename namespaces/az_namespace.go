@@ -0,0 +1,230 @@
+package namespaces
+
+import (
+	"bicep-go/types"
+	"strings"
+)
+
+// The types of the resources that can be used as the scope of a module, like the values of the scope functions.
+const (
+	RESOURCE_GROUP_RESOURCE_TYPE   = "Microsoft.Resources/resourceGroups"
+	SUBSCRIPTION_RESOURCE_TYPE     = "Microsoft.Resources/subscriptions"
+	MANAGEMENT_GROUP_RESOURCE_TYPE = "Microsoft.Management/managementGroups"
+)
+
+var (
+	resourceGroupType = newAzObjectType("resourceGroup",
+		types.NewTypeProperty("id", types.String, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("name", types.String, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("type", types.String, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("location", types.String, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("managedBy", types.String, types.TypePropertyFlagsNone),
+		types.NewTypeProperty("tags", types.Object, types.TypePropertyFlagsNone),
+		types.NewTypeProperty("properties", types.Object, types.TypePropertyFlagsRequired),
+	)
+	subscriptionType = newAzObjectType("subscription",
+		types.NewTypeProperty("id", types.String, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("subscriptionId", types.String, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("tenantId", types.String, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("displayName", types.String, types.TypePropertyFlagsRequired),
+	)
+	managementGroupType = newAzObjectType("managementGroup",
+		types.NewTypeProperty("id", types.String, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("name", types.String, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("type", types.String, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("properties", types.Object, types.TypePropertyFlagsRequired),
+	)
+	tenantType = newAzObjectType("tenant",
+		types.NewTypeProperty("tenantId", types.String, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("countryCode", types.String, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("displayName", types.String, types.TypePropertyFlagsRequired),
+	)
+	deploymentType = newAzObjectType("deployment",
+		types.NewTypeProperty("name", types.String, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("location", types.String, types.TypePropertyFlagsNone),
+		types.NewTypeProperty("properties", types.Object, types.TypePropertyFlagsRequired),
+	)
+	environmentType = newAzObjectType("environment",
+		types.NewTypeProperty("name", types.String, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("authentication", types.Object, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("resourceManager", types.String, types.TypePropertyFlagsRequired),
+		types.NewTypeProperty("suffixes", types.Object, types.TypePropertyFlagsRequired),
+	)
+)
+
+// newAzObjectType creates the return type of a scope function. Other properties are allowed
+// because ARM returns more than the documented ones.
+func newAzObjectType(name string, properties ...*types.TypeProperty) *types.ObjectType {
+	return types.NewObjectType(name, properties, types.Any)
+}
+
+// NewAzNamespace returns the az namespace of a file deployed at the target scope, which decides
+// the overloads of the scope functions. For example, resourceGroup() without arguments is only
+// available at resource group scope.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/Namespaces/AzNamespaceType.cs
+func NewAzNamespace(targetScope types.ResourceScope) *Namespace {
+	return &Namespace{
+		Name:      NAMESPACE_AZ,
+		Functions: newFunctions(append(azFunctionOverloads(), scopeFunctionOverloads(targetScope)...)...),
+	}
+}
+
+// TryGetScopeReference returns the scope that a value returned by a scope function or a resource group,
+// subscription or management group resource refers to, e.g. the resource group scope for resourceGroup(),
+// or ResourceScopeNone for any other type.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Emit/ScopeHelper.cs
+func TryGetScopeReference(typeSymbol types.TypeSymbol) types.ResourceScope {
+	if resourceType, ok := typeSymbol.(*types.ResourceType); ok {
+		switch name := resourceType.TypeReference.Type; {
+		case strings.EqualFold(name, RESOURCE_GROUP_RESOURCE_TYPE):
+			return types.ResourceScopeResourceGroup
+		case strings.EqualFold(name, SUBSCRIPTION_RESOURCE_TYPE):
+			return types.ResourceScopeSubscription
+		case strings.EqualFold(name, MANAGEMENT_GROUP_RESOURCE_TYPE):
+			return types.ResourceScopeManagementGroup
+		}
+		return types.ResourceScopeNone
+	}
+	switch typeSymbol {
+	case tenantType:
+		return types.ResourceScopeTenant
+	case managementGroupType:
+		return types.ResourceScopeManagementGroup
+	case subscriptionType:
+		return types.ResourceScopeSubscription
+	case resourceGroupType:
+		return types.ResourceScopeResourceGroup
+	}
+	return types.ResourceScopeNone
+}
+
+func azFunctionOverloads() []*FunctionOverload {
+	return []*FunctionOverload{
+		NewFunctionOverloadBuilder("deployment").
+			WithDescription("Returns information about the current deployment operation.").
+			WithReturnType(deploymentType).
+			Build(),
+		NewFunctionOverloadBuilder("environment").
+			WithDescription("Returns information about the Azure environment used for deployment.").
+			WithReturnType(environmentType).
+			Build(),
+		NewFunctionOverloadBuilder("extensionResourceId").
+			WithDescription("Returns the resource ID for an extension resource, which is a resource type that is applied to another resource to add to its capabilities.").
+			WithRequiredParameter("resourceId", types.String, "The resource ID for the resource that the extension resource is applied to").
+			WithRequiredParameter("resourceType", types.String, "Type of the extension resource including resource provider namespace").
+			WithVariableParameter("resourceName", types.String, 1, "The extension resource name segment").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("list*").
+			WithDescription("The syntax for this function varies by name of the list operations. Each implementation returns values for the resource type that supports a list operation. The operation name must start with list.").
+			WithRequiredParameter("resourceNameOrIdentifier", types.String, "Name or unique identifier of the resource.").
+			WithRequiredParameter("apiVersion", types.String, "API version of resource runtime state. Typically, in the format, yyyy-mm-dd.").
+			WithOptionalParameter("functionValues", types.Object, "An object that has values for the function. Only provide this object for functions that support receiving an object with parameter values.").
+			WithReturnType(types.Any).
+			WithFlags(FunctionFlagsRequiresInlining).
+			Build(),
+		NewFunctionOverloadBuilder("managementGroupResourceId").
+			WithDescription("Returns the unique identifier for a resource deployed at the management group level.").
+			WithRequiredParameter("resourceType", types.String, "Type of resource including resource provider namespace").
+			WithVariableParameter("resourceName", types.String, 1, "The resource name segment").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("pickZones").
+			WithDescription("Determines whether a resource type supports zones for a region.").
+			WithRequiredParameter("providerNamespace", types.String, "The resource provider namespace for the resource type to check for zone support.").
+			WithRequiredParameter("resourceType", types.String, "The resource type to check for zone support.").
+			WithRequiredParameter("location", types.String, "The region to check for zone support.").
+			WithOptionalParameter("numberOfZones", types.Int, "The number of logical zones to return. The default is 1.").
+			WithOptionalParameter("offset", types.Int, "The offset from the starting logical zone. The function returns an error if offset plus numberOfZones exceeds the number of supported zones.").
+			WithReturnType(types.NewArrayType(types.String)).
+			Build(),
+		NewFunctionOverloadBuilder("providers").
+			WithDescription("Returns information about a resource provider and its supported resource types. If you don't provide a resource type, the function returns all the supported types for the resource provider.").
+			WithRequiredParameter("providerNamespace", types.String, "the namespace of the provider").
+			WithOptionalParameter("resourceType", types.String, "The type of resource within the specified namespace").
+			WithReturnType(types.Any).
+			Build(),
+		NewFunctionOverloadBuilder("reference").
+			WithDescription("Returns an object representing a resource's runtime state.").
+			WithRequiredParameter("resourceNameOrIdentifier", types.String, "Name or unique identifier of a resource.").
+			WithOptionalParameter("apiVersion", types.String, "API version of the specified resource. Include this parameter when the resource isn't provisioned within same template.").
+			WithOptionalParameter("full", types.CreateUnion(types.NewStringLiteralType("Full"), types.Bool), "Value that specifies whether to return the full resource object.").
+			WithReturnType(types.Object).
+			WithFlags(FunctionFlagsRequiresInlining).
+			Build(),
+		NewFunctionOverloadBuilder("resourceId").
+			WithDescription("Returns the unique identifier of a resource. You use this function when the resource name is ambiguous or not provisioned within the same template.").
+			WithVariableParameter("resourceIdSegment", types.String, 2, "The resource ID segments: an optional subscription ID and resource group name, the resource type and the resource name segments").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("subscriptionResourceId").
+			WithDescription("Returns the unique identifier for a resource deployed at the subscription level.").
+			WithVariableParameter("resourceIdSegment", types.String, 2, "The resource ID segments: an optional subscription ID, the resource type and the resource name segments").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("tenantResourceId").
+			WithDescription("Returns the unique identifier for a resource deployed at the tenant level.").
+			WithRequiredParameter("resourceType", types.String, "Type of resource including resource provider namespace").
+			WithVariableParameter("resourceName", types.String, 1, "The resource name segment").
+			WithReturnType(types.String).
+			Build(),
+	}
+}
+
+// scopeFunctionOverloads returns the overloads of tenant(), managementGroup(), subscription() and
+// resourceGroup() that can be called from a file deployed at the target scope.
+func scopeFunctionOverloads(targetScope types.ResourceScope) []*FunctionOverload {
+	var overloads []*FunctionOverload
+	overloads = append(overloads, NewFunctionOverloadBuilder("tenant").
+		WithDescription("Returns the tenant scope.").
+		WithReturnType(tenantType).
+		Build())
+	if targetScope.Has(types.ResourceScopeManagementGroup) {
+		overloads = append(overloads, NewFunctionOverloadBuilder("managementGroup").
+			WithDescription("Returns the current management group scope.").
+			WithReturnType(managementGroupType).
+			Build())
+	}
+	if targetScope.Has(types.ResourceScopeTenant | types.ResourceScopeManagementGroup) {
+		overloads = append(overloads, NewFunctionOverloadBuilder("managementGroup").
+			WithDescription("Returns the named management group scope.").
+			WithRequiredParameter("name", types.String, "The unique identifier of the management group to target").
+			WithReturnType(managementGroupType).
+			Build())
+	}
+	if targetScope.Has(types.ResourceScopeSubscription | types.ResourceScopeResourceGroup) {
+		overloads = append(overloads, NewFunctionOverloadBuilder("subscription").
+			WithDescription("Returns the subscription scope for the current deployment.").
+			WithReturnType(subscriptionType).
+			Build())
+	}
+	if targetScope.Has(types.ResourceScopeTenant | types.ResourceScopeManagementGroup | types.ResourceScopeSubscription | types.ResourceScopeResourceGroup) {
+		overloads = append(overloads, NewFunctionOverloadBuilder("subscription").
+			WithDescription("Returns a named subscription scope.").
+			WithRequiredParameter("subscriptionId", types.String, "The subscription ID").
+			WithReturnType(subscriptionType).
+			Build())
+	}
+	if targetScope.Has(types.ResourceScopeResourceGroup) {
+		overloads = append(overloads, NewFunctionOverloadBuilder("resourceGroup").
+			WithDescription("Returns the current resource group scope.").
+			WithReturnType(resourceGroupType).
+			Build())
+	}
+	if targetScope.Has(types.ResourceScopeSubscription | types.ResourceScopeResourceGroup) {
+		overloads = append(overloads, NewFunctionOverloadBuilder("resourceGroup").
+			WithDescription("Returns a named resource group scope in the current subscription.").
+			WithRequiredParameter("resourceGroupName", types.String, "The resource group name").
+			WithReturnType(resourceGroupType).
+			Build())
+	}
+	if targetScope.Has(types.ResourceScopeTenant | types.ResourceScopeManagementGroup | types.ResourceScopeSubscription | types.ResourceScopeResourceGroup) {
+		overloads = append(overloads, NewFunctionOverloadBuilder("resourceGroup").
+			WithDescription("Returns a named resource group scope in the named subscription.").
+			WithRequiredParameter("subscriptionId", types.String, "The subscription ID").
+			WithRequiredParameter("resourceGroupName", types.String, "The resource group name").
+			WithReturnType(resourceGroupType).
+			Build())
+	}
+	return overloads
+}
@@ -0,0 +1,248 @@
+package namespaces
+
+import (
+	"bicep-go/types"
+	"fmt"
+	"strings"
+)
+
+type FunctionFlags int
+
+const (
+	FunctionFlagsDefault FunctionFlags = 0
+	// FunctionFlagsParamDefaultsOnly marks functions that can only be used in parameter default values, e.g. utcNow().
+	FunctionFlagsParamDefaultsOnly FunctionFlags = 1 << iota
+	// FunctionFlagsRequiresInlining marks functions whose value is only known during the deployment
+	// of a resource, e.g. reference() and list*(), and which must be inlined where they are used.
+	FunctionFlagsRequiresInlining
+	// FunctionFlagsFileLoad marks functions that read a file at compile time, e.g. loadTextContent().
+	FunctionFlagsFileLoad
+
+	// The decorator flags mark the declarations a decorator can be attached to.
+	FunctionFlagsParameterDecorator
+	FunctionFlagsVariableDecorator
+	FunctionFlagsResourceDecorator
+	FunctionFlagsModuleDecorator
+	FunctionFlagsOutputDecorator
+	// FunctionFlagsTypeDecorator covers type declarations and the properties and items of type expressions.
+	FunctionFlagsTypeDecorator
+	FunctionFlagsFunctionDecorator
+
+	FunctionFlagsResourceOrModuleDecorator      = FunctionFlagsResourceDecorator | FunctionFlagsModuleDecorator
+	FunctionFlagsParameterOutputOrTypeDecorator = FunctionFlagsParameterDecorator | FunctionFlagsOutputDecorator | FunctionFlagsTypeDecorator
+	FunctionFlagsAnyDecorator                   = FunctionFlagsParameterOutputOrTypeDecorator | FunctionFlagsVariableDecorator | FunctionFlagsResourceOrModuleDecorator | FunctionFlagsFunctionDecorator
+)
+
+type FunctionParameter struct {
+	Name        string
+	Description string
+	Type        types.TypeSymbol
+	Required    bool
+	// Calculator, if set, refines Type for a given call, e.g. to type the arguments of a lambda after
+	// the items of the array it is applied to.
+	Calculator ParameterTypeCalculator
+}
+
+// VariableParameter accepts any number of trailing arguments of the same type, at least MinimumCount of them.
+type VariableParameter struct {
+	NamePrefix   string
+	Description  string
+	Type         types.TypeSymbol
+	MinimumCount int
+}
+
+// ParameterTypeCalculator calculates the type of a parameter from the types of the other arguments of a call.
+// Lambda arguments are passed as any, so that lambda parameters can be typed before their bodies.
+type ParameterTypeCalculator func(argumentTypes []types.TypeSymbol) types.TypeSymbol
+
+// ReturnTypeBuilder calculates the return type of an overload from the types of the arguments it is called with.
+type ReturnTypeBuilder func(argumentTypes []types.TypeSymbol) types.TypeSymbol
+
+// FunctionOverload is one signature of a function.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/FunctionOverload.cs
+type FunctionOverload struct {
+	Name              string
+	Description       string
+	FixedParameters   []*FunctionParameter
+	VariableParameter *VariableParameter
+	// ReturnType is the type shown in signatures. ReturnTypeBuilder, if set, refines it for a given call.
+	ReturnType        types.TypeSymbol
+	ReturnTypeBuilder ReturnTypeBuilder
+	Flags             FunctionFlags
+}
+
+func (o *FunctionOverload) MinimumArgumentCount() int {
+	count := 0
+	for _, parameter := range o.FixedParameters {
+		if parameter.Required {
+			count++
+		}
+	}
+	if o.VariableParameter != nil {
+		count += o.VariableParameter.MinimumCount
+	}
+	return count
+}
+
+// MaximumArgumentCount returns the number of arguments the overload accepts, or -1 if it is unlimited.
+func (o *FunctionOverload) MaximumArgumentCount() int {
+	if o.VariableParameter != nil {
+		return -1
+	}
+	return len(o.FixedParameters)
+}
+
+func (o *FunctionOverload) HasFlag(flag FunctionFlags) bool {
+	return o.Flags&flag != 0
+}
+
+// GetParameterType returns the type of the parameter at the argument index, or nil if there is no such parameter.
+func (o *FunctionOverload) GetParameterType(index int) types.TypeSymbol {
+	if index < len(o.FixedParameters) {
+		return o.FixedParameters[index].Type
+	}
+	if o.VariableParameter != nil {
+		return o.VariableParameter.Type
+	}
+	return nil
+}
+
+// GetCalculatedParameterType returns the type of the parameter at the argument index for a call with the given
+// argument types, or nil if there is no such parameter.
+func (o *FunctionOverload) GetCalculatedParameterType(index int, argumentTypes []types.TypeSymbol) types.TypeSymbol {
+	if index < len(o.FixedParameters) && o.FixedParameters[index].Calculator != nil {
+		return o.FixedParameters[index].Calculator(argumentTypes)
+	}
+	return o.GetParameterType(index)
+}
+
+// GetReturnType returns the return type of a call with the given argument types.
+func (o *FunctionOverload) GetReturnType(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+	if o.ReturnTypeBuilder != nil {
+		return o.ReturnTypeBuilder(argumentTypes)
+	}
+	return o.ReturnType
+}
+
+// GetTypeSignature renders the overload for hovers and completions, e.g. concat(... : array): array.
+func (o *FunctionOverload) GetTypeSignature() string {
+	var parameters []string
+	for _, parameter := range o.FixedParameters {
+		optionality := ""
+		if !parameter.Required {
+			optionality = "?"
+		}
+		parameters = append(parameters, fmt.Sprintf("%s%s: %s", parameter.Name, optionality, parameter.Type.GetName()))
+	}
+	if o.VariableParameter != nil {
+		parameters = append(parameters, fmt.Sprintf("... : %s", o.VariableParameter.Type.GetName()))
+	}
+	return fmt.Sprintf("%s(%s): %s", o.Name, strings.Join(parameters, ", "), o.ReturnType.GetName())
+}
+
+// Function is a built-in function with one or more overloads.
+type Function struct {
+	Name      string
+	Overloads []*FunctionOverload
+}
+
+func (f *Function) Description() string {
+	return f.Overloads[0].Description
+}
+
+// Decorator is a built-in decorator. Its overload describes the arguments it accepts,
+// and its flags the declarations it can be attached to.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/Decorator.cs
+type Decorator struct {
+	Name     string
+	Overload *FunctionOverload
+	// AttachableType is the type the decorated declaration must have, or any if the decorator applies to every type.
+	AttachableType types.TypeSymbol
+}
+
+// FunctionOverloadBuilder builds overloads with a fluent API.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/FunctionOverloadBuilder.cs
+type FunctionOverloadBuilder struct {
+	overload *FunctionOverload
+}
+
+func NewFunctionOverloadBuilder(name string) *FunctionOverloadBuilder {
+	return &FunctionOverloadBuilder{
+		overload: &FunctionOverload{
+			Name:       name,
+			ReturnType: types.Any,
+		},
+	}
+}
+
+func (b *FunctionOverloadBuilder) WithDescription(description string) *FunctionOverloadBuilder {
+	b.overload.Description = description
+	return b
+}
+
+func (b *FunctionOverloadBuilder) WithRequiredParameter(name string, typeSymbol types.TypeSymbol, description string) *FunctionOverloadBuilder {
+	b.overload.FixedParameters = append(b.overload.FixedParameters, &FunctionParameter{Name: name, Description: description, Type: typeSymbol, Required: true})
+	return b
+}
+
+func (b *FunctionOverloadBuilder) WithOptionalParameter(name string, typeSymbol types.TypeSymbol, description string) *FunctionOverloadBuilder {
+	b.overload.FixedParameters = append(b.overload.FixedParameters, &FunctionParameter{Name: name, Description: description, Type: typeSymbol})
+	return b
+}
+
+// WithRequiredLambdaParameter adds a parameter accepting lambdas of the given type, whose argument types
+// the calculator refines for a given call.
+func (b *FunctionOverloadBuilder) WithRequiredLambdaParameter(name string, lambdaType *types.LambdaType, calculator ParameterTypeCalculator, description string) *FunctionOverloadBuilder {
+	b.overload.FixedParameters = append(b.overload.FixedParameters, &FunctionParameter{Name: name, Description: description, Type: lambdaType, Required: true, Calculator: calculator})
+	return b
+}
+
+func (b *FunctionOverloadBuilder) WithOptionalLambdaParameter(name string, lambdaType *types.LambdaType, calculator ParameterTypeCalculator, description string) *FunctionOverloadBuilder {
+	b.overload.FixedParameters = append(b.overload.FixedParameters, &FunctionParameter{Name: name, Description: description, Type: lambdaType, Calculator: calculator})
+	return b
+}
+
+func (b *FunctionOverloadBuilder) WithVariableParameter(namePrefix string, typeSymbol types.TypeSymbol, minimumCount int, description string) *FunctionOverloadBuilder {
+	b.overload.VariableParameter = &VariableParameter{NamePrefix: namePrefix, Description: description, Type: typeSymbol, MinimumCount: minimumCount}
+	return b
+}
+
+func (b *FunctionOverloadBuilder) WithReturnType(returnType types.TypeSymbol) *FunctionOverloadBuilder {
+	b.overload.ReturnType = returnType
+	return b
+}
+
+func (b *FunctionOverloadBuilder) WithReturnTypeBuilder(returnType types.TypeSymbol, builder ReturnTypeBuilder) *FunctionOverloadBuilder {
+	b.overload.ReturnType = returnType
+	b.overload.ReturnTypeBuilder = builder
+	return b
+}
+
+func (b *FunctionOverloadBuilder) WithFlags(flags FunctionFlags) *FunctionOverloadBuilder {
+	b.overload.Flags = flags
+	return b
+}
+
+func (b *FunctionOverloadBuilder) Build() *FunctionOverload {
+	return b.overload
+}
+
+// newFunctions groups overloads by name, keeping the order in which names first appear.
+func newFunctions(overloads ...*FunctionOverload) []*Function {
+	var functions []*Function
+	byName := map[string]*Function{}
+	for _, overload := range overloads {
+		function, ok := byName[overload.Name]
+		if !ok {
+			function = &Function{Name: overload.Name}
+			byName[overload.Name] = function
+			functions = append(functions, function)
+		}
+		function.Overloads = append(function.Overloads, overload)
+	}
+	return functions
+}
+
+func newDecorator(overload *FunctionOverload, attachableType types.TypeSymbol) *Decorator {
+	return &Decorator{Name: overload.Name, Overload: overload, AttachableType: attachableType}
+}
@@ -0,0 +1,83 @@
+package namespaces
+
+import (
+	"bicep-go/types"
+	"strings"
+)
+
+const (
+	NAMESPACE_SYS = "sys"
+	NAMESPACE_AZ  = "az"
+
+	// a function name ending with the wildcard suffix matches every name with that prefix, e.g. list*
+	WILDCARD_SUFFIX = "*"
+)
+
+// Namespace is a set of built-in functions, decorators and types that Bicep files can reference
+// either directly or qualified by the namespace name, e.g. concat() or sys.concat().
+type Namespace struct {
+	Name       string
+	Functions  []*Function
+	Decorators []*Decorator
+	Types      []string
+}
+
+func (ns *Namespace) TryGetFunction(name string) *Function {
+	for _, function := range ns.Functions {
+		if function.Name == name {
+			return function
+		}
+	}
+	for _, function := range ns.Functions {
+		if prefix, ok := strings.CutSuffix(function.Name, WILDCARD_SUFFIX); ok && strings.HasPrefix(name, prefix) {
+			return function
+		}
+	}
+	return nil
+}
+
+func (ns *Namespace) TryGetDecorator(name string) *Decorator {
+	for _, decorator := range ns.Decorators {
+		if decorator.Name == name {
+			return decorator
+		}
+	}
+	return nil
+}
+
+func (ns *Namespace) HasType(name string) bool {
+	for _, typeName := range ns.Types {
+		if typeName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// TryGetQualifiedFunction finds a function by the name it is called with, either unqualified, e.g. concat,
+// or qualified by the namespace name, e.g. sys.concat. Unqualified names resolve to the first namespace
+// that declares the function.
+func TryGetQualifiedFunction(namespaces []*Namespace, name string) (*Namespace, *Function) {
+	namespaceName, functionName, qualified := strings.Cut(name, ".")
+	if !qualified {
+		functionName = name
+	}
+	for _, ns := range namespaces {
+		if qualified && ns.Name != namespaceName {
+			continue
+		}
+		if function := ns.TryGetFunction(functionName); function != nil {
+			return ns, function
+		}
+	}
+	return nil, nil
+}
+
+// GetDefaultNamespaces returns the namespaces that are implicitly imported into every Bicep file
+// deployed at the target scope.
+func GetDefaultNamespaces(targetScope types.ResourceScope) []*Namespace {
+	return []*Namespace{
+		NewSystemNamespace(),
+		NewAzNamespace(targetScope),
+	}
+}
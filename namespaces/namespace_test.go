@@ -0,0 +1,89 @@
+package namespaces
+
+import (
+	"bicep-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTryGetQualifiedFunction(t *testing.T) {
+	tests := []struct {
+		name              string
+		expectedNamespace string
+		expectedFunction  string
+	}{
+		{"concat", NAMESPACE_SYS, "concat"},
+		{"sys.concat", NAMESPACE_SYS, "concat"},
+		{"resourceGroup", NAMESPACE_AZ, "resourceGroup"},
+		{"az.resourceGroup", NAMESPACE_AZ, "resourceGroup"},
+		{"az.listKeys", NAMESPACE_AZ, "list*"},
+		{"sys.resourceGroup", "", ""},
+		{"foo", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ns, function := TryGetQualifiedFunction(GetDefaultNamespaces(types.ResourceScopeResourceGroup), tt.name)
+			if tt.expectedFunction == "" {
+				require.Nil(t, ns)
+				require.Nil(t, function)
+				return
+			}
+			require.Equal(t, tt.expectedNamespace, ns.Name)
+			require.Equal(t, tt.expectedFunction, function.Name)
+		})
+	}
+}
+
+func TestFunctionOverloads(t *testing.T) {
+	function := NewSystemNamespace().TryGetFunction("concat")
+	require.Len(t, function.Overloads, 2)
+	require.Equal(t, "concat(... : array): array", function.Overloads[0].GetTypeSignature())
+	require.Equal(t, 1, function.Overloads[0].MinimumArgumentCount())
+	require.Equal(t, -1, function.Overloads[0].MaximumArgumentCount())
+
+	returnType := function.Overloads[0].GetReturnType([]types.TypeSymbol{types.NewArrayType(types.String), types.NewTupleType([]types.TypeSymbol{types.Int})})
+	require.Equal(t, "(string | int)[]", returnType.GetName())
+
+	substring := NewSystemNamespace().TryGetFunction("substring").Overloads[0]
+	require.Equal(t, "substring(stringToParse: string, startIndex: int, length?: int): string", substring.GetTypeSignature())
+	require.Equal(t, 2, substring.MinimumArgumentCount())
+	require.Equal(t, 3, substring.MaximumArgumentCount())
+
+	utcNow := NewSystemNamespace().TryGetFunction("utcNow").Overloads[0]
+	require.True(t, utcNow.HasFlag(FunctionFlagsParamDefaultsOnly))
+}
+
+func TestScopeFunctionOverloads(t *testing.T) {
+	tests := []struct {
+		targetScope types.ResourceScope
+		function    string
+		expected    []int
+	}{
+		{types.ResourceScopeResourceGroup, "resourceGroup", []int{0, 1, 2}},
+		{types.ResourceScopeSubscription, "resourceGroup", []int{1, 2}},
+		{types.ResourceScopeTenant, "resourceGroup", []int{2}},
+		{types.ResourceScopeResourceGroup, "subscription", []int{0, 1}},
+		{types.ResourceScopeManagementGroup, "subscription", []int{1}},
+		{types.ResourceScopeManagementGroup, "managementGroup", []int{0, 1}},
+		{types.ResourceScopeTenant, "managementGroup", []int{1}},
+		{types.ResourceScopeDesiredStateConfiguration, "tenant", []int{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.function, func(t *testing.T) {
+			var argumentCounts []int
+			for _, overload := range NewAzNamespace(tt.targetScope).TryGetFunction(tt.function).Overloads {
+				argumentCounts = append(argumentCounts, overload.MinimumArgumentCount())
+			}
+			require.Equal(t, tt.expected, argumentCounts)
+		})
+	}
+
+	require.Nil(t, NewAzNamespace(types.ResourceScopeSubscription).TryGetFunction("managementGroup"))
+
+	resourceGroup := NewAzNamespace(types.ResourceScopeResourceGroup).TryGetFunction("resourceGroup").Overloads[0]
+	require.Equal(t, types.ResourceScopeResourceGroup, TryGetScopeReference(resourceGroup.ReturnType))
+	require.Equal(t, types.ResourceScopeNone, TryGetScopeReference(types.Object))
+}
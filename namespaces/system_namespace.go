@@ -0,0 +1,719 @@
+package namespaces
+
+import (
+	"bicep-go/syntax"
+	"bicep-go/types"
+)
+
+const (
+	DECORATOR_ALLOWED       = "allowed"
+	DECORATOR_BATCH_SIZE    = "batchSize"
+	DECORATOR_DESCRIPTION   = "description"
+	DECORATOR_DISCRIMINATOR = "discriminator"
+	DECORATOR_EXPORT        = "export"
+	DECORATOR_MAX_LENGTH    = "maxLength"
+	DECORATOR_MAX_VALUE     = "maxValue"
+	DECORATOR_METADATA      = "metadata"
+	DECORATOR_MIN_LENGTH    = "minLength"
+	DECORATOR_MIN_VALUE     = "minValue"
+	DECORATOR_SEALED        = "sealed"
+	DECORATOR_SECURE        = "secure"
+)
+
+// The encodings of files loaded at compile time.
+const (
+	FILE_ENCODING_US_ASCII   = "us-ascii"
+	FILE_ENCODING_ISO_8859_1 = "iso-8859-1"
+	FILE_ENCODING_UTF_8      = "utf-8"
+	FILE_ENCODING_UTF_16_BE  = "utf-16BE"
+	FILE_ENCODING_UTF_16     = "utf-16"
+)
+
+var (
+	stringOrInt    = types.CreateUnion(types.String, types.Int)
+	stringOrArray  = types.CreateUnion(types.String, types.Array)
+	stringOrObject = types.CreateUnion(types.String, types.Object)
+	fileEncoding   = types.CreateUnion(
+		types.NewStringLiteralType(FILE_ENCODING_US_ASCII),
+		types.NewStringLiteralType(FILE_ENCODING_ISO_8859_1),
+		types.NewStringLiteralType(FILE_ENCODING_UTF_8),
+		types.NewStringLiteralType(FILE_ENCODING_UTF_16_BE),
+		types.NewStringLiteralType(FILE_ENCODING_UTF_16),
+	)
+)
+
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/Namespaces/SystemNamespaceType.cs
+func NewSystemNamespace() *Namespace {
+	return &Namespace{
+		Name:      NAMESPACE_SYS,
+		Functions: newFunctions(systemFunctionOverloads()...),
+		Decorators: []*Decorator{
+			newDecorator(NewFunctionOverloadBuilder(DECORATOR_ALLOWED).
+				WithDescription("Defines the allowed values of the parameter.").
+				WithRequiredParameter("values", types.Array, "The allowed values.").
+				WithFlags(FunctionFlagsParameterDecorator).
+				Build(), types.Any),
+			newDecorator(NewFunctionOverloadBuilder(DECORATOR_BATCH_SIZE).
+				WithDescription("Causes the resource or module for-expression to be run in sequential batches of specified size instead of the default behavior where all the resources or modules are deployed in parallel.").
+				WithRequiredParameter("batchSize", types.Int, "The size of the batch.").
+				WithFlags(FunctionFlagsResourceOrModuleDecorator).
+				Build(), types.Any),
+			newDecorator(NewFunctionOverloadBuilder(DECORATOR_DESCRIPTION).
+				WithDescription("Describes the declaration.").
+				WithRequiredParameter("text", types.String, "The description.").
+				WithFlags(FunctionFlagsAnyDecorator).
+				Build(), types.Any),
+			newDecorator(NewFunctionOverloadBuilder(DECORATOR_DISCRIMINATOR).
+				WithDescription("Defines the discriminator property to use for a tagged union that is shared between all union members.").
+				WithRequiredParameter("value", types.String, "The discriminator property name.").
+				WithFlags(FunctionFlagsParameterOutputOrTypeDecorator).
+				Build(), types.Any),
+			newDecorator(NewFunctionOverloadBuilder(DECORATOR_EXPORT).
+				WithDescription("Allows the declaration to be imported by other templates.").
+				WithFlags(FunctionFlagsVariableDecorator|FunctionFlagsTypeDecorator|FunctionFlagsFunctionDecorator).
+				Build(), types.Any),
+			newDecorator(NewFunctionOverloadBuilder(DECORATOR_MAX_LENGTH).
+				WithDescription("Defines the maximum length of the string or array.").
+				WithRequiredParameter("length", types.Int, "The maximum length.").
+				WithFlags(FunctionFlagsParameterOutputOrTypeDecorator).
+				Build(), stringOrArray),
+			newDecorator(NewFunctionOverloadBuilder(DECORATOR_MAX_VALUE).
+				WithDescription("Defines the maximum value of the integer.").
+				WithRequiredParameter("value", types.Int, "The maximum value.").
+				WithFlags(FunctionFlagsParameterOutputOrTypeDecorator).
+				Build(), types.Int),
+			newDecorator(NewFunctionOverloadBuilder(DECORATOR_METADATA).
+				WithDescription("Defines metadata of the declaration.").
+				WithRequiredParameter("object", types.Object, "The metadata object.").
+				WithFlags(FunctionFlagsParameterOutputOrTypeDecorator).
+				Build(), types.Any),
+			newDecorator(NewFunctionOverloadBuilder(DECORATOR_MIN_LENGTH).
+				WithDescription("Defines the minimum length of the string or array.").
+				WithRequiredParameter("length", types.Int, "The minimum length.").
+				WithFlags(FunctionFlagsParameterOutputOrTypeDecorator).
+				Build(), stringOrArray),
+			newDecorator(NewFunctionOverloadBuilder(DECORATOR_MIN_VALUE).
+				WithDescription("Defines the minimum value of the integer.").
+				WithRequiredParameter("value", types.Int, "The minimum value.").
+				WithFlags(FunctionFlagsParameterOutputOrTypeDecorator).
+				Build(), types.Int),
+			newDecorator(NewFunctionOverloadBuilder(DECORATOR_SEALED).
+				WithDescription("Marks an object type as not allowing additional properties.").
+				WithFlags(FunctionFlagsParameterOutputOrTypeDecorator).
+				Build(), types.Object),
+			newDecorator(NewFunctionOverloadBuilder(DECORATOR_SECURE).
+				WithDescription("Makes the parameter a secure parameter.").
+				WithFlags(FunctionFlagsParameterOutputOrTypeDecorator).
+				Build(), stringOrObject),
+		},
+		Types: []string{
+			syntax.TYPE_ARRAY, syntax.TYPE_NAME_BOOL, syntax.TYPE_NAME_INT, syntax.TYPE_OBJECT, syntax.TYPE_NAME_STRING,
+		},
+	}
+}
+
+func systemFunctionOverloads() []*FunctionOverload {
+	return []*FunctionOverload{
+		NewFunctionOverloadBuilder("any").
+			WithDescription("Converts the specified value to the `any` type.").
+			WithRequiredParameter("value", types.Any, "The value to convert to `any` type").
+			WithReturnType(types.Any).
+			Build(),
+		NewFunctionOverloadBuilder("array").
+			WithDescription("Converts the value to an array.").
+			WithRequiredParameter("valueToConvert", types.Any, "The value to convert to an array.").
+			WithReturnTypeBuilder(types.Array, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				switch argumentType := argumentTypes[0].(type) {
+				case *types.ArrayType, *types.TupleType:
+					return argumentType
+				case *types.AnyType, *types.ErrorType:
+					return types.Array
+				}
+				return types.NewTupleType(argumentTypes[:1])
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("base64").
+			WithDescription("Returns the base64 representation of the input string.").
+			WithRequiredParameter("inputString", types.String, "The value to return as a base64 representation.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("base64ToJson").
+			WithDescription("Converts a base64 representation to a JSON object.").
+			WithRequiredParameter("base64Value", types.String, "The base64 representation to convert to a JSON object.").
+			WithReturnType(types.Any).
+			Build(),
+		NewFunctionOverloadBuilder("base64ToString").
+			WithDescription("Converts a base64 representation to a string.").
+			WithRequiredParameter("base64Value", types.String, "The base64 representation to convert to a string.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("bool").
+			WithDescription("Converts the parameter to a boolean.").
+			WithRequiredParameter("value", types.Any, "The value to convert to a boolean.").
+			WithReturnType(types.Bool).
+			Build(),
+		NewFunctionOverloadBuilder("cidrHost").
+			WithDescription("Calculates the usable IP address of the host with the specified index on the specified IP address range in CIDR notation.").
+			WithRequiredParameter("network", types.String, "String containing an IP address range to convert in CIDR notation.").
+			WithRequiredParameter("hostIndex", types.Int, "The index of the host IP address to return.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("cidrSubnet").
+			WithDescription("Splits the specified IP address range in CIDR notation into subnets with a new CIDR value and returns the IP address range of the subnet with the specified index.").
+			WithRequiredParameter("network", types.String, "String containing an IP address range to convert in CIDR notation.").
+			WithRequiredParameter("newCIDR", types.Int, "An integer representing the CIDR to be used to subnet.").
+			WithRequiredParameter("subnetIndex", types.Int, "Index of the desired subnet IP address range to return.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("coalesce").
+			WithDescription("Returns first non-null value from the parameters. Empty strings, empty arrays, and empty objects are not null.").
+			WithVariableParameter("argument", types.Any, 1, "The value to coalesce").
+			WithReturnTypeBuilder(types.Any, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				var members []types.TypeSymbol
+				for _, argumentType := range argumentTypes {
+					members = append(members, types.RemoveNullability(argumentType))
+				}
+				return types.CreateUnion(members...)
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("concat").
+			WithDescription("Combines multiple arrays and returns the concatenated array.").
+			WithVariableParameter("array", types.Array, 1, "The array for concatenation").
+			WithReturnTypeBuilder(types.Array, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				var items []types.TypeSymbol
+				for _, argumentType := range argumentTypes {
+					items = append(items, getItemType(argumentType))
+				}
+				return types.NewArrayType(types.CreateUnion(items...))
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("concat").
+			WithDescription("Combines multiple string, integer, or boolean values and returns them as a concatenated string.").
+			WithVariableParameter("argument", types.CreateUnion(types.String, types.Int, types.Bool), 1, "The string, int, or boolean value for concatenation").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("contains").
+			WithDescription("Checks whether an object contains a property. The property name comparison is case-insensitive.").
+			WithRequiredParameter("object", types.Object, "The object").
+			WithRequiredParameter("propertyName", types.String, "The property name.").
+			WithReturnType(types.Bool).
+			Build(),
+		NewFunctionOverloadBuilder("contains").
+			WithDescription("Checks whether an array contains a value.").
+			WithRequiredParameter("array", types.Array, "The array").
+			WithRequiredParameter("itemToFind", types.Any, "The value to find.").
+			WithReturnType(types.Bool).
+			Build(),
+		NewFunctionOverloadBuilder("contains").
+			WithDescription("Checks whether a string contains a substring. The string comparison is case-sensitive.").
+			WithRequiredParameter("string", types.String, "The string.").
+			WithRequiredParameter("itemToFind", types.String, "The value to find.").
+			WithReturnType(types.Bool).
+			Build(),
+		NewFunctionOverloadBuilder("dataUri").
+			WithDescription("Converts a value to a data URI.").
+			WithRequiredParameter("valueToConvert", types.Any, "The value to convert to a data URI.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("dataUriToString").
+			WithDescription("Converts a data URI formatted value to a string.").
+			WithRequiredParameter("dataUriToConvert", types.String, "The data URI value to convert.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("dateTimeAdd").
+			WithDescription("Adds a time duration to a base value. ISO 8601 format is expected.").
+			WithRequiredParameter("base", types.String, "The starting datetime value for the addition. Use ISO 8601 timestamp format.").
+			WithRequiredParameter("duration", types.String, "The time value to add to the base. It can be a negative value. Use ISO 8601 duration format.").
+			WithOptionalParameter("format", types.String, "The output format for the date time result. If not provided, the format of the base value is used.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("dateTimeFromEpoch").
+			WithDescription("Converts an epoch time integer value to an ISO 8601 datetime.").
+			WithRequiredParameter("epochTime", types.Int, "The epoch time value that will be converted to an ISO 8601 datetime.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("dateTimeToEpoch").
+			WithDescription("Converts an ISO 8601 datetime string to an epoch time integer value.").
+			WithRequiredParameter("dateTime", types.String, "An ISO 8601 formatted datetime that will be converted to an epoch time.").
+			WithReturnType(types.Int).
+			Build(),
+		NewFunctionOverloadBuilder("empty").
+			WithDescription("Determines if an array, object, or string is empty.").
+			WithRequiredParameter("itemToTest", types.CreateUnion(types.Null, types.Object, types.Array, types.String), "The value to check if it is empty.").
+			WithReturnType(types.Bool).
+			Build(),
+		NewFunctionOverloadBuilder("endsWith").
+			WithDescription("Determines whether a string ends with a value. The comparison is case-insensitive.").
+			WithRequiredParameter("stringToSearch", types.String, "The value that contains the item to find.").
+			WithRequiredParameter("stringToFind", types.String, "The value to find.").
+			WithReturnType(types.Bool).
+			Build(),
+		NewFunctionOverloadBuilder("filter").
+			WithDescription("Filters an array with a custom filtering function.").
+			WithRequiredParameter("array", types.Array, "The array to filter.").
+			WithRequiredLambdaParameter("predicate", itemLambda(types.Any, 1, true, types.Bool), calculateItemLambda(1, true, types.Bool), "The predicate applied to each input array element. If false, the item will be filtered out of the output array.").
+			WithReturnTypeBuilder(types.Array, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				return types.NewArrayType(getItemType(argumentTypes[0]))
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("first").
+			WithDescription("Returns the first element of the array.").
+			WithRequiredParameter("array", types.Array, "The value to retrieve the first element.").
+			WithReturnTypeBuilder(types.Any, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				if tuple, ok := argumentTypes[0].(*types.TupleType); ok && len(tuple.Items) > 0 {
+					return tuple.Items[0]
+				}
+				return types.CreateNullable(getItemType(argumentTypes[0]))
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("first").
+			WithDescription("Returns the first character of the string.").
+			WithRequiredParameter("string", types.String, "The value to retrieve the first character.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("flatten").
+			WithDescription("Takes an array of arrays, and returns an array of sub-array elements, in the original order. Sub-arrays are only flattened once, not recursively.").
+			WithRequiredParameter("array", types.NewArrayType(types.Array), "The array of sub-arrays to flatten.").
+			WithReturnTypeBuilder(types.Array, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				return types.NewArrayType(getItemType(getItemType(argumentTypes[0])))
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("format").
+			WithDescription("Creates a formatted string from input values.").
+			WithRequiredParameter("formatString", types.String, "The composite format string.").
+			WithVariableParameter("arg", types.Any, 0, "The value to include in the formatted string.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("groupBy").
+			WithDescription("Creates an object with array values from an array, using a grouping function.").
+			WithRequiredParameter("array", types.Array, "The array to group.").
+			WithRequiredLambdaParameter("predicate", itemLambda(types.Any, 1, false, types.String), calculateItemLambda(1, false, types.String), "The predicate applied to each input array element to return the group key.").
+			WithReturnTypeBuilder(types.Object, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				return types.NewObjectType("object", nil, types.NewArrayType(getItemType(argumentTypes[0])))
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("guid").
+			WithDescription("Creates a value in the format of a globally unique identifier based on the values provided as parameters.").
+			WithVariableParameter("guid", types.String, 1, "The value used in the hash function to create the GUID.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("indexOf").
+			WithDescription("Returns the first position of a value within a string. The comparison is case-insensitive.").
+			WithRequiredParameter("stringToSearch", types.String, "The value that contains the item to find.").
+			WithRequiredParameter("stringToFind", types.String, "The value to find.").
+			WithReturnType(types.Int).
+			Build(),
+		NewFunctionOverloadBuilder("indexOf").
+			WithDescription("Returns the index of the first occurrence of an item in an array. The comparison is case-sensitive for strings.").
+			WithRequiredParameter("array", types.Array, "The array that contains the item to find.").
+			WithRequiredParameter("itemToFind", types.Any, "The value to find.").
+			WithReturnType(types.Int).
+			Build(),
+		NewFunctionOverloadBuilder("int").
+			WithDescription("Converts the specified value to an integer.").
+			WithRequiredParameter("valueToConvert", stringOrInt, "The value to convert to an integer.").
+			WithReturnType(types.Int).
+			Build(),
+		NewFunctionOverloadBuilder("intersection").
+			WithDescription("Returns a single array or object with the common elements from the parameters.").
+			WithVariableParameter("object", types.Object, 2, "The object to use for finding common elements.").
+			WithReturnType(types.Object).
+			Build(),
+		NewFunctionOverloadBuilder("intersection").
+			WithDescription("Returns a single array or object with the common elements from the parameters.").
+			WithVariableParameter("array", types.Array, 2, "The array to use for finding common elements.").
+			WithReturnType(types.Array).
+			Build(),
+		NewFunctionOverloadBuilder("items").
+			WithDescription("Returns an array of objects representing the key-value pairs of an object, sorted by key.").
+			WithRequiredParameter("object", types.Object, "The object to return keys and values for").
+			WithReturnTypeBuilder(types.Array, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				valueType := types.TypeSymbol(types.Any)
+				if objectType, ok := argumentTypes[0].(*types.ObjectType); ok {
+					var values []types.TypeSymbol
+					for _, property := range objectType.Properties {
+						values = append(values, property.Type)
+					}
+					if objectType.AdditionalPropertiesType != nil {
+						values = append(values, objectType.AdditionalPropertiesType)
+					}
+					valueType = types.CreateUnion(values...)
+				}
+				properties := []*types.TypeProperty{
+					types.NewTypeProperty("key", types.String, types.TypePropertyFlagsRequired),
+					types.NewTypeProperty("value", valueType, types.TypePropertyFlagsRequired),
+				}
+				return types.NewArrayType(types.NewObjectType(types.FormatObjectTypeName(properties, nil), properties, nil))
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("join").
+			WithDescription("Joins multiple strings into a single string, separated using a delimiter.").
+			WithRequiredParameter("inputArray", types.NewArrayType(types.String), "An array of strings to join.").
+			WithRequiredParameter("delimiter", types.String, "The delimiter to use to join the string.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("json").
+			WithDescription("Converts a valid JSON string into a JSON data type.").
+			WithRequiredParameter("json", types.String, "The value to convert to JSON. The string must be a properly formatted JSON string.").
+			WithReturnType(types.Any).
+			Build(),
+		NewFunctionOverloadBuilder("last").
+			WithDescription("Returns the last element of the array.").
+			WithRequiredParameter("array", types.Array, "The value to retrieve the last element.").
+			WithReturnTypeBuilder(types.Any, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				if tuple, ok := argumentTypes[0].(*types.TupleType); ok && len(tuple.Items) > 0 {
+					return tuple.Items[len(tuple.Items)-1]
+				}
+				return types.CreateNullable(getItemType(argumentTypes[0]))
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("last").
+			WithDescription("Returns the last character of the string.").
+			WithRequiredParameter("string", types.String, "The value to retrieve the last character.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("lastIndexOf").
+			WithDescription("Returns the last position of a value within a string. The comparison is case-insensitive.").
+			WithRequiredParameter("stringToSearch", types.String, "The value that contains the item to find.").
+			WithRequiredParameter("stringToFind", types.String, "The value to find.").
+			WithReturnType(types.Int).
+			Build(),
+		NewFunctionOverloadBuilder("lastIndexOf").
+			WithDescription("Returns the index of the last occurrence of an item in an array. The comparison is case-sensitive for strings.").
+			WithRequiredParameter("array", types.Array, "The array that contains the item to find.").
+			WithRequiredParameter("itemToFind", types.Any, "The value to find.").
+			WithReturnType(types.Int).
+			Build(),
+		NewFunctionOverloadBuilder("length").
+			WithDescription("Returns the number of elements in an array, characters in a string, or root-level properties in an object.").
+			WithRequiredParameter("arg", types.CreateUnion(types.Array, types.String, types.Object), "The array to use for getting the number of elements, the string to use for getting the number of characters, or the object to use for getting the number of root-level properties.").
+			WithReturnTypeBuilder(types.Int, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				switch argumentType := argumentTypes[0].(type) {
+				case *types.TupleType:
+					return types.NewIntegerLiteralType(int64(len(argumentType.Items)))
+				case *types.StringLiteralType:
+					return types.NewIntegerLiteralType(int64(len([]rune(argumentType.Value))))
+				}
+				return types.Int
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("loadFileAsBase64").
+			WithDescription("Loads the specified file as base64 string. File loading occurs during compilation, not at runtime.").
+			WithRequiredParameter("filePath", types.String, "The path to the file that will be loaded.").
+			WithReturnType(types.String).
+			WithFlags(FunctionFlagsFileLoad).
+			Build(),
+		NewFunctionOverloadBuilder("loadJsonContent").
+			WithDescription("Loads the specified JSON file as bicep object. File loading occurs during compilation, not at runtime.").
+			WithRequiredParameter("filePath", types.String, "The path to the file that will be loaded.").
+			WithOptionalParameter("jsonPath", types.String, "JSONPath expression to narrow down the loaded file. If not provided, a root element indicator '$' is used").
+			WithOptionalParameter("encoding", fileEncoding, "File encoding. If not provided, UTF-8 will be used.").
+			WithReturnType(types.Any).
+			WithFlags(FunctionFlagsFileLoad).
+			Build(),
+		NewFunctionOverloadBuilder("loadTextContent").
+			WithDescription("Loads the content of the specified file into a string. Content loading occurs during compilation, not at runtime.").
+			WithRequiredParameter("filePath", types.String, "The path to the file that will be loaded.").
+			WithOptionalParameter("encoding", fileEncoding, "File encoding. If not provided, UTF-8 will be used.").
+			WithReturnType(types.String).
+			WithFlags(FunctionFlagsFileLoad).
+			Build(),
+		NewFunctionOverloadBuilder("loadYamlContent").
+			WithDescription("Loads the specified YAML file as bicep object. File loading occurs during compilation, not at runtime.").
+			WithRequiredParameter("filePath", types.String, "The path to the file that will be loaded.").
+			WithOptionalParameter("pathFilter", types.String, "The path filter is a JsonPath expression to narrow down the loaded file. If not provided, a root element indicator '$' is used").
+			WithOptionalParameter("encoding", fileEncoding, "File encoding. If not provided, UTF-8 will be used.").
+			WithReturnType(types.Any).
+			WithFlags(FunctionFlagsFileLoad).
+			Build(),
+		NewFunctionOverloadBuilder("map").
+			WithDescription("Applies a custom mapping function to each element of an array and returns the result array.").
+			WithRequiredParameter("array", types.Array, "The array to map.").
+			WithRequiredLambdaParameter("predicate", itemLambda(types.Any, 1, true, types.Any), calculateItemLambda(1, true, types.Any), "The predicate applied to each input array element, in order to generate the output array.").
+			WithReturnTypeBuilder(types.Array, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				return types.NewArrayType(getLambdaReturnType(argumentTypes[1]))
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("mapValues").
+			WithDescription("Applies a custom mapping function to the values of an object and returns the result object.").
+			WithRequiredParameter("object", types.Object, "The object to map.").
+			WithRequiredLambdaParameter("predicate", types.NewLambdaType([]types.TypeSymbol{types.Any}, types.Any), func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				return types.NewLambdaType([]types.TypeSymbol{getPropertyValueType(argumentTypes[0])}, types.Any)
+			}, "The predicate applied to each input object value, in order to generate the output object.").
+			WithReturnTypeBuilder(types.Object, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				return types.NewObjectType("object", nil, getLambdaReturnType(argumentTypes[1]))
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("max").
+			WithDescription("Returns the maximum value from an array of integers or a comma-separated list of integers.").
+			WithVariableParameter("int", types.Int, 1, "One of the integers used to calculate the maximum value").
+			WithReturnType(types.Int).
+			Build(),
+		NewFunctionOverloadBuilder("max").
+			WithDescription("Returns the maximum value from an array of integers or a comma-separated list of integers.").
+			WithRequiredParameter("intArray", types.NewArrayType(types.Int), "The array of integers used to calculate the maximum value").
+			WithReturnType(types.Int).
+			Build(),
+		NewFunctionOverloadBuilder("min").
+			WithDescription("Returns the minimum value from an array of integers or a comma-separated list of integers.").
+			WithVariableParameter("int", types.Int, 1, "One of the integers used to calculate the minimum value").
+			WithReturnType(types.Int).
+			Build(),
+		NewFunctionOverloadBuilder("min").
+			WithDescription("Returns the minimum value from an array of integers or a comma-separated list of integers.").
+			WithRequiredParameter("intArray", types.NewArrayType(types.Int), "The array of integers used to calculate the minimum value").
+			WithReturnType(types.Int).
+			Build(),
+		NewFunctionOverloadBuilder("newGuid").
+			WithDescription("Returns a value in the format of a globally unique identifier. This function can only be used in the default value for a parameter.").
+			WithReturnType(types.String).
+			WithFlags(FunctionFlagsParamDefaultsOnly).
+			Build(),
+		NewFunctionOverloadBuilder("objectKeys").
+			WithDescription("Returns the keys of an object, sorted by key.").
+			WithRequiredParameter("object", types.Object, "The object to return the keys of").
+			WithReturnType(types.NewArrayType(types.String)).
+			Build(),
+		NewFunctionOverloadBuilder("padLeft").
+			WithDescription("Returns a right-aligned string by adding characters to the left until reaching the total specified length.").
+			WithRequiredParameter("valueToPad", stringOrInt, "The value to right-align.").
+			WithRequiredParameter("totalLength", types.Int, "The total number of characters in the returned string.").
+			WithOptionalParameter("paddingCharacter", types.String, "The character to use for left-padding until the total length is reached. The default value is a space.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("parseCidr").
+			WithDescription("Parses an IP address range in CIDR notation to get various properties of the address range.").
+			WithRequiredParameter("network", types.String, "String in CIDR notation containing an IP address range to be converted.").
+			WithReturnType(types.Object).
+			Build(),
+		NewFunctionOverloadBuilder("range").
+			WithDescription("Creates an array of integers from a starting integer and containing a number of items.").
+			WithRequiredParameter("startIndex", types.Int, "The first integer in the array. The sum of startIndex and count must be no greater than 2147483647.").
+			WithRequiredParameter("count", types.Int, "The number of integers in the array. Must be non-negative integer up to 10000.").
+			WithReturnType(types.NewArrayType(types.Int)).
+			Build(),
+		NewFunctionOverloadBuilder("reduce").
+			WithDescription("Reduces an array with a custom reduce function.").
+			WithRequiredParameter("array", types.Array, "The array to reduce.").
+			WithRequiredParameter("initialValue", types.Any, "The initial value.").
+			WithRequiredLambdaParameter("predicate", reduceLambda(types.Any), func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				return reduceLambda(getItemType(argumentTypes[0]))
+			}, "The predicate applied to each input array element in order to aggregate the current value and the next value.").
+			WithReturnTypeBuilder(types.Any, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				return getLambdaReturnType(argumentTypes[2])
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("replace").
+			WithDescription("Returns a new string with all instances of one character in the specified string replaced by another character.").
+			WithRequiredParameter("originalString", types.String, "The original string.").
+			WithRequiredParameter("oldString", types.String, "The character to be removed from the original string.").
+			WithRequiredParameter("newString", types.String, "The character to add in place of the removed character.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("shallowMerge").
+			WithDescription("Combines an array of objects, where only the top-level objects are merged.").
+			WithRequiredParameter("entries", types.NewArrayType(types.Object), "The array of objects to merge.").
+			WithReturnType(types.Object).
+			Build(),
+		NewFunctionOverloadBuilder("skip").
+			WithDescription("Returns an array with all the elements after the specified number in the array.").
+			WithRequiredParameter("originalValue", types.Array, "The array to use for skipping.").
+			WithRequiredParameter("numberToSkip", types.Int, "The number of elements to skip. If this value is 0 or less, all the elements in the value are returned. If it's larger than the length of the array, an empty array is returned.").
+			WithReturnTypeBuilder(types.Array, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				return types.NewArrayType(getItemType(argumentTypes[0]))
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("skip").
+			WithDescription("Returns a string with all the characters after the specified number in the string.").
+			WithRequiredParameter("originalValue", types.String, "The string to use for skipping.").
+			WithRequiredParameter("numberToSkip", types.Int, "The number of characters to skip. If this value is 0 or less, all the characters in the value are returned. If it's larger than the length of the string, an empty string is returned.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("sort").
+			WithDescription("Sorts an array with a custom sort function.").
+			WithRequiredParameter("array", types.Array, "The array to sort.").
+			WithRequiredLambdaParameter("predicate", itemLambda(types.Any, 2, false, types.Bool), calculateItemLambda(2, false, types.Bool), "The predicate used to compare two array elements for ordering. If true, the second element will be ordered after the first in the output array.").
+			WithReturnTypeBuilder(types.Array, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				return types.NewArrayType(getItemType(argumentTypes[0]))
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("split").
+			WithDescription("Returns an array of strings that contains the substrings of the input string that are delimited by the specified delimiters.").
+			WithRequiredParameter("inputString", types.String, "The string to split.").
+			WithRequiredParameter("delimiter", types.CreateUnion(types.String, types.NewArrayType(types.String)), "The delimiter to use for splitting the string.").
+			WithReturnType(types.NewArrayType(types.String)).
+			Build(),
+		NewFunctionOverloadBuilder("startsWith").
+			WithDescription("Determines whether a string starts with a value. The comparison is case-insensitive.").
+			WithRequiredParameter("stringToSearch", types.String, "The value that contains the item to find.").
+			WithRequiredParameter("stringToFind", types.String, "The value to find.").
+			WithReturnType(types.Bool).
+			Build(),
+		NewFunctionOverloadBuilder("string").
+			WithDescription("Converts the specified value to a string.").
+			WithRequiredParameter("valueToConvert", types.Any, "The value to convert to string. Any type of value can be converted, including objects and arrays.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("substring").
+			WithDescription("Returns a substring that starts at the specified character position and contains the specified number of characters.").
+			WithRequiredParameter("stringToParse", types.String, "The original string from which the substring is extracted.").
+			WithRequiredParameter("startIndex", types.Int, "The zero-based starting character position for the substring.").
+			WithOptionalParameter("length", types.Int, "The number of characters for the substring. Must refer to a location within the string. Must be zero or greater.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("take").
+			WithDescription("Returns an array with the specified number of elements from the start of the array.").
+			WithRequiredParameter("originalValue", types.Array, "The array to take the elements from.").
+			WithRequiredParameter("numberToTake", types.Int, "The number of elements to take. If this value is 0 or less, an empty array is returned. If it's larger than the length of the given array, all the elements in the array are returned.").
+			WithReturnTypeBuilder(types.Array, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				return types.NewArrayType(getItemType(argumentTypes[0]))
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("take").
+			WithDescription("Returns a string with the specified number of characters from the start of the string.").
+			WithRequiredParameter("originalValue", types.String, "The string to take the characters from.").
+			WithRequiredParameter("numberToTake", types.Int, "The number of characters to take. If this value is 0 or less, an empty string is returned. If it's larger than the length of the given string, all the characters are returned.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("toLower").
+			WithDescription("Converts the specified string to lower case.").
+			WithRequiredParameter("stringToChange", types.String, "The value to convert to lower case.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("toObject").
+			WithDescription("Converts an array to an object with a custom key function and optional custom value function.").
+			WithRequiredParameter("array", types.Array, "The array to map to an object.").
+			WithRequiredLambdaParameter("keyPredicate", itemLambda(types.Any, 1, false, types.String), calculateItemLambda(1, false, types.String), "The predicate applied to each input array element to return the object key.").
+			WithOptionalLambdaParameter("valuePredicate", itemLambda(types.Any, 1, false, types.Any), calculateItemLambda(1, false, types.Any), "The optional predicate applied to each input array element to return the object value.").
+			WithReturnTypeBuilder(types.Object, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				if len(argumentTypes) > 2 {
+					return types.NewObjectType("object", nil, getLambdaReturnType(argumentTypes[2]))
+				}
+				return types.NewObjectType("object", nil, getItemType(argumentTypes[0]))
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("toUpper").
+			WithDescription("Converts the specified string to upper case.").
+			WithRequiredParameter("stringToChange", types.String, "The value to convert to upper case.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("trim").
+			WithDescription("Removes all leading and trailing white-space characters from the specified string.").
+			WithRequiredParameter("stringToTrim", types.String, "The value to trim.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("union").
+			WithDescription("Returns a single object with all elements from the parameters. Duplicate keys are only included once.").
+			WithVariableParameter("object", types.Object, 1, "The first object to use for joining elements.").
+			WithReturnType(types.Object).
+			Build(),
+		NewFunctionOverloadBuilder("union").
+			WithDescription("Returns a single array with all elements from the parameters. Duplicate values are only included once.").
+			WithVariableParameter("array", types.Array, 1, "The first array to use for joining elements.").
+			WithReturnTypeBuilder(types.Array, func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+				var items []types.TypeSymbol
+				for _, argumentType := range argumentTypes {
+					items = append(items, getItemType(argumentType))
+				}
+				return types.NewArrayType(types.CreateUnion(items...))
+			}).
+			Build(),
+		NewFunctionOverloadBuilder("uniqueString").
+			WithDescription("Creates a deterministic hash string based on the values provided as parameters. The returned value is 13 characters long.").
+			WithVariableParameter("string", types.String, 1, "The value used in the hash function to create a unique string.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("uri").
+			WithDescription("Creates an absolute URI by combining the baseUri and the relativeUri string.").
+			WithRequiredParameter("baseUri", types.String, "The base uri string.").
+			WithRequiredParameter("relativeUri", types.String, "The relative uri string to add to the base uri string.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("uriComponent").
+			WithDescription("Encodes a URI.").
+			WithRequiredParameter("stringToEncode", types.String, "The value to encode.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("uriComponentToString").
+			WithDescription("Returns a string of a URI encoded value.").
+			WithRequiredParameter("uriEncodedString", types.String, "The URI encoded value to convert to a string.").
+			WithReturnType(types.String).
+			Build(),
+		NewFunctionOverloadBuilder("utcNow").
+			WithDescription("Returns the current (UTC) datetime value in the specified format. If no format is provided, the ISO 8601 (yyyyMMddTHHmmssZ) format is used. This function can only be used in the default value for a parameter.").
+			WithOptionalParameter("format", types.String, "The format. Use either standard format strings or custom format strings.").
+			WithReturnType(types.String).
+			WithFlags(FunctionFlagsParamDefaultsOnly).
+			Build(),
+	}
+}
+
+// getItemType returns the type of the items of an array type, or any for other types and empty tuples.
+func getItemType(typeSymbol types.TypeSymbol) types.TypeSymbol {
+	switch typeSymbol := typeSymbol.(type) {
+	case *types.ArrayType:
+		return typeSymbol.Item
+	case *types.TupleType:
+		if len(typeSymbol.Items) > 0 {
+			return typeSymbol.Item()
+		}
+	}
+	return types.Any
+}
+
+// getPropertyValueType returns the union of the types of the property values of an object.
+func getPropertyValueType(typeSymbol types.TypeSymbol) types.TypeSymbol {
+	objectType, ok := typeSymbol.(*types.ObjectType)
+	if !ok {
+		return types.Any
+	}
+	var valueTypes []types.TypeSymbol
+	for _, property := range objectType.Properties {
+		valueTypes = append(valueTypes, property.Type)
+	}
+	if objectType.AdditionalPropertiesType != nil {
+		valueTypes = append(valueTypes, objectType.AdditionalPropertiesType)
+	}
+	if len(valueTypes) == 0 {
+		return types.Any
+	}
+	return types.CreateUnion(valueTypes...)
+}
+
+// itemLambda returns the type of lambdas applied to the items of an array, taking count items and,
+// if withIndex is set, the optional index of the first one, e.g. (string, [int]) => bool.
+func itemLambda(itemType types.TypeSymbol, count int, withIndex bool, returnType types.TypeSymbol) *types.LambdaType {
+	lambdaType := &types.LambdaType{ReturnType: returnType}
+	for i := 0; i < count; i++ {
+		lambdaType.ArgumentTypes = append(lambdaType.ArgumentTypes, itemType)
+	}
+	if withIndex {
+		lambdaType.OptionalArgumentTypes = []types.TypeSymbol{types.Int}
+	}
+	return lambdaType
+}
+
+// calculateItemLambda types the arguments of an itemLambda after the items of the array passed first.
+func calculateItemLambda(count int, withIndex bool, returnType types.TypeSymbol) ParameterTypeCalculator {
+	return func(argumentTypes []types.TypeSymbol) types.TypeSymbol {
+		return itemLambda(getItemType(argumentTypes[0]), count, withIndex, returnType)
+	}
+}
+
+// reduceLambda returns the type of the predicate of reduce(), which takes the accumulated value, the next item
+// and optionally its index.
+func reduceLambda(itemType types.TypeSymbol) *types.LambdaType {
+	return &types.LambdaType{
+		ArgumentTypes:         []types.TypeSymbol{types.Any, itemType},
+		OptionalArgumentTypes: []types.TypeSymbol{types.Int},
+		ReturnType:            types.Any,
+	}
+}
+
+// getLambdaReturnType returns the type of the body of a lambda argument.
+func getLambdaReturnType(typeSymbol types.TypeSymbol) types.TypeSymbol {
+	if lambdaType, ok := typeSymbol.(*types.LambdaType); ok {
+		return lambdaType.ReturnType
+	}
+	return types.Any
+}
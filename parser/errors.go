@@ -0,0 +1,158 @@
+package parser
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/util"
+	"fmt"
+)
+
+// parseError is raised with panic to unwind to the closest recovery point.
+type parseError struct {
+	diagnostic *diagnostics.Diagnostic
+}
+
+func (p *Parser) newError(diagnostic *diagnostics.Diagnostic) *parseError {
+	diagnostic.Span = p.peek().Span
+	return &parseError{diagnostic: diagnostic}
+}
+
+func (p *Parser) newErrorAt(span *util.TextSpan, diagnostic *diagnostics.Diagnostic) *parseError {
+	diagnostic.Span = span
+	return &parseError{diagnostic: diagnostic}
+}
+
+// addDiagnostic records a diagnostic unless one was already reported at the same position,
+// which happens when several parts of a broken statement fail on the same token.
+func (p *Parser) addDiagnostic(diagnostic *diagnostics.Diagnostic) {
+	// diagnostics own their span so that shifting token spans never moves them twice
+	diagnostic.Span = util.NewTextSpan(diagnostic.Span.Position, diagnostic.Span.Length)
+
+	if len(p.diagnostics) > 0 {
+		last := p.diagnostics[len(p.diagnostics)-1]
+		if last.Span.Position == diagnostic.Span.Position {
+			return
+		}
+	}
+	p.diagnostics = append(p.diagnostics, diagnostic)
+}
+
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Diagnostics/DiagnosticBuilder.cs
+func unrecognizedDeclaration() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP007", "This declaration type is not recognized. Specify a metadata, parameter, variable, resource, or output declaration.")
+}
+
+func expectedLiteral() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP009", "Expected a literal value, an array, an object, a parenthesized expression, or a function call at this location.")
+}
+
+func expectedKeyword(keyword string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP012", fmt.Sprintf("Expected the \"%s\" keyword at this location.", keyword))
+}
+
+func expectedParameterIdentifier() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP013", "Expected a parameter identifier at this location.")
+}
+
+func expectedVariableIdentifier() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP015", "Expected a variable identifier at this location.")
+}
+
+func expectedOutputIdentifier() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP016", "Expected an output identifier at this location.")
+}
+
+func expectedResourceIdentifier() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP017", "Expected a resource identifier at this location.")
+}
+
+func expectedCharacter(character string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP018", fmt.Sprintf("Expected the \"%s\" character at this location.", character))
+}
+
+func expectedNewLine() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP019", "Expected a new line character at this location.")
+}
+
+func expectedFunctionOrPropertyName() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP020", "Expected a function or property name at this location.")
+}
+
+func expectedPropertyName() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP022", "Expected a property name at this location.")
+}
+
+func expectedResourceTypeString() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP068", "Expected a resource type string. Specify a valid resource type of format \"<types>@<apiVersion>\".")
+}
+
+func expectedModuleIdentifier() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP090", "Expected a module identifier at this location.")
+}
+
+func expectedModulePathString() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP097", "Expected a module path string. This should be a relative path to another bicep file, e.g. 'myModule.bicep' or '../parent/myModule.bicep'")
+}
+
+func invalidString(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP004", "The string at this location is not terminated or contains an unrecognized escape sequence.")
+}
+
+func expectedDecoratorCall() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP123", "Expected a namespace or decorator name at this location.")
+}
+
+func expectedDeclarationAfterDecorator(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP132", "Expected a declaration after the decorator.")
+}
+
+func expectedLoopVariable() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP162", "Expected a loop item variable identifier or \"(\" at this location.")
+}
+
+func expectedResourceBody() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP167", "Expected the \"{\" character, the \"[\" character, or the \"if\" keyword at this location.")
+}
+
+func expectedNestedResourceAfterDecorator() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP153", "Expected a resource or module declaration after the decorator.")
+}
+
+func expectedMetadataIdentifier() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP242", "Expected a metadata identifier at this location.")
+}
+
+func invalidLoopVariableBlock(span *util.TextSpan, count int) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP249", fmt.Sprintf("Expected loop variable block to consist of exactly 2 elements (item variable and index variable), but found %d.", count))
+}
+
+func expectedItemSeparator() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP236", "Expected a new line or comma character at this location.")
+}
+
+func expectedType() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP279", "Expected a type at this location. Please specify a valid type expression or one of the following types: \"array\", \"bool\", \"int\", \"object\", \"string\".")
+}
+
+func expectedFunctionIdentifier() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP343", "Expected a function identifier at this location.")
+}
+
+func expectedTypeIdentifier() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP280", "Expected a type identifier at this location.")
+}
+
+func expectedSymbolListOrWildcard() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP354", "Expected left brace ('{') or asterisk ('*') character at this location.")
+}
+
+func expectedExportedSymbolName() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP355", "Expected the name of an exported symbol at this location.")
+}
+
+func expectedNamespaceIdentifier() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP356", "Expected a valid namespace identifier at this location.")
+}
+
+func pathMissing() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP358", "This declaration is missing a template file path reference.")
+}
@@ -0,0 +1,229 @@
+package parser
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/lexer"
+	"bicep-go/syntax"
+	"bicep-go/token"
+	"bicep-go/util"
+)
+
+// TextEdit replaces the text covered by Span with NewText.
+type TextEdit struct {
+	Span    *util.TextSpan
+	NewText string
+}
+
+func NewTextEdit(position int, length int, newText string) *TextEdit {
+	return &TextEdit{
+		Span:    util.NewTextSpan(position, length),
+		NewText: newText,
+	}
+}
+
+// IncrementalParser keeps the syntax tree of a document up to date as the document is edited.
+//
+// Top-level statements outside of the damaged region are reused instead of being parsed again,
+// and only the tokens between the last new line before the edit and the first point where the
+// new token stream lines up with the old one are lexed again. Statements after the edit are
+// shifted in place, so spans read from a previous tree are only valid until the next edit.
+type IncrementalParser struct {
+	text        string
+	program     *syntax.ProgramSyntax
+	diagnostics []*diagnostics.Diagnostic
+}
+
+func NewIncrementalParser(text string) *IncrementalParser {
+	p := New(text)
+	program := p.Program()
+
+	return &IncrementalParser{
+		text:        text,
+		program:     program,
+		diagnostics: p.GetDiagnostics(),
+	}
+}
+
+func (ip *IncrementalParser) GetText() string {
+	return ip.text
+}
+
+func (ip *IncrementalParser) GetProgram() *syntax.ProgramSyntax {
+	return ip.program
+}
+
+func (ip *IncrementalParser) GetDiagnostics() []*diagnostics.Diagnostic {
+	return ip.diagnostics
+}
+
+// statementBoundary is a top-level new line token, after which the lexer and parser are in a clean state.
+type statementBoundary struct {
+	childIndex int
+	fullEnd    int
+}
+
+func (ip *IncrementalParser) ApplyEdit(edit *TextEdit) *syntax.ProgramSyntax {
+	editStart := edit.Span.Position
+	editEnd := edit.Span.Position + edit.Span.Length
+	newText := ip.text[:editStart] + edit.NewText + ip.text[editEnd:]
+	delta := len(edit.NewText) - edit.Span.Length
+
+	children := ip.program.Children
+	var boundaries []statementBoundary
+	for i, child := range children {
+		if tok, ok := child.(*token.Token); ok && tok.Type == token.TokenTypeNewLine {
+			boundaries = append(boundaries, statementBoundary{childIndex: i, fullEnd: tok.GetFullEnd()})
+		}
+	}
+
+	// find the last boundary that the edit cannot have changed
+	first := -1
+	for i, boundary := range boundaries {
+		if boundary.fullEnd >= editStart {
+			break
+		}
+		first = i
+	}
+
+	// a union type may continue on the next line, so never start the region on a `|`
+	for first >= 0 && startsWithPipe(newText, boundaries[first].fullEnd) {
+		first--
+	}
+
+	prefix, regionStart := 0, 0
+	if first >= 0 {
+		prefix, regionStart = boundaries[first].childIndex+1, boundaries[first].fullEnd
+	}
+
+	oldBoundaries := map[int]int{}
+	for _, boundary := range boundaries[first+1:] {
+		oldBoundaries[boundary.fullEnd] = boundary.childIndex
+	}
+
+	l := lexer.NewAt(newText, regionStart)
+	for {
+		l.LexToken()
+		tokens := l.GetTokens()
+		last := tokens[len(tokens)-1]
+
+		if last.Type == token.TokenTypeEndOfFile {
+			p := NewFromTokens(tokens)
+			region := p.Program()
+
+			newChildren := append(append([]syntax.SyntaxBase{}, children[:prefix]...), region.Children...)
+			ip.program = syntax.NewProgramSyntax(newChildren, region.EndOfFile)
+			ip.diagnostics = append(ip.diagnosticsBefore(regionStart), p.GetDiagnostics()...)
+			break
+		}
+
+		if last.Type != token.TokenTypeNewLine {
+			continue
+		}
+
+		oldEnd := l.GetPosition() - delta
+		childIndex, ok := oldBoundaries[oldEnd]
+		if !ok || oldEnd < editEnd || ip.continuesWithPipe(childIndex) {
+			continue
+		}
+
+		endOfRegion := token.NewToken(token.TokenTypeEndOfFile, "", util.NewTextSpan(l.GetPosition(), 0), nil, nil)
+		p := NewFromTokens(append(append([]*token.Token{}, tokens...), endOfRegion))
+		region := p.Program()
+
+		// the region is only reusable if its last statement ended on this new line
+		if region.Children[len(region.Children)-1] != last {
+			continue
+		}
+
+		newChildren := append(append([]syntax.SyntaxBase{}, children[:prefix]...), region.Children...)
+		for _, child := range children[childIndex+1:] {
+			shiftSyntax(child, delta)
+			newChildren = append(newChildren, child)
+		}
+		shiftSyntax(ip.program.EndOfFile, delta)
+
+		newDiagnostics := append(ip.diagnosticsBefore(regionStart), p.GetDiagnostics()...)
+		for _, diagnostic := range ip.diagnostics {
+			if diagnostic.Span.Position >= oldEnd {
+				diagnostic.Span.Position += delta
+				newDiagnostics = append(newDiagnostics, diagnostic)
+			}
+		}
+
+		ip.program = syntax.NewProgramSyntax(newChildren, ip.program.EndOfFile)
+		ip.diagnostics = newDiagnostics
+		break
+	}
+
+	ip.text = newText
+	return ip.program
+}
+
+func (ip *IncrementalParser) diagnosticsBefore(position int) []*diagnostics.Diagnostic {
+	var result []*diagnostics.Diagnostic
+	for _, diagnostic := range ip.diagnostics {
+		if diagnostic.Span.Position < position {
+			result = append(result, diagnostic)
+		}
+	}
+	return result
+}
+
+// continuesWithPipe reports whether the first statement after the given child starts with a `|`,
+// in which case a union type before it would have consumed it.
+func (ip *IncrementalParser) continuesWithPipe(childIndex int) bool {
+	for _, child := range ip.program.Children[childIndex+1:] {
+		if tok, ok := child.(*token.Token); ok && tok.Type == token.TokenTypeNewLine {
+			continue
+		}
+		first := firstToken(child)
+		return first != nil && first.Type == token.TokenTypePipe
+	}
+	return false
+}
+
+func startsWithPipe(text string, position int) bool {
+	l := lexer.NewAt(text, position)
+	for {
+		l.LexToken()
+		tokens := l.GetTokens()
+		switch tokens[len(tokens)-1].Type {
+		case token.TokenTypeNewLine:
+			continue
+		case token.TokenTypePipe:
+			return true
+		default:
+			return false
+		}
+	}
+}
+
+func firstToken(node syntax.SyntaxBase) *token.Token {
+	var first *token.Token
+	syntax.Inspect(node, func(n syntax.SyntaxBase) bool {
+		if first != nil {
+			return false
+		}
+		if tok, ok := n.(*token.Token); ok {
+			first = tok
+		}
+		return first == nil
+	})
+	return first
+}
+
+func shiftSyntax(node syntax.SyntaxBase, delta int) {
+	if delta == 0 {
+		return
+	}
+
+	syntax.Inspect(node, func(n syntax.SyntaxBase) bool {
+		switch n := n.(type) {
+		case *token.Token:
+			n.Shift(delta)
+		case *syntax.SkippedTriviaSyntax:
+			n.Span.Position += delta
+		}
+		return true
+	})
+}
@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"bicep-go/syntax"
+	"bicep-go/token"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const incrementalSample = `targetScope = 'resourceGroup'
+
+@description('The location')
+param location string = resourceGroup().location
+param names string[] = [
+  'a'
+  'b'
+]
+
+type size = 'small'
+  | 'large'
+
+var prefix = '${location}-app'
+var items = [for (name, i) in names: {
+  name: '${prefix}-${name}'
+  index: i
+}]
+
+resource stg 'Microsoft.Storage/storageAccounts@2023-01-01' = [for item in items: if (item.index > 0) {
+  name: item.name
+  location: location
+}]
+
+/* trailing
+   comment */
+output count int = length(items) // done
+`
+
+var incrementalSnippets = []string{
+	"\n", "\n\n", " ", "{", "}", "[", "]", "(", ")", "'", "${", "'''", "|", "@", ",", ":",
+	"param x string", "var y = ", " 'small'", "@description('d')\n", "[for x in xs: x]",
+	"if (true) ", "/* c */", "// c\n", "resource r 't@v' = {\n}", "| 'medium'", "!", "=>",
+}
+
+func dumpSyntax(program *syntax.ProgramSyntax) string {
+	var builder strings.Builder
+	syntax.Inspect(program, func(node syntax.SyntaxBase) bool {
+		if node == nil {
+			builder.WriteString(")")
+			return false
+		}
+		span := node.GetSpan()
+		fmt.Fprintf(&builder, "(%T%s", node, span.ToString())
+		if tok, ok := node.(*token.Token); ok {
+			fmt.Fprintf(&builder, "%q", tok.Literal)
+			for _, trivia := range append(tok.GetLeadingTrivia(), tok.GetTrailingTrivia()...) {
+				fmt.Fprintf(&builder, "<%s%q>", trivia.Span.ToString(), trivia.Text)
+			}
+		}
+		return true
+	})
+	return builder.String()
+}
+
+func requireSameAsFullParse(t *testing.T, ip *IncrementalParser, message string) {
+	p := New(ip.GetText())
+	expected := p.Program()
+	require.Equal(t, dumpSyntax(expected), dumpSyntax(ip.GetProgram()), message)
+
+	var expectedDiagnostics, actualDiagnostics []string
+	for _, diagnostic := range p.GetDiagnostics() {
+		expectedDiagnostics = append(expectedDiagnostics, diagnostic.ToString())
+	}
+	for _, diagnostic := range ip.GetDiagnostics() {
+		actualDiagnostics = append(actualDiagnostics, diagnostic.ToString())
+	}
+	require.Equal(t, expectedDiagnostics, actualDiagnostics, message)
+}
+
+func TestIncrementalParserMatchesFullParse(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		random := rand.New(rand.NewSource(seed))
+		ip := NewIncrementalParser(incrementalSample)
+
+		for i := 0; i < 100; i++ {
+			text := ip.GetText()
+			position := random.Intn(len(text) + 1)
+
+			length := 0
+			if random.Intn(3) == 0 {
+				length = random.Intn(min(10, len(text)-position) + 1)
+			}
+
+			newText := ""
+			if random.Intn(4) != 0 {
+				newText = incrementalSnippets[random.Intn(len(incrementalSnippets))]
+			}
+
+			edit := NewTextEdit(position, length, newText)
+			ip.ApplyEdit(edit)
+			requireSameAsFullParse(t, ip, fmt.Sprintf("seed %d, edit %d: replace [%d:%d] with %q", seed, i, position, position+length, newText))
+		}
+	}
+}
+
+func TestIncrementalParserReusesUnchangedStatements(t *testing.T) {
+	ip := NewIncrementalParser(incrementalSample)
+	before := ip.GetProgram().Declarations()
+
+	position := strings.Index(incrementalSample, "'${location}-app'")
+	ip.ApplyEdit(NewTextEdit(position, 0, "'prefix-' + "))
+	requireSameAsFullParse(t, ip, "prefix edit")
+
+	after := ip.GetProgram().Declarations()
+	require.Len(t, after, len(before))
+	for i := range before {
+		if i == 4 {
+			require.NotSame(t, before[i], after[i])
+			continue
+		}
+		require.Same(t, before[i], after[i], "declaration %d", i)
+	}
+}
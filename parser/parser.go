@@ -0,0 +1,922 @@
+package parser
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/lexer"
+	"bicep-go/syntax"
+	"bicep-go/token"
+	"bicep-go/util"
+	"strconv"
+)
+
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Parsing/Parser.cs
+type Parser struct {
+	tokens      []*token.Token
+	position    int
+	diagnostics []*diagnostics.Diagnostic
+}
+
+func New(input string) *Parser {
+	l := lexer.New(input)
+	l.Lex()
+	return NewFromTokens(l.GetTokens())
+}
+
+// NewFromTokens creates a parser over already lexed tokens. The last token must be the end of file.
+func NewFromTokens(tokens []*token.Token) *Parser {
+	return &Parser{
+		tokens:      tokens,
+		position:    0,
+		diagnostics: []*diagnostics.Diagnostic{},
+	}
+}
+
+func (p *Parser) GetDiagnostics() []*diagnostics.Diagnostic {
+	return p.diagnostics
+}
+
+func (p *Parser) Program() *syntax.ProgramSyntax {
+	var children []syntax.SyntaxBase
+
+	for !p.isAtEnd() {
+		if p.check(token.TokenTypeNewLine) {
+			children = append(children, p.read())
+			continue
+		}
+
+		children = append(children, p.withRecovery(p.declaration, token.TokenTypeNewLine))
+
+		if !p.isAtEnd() && !p.check(token.TokenTypeNewLine) {
+			children = append(children, p.withRecovery(func() syntax.SyntaxBase {
+				panic(p.newError(expectedNewLine()))
+			}, token.TokenTypeNewLine))
+		}
+	}
+
+	return syntax.NewProgramSyntax(children, p.read())
+}
+
+func (p *Parser) declaration() syntax.SyntaxBase {
+	leadingNodes := p.decorators()
+
+	current := p.peek()
+	if current.Type == token.TokenTypeIdentifier {
+		switch current.Literal {
+		case syntax.KEYWORD_TARGET_SCOPE:
+			return p.targetScope(leadingNodes)
+		case syntax.KEYWORD_METADATA:
+			return p.metadataDeclaration(leadingNodes)
+		case syntax.KEYWORD_PARAM:
+			return p.parameterDeclaration(leadingNodes)
+		case syntax.KEYWORD_VAR:
+			return p.variableDeclaration(leadingNodes)
+		case syntax.KEYWORD_RESOURCE:
+			return p.resourceDeclaration(leadingNodes)
+		case syntax.KEYWORD_MODULE:
+			return p.moduleDeclaration(leadingNodes)
+		case syntax.KEYWORD_OUTPUT:
+			return p.outputDeclaration(leadingNodes)
+		case syntax.KEYWORD_TYPE:
+			return p.typeDeclaration(leadingNodes)
+		case syntax.KEYWORD_FUNC:
+			return p.functionDeclaration(leadingNodes)
+		case syntax.KEYWORD_IMPORT:
+			return p.compileTimeImportDeclaration(leadingNodes)
+		}
+	}
+
+	if len(leadingNodes) > 0 && (current.Type == token.TokenTypeEndOfFile || current.Type == token.TokenTypeNewLine) {
+		p.addDiagnostic(expectedDeclarationAfterDecorator(current.Span))
+		return syntax.NewMissingDeclarationSyntax(leadingNodes)
+	}
+
+	panic(p.newError(unrecognizedDeclaration()))
+}
+
+func (p *Parser) decorators() []syntax.SyntaxBase {
+	var leadingNodes []syntax.SyntaxBase
+
+	for p.check(token.TokenTypeAt) {
+		at := p.read()
+		expression := p.withRecovery(p.decoratorExpression, token.TokenTypeNewLine)
+		leadingNodes = append(leadingNodes, syntax.NewDecoratorSyntax(at, expression))
+
+		for p.check(token.TokenTypeNewLine) {
+			leadingNodes = append(leadingNodes, p.read())
+		}
+	}
+
+	return leadingNodes
+}
+
+func (p *Parser) decoratorExpression() syntax.SyntaxBase {
+	expression := p.memberExpression()
+	switch expression.(type) {
+	case *syntax.FunctionCallSyntax, *syntax.InstanceFunctionCallSyntax:
+		return expression
+	}
+	panic(p.newErrorAt(expression.GetSpan(), expectedDecoratorCall()))
+}
+
+func (p *Parser) targetScope(leadingNodes []syntax.SyntaxBase) syntax.SyntaxBase {
+	keyword := p.expectKeyword(syntax.KEYWORD_TARGET_SCOPE)
+	assignment := p.expectWithRecovery(token.TokenTypeAssignment, token.TokenTypeNewLine)
+	value := p.withRecovery(p.expression, token.TokenTypeNewLine)
+
+	return syntax.NewTargetScopeSyntax(leadingNodes, keyword, assignment, value)
+}
+
+func (p *Parser) metadataDeclaration(leadingNodes []syntax.SyntaxBase) syntax.SyntaxBase {
+	keyword := p.expectKeyword(syntax.KEYWORD_METADATA)
+	name := p.identifierWithRecovery(expectedMetadataIdentifier, token.TokenTypeAssignment, token.TokenTypeNewLine)
+	assignment := p.expectWithRecovery(token.TokenTypeAssignment, token.TokenTypeNewLine)
+	value := p.withRecovery(p.expression, token.TokenTypeNewLine)
+
+	return syntax.NewMetadataDeclarationSyntax(leadingNodes, keyword, name, assignment, value)
+}
+
+func (p *Parser) parameterDeclaration(leadingNodes []syntax.SyntaxBase) syntax.SyntaxBase {
+	keyword := p.expectKeyword(syntax.KEYWORD_PARAM)
+	name := p.identifierWithRecovery(expectedParameterIdentifier, token.TokenTypeIdentifier, token.TokenTypeNewLine)
+	typeSyntax := p.withRecovery(p.typeExpression, token.TokenTypeAssignment, token.TokenTypeNewLine)
+
+	var modifier syntax.SyntaxBase
+	if p.check(token.TokenTypeAssignment) {
+		assignment := p.read()
+		defaultValue := p.withRecovery(p.expression, token.TokenTypeNewLine)
+		modifier = syntax.NewParameterDefaultValueSyntax(assignment, defaultValue)
+	}
+
+	return syntax.NewParameterDeclarationSyntax(leadingNodes, keyword, name, typeSyntax, modifier)
+}
+
+func (p *Parser) variableDeclaration(leadingNodes []syntax.SyntaxBase) syntax.SyntaxBase {
+	keyword := p.expectKeyword(syntax.KEYWORD_VAR)
+	name := p.identifierWithRecovery(expectedVariableIdentifier, token.TokenTypeAssignment, token.TokenTypeNewLine)
+	assignment := p.expectWithRecovery(token.TokenTypeAssignment, token.TokenTypeNewLine)
+	value := p.withRecovery(p.expression, token.TokenTypeNewLine)
+
+	return syntax.NewVariableDeclarationSyntax(leadingNodes, keyword, name, assignment, value)
+}
+
+func (p *Parser) resourceDeclaration(leadingNodes []syntax.SyntaxBase) syntax.SyntaxBase {
+	keyword := p.expectKeyword(syntax.KEYWORD_RESOURCE)
+	name := p.identifierWithRecovery(expectedResourceIdentifier, token.TokenTypeStringComplete, token.TokenTypeNewLine)
+	typeSyntax := p.withRecovery(func() syntax.SyntaxBase {
+		return p.interpolableString(expectedResourceTypeString)
+	}, token.TokenTypeAssignment, token.TokenTypeNewLine)
+
+	var existingKeyword *token.Token
+	if p.checkKeyword(syntax.KEYWORD_EXISTING) {
+		existingKeyword = p.read()
+	}
+
+	assignment := p.expectWithRecovery(token.TokenTypeAssignment, token.TokenTypeNewLine)
+	value := p.withRecovery(p.resourceBody, token.TokenTypeNewLine)
+
+	return syntax.NewResourceDeclarationSyntax(leadingNodes, keyword, name, typeSyntax, existingKeyword, assignment, value)
+}
+
+func (p *Parser) moduleDeclaration(leadingNodes []syntax.SyntaxBase) syntax.SyntaxBase {
+	keyword := p.expectKeyword(syntax.KEYWORD_MODULE)
+	name := p.identifierWithRecovery(expectedModuleIdentifier, token.TokenTypeStringComplete, token.TokenTypeNewLine)
+	path := p.withRecovery(func() syntax.SyntaxBase {
+		return p.interpolableString(expectedModulePathString)
+	}, token.TokenTypeAssignment, token.TokenTypeNewLine)
+	assignment := p.expectWithRecovery(token.TokenTypeAssignment, token.TokenTypeNewLine)
+	value := p.withRecovery(p.resourceBody, token.TokenTypeNewLine)
+
+	return syntax.NewModuleDeclarationSyntax(leadingNodes, keyword, name, path, assignment, value)
+}
+
+// resourceBody parses the value of a resource or module: an object, a condition or a loop.
+func (p *Parser) resourceBody() syntax.SyntaxBase {
+	switch {
+	case p.checkKeyword(syntax.KEYWORD_IF):
+		return p.ifCondition()
+	case p.check(token.TokenTypeLeftSquare):
+		return p.forExpression(true)
+	case p.check(token.TokenTypeLeftBrace):
+		return p.object()
+	}
+	panic(p.newError(expectedResourceBody()))
+}
+
+func (p *Parser) outputDeclaration(leadingNodes []syntax.SyntaxBase) syntax.SyntaxBase {
+	keyword := p.expectKeyword(syntax.KEYWORD_OUTPUT)
+	name := p.identifierWithRecovery(expectedOutputIdentifier, token.TokenTypeIdentifier, token.TokenTypeNewLine)
+	typeSyntax := p.withRecovery(p.typeExpression, token.TokenTypeAssignment, token.TokenTypeNewLine)
+	assignment := p.expectWithRecovery(token.TokenTypeAssignment, token.TokenTypeNewLine)
+	value := p.withRecovery(p.expression, token.TokenTypeNewLine)
+
+	return syntax.NewOutputDeclarationSyntax(leadingNodes, keyword, name, typeSyntax, assignment, value)
+}
+
+func (p *Parser) typeDeclaration(leadingNodes []syntax.SyntaxBase) syntax.SyntaxBase {
+	keyword := p.expectKeyword(syntax.KEYWORD_TYPE)
+	name := p.identifierWithRecovery(expectedTypeIdentifier, token.TokenTypeAssignment, token.TokenTypeNewLine)
+	assignment := p.expectWithRecovery(token.TokenTypeAssignment, token.TokenTypeNewLine)
+	value := p.withRecovery(p.typeExpression, token.TokenTypeNewLine)
+
+	return syntax.NewTypeDeclarationSyntax(leadingNodes, keyword, name, assignment, value)
+}
+
+func (p *Parser) functionDeclaration(leadingNodes []syntax.SyntaxBase) syntax.SyntaxBase {
+	keyword := p.expectKeyword(syntax.KEYWORD_FUNC)
+	name := p.identifierWithRecovery(expectedFunctionIdentifier, token.TokenTypeLeftParen, token.TokenTypeNewLine)
+	lambda := p.withRecovery(p.typedLambda, token.TokenTypeNewLine)
+
+	return syntax.NewFunctionDeclarationSyntax(leadingNodes, keyword, name, lambda)
+}
+
+func (p *Parser) compileTimeImportDeclaration(leadingNodes []syntax.SyntaxBase) syntax.SyntaxBase {
+	keyword := p.expectKeyword(syntax.KEYWORD_IMPORT)
+	importExpression := p.withRecovery(p.importExpression, token.TokenTypeIdentifier, token.TokenTypeNewLine)
+	fromClause := p.withRecovery(p.compileTimeImportFromClause, token.TokenTypeNewLine)
+
+	return syntax.NewCompileTimeImportDeclarationSyntax(leadingNodes, keyword, importExpression, fromClause)
+}
+
+// importExpression parses the symbols of an import: a list such as {a, b as c} or a wildcard such as * as lib.
+func (p *Parser) importExpression() syntax.SyntaxBase {
+	switch {
+	case p.check(token.TokenTypeLeftBrace):
+		return p.importedSymbolsList()
+	case p.check(token.TokenTypeAsterisk):
+		wildcard := p.read()
+		asClause := p.withRecovery(p.aliasAsClause, token.TokenTypeIdentifier, token.TokenTypeNewLine)
+		return syntax.NewWildcardImportSyntax(wildcard, asClause)
+	}
+	panic(p.newError(expectedSymbolListOrWildcard()))
+}
+
+// importedSymbolsList parses a list of imported symbols separated by commas or new lines.
+func (p *Parser) importedSymbolsList() syntax.SyntaxBase {
+	openBrace := p.expect(token.TokenTypeLeftBrace)
+
+	var items []*syntax.ImportedSymbolsListItemSyntax
+	p.skipNewLines()
+	for {
+		if !p.check(token.TokenTypeIdentifier) {
+			break
+		}
+
+		name := syntax.NewIdentifierSyntax(p.read())
+		var asClause *syntax.AliasAsClauseSyntax
+		if p.check(token.TokenTypeAsKeyword) {
+			asClause = syntax.NewAliasAsClauseSyntax(p.read(), p.withRecovery(func() syntax.SyntaxBase {
+				return p.identifier(expectedExportedSymbolName)
+			}, token.TokenTypeComma, token.TokenTypeRightBrace, token.TokenTypeNewLine))
+		}
+		items = append(items, syntax.NewImportedSymbolsListItemSyntax(name, asClause))
+
+		if !p.check(token.TokenTypeComma, token.TokenTypeNewLine) {
+			break
+		}
+		p.skipItemSeparators()
+	}
+
+	closeBrace := p.expectWithRecovery(token.TokenTypeRightBrace, token.TokenTypeIdentifier, token.TokenTypeNewLine)
+	return syntax.NewImportedSymbolsListSyntax(openBrace, items, closeBrace)
+}
+
+func (p *Parser) aliasAsClause() syntax.SyntaxBase {
+	keyword := p.expect(token.TokenTypeAsKeyword)
+	alias := p.withRecovery(func() syntax.SyntaxBase {
+		return p.identifier(expectedNamespaceIdentifier)
+	}, token.TokenTypeIdentifier, token.TokenTypeNewLine)
+	return syntax.NewAliasAsClauseSyntax(keyword, alias)
+}
+
+func (p *Parser) compileTimeImportFromClause() syntax.SyntaxBase {
+	keyword := p.expectKeyword(syntax.KEYWORD_FROM)
+	path := p.withRecovery(func() syntax.SyntaxBase {
+		return p.interpolableString(pathMissing)
+	}, token.TokenTypeNewLine)
+	return syntax.NewCompileTimeImportFromClauseSyntax(keyword, path)
+}
+
+// typedLambda parses the signature and body of a user-defined function, e.g. (a string, b int) string => a.
+func (p *Parser) typedLambda() syntax.SyntaxBase {
+	variableSection := p.typedVariableBlock()
+	returnType := p.withRecovery(p.typeExpression, token.TokenTypeArrow, token.TokenTypeNewLine)
+	arrow := p.expectWithRecovery(token.TokenTypeArrow, token.TokenTypeNewLine)
+	if _, ok := arrow.(*token.Token); ok {
+		p.skipNewLines()
+	}
+	body := p.withRecovery(p.expression, token.TokenTypeNewLine)
+
+	return syntax.NewTypedLambdaSyntax(variableSection, returnType, arrow, body)
+}
+
+func (p *Parser) typedVariableBlock() *syntax.TypedVariableBlockSyntax {
+	openParen := p.expect(token.TokenTypeLeftParen)
+
+	var arguments []*syntax.TypedLocalVariableSyntax
+	for {
+		p.skipNewLines()
+		if !p.check(token.TokenTypeIdentifier) {
+			break
+		}
+
+		name := p.identifier(expectedVariableIdentifier)
+		typeSyntax := p.withRecovery(p.typeExpression, token.TokenTypeComma, token.TokenTypeRightParen)
+		arguments = append(arguments, syntax.NewTypedLocalVariableSyntax(name, typeSyntax))
+
+		p.skipNewLines()
+		if !p.check(token.TokenTypeComma) {
+			break
+		}
+		p.read()
+	}
+
+	closeParen := p.expectWithRecovery(token.TokenTypeRightParen, token.TokenTypeNewLine)
+	return syntax.NewTypedVariableBlockSyntax(openParen, arguments, closeParen)
+}
+
+// expression parses a full expression, including lambdas and the ternary operator.
+func (p *Parser) expression() syntax.SyntaxBase {
+	if p.isLambdaStart() {
+		return p.lambda()
+	}
+
+	condition := p.binaryExpression(0)
+	if !p.check(token.TokenTypeQuestion) {
+		return condition
+	}
+
+	question := p.read()
+	trueExpression := p.expression()
+	colon := p.expect(token.TokenTypeColon)
+	falseExpression := p.expression()
+
+	return syntax.NewTernaryOperationSyntax(condition, question, trueExpression, colon, falseExpression)
+}
+
+func (p *Parser) binaryExpression(minPrecedence int) syntax.SyntaxBase {
+	left := p.unaryExpression()
+
+	for {
+		operator, precedence, ok := getBinaryOperator(p.peek().Type)
+		if !ok || precedence < minPrecedence {
+			return left
+		}
+
+		operatorToken := p.read()
+		right := p.binaryExpression(precedence + 1)
+		left = syntax.NewBinaryOperationSyntax(left, operatorToken, right, operator)
+	}
+}
+
+func (p *Parser) unaryExpression() syntax.SyntaxBase {
+	switch p.peek().Type {
+	case token.TokenTypeExclamation:
+		operatorToken := p.read()
+		return syntax.NewUnaryOperationSyntax(operatorToken, p.unaryExpression(), syntax.UnaryOperatorNot)
+	case token.TokenTypeMinus:
+		operatorToken := p.read()
+		return syntax.NewUnaryOperationSyntax(operatorToken, p.unaryExpression(), syntax.UnaryOperatorMinus)
+	}
+	return p.memberExpression()
+}
+
+func (p *Parser) memberExpression() syntax.SyntaxBase {
+	current := p.primaryExpression()
+
+	for {
+		switch p.peek().Type {
+		case token.TokenTypeLeftSquare:
+			openSquare := p.read()
+			var safeAccessMarker *token.Token
+			if p.check(token.TokenTypeQuestion) {
+				safeAccessMarker = p.read()
+			}
+			indexExpression := p.expression()
+			closeSquare := p.expect(token.TokenTypeRightSquare)
+			current = syntax.NewArrayAccessSyntax(current, openSquare, safeAccessMarker, indexExpression, closeSquare)
+		case token.TokenTypeDot:
+			dot := p.read()
+			var safeAccessMarker *token.Token
+			if p.check(token.TokenTypeQuestion) {
+				safeAccessMarker = p.read()
+			}
+			name := p.identifierOrKeyword(expectedFunctionOrPropertyName)
+			if safeAccessMarker == nil && p.check(token.TokenTypeLeftParen) {
+				openParen, arguments, closeParen := p.functionCallArguments()
+				current = syntax.NewInstanceFunctionCallSyntax(current, dot, name, openParen, arguments, closeParen)
+			} else {
+				current = syntax.NewPropertyAccessSyntax(current, dot, safeAccessMarker, name)
+			}
+		case token.TokenTypeDoubleColon:
+			doubleColon := p.read()
+			name := p.identifier(expectedResourceIdentifier)
+			current = syntax.NewResourceAccessSyntax(current, doubleColon, name)
+		case token.TokenTypeExclamation:
+			current = syntax.NewNonNullAssertionSyntax(current, p.read())
+		default:
+			return current
+		}
+	}
+}
+
+func (p *Parser) primaryExpression() syntax.SyntaxBase {
+	current := p.peek()
+
+	switch current.Type {
+	case token.TokenTypeInteger:
+		return p.integerLiteral()
+	case token.TokenTypeTrueKeyword:
+		return syntax.NewBooleanLiteralSyntax(p.read(), true)
+	case token.TokenTypeFalseKeyword:
+		return syntax.NewBooleanLiteralSyntax(p.read(), false)
+	case token.TokenTypeNullKeyword:
+		return syntax.NewNullLiteralSyntax(p.read())
+	case token.TokenTypeStringComplete, token.TokenTypeStringLeftPiece:
+		return p.interpolableString(expectedLiteral)
+	case token.TokenTypeMultilineString:
+		return p.multilineString()
+	case token.TokenTypeLeftSquare:
+		next := p.peekAt(1)
+		if next.Type == token.TokenTypeIdentifier && next.Literal == syntax.KEYWORD_FOR {
+			return p.forExpression(false)
+		}
+		return p.array()
+	case token.TokenTypeLeftBrace:
+		return p.object()
+	case token.TokenTypeLeftParen:
+		return p.parenthesizedExpression()
+	case token.TokenTypeIdentifier:
+		name := syntax.NewIdentifierSyntax(p.read())
+		if p.check(token.TokenTypeLeftParen) {
+			openParen, arguments, closeParen := p.functionCallArguments()
+			return syntax.NewFunctionCallSyntax(name, openParen, arguments, closeParen)
+		}
+		return syntax.NewVariableAccessSyntax(name)
+	}
+
+	panic(p.newError(expectedLiteral()))
+}
+
+func (p *Parser) integerLiteral() syntax.SyntaxBase {
+	literal := p.read()
+	// integers too large for uint64 are parsed as its maximum, and reported with the other literals outside the
+	// range of int64 when their types are assigned
+	value, _ := strconv.ParseUint(literal.Literal, 10, 64)
+	return syntax.NewIntegerLiteralSyntax(literal, value)
+}
+
+func (p *Parser) interpolableString(onMissing func() *diagnostics.Diagnostic) *syntax.StringSyntax {
+	if !p.check(token.TokenTypeStringComplete, token.TokenTypeStringLeftPiece) {
+		panic(p.newError(onMissing()))
+	}
+
+	tokens := []*token.Token{p.read()}
+	var expressions []syntax.SyntaxBase
+
+	if tokens[0].Type == token.TokenTypeStringLeftPiece {
+		for {
+			expressions = append(expressions, p.expression())
+
+			next := p.peek()
+			if next.Type == token.TokenTypeStringMiddlePiece {
+				tokens = append(tokens, p.read())
+				continue
+			}
+			if next.Type == token.TokenTypeStringRightPiece {
+				tokens = append(tokens, p.read())
+				break
+			}
+			panic(p.newError(expectedCharacter("}")))
+		}
+	}
+
+	segments, ok := lexer.TryGetRawStringSegments(tokens)
+	if !ok {
+		p.addDiagnostic(invalidString(tokens[0].Span.Between(tokens[len(tokens)-1].Span)))
+		segments = make([]string, len(tokens))
+	}
+
+	return syntax.NewStringSyntax(tokens, expressions, segments)
+}
+
+func (p *Parser) multilineString() syntax.SyntaxBase {
+	tok := p.read()
+	value, ok := lexer.TryGetMultilineStringValue(tok)
+	if !ok {
+		p.addDiagnostic(invalidString(tok.Span))
+	}
+	return syntax.NewStringSyntax([]*token.Token{tok}, nil, []string{value})
+}
+
+func (p *Parser) parenthesizedExpression() *syntax.ParenthesizedExpressionSyntax {
+	openParen := p.expect(token.TokenTypeLeftParen)
+	p.skipNewLines()
+	expression := p.withRecovery(p.expression, token.TokenTypeRightParen, token.TokenTypeNewLine)
+	p.skipNewLines()
+	closeParen := p.expectWithRecovery(token.TokenTypeRightParen, token.TokenTypeNewLine)
+
+	return syntax.NewParenthesizedExpressionSyntax(openParen, expression, closeParen)
+}
+
+func (p *Parser) functionCallArguments() (*token.Token, []*syntax.FunctionArgumentSyntax, syntax.SyntaxBase) {
+	openParen := p.expect(token.TokenTypeLeftParen)
+
+	var arguments []*syntax.FunctionArgumentSyntax
+	for {
+		p.skipNewLines()
+		if p.check(token.TokenTypeRightParen, token.TokenTypeEndOfFile) {
+			break
+		}
+
+		expression := p.withRecovery(p.expression, token.TokenTypeComma, token.TokenTypeRightParen)
+		arguments = append(arguments, syntax.NewFunctionArgumentSyntax(expression))
+
+		p.skipNewLines()
+		if !p.check(token.TokenTypeComma) {
+			break
+		}
+		p.read()
+	}
+
+	closeParen := p.expectWithRecovery(token.TokenTypeRightParen, token.TokenTypeNewLine)
+	return openParen, arguments, closeParen
+}
+
+func (p *Parser) array() syntax.SyntaxBase {
+	openBracket := p.expect(token.TokenTypeLeftSquare)
+
+	var items []*syntax.ArrayItemSyntax
+	for {
+		p.skipItemSeparators()
+		if p.check(token.TokenTypeRightSquare, token.TokenTypeEndOfFile) {
+			break
+		}
+
+		start := p.position
+		value := p.withRecovery(p.expression, token.TokenTypeComma, token.TokenTypeRightSquare)
+		items = append(items, syntax.NewArrayItemSyntax(value))
+		p.ensureProgress(start)
+
+		if !p.check(token.TokenTypeComma, token.TokenTypeNewLine, token.TokenTypeRightSquare) {
+			items = append(items, syntax.NewArrayItemSyntax(p.withRecovery(func() syntax.SyntaxBase {
+				panic(p.newError(expectedItemSeparator()))
+			}, token.TokenTypeComma, token.TokenTypeRightSquare)))
+		}
+	}
+
+	closeBracket := p.expectWithRecovery(token.TokenTypeRightSquare, token.TokenTypeNewLine)
+	return syntax.NewArraySyntax(openBracket, items, closeBracket)
+}
+
+func (p *Parser) object() syntax.SyntaxBase {
+	openBrace := p.expect(token.TokenTypeLeftBrace)
+
+	var children []syntax.SyntaxBase
+	for {
+		p.skipItemSeparators()
+		if p.check(token.TokenTypeRightBrace, token.TokenTypeEndOfFile) {
+			break
+		}
+
+		start := p.position
+		children = append(children, p.withRecovery(p.objectChild, token.TokenTypeComma, token.TokenTypeRightBrace))
+		p.ensureProgress(start)
+
+		if !p.check(token.TokenTypeComma, token.TokenTypeNewLine, token.TokenTypeRightBrace) {
+			children = append(children, p.withRecovery(func() syntax.SyntaxBase {
+				panic(p.newError(expectedItemSeparator()))
+			}, token.TokenTypeComma, token.TokenTypeRightBrace))
+		}
+	}
+
+	closeBrace := p.expectWithRecovery(token.TokenTypeRightBrace, token.TokenTypeNewLine)
+	return syntax.NewObjectSyntax(openBrace, children, closeBrace)
+}
+
+func (p *Parser) objectChild() syntax.SyntaxBase {
+	// nested resources may appear among the properties of a resource body
+	if p.check(token.TokenTypeAt) ||
+		(p.checkKeyword(syntax.KEYWORD_RESOURCE) && p.peekAt(1).Type == token.TokenTypeIdentifier) {
+		leadingNodes := p.decorators()
+		if !p.checkKeyword(syntax.KEYWORD_RESOURCE) {
+			panic(p.newError(expectedNestedResourceAfterDecorator()))
+		}
+		return p.resourceDeclaration(leadingNodes)
+	}
+
+	key := p.objectPropertyKey()
+	colon := p.expect(token.TokenTypeColon)
+	value := p.withRecovery(p.expression, token.TokenTypeComma, token.TokenTypeRightBrace)
+
+	return syntax.NewObjectPropertySyntax(key, colon, value)
+}
+
+func (p *Parser) objectPropertyKey() syntax.SyntaxBase {
+	if p.check(token.TokenTypeStringComplete, token.TokenTypeStringLeftPiece) {
+		return p.interpolableString(expectedPropertyName)
+	}
+	return p.identifierOrKeyword(expectedPropertyName)
+}
+
+// forExpression parses a loop. Resource and module loops may use a condition as their body.
+func (p *Parser) forExpression(allowConditionBody bool) syntax.SyntaxBase {
+	openSquare := p.expect(token.TokenTypeLeftSquare)
+	forKeyword := p.expectKeyword(syntax.KEYWORD_FOR)
+	variableSection := p.withRecovery(p.forVariableSection, token.TokenTypeIdentifier, token.TokenTypeColon, token.TokenTypeRightSquare)
+	inKeyword := p.withRecovery(func() syntax.SyntaxBase {
+		return p.expectKeyword(syntax.KEYWORD_IN)
+	}, token.TokenTypeColon, token.TokenTypeRightSquare)
+	expression := p.withRecovery(p.expression, token.TokenTypeColon, token.TokenTypeRightSquare)
+	colon := p.expectWithRecovery(token.TokenTypeColon, token.TokenTypeRightSquare)
+
+	body := p.withRecovery(func() syntax.SyntaxBase {
+		p.skipNewLines()
+		if allowConditionBody && p.checkKeyword(syntax.KEYWORD_IF) {
+			return p.ifCondition()
+		}
+		return p.expression()
+	}, token.TokenTypeRightSquare)
+
+	p.skipNewLines()
+	closeSquare := p.expectWithRecovery(token.TokenTypeRightSquare, token.TokenTypeNewLine)
+
+	return syntax.NewForSyntax(openSquare, forKeyword, variableSection, inKeyword, expression, colon, body, closeSquare)
+}
+
+func (p *Parser) forVariableSection() syntax.SyntaxBase {
+	if p.check(token.TokenTypeIdentifier) {
+		return syntax.NewLocalVariableSyntax(p.identifier(expectedLoopVariable))
+	}
+	if !p.check(token.TokenTypeLeftParen) {
+		panic(p.newError(expectedLoopVariable()))
+	}
+
+	block := p.variableBlock()
+	if len(block.Arguments) != 2 {
+		p.addDiagnostic(invalidLoopVariableBlock(block.GetSpan(), len(block.Arguments)))
+	}
+	return block
+}
+
+func (p *Parser) variableBlock() *syntax.VariableBlockSyntax {
+	openParen := p.expect(token.TokenTypeLeftParen)
+
+	var arguments []*syntax.LocalVariableSyntax
+	for {
+		p.skipNewLines()
+		if !p.check(token.TokenTypeIdentifier) {
+			break
+		}
+
+		arguments = append(arguments, syntax.NewLocalVariableSyntax(p.identifier(expectedVariableIdentifier)))
+
+		p.skipNewLines()
+		if !p.check(token.TokenTypeComma) {
+			break
+		}
+		p.read()
+	}
+
+	closeParen := p.expect(token.TokenTypeRightParen)
+	return syntax.NewVariableBlockSyntax(openParen, arguments, closeParen)
+}
+
+func (p *Parser) ifCondition() syntax.SyntaxBase {
+	keyword := p.expectKeyword(syntax.KEYWORD_IF)
+	conditionExpression := p.withRecovery(func() syntax.SyntaxBase {
+		if !p.check(token.TokenTypeLeftParen) {
+			panic(p.newError(expectedCharacter("(")))
+		}
+		return p.parenthesizedExpression()
+	}, token.TokenTypeLeftBrace, token.TokenTypeNewLine)
+	body := p.withRecovery(p.object, token.TokenTypeNewLine)
+
+	return syntax.NewIfConditionSyntax(keyword, conditionExpression, body)
+}
+
+func (p *Parser) lambda() syntax.SyntaxBase {
+	var variableSection syntax.SyntaxBase
+	if p.check(token.TokenTypeLeftParen) {
+		variableSection = p.variableBlock()
+	} else {
+		variableSection = syntax.NewLocalVariableSyntax(p.identifier(expectedVariableIdentifier))
+	}
+
+	arrow := p.expect(token.TokenTypeArrow)
+	p.skipNewLines()
+	body := p.expression()
+
+	return syntax.NewLambdaSyntax(variableSection, arrow, body)
+}
+
+// isLambdaStart looks ahead for `x =>` or `(x, y) =>` without consuming anything.
+func (p *Parser) isLambdaStart() bool {
+	if p.check(token.TokenTypeIdentifier) {
+		return p.peekAt(1).Type == token.TokenTypeArrow
+	}
+	if !p.check(token.TokenTypeLeftParen) {
+		return false
+	}
+
+	for offset := 1; ; offset++ {
+		switch p.peekAt(offset).Type {
+		case token.TokenTypeIdentifier, token.TokenTypeComma, token.TokenTypeNewLine:
+			continue
+		case token.TokenTypeRightParen:
+			return p.peekAt(offset+1).Type == token.TokenTypeArrow
+		default:
+			return false
+		}
+	}
+}
+
+func (p *Parser) identifier(onMissing func() *diagnostics.Diagnostic) *syntax.IdentifierSyntax {
+	if !p.check(token.TokenTypeIdentifier) {
+		panic(p.newError(onMissing()))
+	}
+	return syntax.NewIdentifierSyntax(p.read())
+}
+
+// identifierOrKeyword accepts keyword tokens as names, e.g. in property access and object keys.
+func (p *Parser) identifierOrKeyword(onMissing func() *diagnostics.Diagnostic) *syntax.IdentifierSyntax {
+	switch p.peek().Type {
+	case token.TokenTypeIdentifier,
+		token.TokenTypeTrueKeyword,
+		token.TokenTypeFalseKeyword,
+		token.TokenTypeNullKeyword,
+		token.TokenTypeWithKeyword,
+		token.TokenTypeAsKeyword:
+		return syntax.NewIdentifierSyntax(p.read())
+	}
+	panic(p.newError(onMissing()))
+}
+
+func (p *Parser) identifierWithRecovery(onMissing func() *diagnostics.Diagnostic, terminators ...token.TokenType) *syntax.IdentifierSyntax {
+	child := p.withRecovery(func() syntax.SyntaxBase {
+		return p.identifier(onMissing).Child
+	}, terminators...)
+	return syntax.NewIdentifierSyntax(child)
+}
+
+func (p *Parser) expect(tokenType token.TokenType) *token.Token {
+	if !p.check(tokenType) {
+		panic(p.newError(expectedCharacter(token.GetTokenText(tokenType))))
+	}
+	return p.read()
+}
+
+func (p *Parser) expectKeyword(keyword string) *token.Token {
+	if !p.checkKeyword(keyword) {
+		panic(p.newError(expectedKeyword(keyword)))
+	}
+	return p.read()
+}
+
+func (p *Parser) expectWithRecovery(tokenType token.TokenType, terminators ...token.TokenType) syntax.SyntaxBase {
+	return p.withRecovery(func() syntax.SyntaxBase {
+		return p.expect(tokenType)
+	}, terminators...)
+}
+
+// withRecovery runs a parse function. On a syntax error, the error is reported and the tokens
+// up to the next new line or terminator are wrapped in a SkippedTriviaSyntax.
+func (p *Parser) withRecovery(parse func() syntax.SyntaxBase, terminators ...token.TokenType) (result syntax.SyntaxBase) {
+	start := p.position
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			return
+		}
+
+		err, ok := r.(*parseError)
+		if !ok {
+			panic(r)
+		}
+
+		p.addDiagnostic(err.diagnostic)
+		p.synchronize(terminators)
+		result = p.skipped(start, err.diagnostic.Message)
+	}()
+
+	return parse()
+}
+
+func (p *Parser) synchronize(terminators []token.TokenType) {
+	for !p.isAtEnd() && !p.check(token.TokenTypeNewLine) && !p.check(terminators...) {
+		p.read()
+	}
+}
+
+// ensureProgress guarantees that item loops never spin on a token that no rule consumes.
+func (p *Parser) ensureProgress(start int) {
+	if p.position == start && !p.isAtEnd() {
+		p.read()
+	}
+}
+
+func (p *Parser) skipped(start int, errorMessage string) *syntax.SkippedTriviaSyntax {
+	var elements []syntax.SyntaxBase
+	for _, tok := range p.tokens[start:p.position] {
+		elements = append(elements, tok)
+	}
+
+	var span *util.TextSpan
+	if len(elements) > 0 {
+		span = elements[0].GetSpan().Between(elements[len(elements)-1].GetSpan())
+	} else {
+		span = util.NewTextSpan(p.peek().Span.Position, 0)
+	}
+
+	return syntax.NewSkippedTriviaSyntax(span, elements, errorMessage)
+}
+
+func (p *Parser) skipNewLines() {
+	for p.check(token.TokenTypeNewLine) {
+		p.read()
+	}
+}
+
+func (p *Parser) skipItemSeparators() {
+	for p.check(token.TokenTypeNewLine, token.TokenTypeComma) {
+		p.read()
+	}
+}
+
+func (p *Parser) peek() *token.Token {
+	return p.peekAt(0)
+}
+
+func (p *Parser) peekAt(offset int) *token.Token {
+	position := p.position + offset
+	if position >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[position]
+}
+
+func (p *Parser) read() *token.Token {
+	current := p.peek()
+	if p.position < len(p.tokens)-1 {
+		p.position++
+	}
+	return current
+}
+
+func (p *Parser) isAtEnd() bool {
+	return p.peek().Type == token.TokenTypeEndOfFile
+}
+
+func (p *Parser) check(tokenTypes ...token.TokenType) bool {
+	current := p.peek().Type
+	for _, tokenType := range tokenTypes {
+		if current == tokenType {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Parser) checkKeyword(keyword string) bool {
+	current := p.peek()
+	return current.Type == token.TokenTypeIdentifier && current.Literal == keyword
+}
+
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Parsing/Operators.cs
+func getBinaryOperator(tokenType token.TokenType) (syntax.BinaryOperator, int, bool) {
+	switch tokenType {
+	case token.TokenTypeAsterisk:
+		return syntax.BinaryOperatorMultiply, 100, true
+	case token.TokenTypeSlash:
+		return syntax.BinaryOperatorDivide, 100, true
+	case token.TokenTypeModulo:
+		return syntax.BinaryOperatorModulo, 100, true
+	case token.TokenTypePlus:
+		return syntax.BinaryOperatorAdd, 90, true
+	case token.TokenTypeMinus:
+		return syntax.BinaryOperatorSubtract, 90, true
+	case token.TokenTypeGreaterThan:
+		return syntax.BinaryOperatorGreaterThan, 80, true
+	case token.TokenTypeGreaterThanOrEqual:
+		return syntax.BinaryOperatorGreaterThanOrEqual, 80, true
+	case token.TokenTypeLessThan:
+		return syntax.BinaryOperatorLessThan, 80, true
+	case token.TokenTypeLessThanOrEqual:
+		return syntax.BinaryOperatorLessThanOrEqual, 80, true
+	case token.TokenTypeEquals:
+		return syntax.BinaryOperatorEquals, 70, true
+	case token.TokenTypeNotEquals:
+		return syntax.BinaryOperatorNotEquals, 70, true
+	case token.TokenTypeEqualsInsensitive:
+		return syntax.BinaryOperatorEqualsInsensitive, 70, true
+	case token.TokenTypeNotEqualsInsensitive:
+		return syntax.BinaryOperatorNotEqualsInsensitive, 70, true
+	case token.TokenTypeLogicalAnd:
+		return syntax.BinaryOperatorLogicalAnd, 50, true
+	case token.TokenTypeLogicalOr:
+		return syntax.BinaryOperatorLogicalOr, 40, true
+	case token.TokenTypeDoubleQuestion:
+		return syntax.BinaryOperatorCoalesce, 30, true
+	}
+	return 0, 0, false
+}
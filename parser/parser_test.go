@@ -0,0 +1,274 @@
+package parser
+
+import (
+	"bicep-go/syntax"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func parseProgram(t *testing.T, input string) *syntax.ProgramSyntax {
+	p := New(input)
+	program := p.Program()
+	for _, diagnostic := range p.GetDiagnostics() {
+		t.Errorf("unexpected diagnostic: %s", diagnostic.ToString())
+	}
+	return program
+}
+
+func TestForExpressions(t *testing.T) {
+	for _, tc := range []struct {
+		input         string
+		itemVariable  string
+		indexVariable string
+	}{
+		{"var v = [for item in items: item.name]", "item", ""},
+		{"var v = [for (item, i) in range(0, 3): '${item}-${i}']", "item", "i"},
+		{"var v = [for (item, i) in range(0, 3): {\n  name: item\n  index: i\n}]", "item", "i"},
+		{"var v = [for item in items: {\n  name: item\n}\n]", "item", ""},
+	} {
+		program := parseProgram(t, tc.input)
+		declarations := program.Declarations()
+		require.Len(t, declarations, 1)
+
+		variable := declarations[0].(*syntax.VariableDeclarationSyntax)
+		loop, ok := variable.Value.(*syntax.ForSyntax)
+		require.True(t, ok, tc.input)
+		require.Equal(t, tc.itemVariable, loop.ItemVariable().Name.IdentifierName())
+		if tc.indexVariable == "" {
+			require.Nil(t, loop.IndexVariable())
+		} else {
+			require.Equal(t, tc.indexVariable, loop.IndexVariable().Name.IdentifierName())
+		}
+	}
+}
+
+func TestResourceConditionsAndLoops(t *testing.T) {
+	input := `param deploy bool = true
+
+resource conditional 'Microsoft.Storage/storageAccounts@2023-01-01' = if (deploy) {
+  name: 'stg'
+}
+
+resource looped 'Microsoft.Storage/storageAccounts@2023-01-01' = [for (name, i) in names: if (i > 0) {
+  name: name
+}]
+
+module mods './mod.bicep' = [for name in names: {
+  name: name
+}]
+`
+
+	program := parseProgram(t, input)
+	declarations := program.Declarations()
+	require.Len(t, declarations, 4)
+
+	conditional := declarations[1].(*syntax.ResourceDeclarationSyntax)
+	condition, ok := conditional.Value.(*syntax.IfConditionSyntax)
+	require.True(t, ok)
+	require.IsType(t, &syntax.ParenthesizedExpressionSyntax{}, condition.ConditionExpression)
+	require.NotNil(t, conditional.TryGetBody())
+
+	looped := declarations[2].(*syntax.ResourceDeclarationSyntax)
+	loop, ok := looped.Value.(*syntax.ForSyntax)
+	require.True(t, ok)
+	require.Equal(t, "i", loop.IndexVariable().Name.IdentifierName())
+	require.IsType(t, &syntax.IfConditionSyntax{}, loop.Body)
+	require.NotNil(t, looped.TryGetBody().TryGetProperty("name"))
+
+	module := declarations[3].(*syntax.ModuleDeclarationSyntax)
+	path, ok := module.TryGetPath()
+	require.True(t, ok)
+	require.Equal(t, "./mod.bicep", path)
+	require.IsType(t, &syntax.ForSyntax{}, module.Value)
+}
+
+func TestFunctionDeclarations(t *testing.T) {
+	input := `@export()
+func greet(name string, count int) string =>
+  '${name}-${count}'
+
+func empty() object => {}
+`
+
+	program := parseProgram(t, input)
+	declarations := program.Declarations()
+	require.Len(t, declarations, 2)
+
+	greet := declarations[0].(*syntax.FunctionDeclarationSyntax)
+	require.Equal(t, "greet", greet.Name.IdentifierName())
+	require.Len(t, greet.Decorators(), 1)
+	lambda := greet.TryGetLambda()
+	require.NotNil(t, lambda)
+	require.Len(t, lambda.GetLocalVariables(), 2)
+	require.Equal(t, "count", lambda.GetLocalVariables()[1].Name.IdentifierName())
+	require.IsType(t, &syntax.TypeVariableAccessSyntax{}, lambda.GetLocalVariables()[1].Type)
+	require.IsType(t, &syntax.TypeVariableAccessSyntax{}, lambda.ReturnType)
+	require.IsType(t, &syntax.StringSyntax{}, lambda.Body)
+
+	empty := declarations[1].(*syntax.FunctionDeclarationSyntax)
+	require.Empty(t, empty.TryGetLambda().GetLocalVariables())
+	require.IsType(t, &syntax.ObjectSyntax{}, empty.TryGetLambda().Body)
+}
+
+func TestCompileTimeImports(t *testing.T) {
+	input := `import {a, b as c} from 'lib.bicep'
+import {
+  d
+  e as f
+} from 'br/public:lib:1.0'
+import * as lib from 'lib.json'
+`
+
+	program := parseProgram(t, input)
+	declarations := program.Declarations()
+	require.Len(t, declarations, 3)
+
+	list := declarations[0].(*syntax.CompileTimeImportDeclarationSyntax)
+	path, ok := list.TryGetPath()
+	require.True(t, ok)
+	require.Equal(t, "lib.bicep", path)
+	items := list.ImportExpression.(*syntax.ImportedSymbolsListSyntax).ImportedSymbols
+	require.Len(t, items, 2)
+	require.Equal(t, "a", items[0].GetName().IdentifierName())
+	require.Equal(t, "b", items[1].OriginalSymbolName.IdentifierName())
+	require.Equal(t, "c", items[1].GetName().IdentifierName())
+
+	multiline := declarations[1].(*syntax.CompileTimeImportDeclarationSyntax)
+	require.Len(t, multiline.ImportExpression.(*syntax.ImportedSymbolsListSyntax).ImportedSymbols, 2)
+
+	wildcard := declarations[2].(*syntax.CompileTimeImportDeclarationSyntax).ImportExpression.(*syntax.WildcardImportSyntax)
+	require.Equal(t, "lib", wildcard.GetName().IdentifierName())
+}
+
+func TestProgram(t *testing.T) {
+	input := `targetScope = 'resourceGroup'
+
+@description('The location')
+@allowed([
+  'westus'
+  'eastus'
+])
+param location string = resourceGroup().location
+param tags object?
+param sizes ('small' | 'large')[]
+
+type config = {
+  @minLength(3)
+  name: string
+  size?: int
+  *: string
+}
+
+var prefix = toLower('${location}-app')
+var enabled = [for s in sizes: s == 'small' ? true : false]
+var total = reduce(sizes, 0, (acc, cur) => acc + length(cur))
+var names = filter(sizes, s => !empty(s) && s != 'large')
+var first = tags.?owner ?? 'unknown'
+
+resource vnet 'Microsoft.Network/virtualNetworks@2023-04-01' = {
+  name: prefix
+  location: location
+  properties: {
+    addressSpace: { addressPrefixes: ['10.0.0.0/16'] }
+  }
+
+  resource subnet 'subnets' = {
+    name: 'default'
+  }
+}
+
+resource existingVnet 'Microsoft.Network/virtualNetworks@2023-04-01' existing = {
+  name: 'other'
+}
+
+output subnetId string = vnet::subnet.id
+output count int = length(sizes) * -1
+output owner string = tags!.owner
+`
+
+	program := parseProgram(t, input)
+	declarations := program.Declarations()
+	require.Len(t, declarations, 15)
+
+	location := declarations[1].(*syntax.ParameterDeclarationSyntax)
+	require.Len(t, location.Decorators(), 2)
+	require.Equal(t, "allowed", location.Decorators()[1].Name())
+	require.NotNil(t, location.DefaultValue())
+
+	tags := declarations[2].(*syntax.ParameterDeclarationSyntax)
+	require.IsType(t, &syntax.NullableTypeSyntax{}, tags.Type)
+
+	sizes := declarations[3].(*syntax.ParameterDeclarationSyntax)
+	arrayType := sizes.Type.(*syntax.ArrayTypeSyntax)
+	require.IsType(t, &syntax.ParenthesizedExpressionSyntax{}, arrayType.Item)
+
+	config := declarations[4].(*syntax.TypeDeclarationSyntax).Value.(*syntax.ObjectTypeSyntax)
+	require.Len(t, config.Properties(), 2)
+	require.True(t, config.Properties()[1].IsOptional())
+	require.NotNil(t, config.AdditionalProperties())
+
+	total := declarations[7].(*syntax.VariableDeclarationSyntax).Value.(*syntax.FunctionCallSyntax)
+	lambda := total.Arguments[2].Expression.(*syntax.LambdaSyntax)
+	require.Len(t, lambda.GetLocalVariables(), 2)
+
+	first := declarations[9].(*syntax.VariableDeclarationSyntax).Value.(*syntax.BinaryOperationSyntax)
+	require.Equal(t, syntax.BinaryOperatorCoalesce, first.Operator)
+	require.True(t, first.LeftExpression.(*syntax.PropertyAccessSyntax).IsSafeAccess())
+
+	vnet := declarations[10].(*syntax.ResourceDeclarationSyntax)
+	require.Len(t, vnet.TryGetBody().Resources(), 1)
+	require.True(t, declarations[11].(*syntax.ResourceDeclarationSyntax).IsExistingResource())
+
+	owner := declarations[14].(*syntax.OutputDeclarationSyntax)
+	require.IsType(t, &syntax.NonNullAssertionSyntax{}, owner.Value.(*syntax.PropertyAccessSyntax).BaseExpression)
+	require.Equal(t, len(input), program.GetSpan().Position+program.GetSpan().Length)
+}
+
+func TestErrorRecovery(t *testing.T) {
+	for _, tc := range []struct {
+		input        string
+		code         string
+		declarations int
+	}{
+		{"var v = [for (a) in xs: a]", "BCP249", 1},
+		{"var v = [for 1 in xs: a]", "BCP162", 1},
+		{"resource r 'a@b' = if deploy {\n}\nvar x = 1", "BCP018", 2},
+		{"resource r 'a@b' = 123\nvar x = 1", "BCP167", 2},
+		{"foo bar\nvar x = 1", "BCP007", 2},
+		{"var x = {\n  a: 1 2\n  b: 3\n}", "BCP236", 1},
+		{"var x = 1 2", "BCP019", 2},
+		{"func (a string) string => a\nvar x = 1", "BCP343", 2},
+		{"func f(a string) string a\nvar x = 1", "BCP018", 2},
+		{"func f(a) string => a\nvar x = 1", "BCP279", 2},
+		{"import a from 'lib.bicep'\nvar x = 1", "BCP354", 2},
+		{"import * from 'lib.bicep'\nvar x = 1", "BCP018", 2},
+		{"import * as 1 from 'lib.bicep'\nvar x = 1", "BCP356", 2},
+		{"import {a} 'lib.bicep'\nvar x = 1", "BCP012", 2},
+		{"import {a} from\nvar x = 1", "BCP358", 2},
+	} {
+		p := New(tc.input)
+		program := p.Program()
+		diagnostics := p.GetDiagnostics()
+		require.NotEmpty(t, diagnostics, tc.input)
+		require.Equal(t, tc.code, diagnostics[0].Code, tc.input)
+		require.Len(t, program.Declarations(), tc.declarations, tc.input)
+	}
+}
+
+func TestWalkVisitsTokensInOrder(t *testing.T) {
+	input := "var v = [for (x, i) in range(0, 2): x + i]\n"
+	program := parseProgram(t, input)
+
+	position := -1
+	syntax.Inspect(program, func(node syntax.SyntaxBase) bool {
+		if node == nil {
+			return false
+		}
+		require.GreaterOrEqual(t, node.GetSpan().Position, position)
+		if len(syntax.GetChildren(node)) == 0 {
+			position = node.GetSpan().Position
+		}
+		return true
+	})
+}
@@ -0,0 +1,171 @@
+package parser
+
+import (
+	"bicep-go/syntax"
+	"bicep-go/token"
+)
+
+// typeExpression parses a type, e.g. string, 'a' | 'b', int[], {name: string}? or resource '...'.
+func (p *Parser) typeExpression() syntax.SyntaxBase {
+	members := []syntax.SyntaxBase{p.postfixTypeExpression()}
+
+	for p.checkUnionContinuation() {
+		p.skipNewLines()
+		p.read()
+		p.skipNewLines()
+		members = append(members, p.postfixTypeExpression())
+	}
+
+	if len(members) == 1 {
+		return members[0]
+	}
+	return syntax.NewUnionTypeSyntax(members)
+}
+
+// checkUnionContinuation reports whether the next token, possibly on a following line, is a `|`.
+func (p *Parser) checkUnionContinuation() bool {
+	offset := 0
+	for p.peekAt(offset).Type == token.TokenTypeNewLine {
+		offset++
+	}
+	return p.peekAt(offset).Type == token.TokenTypePipe
+}
+
+func (p *Parser) postfixTypeExpression() syntax.SyntaxBase {
+	current := p.primaryTypeExpression()
+
+	for {
+		switch {
+		case p.check(token.TokenTypeLeftSquare) && p.peekAt(1).Type == token.TokenTypeRightSquare:
+			openBracket := p.read()
+			closeBracket := p.read()
+			current = syntax.NewArrayTypeSyntax(current, openBracket, closeBracket)
+		case p.check(token.TokenTypeQuestion):
+			current = syntax.NewNullableTypeSyntax(current, p.read())
+		default:
+			return current
+		}
+	}
+}
+
+func (p *Parser) primaryTypeExpression() syntax.SyntaxBase {
+	current := p.peek()
+
+	switch current.Type {
+	case token.TokenTypeIdentifier:
+		if current.Literal == syntax.KEYWORD_RESOURCE && p.peekAt(1).Type == token.TokenTypeStringComplete {
+			keyword := p.read()
+			return syntax.NewResourceTypeSyntax(keyword, p.interpolableString(expectedResourceTypeString))
+		}
+
+		var typeSyntax syntax.SyntaxBase = syntax.NewTypeVariableAccessSyntax(syntax.NewIdentifierSyntax(p.read()))
+		for p.check(token.TokenTypeDot) {
+			dot := p.read()
+			name := p.identifier(expectedTypeIdentifier)
+			typeSyntax = syntax.NewTypePropertyAccessSyntax(typeSyntax, dot, name)
+		}
+		return typeSyntax
+	case token.TokenTypeStringComplete, token.TokenTypeStringLeftPiece:
+		return p.interpolableString(expectedType)
+	case token.TokenTypeMultilineString:
+		return p.multilineString()
+	case token.TokenTypeInteger:
+		return p.integerLiteral()
+	case token.TokenTypeMinus:
+		operatorToken := p.read()
+		if !p.check(token.TokenTypeInteger) {
+			panic(p.newError(expectedType()))
+		}
+		return syntax.NewUnaryOperationSyntax(operatorToken, p.integerLiteral(), syntax.UnaryOperatorMinus)
+	case token.TokenTypeTrueKeyword:
+		return syntax.NewBooleanLiteralSyntax(p.read(), true)
+	case token.TokenTypeFalseKeyword:
+		return syntax.NewBooleanLiteralSyntax(p.read(), false)
+	case token.TokenTypeNullKeyword:
+		return syntax.NewNullLiteralSyntax(p.read())
+	case token.TokenTypeLeftBrace:
+		return p.objectType()
+	case token.TokenTypeLeftSquare:
+		return p.tupleType()
+	case token.TokenTypeLeftParen:
+		openParen := p.read()
+		p.skipNewLines()
+		typeSyntax := p.typeExpression()
+		p.skipNewLines()
+		closeParen := p.expect(token.TokenTypeRightParen)
+		return syntax.NewParenthesizedExpressionSyntax(openParen, typeSyntax, closeParen)
+	}
+
+	panic(p.newError(expectedType()))
+}
+
+func (p *Parser) objectType() syntax.SyntaxBase {
+	openBrace := p.expect(token.TokenTypeLeftBrace)
+
+	var children []syntax.SyntaxBase
+	for {
+		p.skipItemSeparators()
+		if p.check(token.TokenTypeRightBrace, token.TokenTypeEndOfFile) {
+			break
+		}
+
+		start := p.position
+		children = append(children, p.withRecovery(p.objectTypeMember, token.TokenTypeComma, token.TokenTypeRightBrace))
+		p.ensureProgress(start)
+
+		if !p.check(token.TokenTypeComma, token.TokenTypeNewLine, token.TokenTypeRightBrace) {
+			children = append(children, p.withRecovery(func() syntax.SyntaxBase {
+				panic(p.newError(expectedItemSeparator()))
+			}, token.TokenTypeComma, token.TokenTypeRightBrace))
+		}
+	}
+
+	closeBrace := p.expectWithRecovery(token.TokenTypeRightBrace, token.TokenTypeNewLine)
+	return syntax.NewObjectTypeSyntax(openBrace, children, closeBrace)
+}
+
+func (p *Parser) objectTypeMember() syntax.SyntaxBase {
+	leadingNodes := p.decorators()
+
+	if p.check(token.TokenTypeAsterisk) {
+		asterisk := p.read()
+		colon := p.expect(token.TokenTypeColon)
+		value := p.typeExpression()
+		return syntax.NewObjectTypeAdditionalPropertiesSyntax(leadingNodes, asterisk, colon, value)
+	}
+
+	key := p.objectPropertyKey()
+	var optionalityMarker *token.Token
+	if p.check(token.TokenTypeQuestion) {
+		optionalityMarker = p.read()
+	}
+	colon := p.expect(token.TokenTypeColon)
+	value := p.typeExpression()
+
+	return syntax.NewObjectTypePropertySyntax(leadingNodes, key, optionalityMarker, colon, value)
+}
+
+func (p *Parser) tupleType() syntax.SyntaxBase {
+	openBracket := p.expect(token.TokenTypeLeftSquare)
+
+	var items []*syntax.TupleTypeItemSyntax
+	for {
+		p.skipItemSeparators()
+		if p.check(token.TokenTypeRightSquare, token.TokenTypeEndOfFile) {
+			break
+		}
+
+		start := p.position
+		leadingNodes := p.decorators()
+		value := p.withRecovery(p.typeExpression, token.TokenTypeComma, token.TokenTypeRightSquare)
+		items = append(items, syntax.NewTupleTypeItemSyntax(leadingNodes, value))
+		p.ensureProgress(start)
+
+		if !p.check(token.TokenTypeComma, token.TokenTypeNewLine, token.TokenTypeRightSquare) {
+			panic(p.newError(expectedItemSeparator()))
+		}
+	}
+
+	closeBracket := p.expectWithRecovery(token.TokenTypeRightSquare, token.TokenTypeNewLine)
+	return syntax.NewTupleTypeSyntax(openBracket, items, closeBracket)
+}
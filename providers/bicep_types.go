@@ -0,0 +1,174 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The JSON format of the bicep-types index and type files.
+// https://github.com/Azure/bicep-types/blob/main/src/bicep-types/src/types.ts
+
+// Other kinds, e.g. AnyType or FunctionType, are typed as any.
+const (
+	typeKindNull                = "NullType"
+	typeKindBoolean             = "BooleanType"
+	typeKindInteger             = "IntegerType"
+	typeKindString              = "StringType"
+	typeKindStringLiteral       = "StringLiteralType"
+	typeKindObject              = "ObjectType"
+	typeKindDiscriminatedObject = "DiscriminatedObjectType"
+	typeKindArray               = "ArrayType"
+	typeKindUnion               = "UnionType"
+	typeKindResource            = "ResourceType"
+)
+
+// scopeTypeUnknown marks resource types that do not declare their scopes.
+const (
+	scopeTypeUnknown         = 0
+	scopeTypeTenant          = 1 << 0
+	scopeTypeManagementGroup = 1 << 1
+	scopeTypeSubscription    = 1 << 2
+	scopeTypeResourceGroup   = 1 << 3
+	scopeTypeExtension       = 1 << 4
+)
+
+const (
+	typePropertyFlagRequired  = 1 << 0
+	typePropertyFlagReadOnly  = 1 << 1
+	typePropertyFlagWriteOnly = 1 << 2
+)
+
+// typeReference points at a type by its index in a type file. Path is empty for references within the same file.
+type typeReference struct {
+	Path  string
+	Index int
+}
+
+func (r *typeReference) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Ref string `json:"$ref"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	path, index, ok := strings.Cut(raw.Ref, "#/")
+	if !ok {
+		return fmt.Errorf("invalid type reference %q", raw.Ref)
+	}
+	parsed, err := strconv.Atoi(index)
+	if err != nil {
+		return fmt.Errorf("invalid type reference %q", raw.Ref)
+	}
+	r.Path = path
+	r.Index = parsed
+	return nil
+}
+
+type typeIndex struct {
+	Resources map[string]*typeReference `json:"resources"`
+}
+
+type objectTypeProperty struct {
+	Type        *typeReference `json:"type"`
+	Flags       int            `json:"flags"`
+	Description string         `json:"description"`
+}
+
+// namedProperty keeps the properties of an object type in the order they are declared in the file.
+type namedProperty struct {
+	Name string
+	*objectTypeProperty
+}
+
+type orderedProperties []*namedProperty
+
+func (p *orderedProperties) UnmarshalJSON(data []byte) error {
+	return decodeOrderedObject(data, func(key string, value json.RawMessage) error {
+		property := &objectTypeProperty{}
+		if err := json.Unmarshal(value, property); err != nil {
+			return err
+		}
+		*p = append(*p, &namedProperty{Name: key, objectTypeProperty: property})
+		return nil
+	})
+}
+
+type namedReference struct {
+	Name      string
+	Reference *typeReference
+}
+
+type orderedReferences []*namedReference
+
+func (r *orderedReferences) UnmarshalJSON(data []byte) error {
+	return decodeOrderedObject(data, func(key string, value json.RawMessage) error {
+		reference := &typeReference{}
+		if err := json.Unmarshal(value, reference); err != nil {
+			return err
+		}
+		*r = append(*r, &namedReference{Name: key, Reference: reference})
+		return nil
+	})
+}
+
+// decodeOrderedObject calls f for every member of a JSON object in order.
+func decodeOrderedObject(data []byte, f func(key string, value json.RawMessage) error) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		if err := f(token.(string), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// typeDefinition is any of the types of a type file. The kind decides which of the fields are set.
+type typeDefinition struct {
+	Kind string `json:"$type"`
+	Name string `json:"name"`
+
+	// StringLiteralType
+	Value string `json:"value"`
+
+	// ObjectType and DiscriminatedObjectType
+	Properties           orderedProperties `json:"properties"`
+	AdditionalProperties *typeReference    `json:"additionalProperties"`
+	Discriminator        string            `json:"discriminator"`
+	BaseProperties       orderedProperties `json:"baseProperties"`
+
+	// ArrayType
+	ItemType *typeReference `json:"itemType"`
+
+	// UnionType lists its members, DiscriminatedObjectType maps discriminator values to object types.
+	Elements json.RawMessage `json:"elements"`
+
+	// ResourceType
+	Body      *typeReference `json:"body"`
+	ScopeType int            `json:"scopeType"`
+}
+
+func (d *typeDefinition) unionElements() ([]*typeReference, error) {
+	var elements []*typeReference
+	err := json.Unmarshal(d.Elements, &elements)
+	return elements, err
+}
+
+func (d *typeDefinition) discriminatedElements() (orderedReferences, error) {
+	var elements orderedReferences
+	err := json.Unmarshal(d.Elements, &elements)
+	return elements, err
+}
@@ -0,0 +1,263 @@
+package providers
+
+import (
+	"bicep-go/types"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const INDEX_FILE_NAME = "index.json"
+
+// LocalResourceTypeProvider supplies resource types from a local copy of the bicep-types index and type
+// files. Type files are loaded the first time a resource type declared in them is requested.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/TypeSystem/Providers/Az/AzResourceTypeLoader.cs
+type LocalResourceTypeProvider struct {
+	directory string
+	// resources maps lower-case resource type references, e.g. microsoft.storage/storageaccounts@2023-01-01, to their type.
+	resources map[string]*typeReference
+	// apiVersions maps lower-case resource types to their API versions, sorted.
+	apiVersions map[string][]string
+
+	files         map[string][]*typeDefinition
+	resourceTypes map[string]*types.ResourceType
+}
+
+// NewLocalResourceTypeProvider loads the index file of a bicep-types directory.
+func NewLocalResourceTypeProvider(directory string) (*LocalResourceTypeProvider, error) {
+	data, err := os.ReadFile(filepath.Join(directory, INDEX_FILE_NAME))
+	if err != nil {
+		return nil, err
+	}
+	index := &typeIndex{}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("invalid type index %s: %w", INDEX_FILE_NAME, err)
+	}
+
+	p := &LocalResourceTypeProvider{
+		directory:     directory,
+		resources:     map[string]*typeReference{},
+		apiVersions:   map[string][]string{},
+		files:         map[string][]*typeDefinition{},
+		resourceTypes: map[string]*types.ResourceType{},
+	}
+	for name, reference := range index.Resources {
+		typeName, apiVersion, ok := strings.Cut(name, "@")
+		if !ok {
+			return nil, fmt.Errorf("invalid resource type %q in %s", name, INDEX_FILE_NAME)
+		}
+		p.resources[strings.ToLower(name)] = reference
+		p.apiVersions[strings.ToLower(typeName)] = append(p.apiVersions[strings.ToLower(typeName)], apiVersion)
+	}
+	for _, versions := range p.apiVersions {
+		sort.Strings(versions)
+	}
+	return p, nil
+}
+
+// TryGetResourceType returns nil if the index has no such resource type, and an error if its type file cannot be
+// read or does not declare the resource type at the index.
+func (p *LocalResourceTypeProvider) TryGetResourceType(reference *types.ResourceTypeReference) (*types.ResourceType, error) {
+	key := strings.ToLower(reference.FormatName())
+	if resourceType, ok := p.resourceTypes[key]; ok {
+		return resourceType, nil
+	}
+
+	var resourceType *types.ResourceType
+	if typeReference, ok := p.resources[key]; ok {
+		loader, err := p.newTypeLoader(typeReference.Path)
+		if err != nil {
+			return nil, err
+		}
+		if resourceType = loader.loadResourceType(reference, typeReference.Index); resourceType == nil {
+			return nil, fmt.Errorf("invalid type file %s: no resource type at index %d", typeReference.Path, typeReference.Index)
+		}
+	}
+	p.resourceTypes[key] = resourceType
+	return resourceType, nil
+}
+
+func (p *LocalResourceTypeProvider) GetApiVersions(typeName string) []string {
+	return p.apiVersions[strings.ToLower(typeName)]
+}
+
+func (p *LocalResourceTypeProvider) newTypeLoader(path string) (*typeLoader, error) {
+	definitions, ok := p.files[path]
+	if !ok {
+		data, err := os.ReadFile(filepath.Join(p.directory, filepath.FromSlash(path)))
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &definitions); err != nil {
+			return nil, fmt.Errorf("invalid type file %s: %w", path, err)
+		}
+		p.files[path] = definitions
+	}
+	return &typeLoader{definitions: definitions, loaded: map[int]types.TypeSymbol{}}, nil
+}
+
+// typeLoader converts the types of a single type file.
+type typeLoader struct {
+	definitions []*typeDefinition
+	loaded      map[int]types.TypeSymbol
+}
+
+func (l *typeLoader) loadResourceType(reference *types.ResourceTypeReference, index int) *types.ResourceType {
+	definition := l.tryGetDefinition(index)
+	if definition == nil || definition.Kind != typeKindResource || definition.Body == nil {
+		return nil
+	}
+
+	var properties []*types.TypeProperty
+	var additionalPropertiesType types.TypeSymbol
+	if body := l.tryGetDefinition(definition.Body.Index); body != nil && body.Kind == typeKindObject {
+		properties = l.loadProperties(body.Properties)
+		if body.AdditionalProperties != nil {
+			additionalPropertiesType = l.load(body.AdditionalProperties)
+		}
+	}
+	resourceType := types.NewResourceType(reference, types.CreateResourceBody(reference.FormatName(), properties, additionalPropertiesType))
+	if definition.ScopeType != scopeTypeUnknown {
+		resourceType.ValidParentScopes = convertScopeType(definition.ScopeType)
+	}
+	return resourceType
+}
+
+func (l *typeLoader) tryGetDefinition(index int) *typeDefinition {
+	if index < 0 || index >= len(l.definitions) {
+		return nil
+	}
+	return l.definitions[index]
+}
+
+// load converts the referenced type. References to other files, which the index does not use
+// for resource bodies, and invalid references are typed as any.
+func (l *typeLoader) load(reference *typeReference) types.TypeSymbol {
+	if reference == nil || reference.Path != "" {
+		return types.Any
+	}
+	if typeSymbol, ok := l.loaded[reference.Index]; ok {
+		return typeSymbol
+	}
+
+	definition := l.tryGetDefinition(reference.Index)
+	if definition == nil {
+		return types.Any
+	}
+
+	switch definition.Kind {
+	case typeKindObject:
+		// objects are registered before their properties are loaded since they can reference themselves
+		objectType := types.NewObjectType(definition.Name, nil, nil)
+		l.loaded[reference.Index] = objectType
+		objectType.Properties = l.loadProperties(definition.Properties)
+		if definition.AdditionalProperties != nil {
+			objectType.AdditionalPropertiesType = l.load(definition.AdditionalProperties)
+		}
+		return objectType
+	}
+
+	// guards against unions and arrays that reference themselves
+	l.loaded[reference.Index] = types.Any
+	typeSymbol := l.loadDefinition(definition)
+	l.loaded[reference.Index] = typeSymbol
+	return typeSymbol
+}
+
+func (l *typeLoader) loadDefinition(definition *typeDefinition) types.TypeSymbol {
+	switch definition.Kind {
+	case typeKindNull:
+		return types.Null
+	case typeKindBoolean:
+		return types.Bool
+	case typeKindInteger:
+		return types.Int
+	case typeKindString:
+		return types.String
+	case typeKindStringLiteral:
+		return types.NewStringLiteralType(definition.Value)
+	case typeKindArray:
+		return types.NewArrayType(l.load(definition.ItemType))
+	case typeKindUnion:
+		elements, err := definition.unionElements()
+		if err != nil {
+			return types.Any
+		}
+		var members []types.TypeSymbol
+		for _, element := range elements {
+			members = append(members, l.load(element))
+		}
+		return types.CreateUnion(members...)
+	case typeKindDiscriminatedObject:
+		return l.loadDiscriminatedObject(definition)
+	}
+	return types.Any
+}
+
+// loadDiscriminatedObject returns the union of the variants, each with the base properties added.
+func (l *typeLoader) loadDiscriminatedObject(definition *typeDefinition) types.TypeSymbol {
+	elements, err := definition.discriminatedElements()
+	if err != nil {
+		return types.Any
+	}
+
+	baseProperties := l.loadProperties(definition.BaseProperties)
+	var members []types.TypeSymbol
+	for _, element := range elements {
+		variant, ok := l.load(element.Reference).(*types.ObjectType)
+		if !ok {
+			continue
+		}
+		properties := append([]*types.TypeProperty{}, baseProperties...)
+		properties = append(properties, variant.Properties...)
+		members = append(members, types.NewObjectType(variant.Name, properties, variant.AdditionalPropertiesType))
+	}
+	return types.CreateUnion(members...)
+}
+
+func (l *typeLoader) loadProperties(properties orderedProperties) []*types.TypeProperty {
+	var loaded []*types.TypeProperty
+	for _, property := range properties {
+		typeProperty := types.NewTypeProperty(property.Name, l.load(property.Type), convertPropertyFlags(property.Flags))
+		typeProperty.Description = property.Description
+		loaded = append(loaded, typeProperty)
+	}
+	return loaded
+}
+
+func convertScopeType(scopeType int) types.ResourceScope {
+	converted := types.ResourceScopeNone
+	if scopeType&scopeTypeTenant != 0 {
+		converted |= types.ResourceScopeTenant
+	}
+	if scopeType&scopeTypeManagementGroup != 0 {
+		converted |= types.ResourceScopeManagementGroup
+	}
+	if scopeType&scopeTypeSubscription != 0 {
+		converted |= types.ResourceScopeSubscription
+	}
+	if scopeType&scopeTypeResourceGroup != 0 {
+		converted |= types.ResourceScopeResourceGroup
+	}
+	if scopeType&scopeTypeExtension != 0 {
+		converted |= types.ResourceScopeResource
+	}
+	return converted
+}
+
+func convertPropertyFlags(flags int) types.TypePropertyFlags {
+	converted := types.TypePropertyFlagsNone
+	if flags&typePropertyFlagRequired != 0 {
+		converted |= types.TypePropertyFlagsRequired
+	}
+	if flags&typePropertyFlagReadOnly != 0 {
+		converted |= types.TypePropertyFlagsReadOnly
+	}
+	if flags&typePropertyFlagWriteOnly != 0 {
+		converted |= types.TypePropertyFlagsWriteOnly
+	}
+	return converted
+}
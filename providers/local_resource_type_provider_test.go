@@ -0,0 +1,122 @@
+package providers
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/parser"
+	"bicep-go/semantics"
+	"bicep-go/types"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProvider(t *testing.T) *LocalResourceTypeProvider {
+	provider, err := NewLocalResourceTypeProvider("testdata")
+	require.NoError(t, err)
+	return provider
+}
+
+func TestLocalResourceTypeProvider(t *testing.T) {
+	provider := newTestProvider(t)
+
+	require.Equal(t, []string{"2022-09-01", "2023-01-01"}, provider.GetApiVersions("microsoft.storage/StorageAccounts"))
+	require.Nil(t, provider.GetApiVersions("Microsoft.Web/sites"))
+	resourceType, err := provider.TryGetResourceType(types.NewResourceTypeReference("Microsoft.Storage/storageAccounts", "2020-01-01"))
+	require.NoError(t, err)
+	require.Nil(t, resourceType)
+
+	reference := types.TryParseResourceTypeReference("Microsoft.Storage/storageAccounts@2023-01-01")
+	resourceType, err = provider.TryGetResourceType(reference)
+	require.NoError(t, err)
+	require.NotNil(t, resourceType)
+	cached, err := provider.TryGetResourceType(reference)
+	require.NoError(t, err)
+	require.Same(t, resourceType, cached)
+	require.Equal(t, types.ResourceScopeResourceGroup, resourceType.ValidParentScopes)
+
+	body := resourceType.Body
+	require.Equal(t, "Microsoft.Storage/storageAccounts@2023-01-01", body.GetName())
+	require.Nil(t, body.AdditionalPropertiesType)
+	require.True(t, body.TryGetProperty("name").IsRequired())
+	require.True(t, body.TryGetProperty("id").IsReadOnly())
+	require.Equal(t, "'Microsoft.Storage/storageAccounts'", body.TryGetProperty("type").Type.GetName())
+	require.Equal(t, "'StorageV2' | 'BlobStorage'", body.TryGetProperty("kind").Type.GetName())
+	require.Equal(t, "Required. Indicates the type of storage account.", body.TryGetProperty("kind").Description)
+	require.NotNil(t, body.TryGetProperty("dependsOn"))
+
+	properties := body.TryGetProperty("properties").Type.(*types.ObjectType)
+	require.Equal(t, "StorageAccountPropertiesCreateParameters", properties.GetName())
+	require.Equal(t, "IPRule[]", properties.TryGetProperty("networkAcls").Type.(*types.ObjectType).TryGetProperty("ipRules").Type.GetName())
+
+	tags := body.TryGetProperty("tags").Type.(*types.ObjectType)
+	require.Same(t, types.String, tags.AdditionalPropertiesType)
+}
+
+func TestLocalResourceTypeProviderErrors(t *testing.T) {
+	_, err := NewLocalResourceTypeProvider("missing")
+	require.Error(t, err)
+}
+
+func TestLocalResourceTypeProviderTypeFileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		expected string
+	}{
+		{"invalid type file", "[", "invalid type file types.json: unexpected end of JSON input"},
+		{"missing resource type", "[]", "invalid type file types.json: no resource type at index 0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			directory := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(directory, INDEX_FILE_NAME), []byte(`{"resources": {"Microsoft.Web/sites@2023-01-01": {"$ref": "types.json#/0"}}}`), 0644))
+			require.NoError(t, os.WriteFile(filepath.Join(directory, "types.json"), []byte(tt.file), 0644))
+			provider, err := NewLocalResourceTypeProvider(directory)
+			require.NoError(t, err)
+
+			_, err = provider.TryGetResourceType(types.TryParseResourceTypeReference("Microsoft.Web/sites@2023-01-01"))
+			require.EqualError(t, err, tt.expected)
+
+			binder := semantics.NewBinder(parser.New("resource s 'Microsoft.Web/sites@2023-01-01' = {\n  name: 's'\n}\n").Program())
+			typeManager := semantics.NewTypeManager(binder, provider, nil)
+			require.Equal(t, []string{"[11:43] Error BCP081: Resource type \"Microsoft.Web/sites@2023-01-01\" does not have types available. The types could not be loaded: " + tt.expected}, formatDiagnostics(typeManager.GetDiagnostics()))
+		})
+	}
+}
+
+func formatDiagnostics(diagnostics []*diagnostics.Diagnostic) []string {
+	var formatted []string
+	for _, diagnostic := range diagnostics {
+		formatted = append(formatted, diagnostic.ToString())
+	}
+	return formatted
+}
+
+func TestLocalResourceTypeProviderValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"valid", "resource s 'Microsoft.Storage/storageAccounts@2023-01-01' = {\n  name: 's'\n  location: 'west'\n  kind: 'StorageV2'\n  sku: {\n    name: 'Standard_LRS'\n  }\n}\n", nil},
+		{"unknown type", "resource s 'Microsoft.Web/sites@2023-01-01' = {\n  name: 's'\n}\n", []string{"[11:43] Warning BCP081: Resource type \"Microsoft.Web/sites@2023-01-01\" does not have types available."}},
+		{"unknown api version", "resource s 'Microsoft.Storage/storageAccounts@2020-01-01' = {\n  name: 's'\n}\n", []string{"[11:57] Warning BCP081: Resource type \"Microsoft.Storage/storageAccounts@2020-01-01\" does not have types available. Available API versions are \"2022-09-01\", \"2023-01-01\"."}},
+		{"invalid property name", "resource s 'Microsoft.Storage/storageAccounts@2023-01-01' = {\n  name: 's'\n  location: 'west'\n  kind: 'StorageV2'\n  sku: {\n    name: 'Standard_LRS'\n  }\n  properties: {\n    accesTier: 'Hot'\n  }\n}\n", []string{"[171:180] Warning BCP037: The property \"accesTier\" is not allowed on objects of type \"StorageAccountPropertiesCreateParameters\". Permissible properties include \"accessTier\", \"networkAcls\", \"supportsHttpsTrafficOnly\"."}},
+		{"invalid property value", "resource s 'Microsoft.Storage/storageAccounts@2023-01-01' = {\n  name: 's'\n  location: 'west'\n  kind: 'Storage'\n  sku: {\n    name: 'Standard_LRS'\n  }\n}\n", []string{"[101:110] Warning BCP036: The property \"kind\" expected a value of type \"'StorageV2' | 'BlobStorage'\" but the provided value is of type \"'Storage'\"."}},
+		{"invalid scope", "targetScope = 'subscription'\nresource s 'Microsoft.Storage/storageAccounts@2023-01-01' = {\n  name: 's'\n  location: 'west'\n  kind: 'StorageV2'\n  sku: {\n    name: 'Standard_LRS'\n  }\n}\n", []string{"[40:86] Error BCP135: Scope \"subscription\" is not valid for this resource type. Permitted scopes: \"resourceGroup\"."}},
+		{"missing required property", "resource s 'Microsoft.Storage/storageAccounts@2023-01-01' = {\n  name: 's'\n  location: 'west'\n  kind: 'StorageV2'\n}\n", []string{"[60:61] Warning BCP035: The specified \"resource\" declaration is missing the following required properties: \"sku\"."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.New(tt.input)
+			binder := semantics.NewBinder(p.Program())
+			require.Empty(t, binder.GetDiagnostics())
+			typeManager := semantics.NewTypeManager(binder, newTestProvider(t), nil)
+			require.Equal(t, tt.expected, formatDiagnostics(typeManager.GetDiagnostics()))
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	CACHE_MANIFEST_FILE_NAME = "manifest"
+	CACHE_TEMPLATE_FILE_NAME = "main.json"
+)
+
+// Cache stores restored modules on disk, one directory per reference:
+// <root>/br/<registry>/<repository with "/" replaced by "$">/<tag>$ or .../<digest with ":" replaced by "#">.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Registry/OciArtifactRegistry.cs
+type Cache struct {
+	root string
+}
+
+func NewCache(root string) *Cache {
+	return &Cache{root: root}
+}
+
+// GetModuleDirectory returns the directory the files of a module are restored to.
+func (c *Cache) GetModuleDirectory(reference *OciArtifactReference) string {
+	version := reference.Tag + "$"
+	if reference.Digest != "" {
+		version = strings.ReplaceAll(reference.Digest, ":", "#")
+	}
+	return filepath.Join(
+		c.root,
+		OCI_ARTIFACT_SCHEME,
+		strings.ReplaceAll(reference.Registry, ":", "$"),
+		strings.ReplaceAll(reference.Repository, "/", "$"),
+		version)
+}
+
+// IsRestored reports whether the template of a module is in the cache.
+func (c *Cache) IsRestored(reference *OciArtifactReference) bool {
+	_, err := os.Stat(filepath.Join(c.GetModuleDirectory(reference), CACHE_TEMPLATE_FILE_NAME))
+	return err == nil
+}
+
+// ReadTemplate returns the compiled ARM JSON template of a restored module.
+func (c *Cache) ReadTemplate(reference *OciArtifactReference) ([]byte, error) {
+	return os.ReadFile(filepath.Join(c.GetModuleDirectory(reference), CACHE_TEMPLATE_FILE_NAME))
+}
+
+// write stores a module. The template is written last so that interrupted writes are not considered restored.
+func (c *Cache) write(reference *OciArtifactReference, manifest []byte, template []byte) error {
+	directory := c.GetModuleDirectory(reference)
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(directory, CACHE_MANIFEST_FILE_NAME), manifest, 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(directory, CACHE_TEMPLATE_FILE_NAME), template, 0o644)
+}
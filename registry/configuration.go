@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"bicep-go/util"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+const CONFIGURATION_FILE_NAME = "bicepconfig.json"
+
+// ModuleAlias abbreviates the registry and path prefix of OCI artifact references, e.g. "br/public:storage:v1".
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Configuration/ModuleAliasesConfiguration.cs
+type ModuleAlias struct {
+	Registry   string `json:"registry"`
+	ModulePath string `json:"modulePath"`
+}
+
+// Configuration is the registry related part of a bicepconfig.json file.
+type Configuration struct {
+	ModuleAliases struct {
+		Br map[string]*ModuleAlias `json:"br"`
+	} `json:"moduleAliases"`
+}
+
+// DefaultConfiguration returns the built-in configuration, which defines the "public" alias of the public module
+// registry.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Configuration/bicepconfig.json
+func DefaultConfiguration() *Configuration {
+	configuration := &Configuration{}
+	configuration.ModuleAliases.Br = map[string]*ModuleAlias{
+		"public": {Registry: "mcr.microsoft.com", ModulePath: "bicep"},
+	}
+	return configuration
+}
+
+// ParseConfiguration reads the JSON of a bicepconfig.json file, merged into the built-in configuration. Aliases of
+// the file replace built-in aliases of the same name.
+func ParseConfiguration(data []byte) (*Configuration, error) {
+	configuration := DefaultConfiguration()
+	if err := json.Unmarshal(data, configuration); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return configuration, nil
+}
+
+// FindConfiguration reads the bicepconfig.json file closest to a file, looking in its directory and then in each
+// parent directory. Without one, it returns the built-in configuration.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Configuration/ConfigurationManager.cs
+func FindConfiguration(fsys fs.FS, filePath string) (*Configuration, error) {
+	for directory := path.Dir(filePath); ; directory = path.Dir(directory) {
+		configurationPath := path.Join(directory, CONFIGURATION_FILE_NAME)
+		data, err := fs.ReadFile(fsys, configurationPath)
+		if err == nil {
+			configuration, err := ParseConfiguration(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", util.GetDisplayPath(fsys, configurationPath), err)
+			}
+			return configuration, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		if directory == "." {
+			return DefaultConfiguration(), nil
+		}
+	}
+}
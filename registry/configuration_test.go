@@ -0,0 +1,51 @@
+package registry
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindConfiguration(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bicepconfig.json":          {Data: []byte(`{"moduleAliases": {"br": {"acr": {"registry": "example.azurecr.io"}}}}`)},
+		"apps/web/main.bicep":       {Data: []byte("")},
+		"infra/bicepconfig.json":    {Data: []byte(`{"moduleAliases": {"br": {"public": {"registry": "mirror.example.com"}}}}`)},
+		"infra/network/main.bicep":  {Data: []byte("")},
+		"invalid/bicepconfig.json":  {Data: []byte(`{"moduleAliases": []}`)},
+		"invalid/main.bicep":        {Data: []byte("")},
+		"elsewhere/other/app.bicep": {Data: []byte("")},
+	}
+
+	tests := []struct {
+		name     string
+		fsys     fstest.MapFS
+		path     string
+		expected map[string]*ModuleAlias
+		error    string
+	}{
+		{"parent directory", fsys, "apps/web/main.bicep", map[string]*ModuleAlias{
+			"public": {Registry: "mcr.microsoft.com", ModulePath: "bicep"},
+			"acr":    {Registry: "example.azurecr.io"},
+		}, ""},
+		{"closest file", fsys, "infra/network/main.bicep", map[string]*ModuleAlias{
+			"public": {Registry: "mirror.example.com"},
+		}, ""},
+		{"no file", fstest.MapFS{"main.bicep": {Data: []byte("")}}, "main.bicep", map[string]*ModuleAlias{
+			"public": {Registry: "mcr.microsoft.com", ModulePath: "bicep"},
+		}, ""},
+		{"invalid file", fsys, "invalid/main.bicep", nil, "invalid/bicepconfig.json: invalid configuration"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			configuration, err := FindConfiguration(test.fsys, test.path)
+			if test.error != "" {
+				require.ErrorContains(t, err, test.error)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, configuration.ModuleAliases.Br)
+		})
+	}
+}
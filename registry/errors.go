@@ -0,0 +1,56 @@
+package registry
+
+import (
+	"bicep-go/diagnostics"
+	"fmt"
+)
+
+// The diagnostics are created without a span; callers place them on the module path.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Diagnostics/DiagnosticBuilder.cs
+func artifactRestoreFailed(reference string, err error) *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP192", fmt.Sprintf("Unable to restore the artifact with reference \"%s\": %s", reference, err))
+}
+
+func artifactRequiresRestore(reference string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP190", fmt.Sprintf("The artifact with reference \"%s\" has not been restored.", reference))
+}
+
+func invalidOciArtifactReference(aliasName string, reference string) *diagnostics.Diagnostic {
+	clause := fmt.Sprintf("The specified OCI artifact reference \"%s\" is not valid.", reference)
+	if aliasName != "" {
+		clause = fmt.Sprintf("The OCI artifact reference \"%s\" of module alias \"%s\" is not valid.", reference, aliasName)
+	}
+	return diagnostics.NewError(nil, "BCP193", fmt.Sprintf("%s Specify a reference in the format of \"br:<artifact-uri>:<tag>\" or \"br/<module-alias>:<module-name-or-path>:<tag>\".", clause))
+}
+
+func invalidOciArtifactReferenceInvalidPathSegment(segment string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP195", fmt.Sprintf("The artifact path segment \"%s\" is not valid. Each artifact name path segment must be a lowercase alphanumeric string optionally separated by a \".\", \"_\", or \"-\".", segment))
+}
+
+func invalidOciArtifactReferenceMissingTagOrDigest() *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP196", "The module tag or digest is missing.")
+}
+
+func invalidOciArtifactReferenceTagTooLong(tag string, maxLength int) *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP197", fmt.Sprintf("The tag \"%s\" exceeds the maximum length of %d characters.", tag, maxLength))
+}
+
+func invalidOciArtifactReferenceInvalidTag(tag string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP198", fmt.Sprintf("The tag \"%s\" is not valid. Valid characters are alphanumeric, \".\", \"_\", or \"-\" but the tag cannot begin with \".\", \"_\", or \"-\".", tag))
+}
+
+func invalidOciArtifactReferenceRepositoryTooLong(repository string, maxLength int) *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP199", fmt.Sprintf("Module path \"%s\" exceeds the maximum length of %d characters.", repository, maxLength))
+}
+
+func invalidOciArtifactReferenceInvalidDigest(digest string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP200", fmt.Sprintf("The digest \"%s\" is not valid. The valid format is a string \"sha256:\" followed by exactly 64 lowercase hexadecimal digits.", digest))
+}
+
+func invalidModuleAliasName(aliasName string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP211", fmt.Sprintf("The module alias name \"%s\" is invalid. Valid characters are alphanumeric, \"_\", or \"-\".", aliasName))
+}
+
+func ociArtifactModuleAliasNameDoesNotExist(aliasName string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(nil, "BCP216", fmt.Sprintf("The OCI artifact module alias \"%s\" could not be found in the configuration.", aliasName))
+}
@@ -0,0 +1,136 @@
+package registry
+
+import (
+	"bicep-go/diagnostics"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	OCI_ARTIFACT_SCHEME       = "br"
+	MAX_REPOSITORY_LENGTH     = 255
+	MAX_TAG_LENGTH            = 128
+	DIGEST_ALGORITHM_SHA256   = "sha256"
+	ociArtifactSchemePrefix   = OCI_ARTIFACT_SCHEME + ":"
+	ociArtifactAliasPrefix    = OCI_ARTIFACT_SCHEME + "/"
+	ociArtifactAliasSeparator = ":"
+)
+
+var (
+	moduleAliasNamePattern = regexp.MustCompile(`^[a-zA-Z0-9-_]+$`)
+	pathSegmentPattern     = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*$`)
+	tagPattern             = regexp.MustCompile(`^[a-zA-Z0-9_][a-zA-Z0-9._-]*$`)
+	digestPattern          = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+// OciArtifactReference identifies a module in an OCI registry by tag or digest,
+// e.g. "br:example.azurecr.io/bicep/storage:v1".
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Registry/Oci/OciArtifactReference.cs
+type OciArtifactReference struct {
+	Registry   string
+	Repository string
+	// Tag is empty if the reference is pinned to a digest.
+	Tag    string
+	Digest string
+}
+
+// IsOciArtifactReference reports whether a module path refers to an OCI registry instead of a local file.
+func IsOciArtifactReference(value string) bool {
+	return strings.HasPrefix(value, ociArtifactSchemePrefix) || strings.HasPrefix(value, ociArtifactAliasPrefix)
+}
+
+// ParseOciArtifactReference parses "br:<registry>/<repository>:<tag>", "br:<registry>/<repository>@<digest>"
+// and "br/<alias>:<path>:<tag>". The returned diagnostic has no span.
+func ParseOciArtifactReference(value string, configuration *Configuration) (*OciArtifactReference, *diagnostics.Diagnostic) {
+	if rest, ok := strings.CutPrefix(value, ociArtifactSchemePrefix); ok {
+		return parseOciArtifactReference("", "", rest)
+	}
+
+	rest, ok := strings.CutPrefix(value, ociArtifactAliasPrefix)
+	if !ok {
+		return nil, invalidOciArtifactReference("", value)
+	}
+	aliasName, rest, ok := strings.Cut(rest, ociArtifactAliasSeparator)
+	if !ok {
+		return nil, invalidOciArtifactReference("", value)
+	}
+	if !moduleAliasNamePattern.MatchString(aliasName) {
+		return nil, invalidModuleAliasName(aliasName)
+	}
+	var alias *ModuleAlias
+	if configuration != nil {
+		alias = configuration.ModuleAliases.Br[aliasName]
+	}
+	if alias == nil {
+		return nil, ociArtifactModuleAliasNameDoesNotExist(aliasName)
+	}
+
+	prefix := alias.Registry + "/"
+	if alias.ModulePath != "" {
+		prefix += strings.Trim(alias.ModulePath, "/") + "/"
+	}
+	return parseOciArtifactReference(aliasName, prefix, rest)
+}
+
+func parseOciArtifactReference(aliasName string, prefix string, rest string) (*OciArtifactReference, *diagnostics.Diagnostic) {
+	value := prefix + rest
+	registry, repository, ok := strings.Cut(value, "/")
+	if !ok || registry == "" {
+		return nil, invalidOciArtifactReference(aliasName, rest)
+	}
+
+	reference := &OciArtifactReference{Registry: registry}
+	if name, digest, ok := strings.Cut(repository, "@"); ok {
+		if !digestPattern.MatchString(digest) {
+			return nil, invalidOciArtifactReferenceInvalidDigest(digest)
+		}
+		repository = name
+		reference.Digest = digest
+	} else {
+		index := strings.LastIndex(repository, ":")
+		if index < 0 {
+			return nil, invalidOciArtifactReferenceMissingTagOrDigest()
+		}
+		tag := repository[index+1:]
+		if len(tag) > MAX_TAG_LENGTH {
+			return nil, invalidOciArtifactReferenceTagTooLong(tag, MAX_TAG_LENGTH)
+		}
+		if !tagPattern.MatchString(tag) {
+			return nil, invalidOciArtifactReferenceInvalidTag(tag)
+		}
+		repository = repository[:index]
+		reference.Tag = tag
+	}
+
+	if len(repository) > MAX_REPOSITORY_LENGTH {
+		return nil, invalidOciArtifactReferenceRepositoryTooLong(repository, MAX_REPOSITORY_LENGTH)
+	}
+	for _, segment := range strings.Split(repository, "/") {
+		if !pathSegmentPattern.MatchString(segment) {
+			return nil, invalidOciArtifactReferenceInvalidPathSegment(segment)
+		}
+	}
+	reference.Repository = repository
+	return reference, nil
+}
+
+// FormatName returns the fully qualified reference, e.g. "br:example.azurecr.io/bicep/storage:v1".
+func (r *OciArtifactReference) FormatName() string {
+	return fmt.Sprintf("%s%s/%s%s", ociArtifactSchemePrefix, r.Registry, r.Repository, r.formatVersion())
+}
+
+// ManifestReference returns the tag or digest to request the manifest of the artifact by.
+func (r *OciArtifactReference) ManifestReference() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+func (r *OciArtifactReference) formatVersion() string {
+	if r.Digest != "" {
+		return "@" + r.Digest
+	}
+	return ":" + r.Tag
+}
@@ -0,0 +1,54 @@
+package registry
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOciArtifactReference(t *testing.T) {
+	configuration, err := ParseConfiguration([]byte(`{
+  "moduleAliases": {
+    "br": {
+      "public": { "registry": "mcr.microsoft.com", "modulePath": "bicep" },
+      "acr": { "registry": "example.azurecr.io" }
+    }
+  }
+}`))
+	require.NoError(t, err)
+
+	digest := "sha256:" + strings.Repeat("a", 64)
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		error    string
+	}{
+		{"tag", "br:example.azurecr.io/bicep/storage:v1", "br:example.azurecr.io/bicep/storage:v1", ""},
+		{"port", "br:localhost:5000/storage:v1.0", "br:localhost:5000/storage:v1.0", ""},
+		{"digest", "br:example.azurecr.io/storage@" + digest, "br:example.azurecr.io/storage@" + digest, ""},
+		{"alias with path", "br/public:avm/storage:1.0.0", "br:mcr.microsoft.com/bicep/avm/storage:1.0.0", ""},
+		{"alias", "br/acr:storage:v1", "br:example.azurecr.io/storage:v1", ""},
+		{"missing tag", "br:example.azurecr.io/storage", "", "BCP196: The module tag or digest is missing."},
+		{"missing repository", "br:example.azurecr.io:v1", "", "BCP193: The specified OCI artifact reference \"example.azurecr.io:v1\" is not valid. Specify a reference in the format of \"br:<artifact-uri>:<tag>\" or \"br/<module-alias>:<module-name-or-path>:<tag>\"."},
+		{"upper case path", "br:example.azurecr.io/Storage:v1", "", "BCP195: The artifact path segment \"Storage\" is not valid. Each artifact name path segment must be a lowercase alphanumeric string optionally separated by a \".\", \"_\", or \"-\"."},
+		{"invalid tag", "br:example.azurecr.io/storage:-v1", "", "BCP198: The tag \"-v1\" is not valid. Valid characters are alphanumeric, \".\", \"_\", or \"-\" but the tag cannot begin with \".\", \"_\", or \"-\"."},
+		{"long tag", "br:example.azurecr.io/storage:" + strings.Repeat("a", 129), "", "BCP197: The tag \"" + strings.Repeat("a", 129) + "\" exceeds the maximum length of 128 characters."},
+		{"invalid digest", "br:example.azurecr.io/storage@sha256:abc", "", "BCP200: The digest \"sha256:abc\" is not valid. The valid format is a string \"sha256:\" followed by exactly 64 lowercase hexadecimal digits."},
+		{"invalid alias", "br/my.alias:storage:v1", "", "BCP211: The module alias name \"my.alias\" is invalid. Valid characters are alphanumeric, \"_\", or \"-\"."},
+		{"unknown alias", "br/other:storage:v1", "", "BCP216: The OCI artifact module alias \"other\" could not be found in the configuration."},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reference, diagnostic := ParseOciArtifactReference(test.input, configuration)
+			if test.error != "" {
+				require.Nil(t, reference)
+				require.Equal(t, test.error, diagnostic.Code+": "+diagnostic.Message)
+				return
+			}
+			require.Nil(t, diagnostic)
+			require.Equal(t, test.expected, reference.FormatName())
+		})
+	}
+}
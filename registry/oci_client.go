@@ -0,0 +1,163 @@
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// The media types of Bicep modules published to OCI registries.
+const (
+	OCI_MANIFEST_MEDIA_TYPE        = "application/vnd.oci.image.manifest.v1+json"
+	BICEP_MODULE_ARTIFACT_TYPE     = "application/vnd.ms.bicep.module.artifact"
+	BICEP_MODULE_CONFIG_MEDIA_TYPE = "application/vnd.ms.bicep.module.config.v1+json"
+	BICEP_MODULE_LAYER_MEDIA_TYPE  = "application/vnd.ms.bicep.module.layer.v1+json"
+	dockerContentDigestHeader      = "Docker-Content-Digest"
+)
+
+// maxManifestSize is the size limit registries are required to accept for manifests.
+const maxManifestSize int64 = 4 << 20
+
+// OciDescriptor points at a blob of an artifact.
+// https://github.com/opencontainers/image-spec/blob/main/descriptor.md
+type OciDescriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// OciManifest lists the blobs of an artifact.
+// https://github.com/opencontainers/image-spec/blob/main/manifest.md
+type OciManifest struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	MediaType     string           `json:"mediaType,omitempty"`
+	ArtifactType  string           `json:"artifactType,omitempty"`
+	Config        *OciDescriptor   `json:"config"`
+	Layers        []*OciDescriptor `json:"layers"`
+}
+
+// OciClient pulls artifacts from registries implementing the OCI distribution API over HTTPS.
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md
+type OciClient struct {
+	httpClient *http.Client
+}
+
+func NewOciClient(httpClient *http.Client) *OciClient {
+	return &OciClient{httpClient: httpClient}
+}
+
+// PullModule downloads the manifest and the template layer of a module. Every download is verified
+// against its digest, and pinned references against the digest of the manifest.
+func (c *OciClient) PullModule(ctx context.Context, reference *OciArtifactReference) (manifest []byte, template []byte, err error) {
+	manifest, digest, err := c.getManifest(ctx, reference)
+	if err != nil {
+		return nil, nil, err
+	}
+	if reference.Digest != "" && digest != reference.Digest {
+		return nil, nil, fmt.Errorf("the manifest digest %q does not match the requested digest %q", digest, reference.Digest)
+	}
+
+	parsed := &OciManifest{}
+	if err := json.Unmarshal(manifest, parsed); err != nil {
+		return nil, nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if parsed.Config == nil || parsed.Config.MediaType != BICEP_MODULE_CONFIG_MEDIA_TYPE {
+		return nil, nil, fmt.Errorf("the artifact is not a Bicep module")
+	}
+
+	var layer *OciDescriptor
+	for _, descriptor := range parsed.Layers {
+		if descriptor.MediaType == BICEP_MODULE_LAYER_MEDIA_TYPE {
+			if layer != nil {
+				return nil, nil, fmt.Errorf("the module has multiple layers of media type %q", BICEP_MODULE_LAYER_MEDIA_TYPE)
+			}
+			layer = descriptor
+		}
+	}
+	if layer == nil {
+		return nil, nil, fmt.Errorf("the module has no layer of media type %q", BICEP_MODULE_LAYER_MEDIA_TYPE)
+	}
+
+	template, err = c.getBlob(ctx, reference, layer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return manifest, template, nil
+}
+
+// getManifest returns the manifest and its digest, verified against the digest the registry reports.
+func (c *OciClient) getManifest(ctx context.Context, reference *OciArtifactReference) ([]byte, string, error) {
+	response, err := c.get(ctx, reference, "manifests", reference.ManifestReference(), OCI_MANIFEST_MEDIA_TYPE)
+	if err != nil {
+		return nil, "", err
+	}
+	defer response.Body.Close()
+
+	manifest, err := io.ReadAll(io.LimitReader(response.Body, maxManifestSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(manifest)) > maxManifestSize {
+		return nil, "", fmt.Errorf("the manifest exceeds the maximum size of %d bytes", maxManifestSize)
+	}
+
+	digest := computeDigest(manifest)
+	if reported := response.Header.Get(dockerContentDigestHeader); reported != "" && reported != digest {
+		return nil, "", fmt.Errorf("the manifest digest %q does not match the digest %q reported by the registry", digest, reported)
+	}
+	return manifest, digest, nil
+}
+
+func (c *OciClient) getBlob(ctx context.Context, reference *OciArtifactReference, descriptor *OciDescriptor) ([]byte, error) {
+	if !digestPattern.MatchString(descriptor.Digest) {
+		return nil, fmt.Errorf("the layer digest %q is not supported", descriptor.Digest)
+	}
+	response, err := c.get(ctx, reference, "blobs", descriptor.Digest, "")
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	blob, err := io.ReadAll(io.LimitReader(response.Body, descriptor.Size+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(blob)) != descriptor.Size {
+		return nil, fmt.Errorf("the size of blob %q does not match the expected size of %d bytes", descriptor.Digest, descriptor.Size)
+	}
+	if digest := computeDigest(blob); digest != descriptor.Digest {
+		return nil, fmt.Errorf("the digest %q of the blob does not match the expected digest %q", digest, descriptor.Digest)
+	}
+	return blob, nil
+}
+
+func (c *OciClient) get(ctx context.Context, reference *OciArtifactReference, kind string, name string, accept string) (*http.Response, error) {
+	url := fmt.Sprintf("https://%s/v2/%s/%s/%s", reference.Registry, reference.Repository, kind, name)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		request.Header.Set("Accept", accept)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("the registry responded to %s with status %s", strings.TrimPrefix(url, "https://"), response.Status)
+	}
+	return response, nil
+}
+
+func computeDigest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return DIGEST_ALGORITHM_SHA256 + ":" + hex.EncodeToString(sum[:])
+}
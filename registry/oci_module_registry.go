@@ -0,0 +1,69 @@
+package registry
+
+import (
+	"bicep-go/diagnostics"
+	"context"
+)
+
+// OciModuleRegistry resolves "br:" module references to the templates restored into the cache.
+// Modules are only downloaded by Restore; compilation reads them from the cache.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Registry/OciArtifactRegistry.cs
+type OciModuleRegistry struct {
+	configuration *Configuration
+	cache         *Cache
+	client        *OciClient
+	// restoreFailures maps fully qualified references to the error of their last restore.
+	restoreFailures map[string]error
+}
+
+// NewOciModuleRegistry returns a registry resolving aliases from the configuration, which may be nil.
+func NewOciModuleRegistry(configuration *Configuration, cache *Cache, client *OciClient) *OciModuleRegistry {
+	return &OciModuleRegistry{
+		configuration:   configuration,
+		cache:           cache,
+		client:          client,
+		restoreFailures: map[string]error{},
+	}
+}
+
+// ParseReference parses a module path. The returned diagnostic has no span.
+func (r *OciModuleRegistry) ParseReference(value string) (*OciArtifactReference, *diagnostics.Diagnostic) {
+	return ParseOciArtifactReference(value, r.configuration)
+}
+
+// Restore downloads the modules that are not in the cache yet. Failures are reported when the modules are read.
+func (r *OciModuleRegistry) Restore(ctx context.Context, references []*OciArtifactReference) {
+	for _, reference := range references {
+		if r.cache.IsRestored(reference) {
+			continue
+		}
+		if err := r.restore(ctx, reference); err != nil {
+			r.restoreFailures[reference.FormatName()] = err
+		} else {
+			delete(r.restoreFailures, reference.FormatName())
+		}
+	}
+}
+
+func (r *OciModuleRegistry) restore(ctx context.Context, reference *OciArtifactReference) error {
+	manifest, template, err := r.client.PullModule(ctx, reference)
+	if err != nil {
+		return err
+	}
+	return r.cache.write(reference, manifest, template)
+}
+
+// TryReadTemplate returns the template of a restored module. The returned diagnostic has no span.
+func (r *OciModuleRegistry) TryReadTemplate(reference *OciArtifactReference) ([]byte, *diagnostics.Diagnostic) {
+	if err, ok := r.restoreFailures[reference.FormatName()]; ok {
+		return nil, artifactRestoreFailed(reference.FormatName(), err)
+	}
+	if !r.cache.IsRestored(reference) {
+		return nil, artifactRequiresRestore(reference.FormatName())
+	}
+	template, err := r.cache.ReadTemplate(reference)
+	if err != nil {
+		return nil, artifactRestoreFailed(reference.FormatName(), err)
+	}
+	return template, nil
+}
@@ -0,0 +1,86 @@
+package registry_test
+
+import (
+	"bicep-go/registry"
+	"bicep-go/registry/registrytest"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const template = `{"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#"}`
+
+func newTestRegistry(t *testing.T, server *registrytest.Server) (*registry.OciModuleRegistry, *registry.Cache) {
+	cache := registry.NewCache(t.TempDir())
+	return registry.NewOciModuleRegistry(nil, cache, registry.NewOciClient(server.Client())), cache
+}
+
+func parseReference(t *testing.T, modules *registry.OciModuleRegistry, value string) *registry.OciArtifactReference {
+	reference, diagnostic := modules.ParseReference(value)
+	require.Nil(t, diagnostic)
+	return reference
+}
+
+func TestOciModuleRegistryRestore(t *testing.T) {
+	server := registrytest.NewServer()
+	defer server.Close()
+	digest := server.PushModule("bicep/storage", "v1", []byte(template))
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"tag", "br:" + server.Registry() + "/bicep/storage:v1"},
+		{"digest", "br:" + server.Registry() + "/bicep/storage@" + digest},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			modules, cache := newTestRegistry(t, server)
+			reference := parseReference(t, modules, test.value)
+
+			_, diagnostic := modules.TryReadTemplate(reference)
+			require.Equal(t, "BCP190", diagnostic.Code)
+
+			modules.Restore(context.Background(), []*registry.OciArtifactReference{reference})
+			data, diagnostic := modules.TryReadTemplate(reference)
+			require.Nil(t, diagnostic)
+			require.Equal(t, template, string(data))
+
+			manifest, err := os.ReadFile(filepath.Join(cache.GetModuleDirectory(reference), registry.CACHE_MANIFEST_FILE_NAME))
+			require.NoError(t, err)
+			require.Equal(t, digest, registrytest.Digest(manifest))
+		})
+	}
+}
+
+func TestOciModuleRegistryRestoreFailures(t *testing.T) {
+	server := registrytest.NewServer()
+	defer server.Close()
+	server.PushModule("bicep/storage", "v1", []byte(template))
+	server.PushModule("bicep/tampered", "v1", []byte(template+" "))
+	server.ReplaceBlob(registrytest.Digest([]byte(template+" ")), []byte(template+"!"))
+
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{"missing tag", "br:" + server.Registry() + "/bicep/storage:v2", "the registry responded to " + server.Registry() + "/v2/bicep/storage/manifests/v2 with status 404 Not Found"},
+		{"digest mismatch", "br:" + server.Registry() + "/bicep/tampered:v1", "the digest \"" + registrytest.Digest([]byte(template+"!")) + "\" of the blob does not match the expected digest \"" + registrytest.Digest([]byte(template+" ")) + "\""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			modules, cache := newTestRegistry(t, server)
+			reference := parseReference(t, modules, test.value)
+
+			modules.Restore(context.Background(), []*registry.OciArtifactReference{reference})
+			_, diagnostic := modules.TryReadTemplate(reference)
+			require.Equal(t, "BCP192", diagnostic.Code)
+			require.Equal(t, "Unable to restore the artifact with reference \""+test.value+"\": "+test.expected, diagnostic.Message)
+			require.False(t, cache.IsRestored(reference))
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Package registrytest provides an in-process OCI registry for tests.
+package registrytest
+
+import (
+	"bicep-go/registry"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// Server serves pushed modules through the pull endpoints of the OCI distribution API over HTTPS.
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pull
+type Server struct {
+	*httptest.Server
+	mu sync.Mutex
+	// manifests maps repositories to tags and digests to manifests.
+	manifests map[string]map[string][]byte
+	blobs     map[string][]byte
+}
+
+func NewServer() *Server {
+	s := &Server{
+		manifests: map[string]map[string][]byte{},
+		blobs:     map[string][]byte{},
+	}
+	s.Server = httptest.NewTLSServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Registry returns the host of the server to use in references, e.g. "127.0.0.1:12345".
+func (s *Server) Registry() string {
+	return s.Listener.Addr().String()
+}
+
+// PushModule stores a module template under the repository and tag and returns the digest of its manifest.
+func (s *Server) PushModule(repository string, tag string, template []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	config := []byte("{}")
+	manifest, err := json.Marshal(&registry.OciManifest{
+		SchemaVersion: 2,
+		MediaType:     registry.OCI_MANIFEST_MEDIA_TYPE,
+		ArtifactType:  registry.BICEP_MODULE_ARTIFACT_TYPE,
+		Config:        s.putBlob(registry.BICEP_MODULE_CONFIG_MEDIA_TYPE, config),
+		Layers:        []*registry.OciDescriptor{s.putBlob(registry.BICEP_MODULE_LAYER_MEDIA_TYPE, template)},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	digest := Digest(manifest)
+	if s.manifests[repository] == nil {
+		s.manifests[repository] = map[string][]byte{}
+	}
+	s.manifests[repository][tag] = manifest
+	s.manifests[repository][digest] = manifest
+	return digest
+}
+
+// ReplaceBlob serves data for the blob of a digest, e.g. to simulate a tampered registry.
+func (s *Server) ReplaceBlob(digest string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[digest] = data
+}
+
+// Digest returns the OCI digest of data.
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (s *Server) putBlob(mediaType string, data []byte) *registry.OciDescriptor {
+	digest := Digest(data)
+	s.blobs[digest] = data
+	return &registry.OciDescriptor{MediaType: mediaType, Digest: digest, Size: int64(len(data))}
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name, ok := strings.CutPrefix(r.URL.Path, "/v2/")
+	if !ok || r.Method != http.MethodGet {
+		http.NotFound(w, r)
+		return
+	}
+	if repository, reference, ok := cutLast(name, "/manifests/"); ok {
+		manifest, ok := s.manifests[repository][reference]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", registry.OCI_MANIFEST_MEDIA_TYPE)
+		w.Header().Set("Docker-Content-Digest", Digest(manifest))
+		w.Write(manifest)
+		return
+	}
+	if _, digest, ok := cutLast(name, "/blobs/"); ok {
+		blob, ok := s.blobs[digest]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(blob)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func cutLast(s string, separator string) (string, string, bool) {
+	index := strings.LastIndex(s, separator)
+	if index < 0 {
+		return "", "", false
+	}
+	return s[:index], s[index+len(separator):], true
+}
@@ -0,0 +1,326 @@
+package semantics
+
+import (
+	"bicep-go/syntax"
+	"bicep-go/types"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ArmTemplateSemanticModel exposes the parameters, outputs, target scope and exports of a compiled ARM JSON
+// template, e.g. a module restored from a registry.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/ArmTemplateSemanticModel.cs
+type ArmTemplateSemanticModel struct {
+	template *armTemplate
+	content  json.RawMessage
+}
+
+// Templates compiled from Bicep mark exported definitions and functions with this metadata property, and list
+// exported variables under the template metadata property of the same name.
+const (
+	ARM_METADATA_EXPORT             = "__bicep_export!"
+	ARM_METADATA_EXPORTED_VARIABLES = "__bicep_exported_variables!"
+)
+
+type armTemplate struct {
+	Schema      string                           `json:"$schema"`
+	Metadata    *armTemplateMetadata             `json:"metadata"`
+	Definitions map[string]*armTemplateParameter `json:"definitions"`
+	Parameters  map[string]*armTemplateParameter `json:"parameters"`
+	Variables   map[string]json.RawMessage       `json:"variables"`
+	Functions   []*armTemplateFunctionNamespace  `json:"functions"`
+	Outputs     map[string]*armTemplateOutput    `json:"outputs"`
+	// definitionNames, parameterNames and outputNames keep the declaration order of the template.
+	definitionNames []string
+	parameterNames  []string
+	outputNames     []string
+}
+
+type armTemplateMetadata struct {
+	Description       string                 `json:"description"`
+	Export            bool                   `json:"__bicep_export!"`
+	ExportedVariables []*armExportedVariable `json:"__bicep_exported_variables!"`
+}
+
+type armExportedVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// armTemplateParameter is a parameter or a type definition.
+type armTemplateParameter struct {
+	Type          string               `json:"type"`
+	Ref           string               `json:"$ref"`
+	DefaultValue  json.RawMessage      `json:"defaultValue"`
+	AllowedValues []json.RawMessage    `json:"allowedValues"`
+	Nullable      bool                 `json:"nullable"`
+	Metadata      *armTemplateMetadata `json:"metadata"`
+}
+
+type armTemplateOutput struct {
+	Type string `json:"type"`
+}
+
+type armTemplateFunctionNamespace struct {
+	Namespace string                          `json:"namespace"`
+	Members   map[string]*armTemplateFunction `json:"members"`
+	// memberNames keeps the declaration order of the functions.
+	memberNames orderedKeys
+}
+
+func (n *armTemplateFunctionNamespace) UnmarshalJSON(data []byte) error {
+	type plain armTemplateFunctionNamespace
+	if err := json.Unmarshal(data, (*plain)(n)); err != nil {
+		return err
+	}
+	var order struct {
+		Members orderedKeys `json:"members"`
+	}
+	if err := json.Unmarshal(data, &order); err != nil {
+		return err
+	}
+	n.memberNames = order.Members
+	return nil
+}
+
+type armTemplateFunction struct {
+	Parameters []*armTemplateParameter `json:"parameters"`
+	Output     *armTemplateParameter   `json:"output"`
+	Metadata   *armTemplateMetadata    `json:"metadata"`
+}
+
+// NewArmTemplateSemanticModel parses the JSON of a template.
+func NewArmTemplateSemanticModel(data []byte) (*ArmTemplateSemanticModel, error) {
+	template := &armTemplate{}
+	if err := json.Unmarshal(data, template); err != nil {
+		return nil, fmt.Errorf("invalid ARM template: %w", err)
+	}
+
+	var order struct {
+		Definitions orderedKeys `json:"definitions"`
+		Parameters  orderedKeys `json:"parameters"`
+		Outputs     orderedKeys `json:"outputs"`
+	}
+	if err := json.Unmarshal(data, &order); err != nil {
+		return nil, fmt.Errorf("invalid ARM template: %w", err)
+	}
+	template.definitionNames = order.Definitions
+	template.parameterNames = order.Parameters
+	template.outputNames = order.Outputs
+	return &ArmTemplateSemanticModel{template: template, content: data}, nil
+}
+
+// GetContent returns the JSON of the template, which is inlined by modules referencing it.
+func (m *ArmTemplateSemanticModel) GetContent() json.RawMessage {
+	return m.content
+}
+
+// GetTargetScope derives the scope from the schema of the template.
+func (m *ArmTemplateSemanticModel) GetTargetScope() types.ResourceScope {
+	schema := strings.ToLower(m.template.Schema)
+	switch {
+	case strings.HasSuffix(schema, "/subscriptiondeploymenttemplate.json#"):
+		return types.ResourceScopeSubscription
+	case strings.HasSuffix(schema, "/managementgroupdeploymenttemplate.json#"):
+		return types.ResourceScopeManagementGroup
+	case strings.HasSuffix(schema, "/tenantdeploymenttemplate.json#"):
+		return types.ResourceScopeTenant
+	}
+	return types.ResourceScopeResourceGroup
+}
+
+// GetParametersType returns the type of the params object of a module referencing the template.
+// Parameters without a default value that are not nullable are required.
+func (m *ArmTemplateSemanticModel) GetParametersType() *types.ObjectType {
+	var properties []*types.TypeProperty
+	for _, name := range m.template.parameterNames {
+		parameter := m.template.Parameters[name]
+		parameterType := getArmParameterType(parameter)
+		flags := types.TypePropertyFlagsNone
+		if parameter.DefaultValue == nil && !parameter.Nullable {
+			flags = types.TypePropertyFlagsRequired
+		}
+		if parameter.Nullable {
+			parameterType = types.CreateUnion(parameterType, types.Null)
+		}
+		properties = append(properties, types.NewTypeProperty(name, parameterType, flags))
+	}
+	return types.NewObjectType(syntax.MODULE_PROPERTY_PARAMS, properties, nil)
+}
+
+// GetOutputsType returns the type of the outputs of a module referencing the template.
+func (m *ArmTemplateSemanticModel) GetOutputsType() *types.ObjectType {
+	var properties []*types.TypeProperty
+	for _, name := range m.template.outputNames {
+		properties = append(properties, types.NewTypeProperty(name, getArmType(m.template.Outputs[name].Type), types.TypePropertyFlagsRequired|types.TypePropertyFlagsReadOnly))
+	}
+	return types.NewObjectType(syntax.MODULE_PROPERTY_OUTPUTS, properties, nil)
+}
+
+// GetExports returns the exported definitions, variables and functions of a template compiled from Bicep.
+func (m *ArmTemplateSemanticModel) GetExports() []*ExportedSymbol {
+	var exports []*ExportedSymbol
+	for _, name := range m.template.definitionNames {
+		definition := m.template.Definitions[name]
+		if definition.Metadata == nil || !definition.Metadata.Export {
+			continue
+		}
+		exports = append(exports, &ExportedSymbol{
+			Kind:        ExportKindType,
+			Name:        name,
+			Type:        m.getArmDefinitionType(definition, map[string]bool{}),
+			Description: definition.Metadata.Description,
+		})
+	}
+
+	if m.template.Metadata != nil {
+		for _, variable := range m.template.Metadata.ExportedVariables {
+			value, ok := m.template.Variables[variable.Name]
+			if !ok {
+				continue
+			}
+			exports = append(exports, &ExportedSymbol{
+				Kind:        ExportKindVariable,
+				Name:        variable.Name,
+				Type:        getArmValueType(value),
+				Description: variable.Description,
+			})
+		}
+	}
+
+	for _, namespace := range m.template.Functions {
+		for _, name := range namespace.memberNames {
+			function := namespace.Members[name]
+			if function.Metadata == nil || !function.Metadata.Export {
+				continue
+			}
+			var argumentTypes []types.TypeSymbol
+			for _, parameter := range function.Parameters {
+				argumentTypes = append(argumentTypes, m.getArmDefinitionType(parameter, map[string]bool{}))
+			}
+			var returnType types.TypeSymbol = types.Any
+			if function.Output != nil {
+				returnType = m.getArmDefinitionType(function.Output, map[string]bool{})
+			}
+			exports = append(exports, &ExportedSymbol{
+				Kind:        ExportKindFunction,
+				Name:        name,
+				Type:        types.NewLambdaType(argumentTypes, returnType),
+				Description: function.Metadata.Description,
+			})
+		}
+	}
+	return exports
+}
+
+// getArmDefinitionType converts a type definition, following references to other definitions of the template.
+// visiting holds the definitions being converted, so that recursive definitions are typed as any.
+func (m *ArmTemplateSemanticModel) getArmDefinitionType(definition *armTemplateParameter, visiting map[string]bool) types.TypeSymbol {
+	var definitionType types.TypeSymbol
+	if name, ok := strings.CutPrefix(definition.Ref, "#/definitions/"); ok {
+		referenced, ok := m.template.Definitions[name]
+		if !ok || visiting[name] {
+			return types.Any
+		}
+		visiting[name] = true
+		definitionType = m.getArmDefinitionType(referenced, visiting)
+		delete(visiting, name)
+	} else {
+		definitionType = getArmParameterType(definition)
+	}
+
+	if definition.Nullable {
+		return types.CreateUnion(definitionType, types.Null)
+	}
+	return definitionType
+}
+
+// getArmValueType returns the type of the value of a variable. Values holding template expressions are typed as any.
+func getArmValueType(value json.RawMessage) types.TypeSymbol {
+	var decoded any
+	if err := json.Unmarshal(value, &decoded); err != nil {
+		return types.Any
+	}
+	switch decoded := decoded.(type) {
+	case string:
+		if strings.HasPrefix(decoded, "[") && !strings.HasPrefix(decoded, "[[") {
+			return types.Any
+		}
+		return types.String
+	case float64:
+		return types.Int
+	case bool:
+		return types.Bool
+	case map[string]any:
+		return types.Object
+	case []any:
+		return types.Array
+	case nil:
+		return types.Null
+	}
+	return types.Any
+}
+
+// getArmParameterType narrows the type of the parameter to its allowed values if they are all strings or integers.
+func getArmParameterType(parameter *armTemplateParameter) types.TypeSymbol {
+	parameterType := getArmType(parameter.Type)
+	if len(parameter.AllowedValues) == 0 || (parameterType != types.String && parameterType != types.Int) {
+		return parameterType
+	}
+
+	var members []types.TypeSymbol
+	for _, value := range parameter.AllowedValues {
+		var stringValue string
+		var intValue int64
+		switch {
+		case parameterType == types.String && json.Unmarshal(value, &stringValue) == nil:
+			members = append(members, types.NewStringLiteralType(stringValue))
+		case parameterType == types.Int && json.Unmarshal(value, &intValue) == nil:
+			members = append(members, types.NewIntegerLiteralType(intValue))
+		default:
+			return parameterType
+		}
+	}
+	return types.CreateUnion(members...)
+}
+
+// getArmType converts the type names of ARM template parameters and outputs. Unknown types are typed as any.
+func getArmType(typeName string) types.TypeSymbol {
+	switch strings.ToLower(typeName) {
+	case "string", "securestring":
+		return types.String
+	case "int":
+		return types.Int
+	case "bool":
+		return types.Bool
+	case "object", "secureobject":
+		return types.Object
+	case "array":
+		return types.Array
+	}
+	return types.Any
+}
+
+// orderedKeys collects the member names of a JSON object in order.
+type orderedKeys []string
+
+func (k *orderedKeys) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return err
+		}
+		*k = append(*k, token.(string))
+	}
+	return nil
+}
@@ -0,0 +1,644 @@
+package semantics
+
+import (
+	"bicep-go/common"
+	"bicep-go/diagnostics"
+	"bicep-go/namespaces"
+	"bicep-go/syntax"
+	"sort"
+)
+
+// Binder creates the symbols of a file and resolves every name in it to the symbol it refers to.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/Binder.cs
+type Binder struct {
+	program     *syntax.ProgramSyntax
+	hierarchy   *syntax.SyntaxHierarchy
+	fileSymbol  *FileSymbol
+	bindings    map[syntax.SyntaxBase]Symbol
+	diagnostics []*diagnostics.Diagnostic
+
+	// resources maps every resource declaration, including nested ones, to its symbol.
+	resources map[*syntax.ResourceDeclarationSyntax]*ResourceSymbol
+	// resourceBodies maps the body of every resource declaring nested resources to its symbol.
+	resourceBodies map[*syntax.ObjectSyntax]*ResourceSymbol
+	// scopes is the chain of local scopes enclosing the node being bound, innermost last.
+	scopes []*LocalScope
+	// inFunction is set while binding the body of a user-defined function, which can only reference
+	// its parameters, other functions and types.
+	inFunction bool
+}
+
+func NewBinder(program *syntax.ProgramSyntax) *Binder {
+	b := &Binder{
+		program:        program,
+		hierarchy:      syntax.NewSyntaxHierarchy(program),
+		bindings:       map[syntax.SyntaxBase]Symbol{},
+		resources:      map[*syntax.ResourceDeclarationSyntax]*ResourceSymbol{},
+		resourceBodies: map[*syntax.ObjectSyntax]*ResourceSymbol{},
+	}
+
+	b.fileSymbol = b.declareFile()
+	b.bindings[program] = b.fileSymbol
+	b.validateDeclarations()
+	b.bind(program)
+	b.checkCycles()
+
+	diagnostics.Sort(b.diagnostics)
+	return b
+}
+
+func (b *Binder) GetFileSymbol() *FileSymbol {
+	return b.fileSymbol
+}
+
+func (b *Binder) GetHierarchy() *syntax.SyntaxHierarchy {
+	return b.hierarchy
+}
+
+func (b *Binder) GetDiagnostics() []*diagnostics.Diagnostic {
+	return b.diagnostics
+}
+
+// GetSymbolInfo returns the symbol declared by a declaration, or the symbol referenced by a
+// variable access, function call, decorator, type reference or nested resource access.
+// It returns nil for any other syntax.
+func (b *Binder) GetSymbolInfo(node syntax.SyntaxBase) Symbol {
+	return b.bindings[node]
+}
+
+// FindReferences returns the declaration of the symbol and every node referencing it, in source order.
+func (b *Binder) FindReferences(symbol Symbol) []syntax.SyntaxBase {
+	var references []syntax.SyntaxBase
+	syntax.Inspect(b.program, func(node syntax.SyntaxBase) bool {
+		if bound, ok := b.bindings[node]; ok && bound == symbol {
+			references = append(references, node)
+		}
+		return true
+	})
+	return references
+}
+
+// GetParent returns the parent of a node in the syntax tree of the file.
+func (b *Binder) GetParent(node syntax.SyntaxBase) syntax.SyntaxBase {
+	return b.hierarchy.GetParent(node)
+}
+
+func (b *Binder) addDiagnostic(diagnostic *diagnostics.Diagnostic) {
+	b.diagnostics = append(b.diagnostics, diagnostic)
+}
+
+// bindError binds a node to an error symbol and reports the diagnostic.
+func (b *Binder) bindError(node syntax.SyntaxBase, diagnostic *diagnostics.Diagnostic) {
+	b.bindings[node] = &ErrorSymbol{Diagnostic: diagnostic}
+	if diagnostic != nil {
+		b.addDiagnostic(diagnostic)
+	}
+}
+
+func (b *Binder) declareFile() *FileSymbol {
+	file := &FileSymbol{Program: b.program, TargetScope: getTargetScope(b.program)}
+
+	for _, namespace := range namespaces.GetDefaultNamespaces(file.TargetScope) {
+		file.Namespaces = append(file.Namespaces, &NamespaceSymbol{Namespace: namespace})
+	}
+
+	for _, declaration := range b.program.Declarations() {
+		var symbol DeclaredSymbol
+		switch declaration := declaration.(type) {
+		case *syntax.MetadataDeclarationSyntax:
+			metadata := &MetadataSymbol{declaredSymbol{declaration.Name}, declaration}
+			file.Metadata = append(file.Metadata, metadata)
+			symbol = metadata
+		case *syntax.ParameterDeclarationSyntax:
+			parameter := &ParameterSymbol{declaredSymbol{declaration.Name}, declaration}
+			file.Parameters = append(file.Parameters, parameter)
+			symbol = parameter
+		case *syntax.VariableDeclarationSyntax:
+			variable := &VariableSymbol{declaredSymbol{declaration.Name}, declaration}
+			file.Variables = append(file.Variables, variable)
+			symbol = variable
+		case *syntax.ResourceDeclarationSyntax:
+			resource := b.declareResource(declaration, nil)
+			file.Resources = append(file.Resources, resource)
+			symbol = resource
+		case *syntax.ModuleDeclarationSyntax:
+			module := &ModuleSymbol{declaredSymbol{declaration.Name}, declaration}
+			file.Modules = append(file.Modules, module)
+			symbol = module
+		case *syntax.OutputDeclarationSyntax:
+			output := &OutputSymbol{declaredSymbol{declaration.Name}, declaration}
+			file.Outputs = append(file.Outputs, output)
+			symbol = output
+		case *syntax.TypeDeclarationSyntax:
+			typeAlias := &TypeAliasSymbol{declaredSymbol{declaration.Name}, declaration}
+			file.TypeAliases = append(file.TypeAliases, typeAlias)
+			symbol = typeAlias
+		case *syntax.FunctionDeclarationSyntax:
+			function := &DeclaredFunctionSymbol{declaredSymbol{declaration.Name}, declaration}
+			file.Functions = append(file.Functions, function)
+			symbol = function
+		case *syntax.CompileTimeImportDeclarationSyntax:
+			b.declareImports(file, declaration)
+			continue
+		default:
+			continue
+		}
+
+		file.Declarations = append(file.Declarations, symbol)
+		b.bindings[declaration] = symbol
+	}
+
+	return file
+}
+
+// declareImports declares the symbols an import statement introduces into the file.
+func (b *Binder) declareImports(file *FileSymbol, declaration *syntax.CompileTimeImportDeclarationSyntax) {
+	switch expression := declaration.ImportExpression.(type) {
+	case *syntax.ImportedSymbolsListSyntax:
+		for _, item := range expression.ImportedSymbols {
+			imported := &ImportedSymbol{declaredSymbol{item.GetName()}, declaration, item}
+			file.Imports = append(file.Imports, imported)
+			file.Declarations = append(file.Declarations, imported)
+			b.bindings[item] = imported
+		}
+	case *syntax.WildcardImportSyntax:
+		if name := expression.GetName(); name != nil {
+			wildcard := &WildcardImportSymbol{declaredSymbol{name}, declaration, expression}
+			file.WildcardImports = append(file.WildcardImports, wildcard)
+			file.Declarations = append(file.Declarations, wildcard)
+			b.bindings[expression] = wildcard
+		}
+	}
+}
+
+func (b *Binder) declareResource(declaration *syntax.ResourceDeclarationSyntax, parent *ResourceSymbol) *ResourceSymbol {
+	resource := &ResourceSymbol{
+		declaredSymbol: declaredSymbol{declaration.Name},
+		Declaration:    declaration,
+		Parent:         parent,
+	}
+	b.resources[declaration] = resource
+	b.bindings[declaration] = resource
+
+	if body := declaration.TryGetBody(); body != nil {
+		for _, nested := range body.Resources() {
+			resource.NestedResources = append(resource.NestedResources, b.declareResource(nested, resource))
+		}
+		if len(resource.NestedResources) > 0 {
+			b.resourceBodies[body] = resource
+		}
+	}
+
+	return resource
+}
+
+// validateDeclarations reports duplicate and invalid names among the top-level declarations.
+// Outputs and metadata each live in their own namespace.
+func (b *Binder) validateDeclarations() {
+	var values, outputs, metadata []DeclaredSymbol
+	for _, declaration := range b.fileSymbol.Declarations {
+		switch declaration.GetKind() {
+		case SymbolKindOutput:
+			outputs = append(outputs, declaration)
+		case SymbolKindMetadata:
+			metadata = append(metadata, declaration)
+		default:
+			values = append(values, declaration)
+			if namespace := b.fileSymbol.TryGetNamespace(declaration.GetName()); namespace != nil {
+				b.addDiagnostic(symbolicNameCannotUseReservedNamespaceName(declaration.GetNameSyntax().GetSpan(), declaration.GetName(), b.namespaceNames()))
+			}
+		}
+	}
+
+	b.validateNames(values)
+	b.validateNames(outputs)
+	b.validateNames(metadata)
+}
+
+// validateNames reports names that are too long or declared more than once among symbols sharing a namespace.
+func (b *Binder) validateNames(symbols []DeclaredSymbol) {
+	counts := map[string]int{}
+	for _, symbol := range symbols {
+		if symbol.GetNameSyntax().IsValid() {
+			counts[symbol.GetName()]++
+		}
+	}
+
+	for _, symbol := range symbols {
+		nameSyntax := symbol.GetNameSyntax()
+		if !nameSyntax.IsValid() {
+			continue
+		}
+		if len(symbol.GetName()) > common.MAX_IDENTIFIER_LENGTH {
+			b.addDiagnostic(identifierTooLong(nameSyntax.GetSpan(), common.MAX_IDENTIFIER_LENGTH))
+		}
+		if counts[symbol.GetName()] > 1 {
+			b.addDiagnostic(identifierMultipleDeclarations(nameSyntax.GetSpan(), symbol.GetName()))
+		}
+	}
+}
+
+func (b *Binder) namespaceNames() []string {
+	var names []string
+	for _, namespace := range b.fileSymbol.Namespaces {
+		names = append(names, namespace.GetName())
+	}
+	return names
+}
+
+func (b *Binder) pushScope(scope *LocalScope) {
+	if len(b.scopes) > 0 {
+		parent := b.scopes[len(b.scopes)-1]
+		parent.ChildScopes = append(parent.ChildScopes, scope)
+	} else {
+		b.fileSymbol.LocalScopes = append(b.fileSymbol.LocalScopes, scope)
+	}
+	b.scopes = append(b.scopes, scope)
+	b.validateNames(scope.Locals)
+}
+
+func (b *Binder) popScope() {
+	b.scopes = b.scopes[:len(b.scopes)-1]
+}
+
+func (b *Binder) bindChildren(node syntax.SyntaxBase) {
+	for _, child := range syntax.GetChildren(node) {
+		b.bind(child)
+	}
+}
+
+func (b *Binder) bind(node syntax.SyntaxBase) {
+	switch node := node.(type) {
+	case *syntax.DecoratorSyntax:
+		b.bindDecorator(node)
+	case *syntax.VariableAccessSyntax:
+		b.bindVariableAccess(node)
+	case *syntax.FunctionCallSyntax:
+		b.bindFunctionCall(node)
+		b.bindChildren(node)
+	case *syntax.InstanceFunctionCallSyntax:
+		b.bind(node.BaseExpression)
+		b.bindInstanceFunctionCall(node)
+		for _, argument := range node.Arguments {
+			b.bind(argument)
+		}
+	case *syntax.ResourceAccessSyntax:
+		b.bind(node.BaseExpression)
+		b.bindResourceAccess(node)
+	case *syntax.TypeVariableAccessSyntax:
+		b.bindTypeVariableAccess(node)
+	case *syntax.TypePropertyAccessSyntax:
+		b.bindTypePropertyAccess(node)
+	case *syntax.ForSyntax:
+		b.bindFor(node)
+	case *syntax.LambdaSyntax:
+		b.bindLambda(node)
+	case *syntax.TypedLambdaSyntax:
+		b.bindTypedLambda(node)
+	case *syntax.ObjectSyntax:
+		if resource, ok := b.resourceBodies[node]; ok {
+			scope := NewLocalScope(ScopeKindResource, resource.Declaration, node)
+			for _, nested := range resource.NestedResources {
+				scope.Locals = append(scope.Locals, nested)
+			}
+			b.pushScope(scope)
+			b.bindChildren(node)
+			b.popScope()
+			return
+		}
+		b.bindChildren(node)
+	default:
+		b.bindChildren(node)
+	}
+}
+
+func (b *Binder) bindFor(node *syntax.ForSyntax) {
+	// the iterable cannot reference the loop variables
+	b.bind(node.Expression)
+
+	scope := NewLocalScope(ScopeKindLoop, node, node.Body)
+	if item := node.ItemVariable(); item != nil {
+		scope.Locals = append(scope.Locals, b.declareLocal(item, LocalKindForItem))
+	}
+	if index := node.IndexVariable(); index != nil {
+		scope.Locals = append(scope.Locals, b.declareLocal(index, LocalKindForIndex))
+	}
+
+	b.pushScope(scope)
+	b.bind(node.Body)
+	b.popScope()
+}
+
+func (b *Binder) bindLambda(node *syntax.LambdaSyntax) {
+	scope := NewLocalScope(ScopeKindLambda, node, node.Body)
+	for _, variable := range node.GetLocalVariables() {
+		scope.Locals = append(scope.Locals, b.declareLocal(variable, LocalKindLambdaItem))
+	}
+
+	b.pushScope(scope)
+	b.bind(node.Body)
+	b.popScope()
+}
+
+// bindTypedLambda binds the signature of a user-defined function, then its body with only the parameters,
+// functions and types visible.
+func (b *Binder) bindTypedLambda(node *syntax.TypedLambdaSyntax) {
+	scope := NewLocalScope(ScopeKindFunction, b.GetParent(node), node.Body)
+	for _, variable := range node.GetLocalVariables() {
+		b.bind(variable.Type)
+		local := &LocalVariableSymbol{
+			declaredSymbol: declaredSymbol{variable.Name},
+			Declaration:    variable,
+			LocalKind:      LocalKindFunctionParameter,
+		}
+		b.bindings[variable] = local
+		scope.Locals = append(scope.Locals, local)
+	}
+	b.bind(node.ReturnType)
+
+	b.pushScope(scope)
+	b.inFunction = true
+	b.bind(node.Body)
+	b.inFunction = false
+	b.popScope()
+}
+
+func (b *Binder) declareLocal(variable *syntax.LocalVariableSyntax, kind LocalKind) *LocalVariableSymbol {
+	local := &LocalVariableSymbol{
+		declaredSymbol: declaredSymbol{variable.Name},
+		Declaration:    variable,
+		LocalKind:      kind,
+	}
+	b.bindings[variable] = local
+	return local
+}
+
+// lookupDeclaration finds a local or top-level symbol that expressions can reference, innermost scope first.
+// Function bodies only see the top-level functions, types and imports.
+func (b *Binder) lookupDeclaration(name string) DeclaredSymbol {
+	for i := len(b.scopes) - 1; i >= 0; i-- {
+		if local := b.scopes[i].TryGetLocal(name); local != nil {
+			return local
+		}
+	}
+
+	declaration := b.fileSymbol.TryGetDeclaration(name)
+	if b.inFunction && declaration != nil {
+		switch declaration.GetKind() {
+		case SymbolKindDeclaredFunction, SymbolKindTypeAlias, SymbolKindImported, SymbolKindWildcardImport:
+		default:
+			return nil
+		}
+	}
+	return declaration
+}
+
+// lookupNamespaceMembers finds the namespaces declaring a member accepted by the predicate.
+func (b *Binder) lookupNamespaceMembers(predicate func(*namespaces.Namespace) bool) []*NamespaceSymbol {
+	var found []*NamespaceSymbol
+	for _, namespace := range b.fileSymbol.Namespaces {
+		if predicate(namespace.Namespace) {
+			found = append(found, namespace)
+		}
+	}
+	return found
+}
+
+func (b *Binder) bindVariableAccess(node *syntax.VariableAccessSyntax) {
+	if !node.Name.IsValid() {
+		b.bindError(node, nil)
+		return
+	}
+
+	name := node.Name.IdentifierName()
+	span := node.Name.GetSpan()
+
+	if symbol := b.lookupDeclaration(name); symbol != nil {
+		switch symbol.GetKind() {
+		case SymbolKindTypeAlias, SymbolKindDeclaredFunction:
+			b.bindError(node, symbolicNameIsNotAVariableOrParameter(span, name))
+			return
+		}
+		b.bindings[node] = symbol
+		return
+	}
+
+	if namespace := b.fileSymbol.TryGetNamespace(name); namespace != nil {
+		b.bindings[node] = namespace
+		return
+	}
+
+	if b.fileSymbol.TryGetOutput(name) != nil {
+		b.bindError(node, outputReferenceNotSupported(span, name))
+		return
+	}
+
+	if found := b.lookupNamespaceMembers(func(ns *namespaces.Namespace) bool { return ns.TryGetFunction(name) != nil }); len(found) > 0 {
+		b.bindError(node, symbolicNameIsNotAVariableOrParameter(span, name))
+		return
+	}
+
+	b.bindError(node, symbolicNameDoesNotExist(span, name))
+}
+
+func (b *Binder) bindFunctionCall(node *syntax.FunctionCallSyntax) {
+	if !node.Name.IsValid() {
+		b.bindError(node, nil)
+		return
+	}
+
+	name := node.Name.IdentifierName()
+	span := node.Name.GetSpan()
+
+	if symbol := b.lookupDeclaration(name); symbol != nil {
+		switch symbol.GetKind() {
+		case SymbolKindDeclaredFunction, SymbolKindImported:
+			// imported symbols that are not functions are reported by the type checker
+			b.bindings[node] = symbol
+		default:
+			b.bindError(node, symbolicNameIsNotAFunction(span, name))
+		}
+		return
+	}
+
+	found := b.lookupNamespaceMembers(func(ns *namespaces.Namespace) bool { return ns.TryGetFunction(name) != nil })
+	switch len(found) {
+	case 0:
+		b.bindError(node, symbolicNameDoesNotExist(span, name))
+	case 1:
+		b.bindings[node] = newFunctionSymbol(found[0], name)
+	default:
+		var names []string
+		for _, namespace := range found {
+			names = append(names, namespace.GetName())
+		}
+		b.bindError(node, ambiguousSymbolReference(span, name, names))
+	}
+}
+
+func newFunctionSymbol(namespace *NamespaceSymbol, name string) *FunctionSymbol {
+	return &FunctionSymbol{
+		Function:  namespace.Namespace.TryGetFunction(name),
+		Namespace: namespace,
+		Name:      name,
+	}
+}
+
+// bindInstanceFunctionCall binds calls qualified by a namespace, e.g. sys.concat().
+// Calls on resources are resolved by the type checker.
+func (b *Binder) bindInstanceFunctionCall(node *syntax.InstanceFunctionCallSyntax) {
+	namespace, ok := b.bindings[node.BaseExpression].(*NamespaceSymbol)
+	if !ok || !node.Name.IsValid() {
+		return
+	}
+
+	name := node.Name.IdentifierName()
+	if namespace.Namespace.TryGetFunction(name) == nil {
+		b.bindError(node, functionNotFound(node.Name.GetSpan(), name, namespace.GetName()))
+		return
+	}
+	b.bindings[node] = newFunctionSymbol(namespace, name)
+}
+
+// bindDecorator binds the decorator call against the decorators of the namespaces, then binds its arguments.
+func (b *Binder) bindDecorator(node *syntax.DecoratorSyntax) {
+	switch expression := node.Expression.(type) {
+	case *syntax.FunctionCallSyntax:
+		name := expression.Name.IdentifierName()
+		found := b.lookupNamespaceMembers(func(ns *namespaces.Namespace) bool { return ns.TryGetDecorator(name) != nil })
+		switch {
+		case !expression.Name.IsValid():
+			b.bindError(expression, nil)
+		case len(found) == 0:
+			b.bindError(expression, symbolicNameDoesNotExist(expression.Name.GetSpan(), name))
+		default:
+			b.bindings[expression] = &DecoratorSymbol{Decorator: found[0].Namespace.TryGetDecorator(name), Namespace: found[0]}
+		}
+		for _, argument := range expression.Arguments {
+			b.bind(argument)
+		}
+	case *syntax.InstanceFunctionCallSyntax:
+		b.bind(expression.BaseExpression)
+		name := expression.Name.IdentifierName()
+		if namespace, ok := b.bindings[expression.BaseExpression].(*NamespaceSymbol); ok && expression.Name.IsValid() {
+			if decorator := namespace.Namespace.TryGetDecorator(name); decorator != nil {
+				b.bindings[expression] = &DecoratorSymbol{Decorator: decorator, Namespace: namespace}
+			} else {
+				b.bindError(expression, functionNotFound(expression.Name.GetSpan(), name, namespace.GetName()))
+			}
+		}
+		for _, argument := range expression.Arguments {
+			b.bind(argument)
+		}
+	default:
+		b.bind(node.Expression)
+	}
+}
+
+// bindResourceAccess binds a nested resource reference, e.g. vnet::subnet, to the nested resource. The nested
+// resources of an instance of a resource loop, e.g. vnets[i]::subnet, are bound like those of the loop.
+// Accessing nested resources of anything but a resource is reported by the type checker.
+func (b *Binder) bindResourceAccess(node *syntax.ResourceAccessSyntax) {
+	base := node.BaseExpression
+	if access, ok := base.(*syntax.ArrayAccessSyntax); ok && !access.IsSafeAccess() {
+		if resource, ok := b.bindings[access.BaseExpression].(*ResourceSymbol); ok {
+			if _, ok := resource.Declaration.Value.(*syntax.ForSyntax); ok {
+				base = access.BaseExpression
+			}
+		}
+	}
+	resource, ok := b.bindings[base].(*ResourceSymbol)
+	if !ok || !node.ResourceName.IsValid() {
+		return
+	}
+
+	name := node.ResourceName.IdentifierName()
+	if nested := resource.TryGetNestedResource(name); nested != nil {
+		b.bindings[node] = nested
+		return
+	}
+
+	var names []string
+	for _, nested := range resource.NestedResources {
+		names = append(names, nested.GetName())
+	}
+	b.bindError(node, nestedResourceNotFound(node.ResourceName.GetSpan(), resource.GetName(), name, names))
+}
+
+func (b *Binder) bindTypeVariableAccess(node *syntax.TypeVariableAccessSyntax) {
+	if !node.Name.IsValid() {
+		b.bindError(node, nil)
+		return
+	}
+
+	name := node.Name.IdentifierName()
+	span := node.Name.GetSpan()
+
+	if symbol := b.lookupDeclaration(name); symbol != nil {
+		switch symbol.GetKind() {
+		case SymbolKindTypeAlias, SymbolKindImported, SymbolKindWildcardImport:
+			// imported symbols that are not types are reported by the type checker
+			b.bindings[node] = symbol
+		default:
+			b.bindError(node, valueSymbolUsedAsType(span, name))
+		}
+		return
+	}
+
+	if found := b.lookupNamespaceMembers(func(ns *namespaces.Namespace) bool { return ns.HasType(name) }); len(found) > 0 {
+		b.bindings[node] = &AmbientTypeSymbol{Name: name, Namespace: found[0]}
+		return
+	}
+
+	b.bindError(node, symbolicNameIsNotAType(span, name, b.typeNames()))
+}
+
+// bindTypePropertyAccess binds namespace-qualified types, e.g. sys.string. Other property
+// accesses, e.g. on the properties of a user-defined object type, are resolved by the type checker.
+func (b *Binder) bindTypePropertyAccess(node *syntax.TypePropertyAccessSyntax) {
+	if base, ok := node.BaseExpression.(*syntax.TypeVariableAccessSyntax); ok && b.lookupDeclaration(base.Name.IdentifierName()) == nil {
+		if namespace := b.fileSymbol.TryGetNamespace(base.Name.IdentifierName()); namespace != nil {
+			b.bindings[base] = namespace
+
+			name := node.PropertyName.IdentifierName()
+			switch {
+			case !node.PropertyName.IsValid():
+				b.bindError(node, nil)
+			case namespace.Namespace.HasType(name):
+				b.bindings[node] = &AmbientTypeSymbol{Name: name, Namespace: namespace}
+			default:
+				b.bindError(node, symbolicNameIsNotAType(node.PropertyName.GetSpan(), name, namespace.Namespace.Types))
+			}
+			return
+		}
+	}
+
+	b.bind(node.BaseExpression)
+}
+
+// typeNames returns the names of the types that can be referenced, sorted alphabetically.
+func (b *Binder) typeNames() []string {
+	names := map[string]bool{}
+	for _, typeAlias := range b.fileSymbol.TypeAliases {
+		if typeAlias.GetNameSyntax().IsValid() {
+			names[typeAlias.GetName()] = true
+		}
+	}
+	for _, namespace := range b.fileSymbol.Namespaces {
+		for _, typeName := range namespace.Namespace.Types {
+			names[typeName] = true
+		}
+	}
+
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// TryGetResourceSymbol returns the symbol of a resource declaration, including nested ones.
+func (b *Binder) TryGetResourceSymbol(declaration *syntax.ResourceDeclarationSyntax) *ResourceSymbol {
+	return b.resources[declaration]
+}
@@ -0,0 +1,233 @@
+package semantics
+
+import (
+	"bicep-go/parser"
+	"bicep-go/syntax"
+	"bicep-go/types"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func bindText(t *testing.T, text string) *Binder {
+	p := parser.New(text)
+	program := p.Program()
+	require.Empty(t, p.GetDiagnostics())
+	return NewBinder(program)
+}
+
+func diagnosticCodes(b *Binder) []string {
+	var codes []string
+	for _, diagnostic := range b.GetDiagnostics() {
+		codes = append(codes, diagnostic.Code)
+	}
+	return codes
+}
+
+// findVariableAccess returns the n-th access of the given name in source order.
+func findVariableAccess(program syntax.SyntaxBase, name string, n int) *syntax.VariableAccessSyntax {
+	var found []*syntax.VariableAccessSyntax
+	syntax.Inspect(program, func(node syntax.SyntaxBase) bool {
+		if access, ok := node.(*syntax.VariableAccessSyntax); ok && access.Name.IdentifierName() == name {
+			found = append(found, access)
+		}
+		return true
+	})
+	if n >= len(found) {
+		return nil
+	}
+	return found[n]
+}
+
+func TestBinderDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"valid", "param p string\nvar v = p\noutput o string = v\n", nil},
+		{"undefined", "var v = missing\n", []string{"BCP057"}},
+		{"duplicate", "param a string\nvar a = 1\n", []string{"BCP028", "BCP028"}},
+		{"output and param share a name", "param a string\noutput a string = a\n", nil},
+		{"duplicate outputs", "output a int = 1\noutput a int = 2\n", []string{"BCP028", "BCP028"}},
+		{"output reference", "output o int = 1\nvar v = o\n", []string{"BCP058"}},
+		{"reserved namespace name", "var az = 1\n", []string{"BCP084"}},
+		{"not a function", "var v = 1\nvar w = v()\n", []string{"BCP059"}},
+		{"function as value", "var v = concat\n", []string{"BCP063"}},
+		{"unknown namespace function", "var v = sys.nope()\n", []string{"BCP107"}},
+		{"namespace function", "var v = sys.concat('a', 'b')\n", nil},
+		{"wildcard function", "resource r 'a/b@1' existing = {\n  name: 'r'\n}\nvar k = az.listKeys(r.id, '1')\n", nil},
+		{"unknown decorator", "@nope()\nparam p string\n", []string{"BCP057"}},
+		{"qualified decorator", "@sys.description('d')\nparam p string\n", nil},
+		{"unknown type", "param p strin\n", []string{"BCP302"}},
+		{"value used as type", "var v = 1\nparam p v\n", []string{"BCP287"}},
+		{"type used as value", "type t = string\nvar v = t\n", []string{"BCP063"}},
+		{"user-defined type", "type t = {\n  name: string\n}\nparam p t\n", nil},
+		{"qualified type", "param p sys.string\n", nil},
+		{"loop variables", "var v = [for (x, i) in range(0, 3): x + i]\n", nil},
+		{"loop variable out of scope", "var v = [for x in range(0, 3): x]\nvar w = x\n", []string{"BCP057"}},
+		{"loop iterable cannot see loop variable", "var v = [for x in x: x]\n", []string{"BCP057"}},
+		{"duplicate loop variables", "var v = [for (x, x) in range(0, 3): x]\n", []string{"BCP028", "BCP028"}},
+		{"lambda variables", "var v = map(range(0, 3), (x, i) => x + i)\n", nil},
+		{"lambda variable out of scope", "var v = map(range(0, 3), x => x)\nvar w = x\n", []string{"BCP057"}},
+		{"local shadows global", "var x = 'a'\nvar v = [for x in range(0, 3): x]\n", nil},
+		{"forward reference", "var a = b\nvar b = 1\n", nil},
+		{"nested resource", "resource vnet 'a/b@1' = {\n  name: 'v'\n  resource subnet 'c' = {\n    name: 's'\n  }\n}\noutput id string = vnet::subnet.id\n", nil},
+		{"nested resource sibling", "resource p 'a/b@1' = {\n  name: 'p'\n  resource a 'c' = {\n    name: 'a'\n  }\n  resource b 'd' = {\n    name: a.name\n  }\n}\n", nil},
+		{"nested resource out of scope", "resource p 'a/b@1' = {\n  name: 'p'\n  resource c 'c' = {\n    name: 'c'\n  }\n}\nvar v = c\n", []string{"BCP057"}},
+		{"missing nested resource", "resource p 'a/b@1' = {\n  name: 'p'\n}\nvar v = p::c\n", []string{"BCP159"}},
+		{"nested resource in loop", "resource p 'a/b@1' = [for x in range(0, 3): {\n  name: string(x)\n  resource c 'c' = {\n    name: string(x)\n  }\n}]\n", nil},
+		{"function", "func add(a int, b int) int => a + b\nvar v = add(1, 2)\n", nil},
+		{"function calls function", "func double(a int) int => a * 2\nfunc quadruple(a int) int => double(double(a))\n", nil},
+		{"function cannot reference parameter", "param p int\nfunc f() int => p\n", []string{"BCP057"}},
+		{"function cannot reference variable", "var v = 1\nfunc f() int => v\n", []string{"BCP057"}},
+		{"function parameter out of scope", "func f(a int) int => a\nvar v = a\n", []string{"BCP057"}},
+		{"function as value", "func f() int => 1\nvar v = f\n", []string{"BCP063"}},
+		{"recursive function", "func f(a int) int => f(a)\n", []string{"BCP079"}},
+		{"imports", "import {a, b as c} from './a.bicep'\nimport * as lib from './b.bicep'\nparam p c\nvar v = a\nvar w = lib.f()\n", nil},
+		{"import alias collision", "import {a} from './a.bicep'\nparam a string\n", []string{"BCP028", "BCP028"}},
+		{"function can reference import", "import {a} from './a.bicep'\nfunc f() int => a()\n", nil},
+		{"identifier too long", "var " + strings.Repeat("a", 256) + " = 1\n", []string{"BCP024"}},
+		{"local identifier too long", "var v = [for " + strings.Repeat("a", 256) + " in range(0, 3): 1]\n", []string{"BCP024"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := bindText(t, tt.input)
+			require.Equal(t, tt.expected, diagnosticCodes(b))
+		})
+	}
+}
+
+func TestBinderResolvesReferences(t *testing.T) {
+	input := `param location string
+var x = 'global'
+var items = [for x in range(0, 3): x]
+var fn = map(items, x => x)
+resource vnet 'a/b@1' = {
+  name: x
+  location: location
+  resource subnet 'c' = {
+    name: 's'
+  }
+}
+output id string = vnet::subnet.id
+`
+	b := bindText(t, input)
+	require.Empty(t, b.GetDiagnostics())
+	program := b.GetFileSymbol().Program
+
+	file := b.GetFileSymbol()
+	require.Len(t, file.Parameters, 1)
+	require.Len(t, file.Variables, 3)
+	require.Len(t, file.Resources, 1)
+	require.Len(t, file.Outputs, 1)
+	require.Len(t, file.Declarations, 6)
+	require.Len(t, file.AllResources(), 2)
+
+	require.Same(t, file.Parameters[0], b.GetSymbolInfo(findVariableAccess(program, "location", 0)))
+
+	// the loop and the lambda each declare their own x, and the resource body sees the global one
+	loopX := b.GetSymbolInfo(findVariableAccess(program, "x", 0)).(*LocalVariableSymbol)
+	require.Equal(t, LocalKindForItem, loopX.LocalKind)
+	lambdaX := b.GetSymbolInfo(findVariableAccess(program, "x", 1)).(*LocalVariableSymbol)
+	require.Equal(t, LocalKindLambdaItem, lambdaX.LocalKind)
+	require.Same(t, file.Variables[0], b.GetSymbolInfo(findVariableAccess(program, "x", 2)))
+
+	vnet := file.Resources[0]
+	require.Len(t, vnet.NestedResources, 1)
+	subnet := vnet.NestedResources[0]
+	require.Same(t, vnet, subnet.Parent)
+
+	var access *syntax.ResourceAccessSyntax
+	syntax.Inspect(program, func(node syntax.SyntaxBase) bool {
+		if node, ok := node.(*syntax.ResourceAccessSyntax); ok {
+			access = node
+		}
+		return true
+	})
+	require.Same(t, subnet, b.GetSymbolInfo(access))
+	require.Same(t, vnet, b.GetSymbolInfo(access.BaseExpression))
+
+	var call *syntax.FunctionCallSyntax
+	syntax.Inspect(program, func(node syntax.SyntaxBase) bool {
+		if node, ok := node.(*syntax.FunctionCallSyntax); ok && node.Name.IdentifierName() == "map" {
+			call = node
+		}
+		return true
+	})
+	function := b.GetSymbolInfo(call).(*FunctionSymbol)
+	require.Equal(t, "sys", function.Namespace.GetName())
+
+	// loop, lambda and nested resource scopes
+	require.Len(t, file.LocalScopes, 3)
+	require.Equal(t, ScopeKindLoop, file.LocalScopes[0].Kind)
+	require.Equal(t, ScopeKindLambda, file.LocalScopes[1].Kind)
+	require.Equal(t, ScopeKindResource, file.LocalScopes[2].Kind)
+	require.Same(t, subnet, file.LocalScopes[2].Locals[0])
+}
+
+func TestBinderCycles(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"no cycle", "var a = b\nvar b = 1\n", nil},
+		{"self reference", "var a = a\n", []string{"[8:9] Error BCP079: This expression is referencing its own declaration, which is not allowed."}},
+		{"two variables", "var a = b\nvar b = a\n", []string{
+			"[8:9] Error BCP080: The expression is involved in a cycle (\"a\" -> \"b\").",
+			"[18:19] Error BCP080: The expression is involved in a cycle (\"b\" -> \"a\").",
+		}},
+		{"through resource and output", "var a = r.name\nresource r 'a/b@1' = {\n  name: c\n}\nvar c = a\noutput o string = c\n", []string{
+			"[8:9] Error BCP080: The expression is involved in a cycle (\"a\" -> \"r\" -> \"c\").",
+			"[46:47] Error BCP080: The expression is involved in a cycle (\"r\" -> \"c\" -> \"a\").",
+			"[58:59] Error BCP080: The expression is involved in a cycle (\"c\" -> \"a\" -> \"r\").",
+		}},
+		{"parent property", "resource p 'a/b@1' = {\n  name: c.name\n}\nresource c 'a/b/c@1' = {\n  parent: p\n  name: 'c'\n}\n", []string{
+			"[31:32] Error BCP080: The expression is involved in a cycle (\"p\" -> \"c\").",
+			"[75:76] Error BCP080: The expression is involved in a cycle (\"c\" -> \"p\").",
+		}},
+		{"nested resource", "resource p 'a/b@1' = {\n  name: p::c.name\n  resource c 'c' = {\n    name: 'c'\n  }\n}\n", []string{
+			"[31:35] Error BCP080: The expression is involved in a cycle (\"p\" -> \"c\").",
+			"[52:53] Error BCP080: The expression is involved in a cycle (\"c\" -> \"p\").",
+		}},
+		{"nested resource references parent", "resource p 'a/b@1' = {\n  name: 'p'\n  resource c 'c' = {\n    name: p.name\n  }\n}\n", nil},
+		{"module", "module m 'm.bicep' = {\n  name: v\n}\nvar v = m.name\n", []string{
+			"[31:32] Error BCP080: The expression is involved in a cycle (\"m\" -> \"v\").",
+			"[43:44] Error BCP080: The expression is involved in a cycle (\"v\" -> \"m\").",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := bindText(t, tt.input)
+			var formatted []string
+			for _, diagnostic := range b.GetDiagnostics() {
+				formatted = append(formatted, diagnostic.ToString())
+			}
+			require.Equal(t, tt.expected, formatted)
+		})
+	}
+}
+
+func TestBinderTargetScope(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected types.ResourceScope
+	}{
+		{"var a = 1\n", types.ResourceScopeResourceGroup},
+		{"targetScope = 'subscription'\n", types.ResourceScopeSubscription},
+		{"targetScope = 'managementGroup'\n", types.ResourceScopeManagementGroup},
+		{"targetScope = 'tenant'\n", types.ResourceScopeTenant},
+		{"targetScope = 'desiredStateConfiguration'\n", types.ResourceScopeDesiredStateConfiguration},
+		{"targetScope = 'foo'\n", types.ResourceScopeResourceGroup},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			require.Equal(t, tt.expected, bindText(t, tt.input).GetFileSymbol().TargetScope)
+		})
+	}
+}
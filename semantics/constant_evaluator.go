@@ -0,0 +1,461 @@
+package semantics
+
+import (
+	"bicep-go/namespaces"
+	"bicep-go/syntax"
+	"bicep-go/types"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type EvaluationFlags int
+
+const (
+	EvaluationFlagsDefault EvaluationFlags = 0
+	// EvaluationFlagsParameterDefaults evaluates parameters to their default values. Deployments may override
+	// them, so the results are only suitable for showing in tooling, not for validation.
+	EvaluationFlagsParameterDefaults EvaluationFlags = 1 << iota
+)
+
+// ConstantEvaluator folds expressions whose value is known at compile time: literals, interpolations, operators,
+// arrays, objects, accesses into them, variables and calls to the pure functions of the sys namespace. Values are
+// nil, bool, int64, string, []any and map[string]any.
+type ConstantEvaluator struct {
+	binder     *Binder
+	flags      EvaluationFlags
+	values     map[syntax.SyntaxBase]constantValue
+	inProgress map[syntax.SyntaxBase]bool
+}
+
+type constantValue struct {
+	value any
+	ok    bool
+}
+
+func NewConstantEvaluator(binder *Binder, flags EvaluationFlags) *ConstantEvaluator {
+	return &ConstantEvaluator{
+		binder:     binder,
+		flags:      flags,
+		values:     map[syntax.SyntaxBase]constantValue{},
+		inProgress: map[syntax.SyntaxBase]bool{},
+	}
+}
+
+// TryEvaluate returns the value of an expression, or false if it is not known at compile time.
+func (e *ConstantEvaluator) TryEvaluate(expression syntax.SyntaxBase) (any, bool) {
+	if result, ok := e.values[expression]; ok {
+		return result.value, result.ok
+	}
+	if e.inProgress[expression] {
+		return nil, false
+	}
+
+	e.inProgress[expression] = true
+	value, ok := e.evaluate(expression)
+	delete(e.inProgress, expression)
+
+	e.values[expression] = constantValue{value: value, ok: ok}
+	return value, ok
+}
+
+func (e *ConstantEvaluator) evaluate(expression syntax.SyntaxBase) (any, bool) {
+	switch expression := expression.(type) {
+	case *syntax.StringSyntax:
+		return e.evaluateString(expression)
+
+	case *syntax.IntegerLiteralSyntax:
+		if expression.Value > math.MaxInt64 {
+			return nil, false
+		}
+		return int64(expression.Value), true
+
+	case *syntax.BooleanLiteralSyntax:
+		return expression.Value, true
+
+	case *syntax.NullLiteralSyntax:
+		return nil, true
+
+	case *syntax.ArraySyntax:
+		items := make([]any, 0, len(expression.Items))
+		for _, item := range expression.Items {
+			value, ok := e.TryEvaluate(item.Value)
+			if !ok {
+				return nil, false
+			}
+			items = append(items, value)
+		}
+		return items, true
+
+	case *syntax.ObjectSyntax:
+		object := map[string]any{}
+		for _, property := range expression.Properties() {
+			name, ok := property.TryGetKeyText()
+			if !ok {
+				return nil, false
+			}
+			value, ok := e.TryEvaluate(property.Value)
+			if !ok {
+				return nil, false
+			}
+			object[name] = value
+		}
+		return object, true
+
+	case *syntax.ParenthesizedExpressionSyntax:
+		return e.TryEvaluate(expression.Expression)
+
+	case *syntax.FunctionArgumentSyntax:
+		return e.TryEvaluate(expression.Expression)
+
+	case *syntax.NonNullAssertionSyntax:
+		return e.TryEvaluate(expression.BaseExpression)
+
+	case *syntax.VariableAccessSyntax:
+		switch symbol := e.binder.GetSymbolInfo(expression).(type) {
+		case *VariableSymbol:
+			return e.TryEvaluate(symbol.Declaration.Value)
+		case *ParameterSymbol:
+			if defaultValue := symbol.Declaration.DefaultValue(); defaultValue != nil && e.flags&EvaluationFlagsParameterDefaults != 0 {
+				return e.TryEvaluate(defaultValue)
+			}
+		}
+		return nil, false
+
+	case *syntax.PropertyAccessSyntax:
+		base, ok := e.TryEvaluate(expression.BaseExpression)
+		if !ok || !expression.PropertyName.IsValid() {
+			return nil, false
+		}
+		return accessConstant(base, expression.PropertyName.IdentifierName(), expression.IsSafeAccess())
+
+	case *syntax.ArrayAccessSyntax:
+		base, ok := e.TryEvaluate(expression.BaseExpression)
+		if !ok {
+			return nil, false
+		}
+		index, ok := e.TryEvaluate(expression.IndexExpression)
+		if !ok {
+			return nil, false
+		}
+		return accessConstant(base, index, expression.IsSafeAccess())
+
+	case *syntax.UnaryOperationSyntax:
+		if literal, ok := expression.Expression.(*syntax.IntegerLiteralSyntax); ok && expression.Operator == syntax.UnaryOperatorMinus && literal.Value == -math.MinInt64 {
+			return int64(math.MinInt64), true
+		}
+		operand, ok := e.TryEvaluate(expression.Expression)
+		if !ok {
+			return nil, false
+		}
+		return evaluateUnaryOperation(expression.Operator, operand)
+
+	case *syntax.BinaryOperationSyntax:
+		return e.evaluateBinaryOperation(expression)
+
+	case *syntax.TernaryOperationSyntax:
+		condition, ok := e.TryEvaluate(expression.ConditionExpression)
+		if !ok {
+			return nil, false
+		}
+		switch condition {
+		case true:
+			return e.TryEvaluate(expression.TrueExpression)
+		case false:
+			return e.TryEvaluate(expression.FalseExpression)
+		}
+		return nil, false
+
+	case *syntax.FunctionCallSyntax:
+		return e.evaluateFunctionCall(expression, expression.Arguments)
+
+	case *syntax.InstanceFunctionCallSyntax:
+		return e.evaluateFunctionCall(expression, expression.Arguments)
+	}
+	return nil, false
+}
+
+// evaluateString interpolates the values of the expressions of a string. Only scalar values are interpolated,
+// as arrays and objects are formatted as JSON by the deployment.
+func (e *ConstantEvaluator) evaluateString(expression *syntax.StringSyntax) (any, bool) {
+	var builder strings.Builder
+	for i, segment := range expression.SegmentValues {
+		builder.WriteString(segment)
+		if i >= len(expression.Expressions) {
+			continue
+		}
+		value, ok := e.TryEvaluate(expression.Expressions[i])
+		if !ok {
+			return nil, false
+		}
+		text, ok := formatScalar(value)
+		if !ok {
+			return nil, false
+		}
+		builder.WriteString(text)
+	}
+	return builder.String(), true
+}
+
+func (e *ConstantEvaluator) evaluateBinaryOperation(expression *syntax.BinaryOperationSyntax) (any, bool) {
+	left, ok := e.TryEvaluate(expression.LeftExpression)
+	if !ok {
+		return nil, false
+	}
+
+	// the right operand of short-circuiting operators does not need to be constant if it is not evaluated
+	switch expression.Operator {
+	case syntax.BinaryOperatorLogicalOr:
+		if left == true {
+			return true, true
+		}
+	case syntax.BinaryOperatorLogicalAnd:
+		if left == false {
+			return false, true
+		}
+	case syntax.BinaryOperatorCoalesce:
+		if left != nil {
+			return left, true
+		}
+	}
+
+	right, ok := e.TryEvaluate(expression.RightExpression)
+	if !ok {
+		return nil, false
+	}
+
+	switch expression.Operator {
+	case syntax.BinaryOperatorLogicalOr, syntax.BinaryOperatorLogicalAnd:
+		if _, ok := left.(bool); ok {
+			if right, ok := right.(bool); ok {
+				return right, true
+			}
+		}
+	case syntax.BinaryOperatorCoalesce:
+		return right, true
+	case syntax.BinaryOperatorEquals:
+		return reflect.DeepEqual(left, right), true
+	case syntax.BinaryOperatorNotEquals:
+		return !reflect.DeepEqual(left, right), true
+	case syntax.BinaryOperatorEqualsInsensitive, syntax.BinaryOperatorNotEqualsInsensitive:
+		leftText, leftOk := left.(string)
+		rightText, rightOk := right.(string)
+		if leftOk && rightOk {
+			return strings.EqualFold(leftText, rightText) == (expression.Operator == syntax.BinaryOperatorEqualsInsensitive), true
+		}
+	case syntax.BinaryOperatorLessThan, syntax.BinaryOperatorLessThanOrEqual, syntax.BinaryOperatorGreaterThan, syntax.BinaryOperatorGreaterThanOrEqual:
+		comparison, ok := compareConstants(left, right)
+		if !ok {
+			return nil, false
+		}
+		switch expression.Operator {
+		case syntax.BinaryOperatorLessThan:
+			return comparison < 0, true
+		case syntax.BinaryOperatorLessThanOrEqual:
+			return comparison <= 0, true
+		case syntax.BinaryOperatorGreaterThan:
+			return comparison > 0, true
+		}
+		return comparison >= 0, true
+	case syntax.BinaryOperatorAdd, syntax.BinaryOperatorSubtract, syntax.BinaryOperatorMultiply, syntax.BinaryOperatorDivide, syntax.BinaryOperatorModulo:
+		leftInt, leftOk := left.(int64)
+		rightInt, rightOk := right.(int64)
+		if leftOk && rightOk {
+			return evaluateArithmetic(expression.Operator, leftInt, rightInt)
+		}
+	}
+	return nil, false
+}
+
+// evaluateFunctionCall evaluates calls to the pure functions of the sys namespace with constant arguments.
+func (e *ConstantEvaluator) evaluateFunctionCall(call syntax.SyntaxBase, arguments []*syntax.FunctionArgumentSyntax) (any, bool) {
+	symbol, ok := e.binder.GetSymbolInfo(call).(*FunctionSymbol)
+	if !ok || symbol.Namespace.GetName() != namespaces.NAMESPACE_SYS {
+		return nil, false
+	}
+	function, ok := constantFunctions[symbol.Function.Name]
+	if !ok {
+		return nil, false
+	}
+
+	values := make([]any, 0, len(arguments))
+	for _, argument := range arguments {
+		value, ok := e.TryEvaluate(argument.Expression)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, value)
+	}
+	return function(values)
+}
+
+func evaluateUnaryOperation(operator syntax.UnaryOperator, operand any) (any, bool) {
+	switch operator {
+	case syntax.UnaryOperatorNot:
+		if value, ok := operand.(bool); ok {
+			return !value, true
+		}
+	case syntax.UnaryOperatorMinus:
+		if value, ok := operand.(int64); ok && value != math.MinInt64 {
+			return -value, true
+		}
+	}
+	return nil, false
+}
+
+// evaluateArithmetic folds integer arithmetic. Division by zero and results outside the range of int64 fail at
+// deployment, so they are not folded.
+func evaluateArithmetic(operator syntax.BinaryOperator, left int64, right int64) (any, bool) {
+	switch operator {
+	case syntax.BinaryOperatorAdd:
+		if sum := left + right; (sum > left) == (right > 0) {
+			return sum, true
+		}
+	case syntax.BinaryOperatorSubtract:
+		if difference := left - right; (difference < left) == (right > 0) {
+			return difference, true
+		}
+	case syntax.BinaryOperatorMultiply:
+		if left == 0 || right == 0 {
+			return int64(0), true
+		}
+		if product := left * right; product/right == left && !(left == math.MinInt64 && right == -1) {
+			return product, true
+		}
+	case syntax.BinaryOperatorDivide:
+		if right != 0 && !(left == math.MinInt64 && right == -1) {
+			return left / right, true
+		}
+	case syntax.BinaryOperatorModulo:
+		if right != 0 {
+			return left % right, true
+		}
+	}
+	return nil, false
+}
+
+// compareConstants compares two integers or two strings, returning a negative number if left is smaller.
+func compareConstants(left any, right any) (int, bool) {
+	switch left := left.(type) {
+	case int64:
+		if right, ok := right.(int64); ok {
+			switch {
+			case left < right:
+				return -1, true
+			case left > right:
+				return 1, true
+			}
+			return 0, true
+		}
+	case string:
+		if right, ok := right.(string); ok {
+			return strings.Compare(left, right), true
+		}
+	}
+	return 0, false
+}
+
+// accessConstant reads a property of an object or an item of an array. Safe accesses to missing properties and
+// items are null, other accesses to them fail at deployment and are not folded.
+func accessConstant(base any, index any, safe bool) (any, bool) {
+	if base == nil && safe {
+		return nil, true
+	}
+	switch base := base.(type) {
+	case map[string]any:
+		if name, ok := index.(string); ok {
+			if value, ok := base[name]; ok {
+				return value, true
+			}
+			return nil, safe
+		}
+	case []any:
+		if index, ok := index.(int64); ok {
+			if index >= 0 && index < int64(len(base)) {
+				return base[index], true
+			}
+			return nil, safe
+		}
+	}
+	return nil, false
+}
+
+// formatScalar formats a string, integer or boolean as it appears in an interpolated string.
+func formatScalar(value any) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case int64:
+		return strconv.FormatInt(value, 10), true
+	case bool:
+		return strconv.FormatBool(value), true
+	}
+	return "", false
+}
+
+// getConstantType returns the literal type of an evaluated value, or nil for objects, whose types are not literal.
+func getConstantType(value any) types.TypeSymbol {
+	switch value := value.(type) {
+	case nil:
+		return types.Null
+	case bool:
+		return types.NewBooleanLiteralType(value)
+	case int64:
+		return types.NewIntegerLiteralType(value)
+	case string:
+		return types.NewStringLiteralType(value)
+	case []any:
+		items := make([]types.TypeSymbol, 0, len(value))
+		for _, item := range value {
+			itemType := getConstantType(item)
+			if itemType == nil {
+				return nil
+			}
+			items = append(items, itemType)
+		}
+		return types.NewTupleType(items)
+	}
+	return nil
+}
+
+var identifierPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// FormatConstantValue renders an evaluated value as a Bicep expression, e.g. { name: 'app', sizes: [1, 2] }.
+// Object properties are sorted by name.
+func FormatConstantValue(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "'" + types.EscapeStringLiteral(value) + "'"
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, FormatConstantValue(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]any:
+		if len(value) == 0 {
+			return "{}"
+		}
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		properties := make([]string, 0, len(names))
+		for _, name := range names {
+			key := name
+			if !identifierPattern.MatchString(name) {
+				key = FormatConstantValue(name)
+			}
+			properties = append(properties, key+": "+FormatConstantValue(value[name]))
+		}
+		return "{ " + strings.Join(properties, ", ") + " }"
+	}
+	text, _ := formatScalar(value)
+	return text
+}
@@ -0,0 +1,100 @@
+package semantics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstantEvaluator(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"'a'", "'a'"},
+		{"-3", "-3"},
+		{"null", "null"},
+		{"[1, 'a', true]", "[1, 'a', true]"},
+		{"{ b: 1, a: { 'c-d': 'x' } }", "{ a: { 'c-d': 'x' }, b: 1 }"},
+		{"'${prefix}-${env}'", "'app-dev'"},
+		{"'${size}'", "'3'"},
+		{"1 + 2 * 3", "7"},
+		{"7 / 2", "3"},
+		{"7 % 0", ""},
+		{"9223372036854775807 + 1", ""},
+		{"-9223372036854775807 - 2", ""},
+		{"4611686018427387904 * 2", ""},
+		{"-9223372036854775808 / -1", ""},
+		{"-(-9223372036854775808)", ""},
+		{"-4611686018427387904 * 2", "-9223372036854775808"},
+		{"!true || false", "false"},
+		{"'A' =~ 'a'", "true"},
+		{"[1, 2] == [1, 2]", "true"},
+		{"'a' < 'b'", "true"},
+		{"size > 2 ? 'large' : 'small'", "'large'"},
+		{"null ?? 'x'", "'x'"},
+		{"settings.name", "'app'"},
+		{"settings.?missing", "null"},
+		{"settings.missing", ""},
+		{"settings['name']", "'app'"},
+		{"sizes[1]", "2"},
+		{"sizes[5]", ""},
+		{"length([1, 2])", "2"},
+		{"sys.length('abc')", "3"},
+		{"toLower('X')", "'x'"},
+		{"toUpper(prefix)", "'APP'"},
+		{"concat([1], [2, 3])", "[1, 2, 3]"},
+		{"concat('a', 1, true)", "'a1true'"},
+		{"substring('abcdef', 1, 3)", "'bcd'"},
+		{"substring('abc', 2, 5)", ""},
+		{"replace('a-b-c', '-', '.')", "'a.b.c'"},
+		{"startsWith('Hello', 'he')", "true"},
+		{"contains(settings, 'NAME')", "true"},
+		{"split('a,b', ',')", "['a', 'b']"},
+		{"join(['a', 'b'], '-')", "'a-b'"},
+		{"padLeft(7, 3, '0')", "'007'"},
+		{"first(sizes)", "1"},
+		{"last('abc')", "'c'"},
+		{"min(3, 1, 2)", "1"},
+		{"max(sizes)", "3"},
+		{"range(1, 3)", "[1, 2, 3]"},
+		{"empty({})", "true"},
+		{"int('42') + 1", "43"},
+		{"string(size)", "'3'"},
+		{"p", ""},
+		{"uniqueString('a')", ""},
+		{"resourceGroup().location", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			input := "param p string\nvar prefix = 'app'\nvar env = 'dev'\nvar size = 3\nvar sizes = [1, 2, 3]\nvar settings = {\n  name: prefix\n}\nvar v = " + tt.expression + "\n"
+			binder, _ := checkText(t, input)
+			evaluator := NewConstantEvaluator(binder, EvaluationFlagsDefault)
+
+			variables := binder.GetFileSymbol().Variables
+			value, ok := evaluator.TryEvaluate(variables[len(variables)-1].Declaration.Value)
+			formatted := ""
+			if ok {
+				formatted = FormatConstantValue(value)
+			}
+			require.Equal(t, tt.expected, formatted)
+		})
+	}
+}
+
+func TestConstantEvaluatorParameterDefaults(t *testing.T) {
+	binder, _ := checkText(t, "param prefix string = 'app'\nparam env string\nvar name = '${prefix}-x'\nvar full = '${prefix}-${env}'\n")
+	variables := binder.GetFileSymbol().Variables
+
+	_, ok := NewConstantEvaluator(binder, EvaluationFlagsDefault).TryEvaluate(variables[0].Declaration.Value)
+	require.False(t, ok)
+
+	evaluator := NewConstantEvaluator(binder, EvaluationFlagsParameterDefaults)
+	value, ok := evaluator.TryEvaluate(variables[0].Declaration.Value)
+	require.True(t, ok)
+	require.Equal(t, "app-x", value)
+
+	_, ok = evaluator.TryEvaluate(variables[1].Declaration.Value)
+	require.False(t, ok)
+}
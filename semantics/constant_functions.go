@@ -0,0 +1,343 @@
+package semantics
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// constantFunction evaluates a call with constant arguments, returning false if the call would fail at deployment.
+type constantFunction func(arguments []any) (any, bool)
+
+// constantFunctions are the functions of the sys namespace whose result only depends on their arguments.
+// https://learn.microsoft.com/azure/azure-resource-manager/bicep/bicep-functions
+var constantFunctions = map[string]constantFunction{
+	"length": func(arguments []any) (any, bool) {
+		if len(arguments) != 1 {
+			return nil, false
+		}
+		switch value := arguments[0].(type) {
+		case string:
+			return int64(len([]rune(value))), true
+		case []any:
+			return int64(len(value)), true
+		case map[string]any:
+			return int64(len(value)), true
+		}
+		return nil, false
+	},
+	"empty": func(arguments []any) (any, bool) {
+		if len(arguments) != 1 {
+			return nil, false
+		}
+		switch value := arguments[0].(type) {
+		case nil:
+			return true, true
+		case string:
+			return value == "", true
+		case []any:
+			return len(value) == 0, true
+		case map[string]any:
+			return len(value) == 0, true
+		}
+		return nil, false
+	},
+	"toLower": stringFunction(func(value string) any { return strings.ToLower(value) }),
+	"toUpper": stringFunction(func(value string) any { return strings.ToUpper(value) }),
+	"trim":    stringFunction(func(value string) any { return strings.TrimSpace(value) }),
+	"concat": func(arguments []any) (any, bool) {
+		if len(arguments) == 0 {
+			return nil, false
+		}
+		if _, ok := arguments[0].([]any); ok {
+			var items []any
+			for _, argument := range arguments {
+				array, ok := argument.([]any)
+				if !ok {
+					return nil, false
+				}
+				items = append(items, array...)
+			}
+			if items == nil {
+				items = []any{}
+			}
+			return items, true
+		}
+		var builder strings.Builder
+		for _, argument := range arguments {
+			text, ok := formatScalar(argument)
+			if !ok {
+				return nil, false
+			}
+			builder.WriteString(text)
+		}
+		return builder.String(), true
+	},
+	"string": func(arguments []any) (any, bool) {
+		if len(arguments) != 1 {
+			return nil, false
+		}
+		text, ok := formatScalar(arguments[0])
+		return text, ok
+	},
+	"int": func(arguments []any) (any, bool) {
+		if len(arguments) != 1 {
+			return nil, false
+		}
+		switch value := arguments[0].(type) {
+		case int64:
+			return value, true
+		case string:
+			if parsed, err := strconv.ParseInt(value, 10, 64); err == nil {
+				return parsed, true
+			}
+		}
+		return nil, false
+	},
+	"substring": func(arguments []any) (any, bool) {
+		if len(arguments) < 2 || len(arguments) > 3 {
+			return nil, false
+		}
+		value, ok := arguments[0].(string)
+		start, startOk := arguments[1].(int64)
+		if !ok || !startOk {
+			return nil, false
+		}
+		runes := []rune(value)
+		length := int64(len(runes)) - start
+		if len(arguments) == 3 {
+			if length, ok = arguments[2].(int64); !ok {
+				return nil, false
+			}
+		}
+		if start < 0 || length < 0 || start+length > int64(len(runes)) {
+			return nil, false
+		}
+		return string(runes[start : start+length]), true
+	},
+	"replace": func(arguments []any) (any, bool) {
+		values, ok := stringArguments(arguments, 3)
+		if !ok {
+			return nil, false
+		}
+		return strings.ReplaceAll(values[0], values[1], values[2]), true
+	},
+	// startsWith, endsWith, indexOf and lastIndexOf compare strings case-insensitively.
+	"startsWith": func(arguments []any) (any, bool) {
+		values, ok := stringArguments(arguments, 2)
+		if !ok {
+			return nil, false
+		}
+		return strings.HasPrefix(strings.ToLower(values[0]), strings.ToLower(values[1])), true
+	},
+	"endsWith": func(arguments []any) (any, bool) {
+		values, ok := stringArguments(arguments, 2)
+		if !ok {
+			return nil, false
+		}
+		return strings.HasSuffix(strings.ToLower(values[0]), strings.ToLower(values[1])), true
+	},
+	"indexOf": func(arguments []any) (any, bool) {
+		values, ok := stringArguments(arguments, 2)
+		if !ok {
+			return nil, false
+		}
+		return int64(strings.Index(strings.ToLower(values[0]), strings.ToLower(values[1]))), true
+	},
+	"lastIndexOf": func(arguments []any) (any, bool) {
+		values, ok := stringArguments(arguments, 2)
+		if !ok {
+			return nil, false
+		}
+		return int64(strings.LastIndex(strings.ToLower(values[0]), strings.ToLower(values[1]))), true
+	},
+	"contains": func(arguments []any) (any, bool) {
+		if len(arguments) != 2 {
+			return nil, false
+		}
+		switch container := arguments[0].(type) {
+		case string:
+			if value, ok := formatScalar(arguments[1]); ok {
+				return strings.Contains(container, value), true
+			}
+		case []any:
+			for _, item := range container {
+				if reflect.DeepEqual(item, arguments[1]) {
+					return true, true
+				}
+			}
+			return false, true
+		case map[string]any:
+			if key, ok := arguments[1].(string); ok {
+				for name := range container {
+					if strings.EqualFold(name, key) {
+						return true, true
+					}
+				}
+				return false, true
+			}
+		}
+		return nil, false
+	},
+	"split": func(arguments []any) (any, bool) {
+		values, ok := stringArguments(arguments, 2)
+		if !ok {
+			return nil, false
+		}
+		var items []any
+		for _, part := range strings.Split(values[0], values[1]) {
+			items = append(items, part)
+		}
+		return items, true
+	},
+	"join": func(arguments []any) (any, bool) {
+		if len(arguments) != 2 {
+			return nil, false
+		}
+		items, ok := arguments[0].([]any)
+		delimiter, delimiterOk := arguments[1].(string)
+		if !ok || !delimiterOk {
+			return nil, false
+		}
+		parts := make([]string, 0, len(items))
+		for _, item := range items {
+			text, ok := formatScalar(item)
+			if !ok {
+				return nil, false
+			}
+			parts = append(parts, text)
+		}
+		return strings.Join(parts, delimiter), true
+	},
+	"padLeft": func(arguments []any) (any, bool) {
+		if len(arguments) < 2 || len(arguments) > 3 {
+			return nil, false
+		}
+		value, ok := formatScalar(arguments[0])
+		width, widthOk := arguments[1].(int64)
+		if !ok || !widthOk {
+			return nil, false
+		}
+		padding := " "
+		if len(arguments) == 3 {
+			if padding, ok = arguments[2].(string); !ok || len([]rune(padding)) != 1 {
+				return nil, false
+			}
+		}
+		if missing := int(width) - len([]rune(value)); missing > 0 {
+			value = strings.Repeat(padding, missing) + value
+		}
+		return value, true
+	},
+	"first": func(arguments []any) (any, bool) {
+		return endItem(arguments, true)
+	},
+	"last": func(arguments []any) (any, bool) {
+		return endItem(arguments, false)
+	},
+	"min": func(arguments []any) (any, bool) {
+		return extremeInt(arguments, func(a int64, b int64) bool { return a < b })
+	},
+	"max": func(arguments []any) (any, bool) {
+		return extremeInt(arguments, func(a int64, b int64) bool { return a > b })
+	},
+	"range": func(arguments []any) (any, bool) {
+		if len(arguments) != 2 {
+			return nil, false
+		}
+		start, ok := arguments[0].(int64)
+		count, countOk := arguments[1].(int64)
+		if !ok || !countOk || count < 0 {
+			return nil, false
+		}
+		items := make([]any, 0, count)
+		for i := int64(0); i < count; i++ {
+			items = append(items, start+i)
+		}
+		return items, true
+	},
+	"coalesce": func(arguments []any) (any, bool) {
+		for _, argument := range arguments {
+			if argument != nil {
+				return argument, true
+			}
+		}
+		return nil, true
+	},
+}
+
+func stringFunction(function func(value string) any) constantFunction {
+	return func(arguments []any) (any, bool) {
+		values, ok := stringArguments(arguments, 1)
+		if !ok {
+			return nil, false
+		}
+		return function(values[0]), true
+	}
+}
+
+// stringArguments returns the arguments as strings if there are count of them and they are all strings.
+func stringArguments(arguments []any, count int) ([]string, bool) {
+	if len(arguments) != count {
+		return nil, false
+	}
+	values := make([]string, 0, count)
+	for _, argument := range arguments {
+		value, ok := argument.(string)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, value)
+	}
+	return values, true
+}
+
+// endItem returns the first or last item of an array or character of a string. It is null for empty arrays.
+func endItem(arguments []any, first bool) (any, bool) {
+	if len(arguments) != 1 {
+		return nil, false
+	}
+	switch value := arguments[0].(type) {
+	case string:
+		runes := []rune(value)
+		if len(runes) == 0 {
+			return "", true
+		}
+		if first {
+			return string(runes[0]), true
+		}
+		return string(runes[len(runes)-1]), true
+	case []any:
+		if len(value) == 0 {
+			return nil, true
+		}
+		if first {
+			return value[0], true
+		}
+		return value[len(value)-1], true
+	}
+	return nil, false
+}
+
+// extremeInt returns the integer argument, or item of a single array argument, that wins every comparison.
+func extremeInt(arguments []any, wins func(a int64, b int64) bool) (any, bool) {
+	if len(arguments) == 1 {
+		if items, ok := arguments[0].([]any); ok {
+			arguments = items
+		}
+	}
+	if len(arguments) == 0 {
+		return nil, false
+	}
+	var result int64
+	for i, argument := range arguments {
+		value, ok := argument.(int64)
+		if !ok {
+			return nil, false
+		}
+		if i == 0 || wins(value, result) {
+			result = value
+		}
+	}
+	return result, true
+}
@@ -0,0 +1,178 @@
+package semantics
+
+import "bicep-go/syntax"
+
+// declarationReference is a reference from the body of one declaration to another declaration.
+type declarationReference struct {
+	Target DeclaredSymbol
+	// Syntax is the referencing expression, or nil for the implicit reference of a nested resource to its parent.
+	Syntax syntax.SyntaxBase
+}
+
+// checkCycles reports declarations that reference themselves, directly or through other declarations,
+// and rebinds the references involved to errors so that later passes do not follow them.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/CyclicCheckVisitor.cs
+func (b *Binder) checkCycles() {
+	graph := b.getDeclarationGraph()
+
+	for _, cycle := range findCycles(b.getGraphDeclarations(), graph) {
+		if len(cycle) == 1 {
+			for _, reference := range graph[cycle[0]] {
+				if reference.Target == cycle[0] && reference.Syntax != nil {
+					b.bindError(reference.Syntax, cyclicSelfReference(reference.Syntax.GetSpan()))
+				}
+			}
+			continue
+		}
+
+		for i, symbol := range cycle {
+			next := cycle[(i+1)%len(cycle)]
+			path := make([]string, 0, len(cycle))
+			for j := range cycle {
+				path = append(path, cycle[(i+j)%len(cycle)].GetName())
+			}
+
+			reported := false
+			for _, reference := range graph[symbol] {
+				if reference.Target != next || reference.Syntax == nil {
+					continue
+				}
+				b.bindError(reference.Syntax, cyclicExpression(reference.Syntax.GetSpan(), path))
+				reported = true
+			}
+			if !reported {
+				b.addDiagnostic(cyclicExpression(symbol.GetNameSyntax().GetSpan(), path))
+			}
+		}
+	}
+}
+
+// getGraphDeclarations returns the declarations that can take part in cycles, including nested resources, in source order.
+func (b *Binder) getGraphDeclarations() []DeclaredSymbol {
+	var declarations []DeclaredSymbol
+	for _, declaration := range b.fileSymbol.Declarations {
+		switch declaration := declaration.(type) {
+		case *TypeAliasSymbol, *MetadataSymbol:
+			continue
+		case *ResourceSymbol:
+			var add func(*ResourceSymbol)
+			add = func(resource *ResourceSymbol) {
+				declarations = append(declarations, resource)
+				for _, nested := range resource.NestedResources {
+					add(nested)
+				}
+			}
+			add(declaration)
+		default:
+			declarations = append(declarations, declaration)
+		}
+	}
+	return declarations
+}
+
+// getDeclarationGraph collects the references from every declaration to the declarations it uses.
+// References in the body of a nested resource belong to the nested resource, which in turn
+// implicitly references its parent.
+func (b *Binder) getDeclarationGraph() map[DeclaredSymbol][]*declarationReference {
+	graph := map[DeclaredSymbol][]*declarationReference{}
+
+	for _, declaration := range b.getGraphDeclarations() {
+		root := declaration.GetDeclaringSyntax()
+		var references []*declarationReference
+		if resource, ok := declaration.(*ResourceSymbol); ok && resource.Parent != nil {
+			references = append(references, &declarationReference{Target: resource.Parent})
+		}
+
+		syntax.Inspect(root, func(node syntax.SyntaxBase) bool {
+			if nested, ok := node.(*syntax.ResourceDeclarationSyntax); ok && nested != root {
+				return false
+			}
+			switch node.(type) {
+			case *syntax.VariableAccessSyntax, *syntax.ResourceAccessSyntax, *syntax.FunctionCallSyntax:
+				if target, ok := b.bindings[node].(DeclaredSymbol); ok && isCycleTarget(target) {
+					references = append(references, &declarationReference{Target: target, Syntax: node})
+					// the base of a nested resource access, e.g. vnet in vnet::subnet, is not a reference of its own
+					return false
+				}
+			}
+			return true
+		})
+
+		graph[declaration] = references
+	}
+
+	return graph
+}
+
+func isCycleTarget(symbol DeclaredSymbol) bool {
+	switch symbol.GetKind() {
+	case SymbolKindParameter, SymbolKindVariable, SymbolKindResource, SymbolKindModule, SymbolKindOutput, SymbolKindDeclaredFunction:
+		return true
+	}
+	return false
+}
+
+// findCycles returns the cycles of the graph, searching from the declarations in order. Each cycle starts
+// at the declaration through which the search entered it, and a declaration is part of at most one cycle.
+func findCycles(declarations []DeclaredSymbol, graph map[DeclaredSymbol][]*declarationReference) [][]DeclaredSymbol {
+	var cycles [][]DeclaredSymbol
+	inCycle := map[DeclaredSymbol]bool{}
+	visited := map[DeclaredSymbol]bool{}
+	// path is the chain of declarations being visited, outermost first
+	var path []DeclaredSymbol
+	onPath := map[DeclaredSymbol]bool{}
+
+	var visit func(DeclaredSymbol)
+	visit = func(symbol DeclaredSymbol) {
+		visited[symbol] = true
+		path = append(path, symbol)
+		onPath[symbol] = true
+
+		for _, reference := range graph[symbol] {
+			target := reference.Target
+			if onPath[target] {
+				cycle := pathFrom(path, target)
+				if !anyInCycle(cycle, inCycle) {
+					for _, member := range cycle {
+						inCycle[member] = true
+					}
+					cycles = append(cycles, cycle)
+				}
+				continue
+			}
+			if !visited[target] {
+				visit(target)
+			}
+		}
+
+		path = path[:len(path)-1]
+		delete(onPath, symbol)
+	}
+
+	for _, declaration := range declarations {
+		if !visited[declaration] {
+			visit(declaration)
+		}
+	}
+
+	return cycles
+}
+
+// pathFrom returns a copy of the path starting at the given declaration.
+func pathFrom(path []DeclaredSymbol, from DeclaredSymbol) []DeclaredSymbol {
+	for i, symbol := range path {
+		if symbol == from {
+			return append([]DeclaredSymbol{}, path[i:]...)
+		}
+	}
+	return nil
+}
+
+func anyInCycle(cycle []DeclaredSymbol, inCycle map[DeclaredSymbol]bool) bool {
+	for _, symbol := range cycle {
+		if inCycle[symbol] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,397 @@
+package semantics
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/namespaces"
+	"bicep-go/syntax"
+	"bicep-go/types"
+	"bicep-go/util"
+)
+
+const minimumBatchSize = 1
+
+// validateDecorators checks the decorators of every declaration and type member: that they can be
+// attached to the declaration and its type, that each is used once, and that their arguments are valid.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/TypeSystem/TypeAssignmentVisitor.cs
+func (m *TypeManager) validateDecorators(program *syntax.ProgramSyntax) {
+	syntax.Inspect(program, func(node syntax.SyntaxBase) bool {
+		if decorable, ok := node.(syntax.DecorableSyntax); ok {
+			m.validateDecorable(decorable)
+		}
+		return true
+	})
+}
+
+func (m *TypeManager) validateDecorable(target syntax.DecorableSyntax) {
+	flag, isDeclaration := getDecoratorTargetFlag(target)
+	applied := map[*namespaces.Decorator]bool{}
+	constraints := map[string]int64{}
+
+	for _, decorator := range target.Decorators() {
+		arguments := decorator.Arguments()
+		argumentTypes := m.getArgumentTypes(arguments)
+
+		symbol, ok := m.binder.GetSymbolInfo(decorator.Expression).(*DecoratorSymbol)
+		if !ok || !isDeclaration {
+			// unknown decorators are reported by the binder, decorators without a declaration by the parser
+			continue
+		}
+		if !symbol.Decorator.Overload.HasFlag(flag) {
+			m.addDiagnostic(cannotUseDecorator(decorator.Expression.GetSpan(), target, symbol.Decorator.Name))
+			continue
+		}
+		if applied[symbol.Decorator] {
+			m.addDiagnostic(duplicateDecorator(decorator.Expression.GetSpan(), symbol.Decorator.Name))
+			continue
+		}
+		applied[symbol.Decorator] = true
+
+		name := getDecoratorNameSyntax(decorator)
+		if _, ok := m.resolveOverloads([]*namespaces.FunctionOverload{symbol.Decorator.Overload}, name, arguments, argumentTypes).(*types.ErrorType); ok {
+			continue
+		}
+		if !m.validateDecoratorTarget(target, decorator, symbol.Decorator, argumentTypes) {
+			continue
+		}
+
+		if len(argumentTypes) == 1 {
+			if value, ok := argumentTypes[0].(*types.IntegerLiteralType); ok {
+				constraints[symbol.Decorator.Name] = value.Value
+			}
+		}
+	}
+
+	if parameter, ok := target.(*syntax.ParameterDeclarationSyntax); ok {
+		if defaultValue := parameter.DefaultValue(); defaultValue != nil {
+			m.validateConstraints(defaultValue, constraints)
+		}
+	}
+}
+
+// getDecoratorTargetFlag returns the flag decorators need to be attached to the target. The second result is
+// false for decorators that are not followed by a declaration.
+func getDecoratorTargetFlag(target syntax.DecorableSyntax) (namespaces.FunctionFlags, bool) {
+	switch target.(type) {
+	case *syntax.ParameterDeclarationSyntax:
+		return namespaces.FunctionFlagsParameterDecorator, true
+	case *syntax.VariableDeclarationSyntax:
+		return namespaces.FunctionFlagsVariableDecorator, true
+	case *syntax.ResourceDeclarationSyntax:
+		return namespaces.FunctionFlagsResourceDecorator, true
+	case *syntax.ModuleDeclarationSyntax:
+		return namespaces.FunctionFlagsModuleDecorator, true
+	case *syntax.OutputDeclarationSyntax:
+		return namespaces.FunctionFlagsOutputDecorator, true
+	case *syntax.TypeDeclarationSyntax, *syntax.ObjectTypePropertySyntax, *syntax.ObjectTypeAdditionalPropertiesSyntax, *syntax.TupleTypeItemSyntax:
+		return namespaces.FunctionFlagsTypeDecorator, true
+	case *syntax.FunctionDeclarationSyntax:
+		return namespaces.FunctionFlagsFunctionDecorator, true
+	case *syntax.MissingDeclarationSyntax:
+		return namespaces.FunctionFlagsDefault, false
+	}
+	return namespaces.FunctionFlagsDefault, true
+}
+
+func cannotUseDecorator(span *util.TextSpan, target syntax.DecorableSyntax, decoratorName string) *diagnostics.Diagnostic {
+	switch target.(type) {
+	case *syntax.ParameterDeclarationSyntax:
+		return cannotUseFunctionAsParameterDecorator(span, decoratorName)
+	case *syntax.VariableDeclarationSyntax:
+		return cannotUseFunctionAsVariableDecorator(span, decoratorName)
+	case *syntax.ResourceDeclarationSyntax:
+		return cannotUseFunctionAsResourceDecorator(span, decoratorName)
+	case *syntax.ModuleDeclarationSyntax:
+		return cannotUseFunctionAsModuleDecorator(span, decoratorName)
+	case *syntax.OutputDeclarationSyntax:
+		return cannotUseFunctionAsOutputDecorator(span, decoratorName)
+	case *syntax.TypeDeclarationSyntax, *syntax.ObjectTypePropertySyntax, *syntax.ObjectTypeAdditionalPropertiesSyntax, *syntax.TupleTypeItemSyntax:
+		return cannotUseFunctionAsTypeDecorator(span, decoratorName)
+	}
+	return decoratorsNotAllowed(span)
+}
+
+func getDecoratorNameSyntax(decorator *syntax.DecoratorSyntax) *syntax.IdentifierSyntax {
+	switch expression := decorator.Expression.(type) {
+	case *syntax.FunctionCallSyntax:
+		return expression.Name
+	case *syntax.InstanceFunctionCallSyntax:
+		return expression.Name
+	}
+	return nil
+}
+
+// getDecoratorTargetType returns the type of the value the decorator applies to, or nil for resources and modules.
+// Parameters are checked against the type they are declared with, before @allowed narrows it.
+func (m *TypeManager) getDecoratorTargetType(target syntax.DecorableSyntax) types.TypeSymbol {
+	switch target := target.(type) {
+	case *syntax.ParameterDeclarationSyntax:
+		return m.GetDeclaredType(target.Type)
+	case *syntax.VariableDeclarationSyntax:
+		return m.GetTypeInfo(target.Value)
+	case *syntax.OutputDeclarationSyntax:
+		return m.GetDeclaredType(target)
+	case *syntax.TypeDeclarationSyntax:
+		return m.GetDeclaredType(target)
+	case *syntax.ObjectTypePropertySyntax:
+		return m.GetDeclaredType(target.Value)
+	case *syntax.ObjectTypeAdditionalPropertiesSyntax:
+		return m.GetDeclaredType(target.Value)
+	case *syntax.TupleTypeItemSyntax:
+		return m.GetDeclaredType(target.Value)
+	}
+	return nil
+}
+
+// validateDecoratorTarget checks the decorator against the declaration it is attached to, and reports whether it is valid.
+func (m *TypeManager) validateDecoratorTarget(target syntax.DecorableSyntax, decorator *syntax.DecoratorSyntax, symbol *namespaces.Decorator, argumentTypes []types.TypeSymbol) bool {
+	span := decorator.Expression.GetSpan()
+
+	if targetType := m.getDecoratorTargetType(target); targetType != nil {
+		targetType = types.RemoveNullability(targetType)
+		if !types.AreTypesAssignable(targetType, symbol.AttachableType) {
+			m.addDiagnostic(cannotAttachDecoratorToTarget(span, symbol.Name, symbol.AttachableType, targetType))
+			return false
+		}
+		if symbol.Name == namespaces.DECORATOR_DISCRIMINATOR && !isObjectUnion(targetType) && !isDiscriminatedObject(targetType) {
+			m.addDiagnostic(invalidDiscriminatorDecoratorTarget(span))
+			return false
+		}
+	}
+
+	switch symbol.Name {
+	case namespaces.DECORATOR_EXPORT:
+		if _, ok := target.(syntax.NamedDeclarationSyntax); !ok {
+			m.addDiagnostic(exportDecoratorMustTargetStatement(span))
+			return false
+		}
+	case namespaces.DECORATOR_BATCH_SIZE:
+		if _, ok := getDeclarationValue(target).(*syntax.ForSyntax); !ok {
+			m.addDiagnostic(batchSizeNotAllowed(span, symbol.Name))
+			return false
+		}
+		if value, ok := argumentTypes[0].(*types.IntegerLiteralType); ok && value.Value < minimumBatchSize {
+			m.addDiagnostic(batchSizeTooSmall(decorator.Arguments()[0].GetSpan(), value.Value, minimumBatchSize))
+			return false
+		}
+	case namespaces.DECORATOR_ALLOWED:
+		if parameter, ok := target.(*syntax.ParameterDeclarationSyntax); ok {
+			return m.validateAllowedValues(parameter, decorator.Arguments()[0].Expression)
+		}
+	}
+	return true
+}
+
+func getDeclarationValue(target syntax.DecorableSyntax) syntax.SyntaxBase {
+	switch target := target.(type) {
+	case *syntax.ResourceDeclarationSyntax:
+		return target.Value
+	case *syntax.ModuleDeclarationSyntax:
+		return target.Value
+	}
+	return nil
+}
+
+// isObjectUnion reports whether the type is a union whose members are all object types or discriminated unions of them.
+func isObjectUnion(typeSymbol types.TypeSymbol) bool {
+	union, ok := typeSymbol.(*types.UnionType)
+	if !ok {
+		return false
+	}
+	for _, member := range union.Members {
+		switch member.(type) {
+		case *types.ObjectType, *types.DiscriminatedObjectType:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isDiscriminatedObject(typeSymbol types.TypeSymbol) bool {
+	_, ok := typeSymbol.(*types.DiscriminatedObjectType)
+	return ok
+}
+
+// getDecoratedType applies the decorators that change the type of a declaration or type member to its type.
+// Only @discriminator does so; @allowed narrows parameters separately, as it depends on their default values.
+func (m *TypeManager) getDecoratedType(target syntax.DecorableSyntax, declaredType types.TypeSymbol) types.TypeSymbol {
+	if discriminatedType := m.tryGetDiscriminatedType(target, declaredType); discriminatedType != nil {
+		return discriminatedType
+	}
+	return declaredType
+}
+
+// tryGetDiscriminatedType turns an object union into a union discriminated by the property named by the
+// @discriminator decorator, flattening the variants of nested discriminated unions. Each variant must have
+// a unique string literal value for the property. It returns nil if the target has no valid @discriminator.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/TypeSystem/TypeAssignmentVisitor.cs
+func (m *TypeManager) tryGetDiscriminatedType(target syntax.DecorableSyntax, declaredType types.TypeSymbol) types.TypeSymbol {
+	decorator := m.binder.TryGetDecorator(target, namespaces.DECORATOR_DISCRIMINATOR)
+	if decorator == nil || len(decorator.Arguments()) != 1 {
+		return nil
+	}
+	argument := decorator.Arguments()[0]
+	key, ok := m.GetTypeInfo(argument.Expression).(*types.StringLiteralType)
+	if !ok {
+		return nil
+	}
+
+	// targets that are not object unions are reported by the decorator validation
+	var candidates []types.TypeSymbol
+	switch baseType := types.RemoveNullability(declaredType).(type) {
+	case *types.UnionType:
+		candidates = baseType.Members
+	case *types.DiscriminatedObjectType:
+		for _, member := range baseType.Members {
+			candidates = append(candidates, member)
+		}
+	default:
+		return nil
+	}
+
+	var variants []*types.ObjectType
+	for _, candidate := range candidates {
+		switch candidate := candidate.(type) {
+		case *types.ObjectType:
+			variants = append(variants, candidate)
+		case *types.DiscriminatedObjectType:
+			variants = append(variants, candidate.Members...)
+		default:
+			return nil
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, variant := range variants {
+		property := variant.TryGetProperty(key.Value)
+		if property == nil || !property.IsRequired() {
+			m.addDiagnostic(invalidDiscriminatorPropertyType(argument.GetSpan(), key.Value))
+			return nil
+		}
+		value, ok := property.Type.(*types.StringLiteralType)
+		if !ok {
+			m.addDiagnostic(invalidDiscriminatorPropertyType(argument.GetSpan(), key.Value))
+			return nil
+		}
+		if seen[value.Value] {
+			m.addDiagnostic(duplicatedDiscriminatorValue(argument.GetSpan(), key.Value, value.Value))
+			return nil
+		}
+		seen[value.Value] = true
+	}
+
+	discriminatedType := types.NewDiscriminatedObjectType(key.Value, variants)
+	if types.IsNullable(declaredType) {
+		return types.CreateNullable(discriminatedType)
+	}
+	return discriminatedType
+}
+
+// validateAllowedValues checks that the allowed values are assignable to the declared type of the parameter,
+// or to its items if the parameter is an array.
+func (m *TypeManager) validateAllowedValues(parameter *syntax.ParameterDeclarationSyntax, values syntax.SyntaxBase) bool {
+	array, ok := values.(*syntax.ArraySyntax)
+	if !ok {
+		return true
+	}
+	if len(array.Items) == 0 {
+		m.addDiagnostic(allowedMustContainItems(array.GetSpan()))
+		return false
+	}
+
+	count := len(m.diagnostics)
+	itemType := getAllowedItemType(m.GetDeclaredType(parameter.Type))
+	for _, item := range array.Items {
+		m.validateAssignment(item.Value, itemType, false, arrayTypeMismatch)
+	}
+	return len(m.diagnostics) == count
+}
+
+// getAllowedItemType returns the type allowed values must have. The values of array parameters are their items.
+func getAllowedItemType(declaredType types.TypeSymbol) types.TypeSymbol {
+	if arrayType, ok := types.RemoveNullability(declaredType).(*types.ArrayType); ok {
+		return arrayType.Item
+	}
+	return types.RemoveNullability(declaredType)
+}
+
+// tryGetAllowedType narrows the declared type of a parameter to the union of its allowed values, or to
+// an array of them for array parameters. It returns nil if the parameter has no valid @allowed decorator.
+func (m *TypeManager) tryGetAllowedType(parameter *syntax.ParameterDeclarationSyntax, declaredType types.TypeSymbol) types.TypeSymbol {
+	for _, decorator := range parameter.Decorators() {
+		symbol, ok := m.binder.GetSymbolInfo(decorator.Expression).(*DecoratorSymbol)
+		if !ok || symbol.Decorator.Name != namespaces.DECORATOR_ALLOWED || len(decorator.Arguments()) != 1 {
+			continue
+		}
+		array, ok := decorator.Arguments()[0].Expression.(*syntax.ArraySyntax)
+		if !ok || len(array.Items) == 0 {
+			return nil
+		}
+
+		itemType := getAllowedItemType(declaredType)
+		var members []types.TypeSymbol
+		for _, item := range array.Items {
+			member := m.GetTypeInfo(item.Value)
+			if !types.AreTypesAssignable(member, itemType) {
+				return nil
+			}
+			members = append(members, member)
+		}
+
+		allowedType := types.CreateUnion(members...)
+		if _, ok := types.RemoveNullability(declaredType).(*types.ArrayType); ok {
+			allowedType = types.NewArrayType(allowedType)
+		}
+		if types.IsNullable(declaredType) {
+			allowedType = types.CreateNullable(allowedType)
+		}
+		return allowedType
+	}
+	return nil
+}
+
+// validateConstraints checks a value whose length or value is known at compile time against the
+// @minLength, @maxLength, @minValue and @maxValue constraints of its declaration.
+func (m *TypeManager) validateConstraints(value syntax.SyntaxBase, constraints map[string]int64) {
+	span := value.GetSpan()
+
+	if constant, ok := m.evaluator.TryEvaluate(value); ok {
+		switch constant := constant.(type) {
+		case int64:
+			m.validateValueConstraints(span, constant, constraints)
+		case string:
+			m.validateLengthConstraints(span, int64(len([]rune(constant))), constraints)
+		case []any:
+			m.validateLengthConstraints(span, int64(len(constant)), constraints)
+		}
+		return
+	}
+
+	// the length of arrays and literal types is known even if their values are not
+	switch valueType := m.GetTypeInfo(value).(type) {
+	case *types.IntegerLiteralType:
+		m.validateValueConstraints(span, valueType.Value, constraints)
+	case *types.StringLiteralType:
+		m.validateLengthConstraints(span, int64(len([]rune(valueType.Value))), constraints)
+	case *types.TupleType:
+		m.validateLengthConstraints(span, int64(len(valueType.Items)), constraints)
+	}
+}
+
+func (m *TypeManager) validateValueConstraints(span *util.TextSpan, value int64, constraints map[string]int64) {
+	if maxValue, ok := constraints[namespaces.DECORATOR_MAX_VALUE]; ok && value > maxValue {
+		m.addDiagnostic(sourceValueTooLarge(span, value, maxValue))
+	}
+	if minValue, ok := constraints[namespaces.DECORATOR_MIN_VALUE]; ok && value < minValue {
+		m.addDiagnostic(sourceValueTooSmall(span, value, minValue))
+	}
+}
+
+func (m *TypeManager) validateLengthConstraints(span *util.TextSpan, length int64, constraints map[string]int64) {
+	if maxLength, ok := constraints[namespaces.DECORATOR_MAX_LENGTH]; ok && length > maxLength {
+		m.addDiagnostic(sourceValueTooLong(span, length, maxLength))
+	}
+	if minLength, ok := constraints[namespaces.DECORATOR_MIN_LENGTH]; ok && length < minLength {
+		m.addDiagnostic(sourceValueTooShort(span, length, minLength))
+	}
+}
@@ -0,0 +1,402 @@
+package semantics
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/namespaces"
+	"bicep-go/types"
+	"bicep-go/util"
+	"fmt"
+	"strings"
+)
+
+func quoteAll(names []string) string {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		quoted = append(quoted, fmt.Sprintf("\"%s\"", name))
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Diagnostics/DiagnosticBuilder.cs
+func invalidInteger(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP010", "Expected a valid 64-bit signed integer.")
+}
+
+func identifierTooLong(span *util.TextSpan, maxLength int) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP024", fmt.Sprintf("The identifier exceeds the limit of %d. Reduce the length of the identifier.", maxLength))
+}
+
+func identifierMultipleDeclarations(span *util.TextSpan, name string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP028", fmt.Sprintf("Identifier \"%s\" is declared multiple times. Remove or rename the duplicates.", name))
+}
+
+func symbolicNameCannotUseReservedNamespaceName(span *util.TextSpan, name string, namespaceNames []string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP084", fmt.Sprintf("The symbolic name \"%s\" is reserved. Please use a different symbolic name. Reserved namespaces are %s.", name, quoteAll(namespaceNames)))
+}
+
+func ambiguousSymbolReference(span *util.TextSpan, name string, namespaceNames []string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP056", fmt.Sprintf("The reference to name \"%s\" is ambiguous because it exists in namespaces %s. The reference must be fully-qualified.", name, quoteAll(namespaceNames)))
+}
+
+func symbolicNameDoesNotExist(span *util.TextSpan, name string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP057", fmt.Sprintf("The name \"%s\" does not exist in the current context.", name))
+}
+
+func outputReferenceNotSupported(span *util.TextSpan, name string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP058", fmt.Sprintf("The name \"%s\" is an output. Outputs cannot be referenced in expressions.", name))
+}
+
+func symbolicNameIsNotAFunction(span *util.TextSpan, name string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP059", fmt.Sprintf("The name \"%s\" is not a function.", name))
+}
+
+func symbolicNameIsNotAVariableOrParameter(span *util.TextSpan, name string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP063", fmt.Sprintf("The name \"%s\" is not a parameter, variable, resource or module.", name))
+}
+
+func cyclicSelfReference(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP079", "This expression is referencing its own declaration, which is not allowed.")
+}
+
+func cyclicExpression(span *util.TextSpan, cycle []string) *diagnostics.Diagnostic {
+	names := make([]string, 0, len(cycle))
+	for _, name := range cycle {
+		names = append(names, fmt.Sprintf("\"%s\"", name))
+	}
+	return diagnostics.NewError(span, "BCP080", fmt.Sprintf("The expression is involved in a cycle (%s).", strings.Join(names, " -> ")))
+}
+
+func functionNotFound(span *util.TextSpan, functionName string, namespaceName string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP107", fmt.Sprintf("The function \"%s\" does not exist in namespace \"%s\".", functionName, namespaceName))
+}
+
+func nestedResourceNotFound(span *util.TextSpan, resourceName string, identifierName string, nestedResourceNames []string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP159", fmt.Sprintf("The resource \"%s\" does not contain a nested resource named \"%s\". Known nested resources are: %s.", resourceName, identifierName, quoteAll(nestedResourceNames)))
+}
+
+func valueSymbolUsedAsType(span *util.TextSpan, name string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP287", fmt.Sprintf("'%s' refers to a value but is being used as a type here.", name))
+}
+
+func symbolicNameIsNotAType(span *util.TextSpan, name string, validTypes []string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP302", fmt.Sprintf("The name \"%s\" is not a valid type. Please specify one of the following types: %s.", name, quoteAll(validTypes)))
+}
+
+func propertyMultipleDeclarations(span *util.TextSpan, property string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP025", fmt.Sprintf("The property \"%s\" is declared multiple times in this object. Remove or rename the duplicate properties.", property))
+}
+
+func outputTypeMismatch(span *util.TextSpan, expectedType types.TypeSymbol, actualType types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP026", fmt.Sprintf("The output expects a value of type \"%s\" but the provided value is of type \"%s\".", expectedType.GetName(), actualType.GetName()))
+}
+
+func parameterTypeMismatch(span *util.TextSpan, expectedType types.TypeSymbol, actualType types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP027", fmt.Sprintf("The parameter expects a default value of type \"%s\" but provided value is of type \"%s\".", expectedType.GetName(), actualType.GetName()))
+}
+
+func invalidResourceType(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP029", "The resource type is not valid. Specify a valid resource type of format \"<types>@<apiVersion>\".")
+}
+
+func compileTimeConstantRequired(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP032", "The value must be a compile-time constant.")
+}
+
+func expectedValueTypeMismatch(span *util.TextSpan, expectedType types.TypeSymbol, actualType types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP033", fmt.Sprintf("Expected a value of type \"%s\" but the provided value is of type \"%s\".", expectedType.GetName(), actualType.GetName()))
+}
+
+func arrayTypeMismatch(span *util.TextSpan, expectedType types.TypeSymbol, actualType types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP034", fmt.Sprintf("The enclosing array expected an item of type \"%s\", but the provided item was of type \"%s\".", expectedType.GetName(), actualType.GetName()))
+}
+
+func missingRequiredProperties(span *util.TextSpan, blockName string, properties []string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP035", fmt.Sprintf("The specified \"%s\" declaration is missing the following required properties: %s.", blockName, quoteAll(properties)))
+}
+
+func propertyTypeMismatch(span *util.TextSpan, property string, expectedType types.TypeSymbol, actualType types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP036", fmt.Sprintf("The property \"%s\" expected a value of type \"%s\" but the provided value is of type \"%s\".", property, expectedType.GetName(), actualType.GetName()))
+}
+
+func disallowedProperty(span *util.TextSpan, property string, typeSymbol types.TypeSymbol, permissibleProperties []string) *diagnostics.Diagnostic {
+	clause := " No other properties are allowed."
+	if len(permissibleProperties) > 0 {
+		clause = fmt.Sprintf(" Permissible properties include %s.", quoteAll(permissibleProperties))
+	}
+	return diagnostics.NewError(span, "BCP037", fmt.Sprintf("The property \"%s\" is not allowed on objects of type \"%s\".%s", property, typeSymbol.GetName(), clause))
+}
+
+func unaryOperatorInvalidType(span *util.TextSpan, operator string, typeSymbol types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP044", fmt.Sprintf("Cannot apply operator \"%s\" to operand of type \"%s\".", operator, typeSymbol.GetName()))
+}
+
+func binaryOperatorInvalidType(span *util.TextSpan, operator string, leftType types.TypeSymbol, rightType types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP045", fmt.Sprintf("Cannot apply operator \"%s\" to operands of type \"%s\" and \"%s\".", operator, leftType.GetName(), rightType.GetName()))
+}
+
+func valueTypeMismatch(span *util.TextSpan, expectedType types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP046", fmt.Sprintf("Expected a value of type \"%s\".", expectedType.GetName()))
+}
+
+func cannotResolveFunctionOverload(span *util.TextSpan, overloads []*namespaces.FunctionOverload) *diagnostics.Diagnostic {
+	signatures := make([]string, 0, len(overloads))
+	for _, overload := range overloads {
+		signatures = append(signatures, overload.GetTypeSignature())
+	}
+	return diagnostics.NewError(span, "BCP048", fmt.Sprintf("Cannot resolve function overload. Candidate overloads are: %s.", strings.Join(signatures, ", ")))
+}
+
+func unknownProperty(span *util.TextSpan, typeSymbol types.TypeSymbol, property string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP052", fmt.Sprintf("The type \"%s\" does not contain property \"%s\".", typeSymbol.GetName(), property))
+}
+
+func unknownPropertyWithAvailableProperties(span *util.TextSpan, typeSymbol types.TypeSymbol, property string, availableProperties []string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP053", fmt.Sprintf("The type \"%s\" does not contain property \"%s\". Available properties include %s.", typeSymbol.GetName(), property, quoteAll(availableProperties)))
+}
+
+func objectRequiredForPropertyAccess(span *util.TextSpan, typeSymbol types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP055", fmt.Sprintf("Cannot access properties of type \"%s\". An \"object\" type is required.", typeSymbol.GetName()))
+}
+
+func propertyReadOnly(span *util.TextSpan, property string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP073", fmt.Sprintf("The property \"%s\" is read-only. Expressions cannot be assigned to read-only properties.", property))
+}
+
+func arraysRequireIntegerIndex(span *util.TextSpan, typeSymbol types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP074", fmt.Sprintf("Indexing over arrays requires an index of type \"int\" but the provided index was of type \"%s\".", typeSymbol.GetName()))
+}
+
+func objectsRequireStringIndex(span *util.TextSpan, typeSymbol types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP075", fmt.Sprintf("Indexing over objects requires an index of type \"string\" but the provided index was of type \"%s\".", typeSymbol.GetName()))
+}
+
+func indexerRequiresObjectOrArray(span *util.TextSpan, typeSymbol types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP076", fmt.Sprintf("Cannot index over expression of type \"%s\". Arrays or objects are required.", typeSymbol.GetName()))
+}
+
+func writeOnlyProperty(span *util.TextSpan, typeSymbol types.TypeSymbol, property string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP077", fmt.Sprintf("The property \"%s\" on type \"%s\" is write-only. Write-only properties cannot be accessed.", property, typeSymbol.GetName()))
+}
+
+func missingDiscriminatorProperty(span *util.TextSpan, property string, expectedType types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP078", fmt.Sprintf("The property \"%s\" requires a value of type \"%s\", but none was supplied.", property, expectedType.GetName()))
+}
+
+func argumentTypeMismatch(span *util.TextSpan, argumentType types.TypeSymbol, parameterType types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP070", fmt.Sprintf("Argument of type \"%s\" is not assignable to parameter of type \"%s\".", argumentType.GetName(), parameterType.GetName()))
+}
+
+func argumentCountMismatch(span *util.TextSpan, argumentCount int, minimumArgumentCount int, maximumArgumentCount int) *diagnostics.Diagnostic {
+	var expected string
+	switch {
+	case minimumArgumentCount == maximumArgumentCount:
+		expected = pluralizeArguments(minimumArgumentCount)
+	case maximumArgumentCount < 0:
+		expected = "at least " + pluralizeArguments(minimumArgumentCount)
+	default:
+		expected = fmt.Sprintf("%d to %d arguments", minimumArgumentCount, maximumArgumentCount)
+	}
+	return diagnostics.NewError(span, "BCP071", fmt.Sprintf("Expected %s, but got %d.", expected, argumentCount))
+}
+
+func pluralizeArguments(count int) string {
+	if count == 1 {
+		return "1 argument"
+	}
+	return fmt.Sprintf("%d arguments", count)
+}
+
+func resourceTypesUnavailable(span *util.TextSpan, reference *types.ResourceTypeReference) *diagnostics.Diagnostic {
+	return diagnostics.NewWarning(span, "BCP081", fmt.Sprintf("Resource type \"%s\" does not have types available.", reference.FormatName()))
+}
+
+func resourceTypeApiVersionUnavailable(span *util.TextSpan, reference *types.ResourceTypeReference, apiVersions []string) *diagnostics.Diagnostic {
+	return diagnostics.NewWarning(span, "BCP081", fmt.Sprintf("Resource type \"%s\" does not have types available. Available API versions are %s.", reference.FormatName(), quoteAll(apiVersions)))
+}
+
+// resourceTypeUnloadable reports types the provider has but cannot load as an error, unlike unavailable types, since
+// the configured types are broken rather than incomplete.
+func resourceTypeUnloadable(span *util.TextSpan, reference *types.ResourceTypeReference, err error) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP081", fmt.Sprintf("Resource type \"%s\" does not have types available. The types could not be loaded: %s", reference.FormatName(), err))
+}
+
+func allowedMustContainItems(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP099", "The \"allowed\" array must contain one or more items.")
+}
+
+func functionNotFoundOnType(span *util.TextSpan, typeSymbol types.TypeSymbol, functionName string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP109", fmt.Sprintf("The type \"%s\" does not contain function \"%s\".", typeSymbol.GetName(), functionName))
+}
+
+func unsupportedModuleScope(span *util.TextSpan, targetScope types.ResourceScope) *diagnostics.Diagnostic {
+	switch targetScope {
+	case types.ResourceScopeTenant:
+		return diagnostics.NewError(span, "BCP113", "Unsupported scope for module deployment in a \"tenant\" target scope. Omit this property to inherit the current scope, or specify a valid scope. Permissible scopes include tenant: tenant(), named management group: managementGroup(<name>), named subscription: subscription(<subId>), or named resource group in a named subscription: resourceGroup(<subId>, <name>).")
+	case types.ResourceScopeManagementGroup:
+		return diagnostics.NewError(span, "BCP114", "Unsupported scope for module deployment in a \"managementGroup\" target scope. Omit this property to inherit the current scope, or specify a valid scope. Permissible scopes include current management group: managementGroup(), named management group: managementGroup(<name>), named subscription: subscription(<subId>), tenant: tenant(), or named resource group in a named subscription: resourceGroup(<subId>, <name>).")
+	case types.ResourceScopeSubscription:
+		return diagnostics.NewError(span, "BCP115", "Unsupported scope for module deployment in a \"subscription\" target scope. Omit this property to inherit the current scope, or specify a valid scope. Permissible scopes include current subscription: subscription(), named subscription: subscription(<subId>), named resource group in same subscription: resourceGroup(<name>), named resource group in different subscription: resourceGroup(<subId>, <name>), or tenant: tenant().")
+	}
+	return diagnostics.NewError(span, "BCP116", "Unsupported scope for module deployment in a \"resourceGroup\" target scope. Omit this property to inherit the current scope, or specify a valid scope. Permissible scopes include current resource group: resourceGroup(), named resource group in same subscription: resourceGroup(<name>), named resource group in a different subscription: resourceGroup(<subId>, <name>), current subscription: subscription(), named subscription: subscription(<subId>) or tenant: tenant().")
+}
+
+func cannotAttachDecoratorToTarget(span *util.TextSpan, decoratorName string, attachableType types.TypeSymbol, targetType types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP124", fmt.Sprintf("The decorator \"%s\" can only be attached to targets of type \"%s\", but the target has type \"%s\".", decoratorName, attachableType.GetName(), targetType.GetName()))
+}
+
+func cannotUseFunctionAsParameterDecorator(span *util.TextSpan, functionName string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP125", fmt.Sprintf("Function \"%s\" cannot be used as a parameter decorator.", functionName))
+}
+
+func cannotUseFunctionAsVariableDecorator(span *util.TextSpan, functionName string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP126", fmt.Sprintf("Function \"%s\" cannot be used as a variable decorator.", functionName))
+}
+
+func cannotUseFunctionAsResourceDecorator(span *util.TextSpan, functionName string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP127", fmt.Sprintf("Function \"%s\" cannot be used as a resource decorator.", functionName))
+}
+
+func cannotUseFunctionAsModuleDecorator(span *util.TextSpan, functionName string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP128", fmt.Sprintf("Function \"%s\" cannot be used as a module decorator.", functionName))
+}
+
+func cannotUseFunctionAsOutputDecorator(span *util.TextSpan, functionName string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP129", fmt.Sprintf("Function \"%s\" cannot be used as an output decorator.", functionName))
+}
+
+func decoratorsNotAllowed(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP130", "Decorators are not allowed here.")
+}
+
+func invalidModuleScope(span *util.TextSpan, suppliedScope types.ResourceScope, permittedScopes types.ResourceScope) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP134", fmt.Sprintf("Scope %s is not valid for this module. Permitted scopes: %s.", quoteAll(formatResourceScopes(suppliedScope)), quoteAll(formatResourceScopes(permittedScopes))))
+}
+
+func invalidResourceScope(span *util.TextSpan, suppliedScope types.ResourceScope, permittedScopes types.ResourceScope) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP135", fmt.Sprintf("Scope %s is not valid for this resource type. Permitted scopes: %s.", quoteAll(formatResourceScopes(suppliedScope)), quoteAll(formatResourceScopes(permittedScopes))))
+}
+
+func loopArrayExpressionTypeMismatch(span *util.TextSpan, actualType types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP137", fmt.Sprintf("Loop expected an expression of type \"array\" but the provided value is of type \"%s\".", actualType.GetName()))
+}
+
+func invalidCrossScopeResourceDeployment(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP139", "A resource's scope must match the scope of the Bicep file for it to be deployable. You must use modules to deploy resources to a different scope.")
+}
+
+func directAccessToCollectionNotSupported(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP144", "Directly referencing a resource or module collection is not currently supported here. Apply an array indexer to the expression.")
+}
+
+func batchSizeTooSmall(span *util.TextSpan, value int64, limit int64) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP154", fmt.Sprintf("Expected a batch size of at least %d but the specified value was \"%d\".", limit, value))
+}
+
+func batchSizeNotAllowed(span *util.TextSpan, decoratorName string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP155", fmt.Sprintf("The decorator \"%s\" can only be attached to resource or module collections.", decoratorName))
+}
+
+func nestedResourceAccessNotSupported(span *util.TextSpan, typeSymbol types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP158", fmt.Sprintf("Cannot access nested resources of type \"%s\".", typeSymbol.GetName()))
+}
+
+func duplicateDecorator(span *util.TextSpan, decoratorName string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP166", fmt.Sprintf("Duplicate \"%s\" decorator.", decoratorName))
+}
+
+func unparsableJsonFile(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP186", "Unable to parse literal JSON value. Please ensure that it is well-formed.")
+}
+
+func jsonPathNotFound(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP235", "Specified JSONPath does not exist in the given file or is invalid.")
+}
+
+func lambdaFunctionsOnlyValidInFunctionArguments(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP242", "Lambda functions may only be specified directly as function arguments.")
+}
+
+func lambdaExpectedArgCountMismatch(span *util.TextSpan, lambdaType *types.LambdaType, actualArgCount int) *diagnostics.Diagnostic {
+	minimum, maximum := lambdaType.MinimumArgumentCount(), lambdaType.MaximumArgumentCount()
+	if minimum == maximum {
+		return diagnostics.NewError(span, "BCP244", fmt.Sprintf("Expected lambda expression of type \"%s\" with %d arguments but received %d arguments.", lambdaType.GetName(), minimum, actualArgCount))
+	}
+	return diagnostics.NewError(span, "BCP244", fmt.Sprintf("Expected lambda expression of type \"%s\" with between %d and %d arguments but received %d arguments.", lambdaType.GetName(), minimum, maximum, actualArgCount))
+}
+
+func cannotUseFunctionAsTypeDecorator(span *util.TextSpan, functionName string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP297", fmt.Sprintf("Function \"%s\" cannot be used as a type decorator.", functionName))
+}
+
+func indexOutOfBounds(span *util.TextSpan, index int64, typeSymbol types.TypeSymbol, maxIndex int) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP311", fmt.Sprintf("The provided index value of \"%d\" is not valid for type \"%s\". Indexes for this type must be between 0 and %d.", index, typeSymbol.GetName(), maxIndex))
+}
+
+func dereferenceOfPossiblyNullReference(span *util.TextSpan, possiblyNullType types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewWarning(span, "BCP318", fmt.Sprintf("The value of type \"%s\" may be null at the start of the deployment, which would cause this access expression (and the overall expression) to fail with a null dereference error. If you do not know whether the value will be null and the template would handle a null value for the overall expression, use a `.?` (safe dereference) operator to short-circuit the access expression if the base expression's value is null. If you know the value will not be null, use a non-null assertion operator to inform the compiler that the value will not be null.", possiblyNullType.GetName()))
+}
+
+func possibleNullReferenceAssignment(span *util.TextSpan, expectedType types.TypeSymbol, actualType types.TypeSymbol) *diagnostics.Diagnostic {
+	return diagnostics.NewWarning(span, "BCP321", fmt.Sprintf("Expected a value of type \"%s\" but the provided value is of type \"%s\". If you know the value will not be null, use a non-null assertion operator to inform the compiler that the value will not be null.", expectedType.GetName(), actualType.GetName()))
+}
+
+func sourceValueTooLarge(span *util.TextSpan, sourceMin int64, targetMax int64) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP327", fmt.Sprintf("The provided value (which will always be greater than or equal to %d) is too large to assign to a target for which the maximum allowable value is %d.", sourceMin, targetMax))
+}
+
+func sourceValueTooSmall(span *util.TextSpan, sourceMax int64, targetMin int64) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP328", fmt.Sprintf("The provided value (which will always be less than or equal to %d) is too small to assign to a target for which the minimum allowable value is %d.", sourceMax, targetMin))
+}
+
+func sourceValueTooLong(span *util.TextSpan, sourceMinLength int64, targetMaxLength int64) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP332", fmt.Sprintf("The provided value (whose length will always be greater than or equal to %d) is too long to assign to a target for which the maximum allowable length is %d.", sourceMinLength, targetMaxLength))
+}
+
+func sourceValueTooShort(span *util.TextSpan, sourceMaxLength int64, targetMinLength int64) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP333", fmt.Sprintf("The provided value (whose length will always be less than or equal to %d) is too short to assign to a target for which the minimum allowable length is %d.", sourceMaxLength, targetMinLength))
+}
+
+func unparsableYamlFile(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP340", "Unable to parse literal YAML value. Please ensure that it is well-formed.")
+}
+
+func functionValueRequiredAtDeploymentStart(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP341", "This expression is being used inside a function declaration, which requires a value that can be calculated at the start of the deployment.")
+}
+
+func importedSymbolNotFound(span *util.TextSpan, name string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP360", fmt.Sprintf("The \"%s\" symbol was not found in (or was not exported by) the imported template.", name))
+}
+
+func exportDecoratorMustTargetStatement(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP361", "The \"@export()\" decorator must target a top-level statement.")
+}
+
+func symbolImportedMultipleTimes(span *util.TextSpan, names []string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP362", fmt.Sprintf("This symbol is imported multiple times under the names %s.", quoteAll(names)))
+}
+
+func invalidDiscriminatorDecoratorTarget(span *util.TextSpan) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP363", "The \"discriminator\" decorator can only be applied to object-only union types with unique member types.")
+}
+
+func invalidDiscriminatorPropertyType(span *util.TextSpan, property string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP364", fmt.Sprintf("The property \"%s\" must be a required string literal on all union member types.", property))
+}
+
+func duplicatedDiscriminatorValue(span *util.TextSpan, property string, value string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP365", fmt.Sprintf("The value \"%s\" for discriminator property \"%s\" is duplicated across multiple union member types. The value must be unique across all union member types.", value, property))
+}
+
+func cannotExportVariableWithUnexportableReferences(span *util.TextSpan, names []string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP372", fmt.Sprintf("The \"@export()\" decorator may not be applied to variables that refer to parameters, modules, or resource, either directly or indirectly. The target of this decorator contains direct or transitive references to the following unexportable symbols: %s.", quoteAll(names)))
+}
+
+func importedModelContainsAmbiguousExports(span *util.TextSpan, names []string) *diagnostics.Diagnostic {
+	return diagnostics.NewError(span, "BCP374", fmt.Sprintf("The imported model cannot be loaded with a wildcard because it contains the following duplicated exports: %s.", quoteAll(names)))
+}
+
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Analyzers/Linter/Rules/NoUnnecessaryDependsOnRule.cs
+func unnecessaryDependsOn(span *util.TextSpan, name string) *diagnostics.Diagnostic {
+	return diagnostics.NewWarning(span, "no-unnecessary-dependson", fmt.Sprintf("Remove unnecessary dependsOn entry '%s'.", name))
+}
@@ -0,0 +1,122 @@
+package semantics
+
+import (
+	"bicep-go/namespaces"
+	"bicep-go/syntax"
+	"bicep-go/types"
+	"sort"
+)
+
+type ExportKind int
+
+const (
+	ExportKindType ExportKind = iota
+	ExportKindVariable
+	ExportKindFunction
+)
+
+// ExportedSymbol is a type, variable or function that a file makes available to import statements.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/Metadata/ExportMetadata.cs
+type ExportedSymbol struct {
+	Kind ExportKind
+	Name string
+	// Type is the type an exported type denotes, the type of the value of an exported variable,
+	// or the LambdaType of an exported function.
+	Type        types.TypeSymbol
+	Description string
+}
+
+// tryGetExport returns the export with the given name, or nil if there is none.
+func tryGetExport(exports []*ExportedSymbol, name string) *ExportedSymbol {
+	for _, export := range exports {
+		if export.Name == name {
+			return export
+		}
+	}
+	return nil
+}
+
+// getWildcardImportType returns the type of the namespace of a wildcard import, whose properties are the
+// exports of the given kind.
+func getWildcardImportType(name string, exports []*ExportedSymbol, kind ExportKind) *types.ObjectType {
+	var properties []*types.TypeProperty
+	for _, export := range exports {
+		if export.Kind == kind {
+			properties = append(properties, types.NewTypeProperty(export.Name, export.Type, types.TypePropertyFlagsRequired|types.TypePropertyFlagsReadOnly))
+		}
+	}
+	return types.NewObjectType(name, properties, nil)
+}
+
+// getDuplicateExportNames returns the names exported more than once, e.g. by a definition and a variable of
+// an ARM JSON template, sorted alphabetically.
+func getDuplicateExportNames(exports []*ExportedSymbol) []string {
+	counts := map[string]int{}
+	for _, export := range exports {
+		counts[export.Name]++
+	}
+	var names []string
+	for name, count := range counts {
+		if count > 1 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// TryGetDecorator returns the decorator of the given name from the sys namespace applied to the declaration,
+// or nil if there is none.
+func (b *Binder) TryGetDecorator(declaration syntax.DecorableSyntax, name string) *syntax.DecoratorSyntax {
+	for _, decorator := range declaration.Decorators() {
+		if symbol, ok := b.GetSymbolInfo(decorator.Expression).(*DecoratorSymbol); ok && symbol.Decorator.Name == name && symbol.Namespace.GetName() == namespaces.NAMESPACE_SYS {
+			return decorator
+		}
+	}
+	return nil
+}
+
+// getDescription returns the literal value of the @description() decorator of the declaration, if any.
+func (b *Binder) getDescription(declaration syntax.DecorableSyntax) string {
+	decorator := b.TryGetDecorator(declaration, namespaces.DECORATOR_DESCRIPTION)
+	if decorator == nil {
+		return ""
+	}
+	arguments := decorator.Arguments()
+	if len(arguments) != 1 {
+		return ""
+	}
+	if value, ok := arguments[0].Expression.(*syntax.StringSyntax); ok {
+		description, _ := value.TryGetLiteralValue()
+		return description
+	}
+	return ""
+}
+
+// GetExports returns the types, variables and functions of the file decorated with @export(), in source order.
+func (m *SemanticModel) GetExports() []*ExportedSymbol {
+	var exports []*ExportedSymbol
+	for _, declaration := range m.Binder.GetFileSymbol().Declarations {
+		decorable, ok := declaration.GetDeclaringSyntax().(syntax.DecorableSyntax)
+		if !ok || !declaration.GetNameSyntax().IsValid() || m.Binder.TryGetDecorator(decorable, namespaces.DECORATOR_EXPORT) == nil {
+			continue
+		}
+
+		export := &ExportedSymbol{Name: declaration.GetName(), Description: m.Binder.getDescription(decorable)}
+		switch declaration := declaration.(type) {
+		case *TypeAliasSymbol:
+			export.Kind = ExportKindType
+			export.Type = m.TypeManager.GetDeclaredType(declaration.Declaration)
+		case *VariableSymbol:
+			export.Kind = ExportKindVariable
+			export.Type = m.TypeManager.GetTypeInfo(declaration.Declaration.Value)
+		case *DeclaredFunctionSymbol:
+			export.Kind = ExportKindFunction
+			export.Type = m.TypeManager.GetDeclaredType(declaration.Declaration)
+		default:
+			continue
+		}
+		exports = append(exports, export)
+	}
+	return exports
+}
@@ -0,0 +1,238 @@
+package semantics
+
+import (
+	"bicep-go/namespaces"
+	"bicep-go/syntax"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"gopkg.in/yaml.v3"
+)
+
+// loadFile reads the file of a call to a function loading files at compile time, whose arguments evaluated to
+// values, and records the value the call is replaced with in the template.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/Namespaces/SystemNamespaceType.cs
+func (m *TypeManager) loadFile(call syntax.SyntaxBase, function *namespaces.Function, arguments []*syntax.FunctionArgumentSyntax, values []string) {
+	if m.modules == nil {
+		return
+	}
+	pathSpan := arguments[0].GetSpan()
+	data, diagnostic := m.modules.TryReadFile(m.binder.program, pathSpan, values[0])
+	if diagnostic != nil {
+		m.addDiagnostic(diagnostic)
+		return
+	}
+
+	switch function.Name {
+	case "loadFileAsBase64":
+		m.loadedFiles[call] = base64.StdEncoding.EncodeToString(data)
+
+	case "loadTextContent":
+		m.loadedFiles[call] = decodeFile(data, optionalArgument(values, 1))
+
+	case "loadJsonContent", "loadYamlContent":
+		text := decodeFile(data, optionalArgument(values, 2))
+		var root yaml.Node
+		if function.Name == "loadJsonContent" && !json.Valid([]byte(text)) {
+			m.addDiagnostic(unparsableJsonFile(pathSpan))
+			return
+		}
+		if err := yaml.Unmarshal([]byte(text), &root); err != nil {
+			if function.Name == "loadJsonContent" {
+				m.addDiagnostic(unparsableJsonFile(pathSpan))
+			} else {
+				m.addDiagnostic(unparsableYamlFile(pathSpan))
+			}
+			return
+		}
+		node := &root
+		if len(root.Content) > 0 {
+			node = root.Content[0]
+		}
+		if path := optionalArgument(values, 1); path != "" {
+			if node = selectJsonPath(node, path); node == nil {
+				m.addDiagnostic(jsonPathNotFound(arguments[1].GetSpan()))
+				return
+			}
+		}
+		var buffer bytes.Buffer
+		if !writeYamlNodeAsJson(&buffer, node) {
+			m.addDiagnostic(unparsableYamlFile(pathSpan))
+			return
+		}
+		m.loadedFiles[call] = json.RawMessage(buffer.Bytes())
+	}
+}
+
+func optionalArgument(values []string, index int) string {
+	if index < len(values) {
+		return values[index]
+	}
+	return ""
+}
+
+// decodeFile converts the content of a file to text. A byte order mark takes precedence over the encoding, which
+// defaults to UTF-8.
+func decodeFile(data []byte, encoding string) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		return string(data[3:])
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		return decodeUtf16(data[2:], false)
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		return decodeUtf16(data[2:], true)
+	}
+
+	switch encoding {
+	case namespaces.FILE_ENCODING_US_ASCII:
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+			if b > 0x7F {
+				runes[i] = '?'
+			}
+		}
+		return string(runes)
+	case namespaces.FILE_ENCODING_ISO_8859_1:
+		runes := make([]rune, len(data))
+		for i, b := range data {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	case namespaces.FILE_ENCODING_UTF_16:
+		return decodeUtf16(data, false)
+	case namespaces.FILE_ENCODING_UTF_16_BE:
+		return decodeUtf16(data, true)
+	}
+	return string(data)
+}
+
+func decodeUtf16(data []byte, bigEndian bool) string {
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// selectJsonPath returns the node a JSONPath selects, or nil if it is not found. Only the root "$", properties
+// written as ".name" or "['name']", and array indexes are supported.
+func selectJsonPath(node *yaml.Node, path string) *yaml.Node {
+	path = strings.TrimPrefix(path, "$")
+	if path != "" && path[0] != '.' && path[0] != '[' {
+		path = "." + path
+	}
+	for path != "" {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		}
+		var key string
+		switch {
+		case path[0] == '.':
+			end := strings.IndexAny(path[1:], ".[") + 1
+			if end == 0 {
+				end = len(path)
+			}
+			key, path = path[1:end], path[end:]
+			if key == "" {
+				return nil
+			}
+		case strings.HasPrefix(path, "['") || strings.HasPrefix(path, "[\""):
+			end := strings.Index(path[2:], string(path[1])+"]")
+			if end < 0 {
+				return nil
+			}
+			key, path = path[2:2+end], path[2+end+2:]
+		default:
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return nil
+			}
+			index, err := strconv.Atoi(path[1:end])
+			if err != nil || node.Kind != yaml.SequenceNode || index < 0 || index >= len(node.Content) {
+				return nil
+			}
+			node, path = node.Content[index], path[end+1:]
+			continue
+		}
+
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		var found *yaml.Node
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				found = node.Content[i+1]
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		node = found
+	}
+	return node
+}
+
+// writeYamlNodeAsJson writes a node as compact JSON, keeping the order of properties. It returns false for values
+// JSON cannot represent.
+func writeYamlNodeAsJson(buffer *bytes.Buffer, node *yaml.Node) bool {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buffer.WriteString("null")
+			return true
+		}
+		return writeYamlNodeAsJson(buffer, node.Content[0])
+
+	case yaml.AliasNode:
+		return writeYamlNodeAsJson(buffer, node.Alias)
+
+	case yaml.MappingNode:
+		buffer.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			key, _ := json.Marshal(node.Content[i].Value)
+			buffer.Write(key)
+			buffer.WriteByte(':')
+			if !writeYamlNodeAsJson(buffer, node.Content[i+1]) {
+				return false
+			}
+		}
+		buffer.WriteByte('}')
+		return true
+
+	case yaml.SequenceNode:
+		buffer.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			if !writeYamlNodeAsJson(buffer, item) {
+				return false
+			}
+		}
+		buffer.WriteByte(']')
+		return true
+	}
+
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return false
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false
+	}
+	buffer.Write(data)
+	return true
+}
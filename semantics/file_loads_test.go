@@ -0,0 +1,64 @@
+package semantics
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestDecodeFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		encoding string
+		expected string
+	}{
+		{"utf-8", []byte("h\xc3\xa9"), "", "hé"},
+		{"utf-8 byte order mark", []byte("\xef\xbb\xbfa"), "us-ascii", "a"},
+		{"us-ascii", []byte("h\xe9"), "us-ascii", "h?"},
+		{"iso-8859-1", []byte("h\xe9"), "iso-8859-1", "hé"},
+		{"utf-16", []byte{'h', 0, 0xe9, 0}, "utf-16", "hé"},
+		{"utf-16BE", []byte{0, 'h', 0, 0xe9}, "utf-16BE", "hé"},
+		{"utf-16 byte order mark", []byte{0xfe, 0xff, 0, 'h'}, "", "h"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, decodeFile(tt.data, tt.encoding))
+		})
+	}
+}
+
+func TestSelectJsonPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		expected string
+	}{
+		{"root", "$", `{"a":{"b c":[1,{"d":true}]}}`},
+		{"property", "$.a", `{"b c":[1,{"d":true}]}`},
+		{"property without root", "a", `{"b c":[1,{"d":true}]}`},
+		{"bracketed property", "$.a['b c']", `[1,{"d":true}]`},
+		{"index", "$.a['b c'][1].d", `true`},
+		{"missing property", "$.b", ""},
+		{"index out of range", "$.a['b c'][2]", ""},
+		{"wildcard", "$.*", ""},
+	}
+
+	var root yaml.Node
+	require.NoError(t, yaml.Unmarshal([]byte(`{"a": {"b c": [1, {"d": true}]}}`), &root))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := selectJsonPath(root.Content[0], tt.path)
+			if tt.expected == "" {
+				require.Nil(t, node)
+				return
+			}
+			var buffer bytes.Buffer
+			require.True(t, writeYamlNodeAsJson(&buffer, node))
+			require.Equal(t, tt.expected, buffer.String())
+		})
+	}
+}
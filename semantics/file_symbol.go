@@ -0,0 +1,84 @@
+package semantics
+
+import (
+	"bicep-go/syntax"
+	"bicep-go/types"
+)
+
+// FileSymbol holds the top-level declarations of a Bicep file and the namespaces imported into it.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/FileSymbol.cs
+type FileSymbol struct {
+	Program *syntax.ProgramSyntax
+	// TargetScope is the scope the file is deployed at, as declared by targetScope. It defaults to the resource group scope.
+	TargetScope types.ResourceScope
+	Namespaces  []*NamespaceSymbol
+	Metadata    []*MetadataSymbol
+	Parameters  []*ParameterSymbol
+	Variables   []*VariableSymbol
+	Resources   []*ResourceSymbol
+	Modules     []*ModuleSymbol
+	Outputs     []*OutputSymbol
+	TypeAliases []*TypeAliasSymbol
+	Functions   []*DeclaredFunctionSymbol
+	// Imports are the symbols imported by name, and WildcardImports the namespaces of wildcard imports.
+	Imports         []*ImportedSymbol
+	WildcardImports []*WildcardImportSymbol
+	// Declarations are the top-level declared symbols in source order.
+	Declarations []DeclaredSymbol
+	LocalScopes  []*LocalScope
+}
+
+func (s *FileSymbol) GetName() string {
+	return "<file>"
+}
+
+func (s *FileSymbol) GetKind() SymbolKind {
+	return SymbolKindFile
+}
+
+// AllResources returns the top-level resources and all of their nested resources.
+func (s *FileSymbol) AllResources() []*ResourceSymbol {
+	var resources []*ResourceSymbol
+	var add func([]*ResourceSymbol)
+	add = func(symbols []*ResourceSymbol) {
+		for _, symbol := range symbols {
+			resources = append(resources, symbol)
+			add(symbol.NestedResources)
+		}
+	}
+	add(s.Resources)
+	return resources
+}
+
+// TryGetDeclaration returns the top-level symbol with the given name that expressions can reference.
+// Outputs and metadata live in separate namespaces and are never returned.
+func (s *FileSymbol) TryGetDeclaration(name string) DeclaredSymbol {
+	for _, declaration := range s.Declarations {
+		switch declaration.GetKind() {
+		case SymbolKindOutput, SymbolKindMetadata:
+			continue
+		}
+		if declaration.GetName() == name {
+			return declaration
+		}
+	}
+	return nil
+}
+
+func (s *FileSymbol) TryGetOutput(name string) *OutputSymbol {
+	for _, output := range s.Outputs {
+		if output.GetName() == name {
+			return output
+		}
+	}
+	return nil
+}
+
+func (s *FileSymbol) TryGetNamespace(name string) *NamespaceSymbol {
+	for _, namespace := range s.Namespaces {
+		if namespace.GetName() == name {
+			return namespace
+		}
+	}
+	return nil
+}
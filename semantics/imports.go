@@ -0,0 +1,227 @@
+package semantics
+
+import (
+	"bicep-go/namespaces"
+	"bicep-go/syntax"
+	"bicep-go/types"
+)
+
+// getImportedModel returns the model of the file an import statement references, or nil if the file
+// cannot be used, which is reported once on the statement.
+func (m *TypeManager) getImportedModel(declaration *syntax.CompileTimeImportDeclarationSyntax) ModuleModel {
+	if model, ok := m.importedModels[declaration]; ok {
+		return model
+	}
+
+	model, diagnostic := m.modules.TryGetImportedModel(declaration)
+	if diagnostic != nil {
+		m.addDiagnostic(diagnostic)
+	}
+	m.importedModels[declaration] = model
+	return model
+}
+
+// tryGetImportedExport returns the export an imported symbol refers to, or nil if it cannot be resolved.
+// Without a module lookup, imported symbols are never resolved.
+func (m *TypeManager) tryGetImportedExport(symbol *ImportedSymbol) *ExportedSymbol {
+	if m.modules == nil {
+		return nil
+	}
+	model := m.getImportedModel(symbol.Declaration)
+	if model == nil {
+		return nil
+	}
+	return tryGetExport(model.GetExports(), symbol.GetOriginalSymbolName())
+}
+
+// tryGetWildcardExports returns the exports of the file a wildcard import references. The second result
+// is false if the exports are unknown.
+func (m *TypeManager) tryGetWildcardExports(symbol *WildcardImportSymbol) ([]*ExportedSymbol, bool) {
+	if m.modules == nil {
+		return nil, false
+	}
+	model := m.getImportedModel(symbol.Declaration)
+	if model == nil {
+		return nil, false
+	}
+	return model.GetExports(), true
+}
+
+// getImportedSymbolType returns the type of an imported variable used as a value.
+func (m *TypeManager) getImportedSymbolType(symbol *ImportedSymbol) types.TypeSymbol {
+	if m.modules == nil {
+		return types.Any
+	}
+	if export := m.tryGetImportedExport(symbol); export != nil && export.Kind == ExportKindVariable {
+		return export.Type
+	}
+	return types.Error
+}
+
+// getImportedTypeType returns the type denoted by an imported type used in a type expression.
+func (m *TypeManager) getImportedTypeType(node syntax.SyntaxBase, symbol *ImportedSymbol) types.TypeSymbol {
+	if m.modules == nil {
+		return types.Any
+	}
+	export := m.tryGetImportedExport(symbol)
+	if export == nil {
+		return types.Error
+	}
+	if export.Kind != ExportKindType {
+		m.addDiagnostic(valueSymbolUsedAsType(node.GetSpan(), symbol.GetName()))
+		return types.Error
+	}
+	return export.Type
+}
+
+// getWildcardImportType returns the namespace of a wildcard import as an object whose properties are the
+// exported variables, or the exported types when used in a type expression.
+func (m *TypeManager) getWildcardImportType(symbol *WildcardImportSymbol, kind ExportKind) types.TypeSymbol {
+	exports, ok := m.tryGetWildcardExports(symbol)
+	if !ok {
+		if m.modules == nil {
+			return types.Any
+		}
+		return types.Error
+	}
+	return getWildcardImportType(symbol.GetName(), exports, kind)
+}
+
+func (m *TypeManager) getImportedFunctionCallType(node *syntax.FunctionCallSyntax, symbol *ImportedSymbol, argumentTypes []types.TypeSymbol) types.TypeSymbol {
+	if m.modules == nil {
+		return types.Any
+	}
+	export := m.tryGetImportedExport(symbol)
+	if export == nil {
+		return types.Error
+	}
+	lambdaType, ok := export.Type.(*types.LambdaType)
+	if export.Kind != ExportKindFunction || !ok {
+		m.addDiagnostic(symbolicNameIsNotAFunction(node.Name.GetSpan(), symbol.GetName()))
+		return types.Error
+	}
+	return m.resolveOverloads([]*namespaces.FunctionOverload{newLambdaOverload(symbol.GetName(), nil, lambdaType)}, node.Name, node.Arguments, argumentTypes)
+}
+
+// getWildcardFunctionCallType resolves a call to a function imported with a wildcard, e.g. lib.format().
+func (m *TypeManager) getWildcardFunctionCallType(node *syntax.InstanceFunctionCallSyntax, symbol *WildcardImportSymbol, argumentTypes []types.TypeSymbol) types.TypeSymbol {
+	exports, ok := m.tryGetWildcardExports(symbol)
+	if !ok {
+		if m.modules == nil {
+			return types.Any
+		}
+		return types.Error
+	}
+	if !node.Name.IsValid() {
+		return types.Error
+	}
+
+	name := node.Name.IdentifierName()
+	for _, export := range exports {
+		if lambdaType, ok := export.Type.(*types.LambdaType); ok && export.Kind == ExportKindFunction && export.Name == name {
+			return m.resolveOverloads([]*namespaces.FunctionOverload{newLambdaOverload(name, nil, lambdaType)}, node.Name, node.Arguments, argumentTypes)
+		}
+	}
+	m.addDiagnostic(functionNotFound(node.Name.GetSpan(), name, symbol.GetName()))
+	return types.Error
+}
+
+// validateImports reports imported files that cannot be used, symbols they do not export, symbols imported
+// more than once, and wildcard imports of files exporting the same name twice.
+func (m *TypeManager) validateImports() {
+	if m.modules == nil {
+		return
+	}
+	file := m.binder.GetFileSymbol()
+
+	for _, declaration := range file.Program.Declarations() {
+		if declaration, ok := declaration.(*syntax.CompileTimeImportDeclarationSyntax); ok {
+			m.getImportedModel(declaration)
+		}
+	}
+
+	// names maps the imported files and original names of the symbols to the names they are imported under
+	names := map[ModuleModel]map[string][]*ImportedSymbol{}
+	for _, imported := range file.Imports {
+		model := m.getImportedModel(imported.Declaration)
+		if model == nil {
+			continue
+		}
+		if tryGetExport(model.GetExports(), imported.GetOriginalSymbolName()) == nil {
+			m.addDiagnostic(importedSymbolNotFound(imported.Item.OriginalSymbolName.GetSpan(), imported.GetOriginalSymbolName()))
+			continue
+		}
+		if names[model] == nil {
+			names[model] = map[string][]*ImportedSymbol{}
+		}
+		names[model][imported.GetOriginalSymbolName()] = append(names[model][imported.GetOriginalSymbolName()], imported)
+	}
+	for _, imported := range file.Imports {
+		model := m.importedModels[imported.Declaration]
+		duplicates := names[model][imported.GetOriginalSymbolName()]
+		if model == nil || len(duplicates) < 2 {
+			continue
+		}
+		var aliases []string
+		for _, duplicate := range duplicates {
+			aliases = append(aliases, duplicate.GetName())
+		}
+		m.addDiagnostic(symbolImportedMultipleTimes(imported.Item.GetSpan(), aliases))
+	}
+
+	for _, wildcard := range file.WildcardImports {
+		exports, ok := m.tryGetWildcardExports(wildcard)
+		if !ok {
+			continue
+		}
+		if duplicates := getDuplicateExportNames(exports); len(duplicates) > 0 {
+			m.addDiagnostic(importedModelContainsAmbiguousExports(wildcard.Wildcard.GetSpan(), duplicates))
+		}
+	}
+}
+
+// validateExports reports exported variables that depend on parameters, resources or modules, whose values
+// are only known during a deployment.
+func (m *TypeManager) validateExports() {
+	for _, variable := range m.binder.GetFileSymbol().Variables {
+		decorator := m.binder.TryGetDecorator(variable.Declaration, namespaces.DECORATOR_EXPORT)
+		if decorator == nil {
+			continue
+		}
+		if names := m.getUnexportableReferences(variable); len(names) > 0 {
+			m.addDiagnostic(cannotExportVariableWithUnexportableReferences(decorator.Expression.GetSpan(), names))
+		}
+	}
+}
+
+// getUnexportableReferences returns the names of the parameters, resources and modules a variable references,
+// directly or through other variables, in the order they are found.
+func (m *TypeManager) getUnexportableReferences(variable *VariableSymbol) []string {
+	var names []string
+	found := map[DeclaredSymbol]bool{}
+	visited := map[*VariableSymbol]bool{}
+
+	var visit func(*VariableSymbol)
+	visit = func(variable *VariableSymbol) {
+		if visited[variable] {
+			return
+		}
+		visited[variable] = true
+
+		syntax.Inspect(variable.Declaration.Value, func(node syntax.SyntaxBase) bool {
+			switch symbol := m.binder.GetSymbolInfo(node).(type) {
+			case *VariableSymbol:
+				visit(symbol)
+			case *ParameterSymbol, *ResourceSymbol, *ModuleSymbol:
+				if !found[symbol.(DeclaredSymbol)] {
+					found[symbol.(DeclaredSymbol)] = true
+					names = append(names, symbol.GetName())
+				}
+			}
+			return true
+		})
+	}
+
+	visit(variable)
+	return names
+}
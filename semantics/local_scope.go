@@ -0,0 +1,46 @@
+package semantics
+
+import "bicep-go/syntax"
+
+type ScopeKind int
+
+const (
+	// ScopeKindLoop holds the item and index variables of a for-expression.
+	ScopeKindLoop ScopeKind = iota
+	// ScopeKindLambda holds the parameters of a lambda.
+	ScopeKindLambda
+	// ScopeKindResource holds the resources nested in the body of a resource.
+	ScopeKindResource
+	// ScopeKindFunction holds the parameters of a user-defined function.
+	ScopeKindFunction
+)
+
+// LocalScope is a scope nested in the file, in which the locals are visible only within the binding syntax.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/LocalScope.cs
+type LocalScope struct {
+	Kind ScopeKind
+	// DeclaringSyntax is the ForSyntax, LambdaSyntax, ResourceDeclarationSyntax or FunctionDeclarationSyntax
+	// that introduces the scope.
+	DeclaringSyntax syntax.SyntaxBase
+	// BindingSyntax is the part of the declaring syntax in which the locals can be referenced.
+	BindingSyntax syntax.SyntaxBase
+	Locals        []DeclaredSymbol
+	ChildScopes   []*LocalScope
+}
+
+func NewLocalScope(kind ScopeKind, declaringSyntax syntax.SyntaxBase, bindingSyntax syntax.SyntaxBase) *LocalScope {
+	return &LocalScope{
+		Kind:            kind,
+		DeclaringSyntax: declaringSyntax,
+		BindingSyntax:   bindingSyntax,
+	}
+}
+
+func (s *LocalScope) TryGetLocal(name string) DeclaredSymbol {
+	for _, local := range s.Locals {
+		if local.GetName() == name {
+			return local
+		}
+	}
+	return nil
+}
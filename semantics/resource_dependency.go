@@ -0,0 +1,341 @@
+package semantics
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/syntax"
+)
+
+// ResourceDependency is a dependency of a resource or module on another resource or module.
+type ResourceDependency struct {
+	// Resource is the ResourceSymbol or ModuleSymbol that is depended on.
+	Resource DeclaredSymbol
+	// IndexExpression selects a single instance of a resource or module declared with a loop, e.g. i in
+	// storage[i]. It is nil when the dependency is on every instance.
+	IndexExpression syntax.SyntaxBase
+	// Explicit is set for dependencies declared by a dependsOn entry.
+	Explicit bool
+}
+
+// DependencyGraph holds the dependencies of every resource and module of a file. Dependencies are
+// inferred from the references in a declaration, looking through variables, and dependencies on
+// existing resources are replaced by the dependencies of those resources since they are not deployed.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Emit/ResourceDependencyVisitor.cs
+type DependencyGraph struct {
+	binder      *Binder
+	diagnostics []*diagnostics.Diagnostic
+
+	// direct are the dependencies of a declaration before existing resources are looked through.
+	direct map[DeclaredSymbol][]*ResourceDependency
+	// variables are the resources and modules a variable references, directly or through other variables.
+	variables map[*VariableSymbol][]*ResourceDependency
+	// resolved are the dependencies of a declaration with existing resources looked through.
+	resolved     map[DeclaredSymbol][]*ResourceDependency
+	dependencies map[DeclaredSymbol][]*ResourceDependency
+	inProgress   map[Symbol]bool
+}
+
+func NewDependencyGraph(binder *Binder) *DependencyGraph {
+	g := &DependencyGraph{
+		binder:       binder,
+		direct:       map[DeclaredSymbol][]*ResourceDependency{},
+		variables:    map[*VariableSymbol][]*ResourceDependency{},
+		resolved:     map[DeclaredSymbol][]*ResourceDependency{},
+		dependencies: map[DeclaredSymbol][]*ResourceDependency{},
+		inProgress:   map[Symbol]bool{},
+	}
+
+	declarations := g.getDeclarations()
+	for _, declaration := range declarations {
+		g.direct[declaration] = g.collectDependencies(declaration)
+	}
+	for _, declaration := range declarations {
+		g.dependencies[declaration] = g.reduce(g.getResolvedDependencies(declaration))
+		g.checkExplicitDependencies(declaration)
+	}
+
+	diagnostics.Sort(g.diagnostics)
+	return g
+}
+
+func (g *DependencyGraph) GetDiagnostics() []*diagnostics.Diagnostic {
+	return g.diagnostics
+}
+
+// GetDependencies returns the minimal set of dependencies of a resource or module: dependencies
+// that are implied by another dependency are left out. It returns nil for any other symbol.
+func (g *DependencyGraph) GetDependencies(symbol DeclaredSymbol) []*ResourceDependency {
+	return g.dependencies[symbol]
+}
+
+// getDeclarations returns every resource, including nested ones, and every module of the file.
+func (g *DependencyGraph) getDeclarations() []DeclaredSymbol {
+	var declarations []DeclaredSymbol
+	for _, resource := range g.binder.GetFileSymbol().AllResources() {
+		declarations = append(declarations, resource)
+	}
+	for _, module := range g.binder.GetFileSymbol().Modules {
+		declarations = append(declarations, module)
+	}
+	return declarations
+}
+
+// collectDependencies finds the resources and modules referenced by a declaration. References in nested
+// resources belong to the nested resource, which in turn depends on its parent.
+func (g *DependencyGraph) collectDependencies(declaration DeclaredSymbol) []*ResourceDependency {
+	var dependencies []*ResourceDependency
+	if resource, ok := declaration.(*ResourceSymbol); ok && resource.Parent != nil {
+		dependencies = append(dependencies, &ResourceDependency{Resource: resource.Parent})
+	}
+
+	var dependsOn *syntax.ObjectPropertySyntax
+	if body := tryGetSymbolBody(declaration); body != nil {
+		dependsOn = body.TryGetProperty(syntax.RESOURCE_PROPERTY_DEPENDS_ON)
+	}
+
+	root := declaration.GetDeclaringSyntax()
+	syntax.Inspect(root, func(node syntax.SyntaxBase) bool {
+		if nested, ok := node.(*syntax.ResourceDeclarationSyntax); ok && nested != root {
+			return false
+		}
+		if dependsOn != nil && node == dependsOn {
+			for _, dependency := range g.collectReferences(dependsOn.Value, declaration) {
+				dependency.Explicit = true
+				dependencies = append(dependencies, dependency)
+			}
+			return false
+		}
+		references, ok := g.tryGetReferences(node, declaration)
+		dependencies = append(dependencies, references...)
+		return !ok
+	})
+
+	return dependencies
+}
+
+// collectReferences finds the resources and modules referenced by an expression.
+func (g *DependencyGraph) collectReferences(expression syntax.SyntaxBase, declaration DeclaredSymbol) []*ResourceDependency {
+	var dependencies []*ResourceDependency
+	syntax.Inspect(expression, func(node syntax.SyntaxBase) bool {
+		references, ok := g.tryGetReferences(node, declaration)
+		dependencies = append(dependencies, references...)
+		return !ok
+	})
+	return dependencies
+}
+
+// tryGetReferences returns the resources and modules a name refers to, other than the declaration itself.
+// It reports false for nodes that are not references to declarations.
+func (g *DependencyGraph) tryGetReferences(node syntax.SyntaxBase, declaration DeclaredSymbol) ([]*ResourceDependency, bool) {
+	switch node.(type) {
+	case *syntax.VariableAccessSyntax, *syntax.ResourceAccessSyntax:
+	default:
+		return nil, false
+	}
+
+	switch symbol := g.binder.GetSymbolInfo(node).(type) {
+	case *ResourceSymbol, *ModuleSymbol:
+		// the base of a nested resource access, e.g. vnet in vnet::subnet, is not a dependency of its own
+		if symbol == declaration {
+			return nil, true
+		}
+		return []*ResourceDependency{{Resource: symbol.(DeclaredSymbol), IndexExpression: g.tryGetIndexExpression(node)}}, true
+	case *VariableSymbol:
+		return g.getVariableDependencies(symbol), true
+	}
+	return nil, false
+}
+
+// tryGetIndexExpression returns the index of a reference to a single instance of a loop, e.g. i in storage[i].
+func (g *DependencyGraph) tryGetIndexExpression(node syntax.SyntaxBase) syntax.SyntaxBase {
+	if access, ok := g.binder.GetParent(node).(*syntax.ArrayAccessSyntax); ok && access.BaseExpression == node {
+		return access.IndexExpression
+	}
+	return nil
+}
+
+func (g *DependencyGraph) getVariableDependencies(variable *VariableSymbol) []*ResourceDependency {
+	if dependencies, ok := g.variables[variable]; ok {
+		return copyDependencies(dependencies)
+	}
+	if g.inProgress[variable] {
+		// cycles are reported by the binder
+		return nil
+	}
+
+	g.inProgress[variable] = true
+	var dependencies []*ResourceDependency
+	for _, dependency := range g.collectReferences(variable.Declaration.Value, variable) {
+		// a variable holds every instance of a loop it references
+		dependencies = append(dependencies, &ResourceDependency{Resource: dependency.Resource})
+	}
+	delete(g.inProgress, variable)
+
+	g.variables[variable] = dependencies
+	return copyDependencies(dependencies)
+}
+
+// getResolvedDependencies returns the dependencies of a declaration, replacing dependencies on existing
+// resources with the dependencies of those resources.
+func (g *DependencyGraph) getResolvedDependencies(declaration DeclaredSymbol) []*ResourceDependency {
+	if dependencies, ok := g.resolved[declaration]; ok {
+		return dependencies
+	}
+	if g.inProgress[declaration] {
+		return nil
+	}
+
+	g.inProgress[declaration] = true
+	var dependencies []*ResourceDependency
+	for _, dependency := range g.direct[declaration] {
+		if resource, ok := dependency.Resource.(*ResourceSymbol); ok && resource.Declaration.IsExistingResource() {
+			for _, inherited := range g.getResolvedDependencies(resource) {
+				dependencies = append(dependencies, &ResourceDependency{
+					Resource:        inherited.Resource,
+					IndexExpression: inherited.IndexExpression,
+					Explicit:        dependency.Explicit,
+				})
+			}
+			continue
+		}
+		dependencies = append(dependencies, dependency)
+	}
+	delete(g.inProgress, declaration)
+
+	dependencies = deduplicateDependencies(dependencies)
+	g.resolved[declaration] = dependencies
+	return dependencies
+}
+
+// reduce leaves out the dependencies that are reachable through another dependency.
+func (g *DependencyGraph) reduce(dependencies []*ResourceDependency) []*ResourceDependency {
+	var reduced []*ResourceDependency
+	for _, dependency := range dependencies {
+		implied := false
+		for _, other := range dependencies {
+			if other.Resource != dependency.Resource && g.isReachable(other.Resource, dependency.Resource) {
+				implied = true
+				break
+			}
+		}
+		if !implied {
+			reduced = append(reduced, dependency)
+		}
+	}
+	return reduced
+}
+
+// isReachable reports whether the target is a dependency of the source, directly or transitively.
+func (g *DependencyGraph) isReachable(source DeclaredSymbol, target DeclaredSymbol) bool {
+	visited := map[DeclaredSymbol]bool{}
+	var visit func(DeclaredSymbol) bool
+	visit = func(symbol DeclaredSymbol) bool {
+		if visited[symbol] {
+			return false
+		}
+		visited[symbol] = true
+		for _, dependency := range g.getResolvedDependencies(symbol) {
+			if dependency.Resource == target || visit(dependency.Resource) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(source)
+}
+
+// checkExplicitDependencies reports dependsOn entries on resources or modules that the declaration
+// already depends on through its other references.
+func (g *DependencyGraph) checkExplicitDependencies(declaration DeclaredSymbol) {
+	body := tryGetSymbolBody(declaration)
+	if body == nil {
+		return
+	}
+	dependsOn := body.TryGetProperty(syntax.RESOURCE_PROPERTY_DEPENDS_ON)
+	if dependsOn == nil {
+		return
+	}
+	entries, ok := dependsOn.Value.(*syntax.ArraySyntax)
+	if !ok {
+		return
+	}
+
+	var implicit []*ResourceDependency
+	for _, dependency := range g.getResolvedDependencies(declaration) {
+		if !dependency.Explicit {
+			implicit = append(implicit, dependency)
+		}
+	}
+
+	for _, entry := range entries.Items {
+		symbol, ok := g.binder.GetSymbolInfo(entry.Value).(DeclaredSymbol)
+		if !ok {
+			continue
+		}
+		switch symbol.(type) {
+		case *ResourceSymbol, *ModuleSymbol:
+		default:
+			continue
+		}
+		for _, dependency := range implicit {
+			if dependency.Resource == symbol || g.isReachable(dependency.Resource, symbol) {
+				g.diagnostics = append(g.diagnostics, unnecessaryDependsOn(entry.Value.GetSpan(), symbol.GetName()))
+				break
+			}
+		}
+	}
+}
+
+func tryGetSymbolBody(declaration DeclaredSymbol) *syntax.ObjectSyntax {
+	switch declaration := declaration.(type) {
+	case *ResourceSymbol:
+		return declaration.Declaration.TryGetBody()
+	case *ModuleSymbol:
+		return declaration.Declaration.TryGetBody()
+	}
+	return nil
+}
+
+// deduplicateDependencies removes repeated dependencies, keeping the first one. A dependency on every
+// instance of a loop replaces dependencies on single instances, and implicit dependencies replace explicit ones.
+func deduplicateDependencies(dependencies []*ResourceDependency) []*ResourceDependency {
+	whole := map[DeclaredSymbol]*ResourceDependency{}
+	implicit := map[DeclaredSymbol]bool{}
+	for _, dependency := range dependencies {
+		if dependency.IndexExpression == nil && whole[dependency.Resource] == nil {
+			whole[dependency.Resource] = dependency
+		}
+		if !dependency.Explicit {
+			implicit[dependency.Resource] = true
+		}
+	}
+
+	var deduplicated []*ResourceDependency
+	seen := map[any]bool{}
+	for _, dependency := range dependencies {
+		if dependency.IndexExpression != nil && whole[dependency.Resource] != nil {
+			dependency = whole[dependency.Resource]
+		}
+		var key any = dependency.Resource
+		if dependency.IndexExpression != nil {
+			key = dependency.IndexExpression
+		}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		deduplicated = append(deduplicated, &ResourceDependency{
+			Resource:        dependency.Resource,
+			IndexExpression: dependency.IndexExpression,
+			Explicit:        dependency.Explicit && !implicit[dependency.Resource],
+		})
+	}
+	return deduplicated
+}
+
+func copyDependencies(dependencies []*ResourceDependency) []*ResourceDependency {
+	copied := make([]*ResourceDependency, 0, len(dependencies))
+	for _, dependency := range dependencies {
+		copied = append(copied, &ResourceDependency{Resource: dependency.Resource, IndexExpression: dependency.IndexExpression})
+	}
+	return copied
+}
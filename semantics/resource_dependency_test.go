@@ -0,0 +1,81 @@
+package semantics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func formatDependencies(dependencies []*ResourceDependency) []string {
+	var formatted []string
+	for _, dependency := range dependencies {
+		name := dependency.Resource.GetName()
+		if dependency.IndexExpression != nil {
+			name += "[i]"
+		}
+		if dependency.Explicit {
+			name += " (explicit)"
+		}
+		formatted = append(formatted, name)
+	}
+	return formatted
+}
+
+func TestDependencyGraph(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string][]string
+	}{
+		{"implicit", "resource a 'A.B/c@1' = {\n  name: 'a'\n}\nresource b 'A.B/c@1' = {\n  name: a.name\n}\n", map[string][]string{"a": nil, "b": {"a"}}},
+		{"through variables", "resource a 'A.B/c@1' = {\n  name: 'a'\n}\nvar v = w\nvar w = a.id\nresource b 'A.B/c@1' = {\n  name: v\n}\n", map[string][]string{"b": {"a"}}},
+		{"minimal", "resource a 'A.B/c@1' = {\n  name: 'a'\n}\nresource b 'A.B/c@1' = {\n  name: a.name\n}\nresource c 'A.B/c@1' = {\n  name: '${a.name}-${b.name}'\n}\n", map[string][]string{"c": {"b"}}},
+		{"existing", "resource a 'A.B/c@1' = {\n  name: 'a'\n}\nresource e 'A.B/c@1' existing = {\n  name: a.name\n}\nresource b 'A.B/c@1' = {\n  name: e.id\n}\n", map[string][]string{"e": {"a"}, "b": {"a"}}},
+		{"loop instance", "resource a 'A.B/c@1' = [for i in range(0, 2): {\n  name: string(i)\n}]\nresource b 'A.B/c@1' = [for i in range(0, 2): {\n  name: a[i].name\n}]\n", map[string][]string{"b": {"a[i]"}}},
+		{"loop collection", "resource a 'A.B/c@1' = [for i in range(0, 2): {\n  name: string(i)\n}]\nresource b 'A.B/c@1' = {\n  name: a[0].name\n  properties: {\n    all: a\n  }\n}\n", map[string][]string{"b": {"a"}}},
+		{"nested resource", "resource p 'A.B/c@1' = {\n  name: 'p'\n  resource c 'd' = {\n    name: 'c'\n  }\n}\noutput id string = p::c.id\n", map[string][]string{"p": nil, "c": {"p"}}},
+		{"module", "resource a 'A.B/c@1' = {\n  name: 'a'\n}\nmodule m 'm.bicep' = {\n  name: 'm'\n  params: {\n    id: a.id\n  }\n}\n", map[string][]string{"m": {"a"}}},
+		{"explicit", "resource a 'A.B/c@1' = {\n  name: 'a'\n}\nresource b 'A.B/c@1' = {\n  name: 'b'\n  dependsOn: [\n    a\n  ]\n}\n", map[string][]string{"b": {"a (explicit)"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := bindText(t, tt.input)
+			require.Empty(t, b.GetDiagnostics())
+			graph := NewDependencyGraph(b)
+			require.Empty(t, graph.GetDiagnostics())
+
+			for name, expected := range tt.expected {
+				var symbol DeclaredSymbol
+				for _, declaration := range graph.getDeclarations() {
+					if declaration.GetName() == name {
+						symbol = declaration
+					}
+				}
+				require.NotNil(t, symbol, name)
+				require.Equal(t, expected, formatDependencies(graph.GetDependencies(symbol)), name)
+			}
+		})
+	}
+}
+
+func TestDependencyGraphDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"necessary", "resource a 'A.B/c@1' = {\n  name: 'a'\n}\nresource b 'A.B/c@1' = {\n  name: 'b'\n  dependsOn: [\n    a\n  ]\n}\n", nil},
+		{"direct", "resource a 'A.B/c@1' = {\n  name: 'a'\n}\nresource b 'A.B/c@1' = {\n  name: a.name\n  dependsOn: [\n    a\n  ]\n}\n", []string{"[98:99] Warning no-unnecessary-dependson: Remove unnecessary dependsOn entry 'a'."}},
+		{"transitive", "resource a 'A.B/c@1' = {\n  name: 'a'\n}\nresource b 'A.B/c@1' = {\n  name: a.name\n}\nresource c 'A.B/c@1' = {\n  name: b.name\n  dependsOn: [\n    a\n  ]\n}\n", []string{"[140:141] Warning no-unnecessary-dependson: Remove unnecessary dependsOn entry 'a'."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := bindText(t, tt.input)
+			require.Empty(t, b.GetDiagnostics())
+			graph := NewDependencyGraph(b)
+			require.Equal(t, tt.expected, formatDiagnostics(graph.GetDiagnostics()))
+		})
+	}
+}
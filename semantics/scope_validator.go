@@ -0,0 +1,161 @@
+package semantics
+
+import (
+	"bicep-go/namespaces"
+	"bicep-go/syntax"
+	"bicep-go/types"
+)
+
+const resourceScopeNameResource = "resource"
+
+// resourceScopeNames are the names of the scopes in the order they are listed in diagnostics.
+// All but the resource scope can be declared as the targetScope of a file.
+var resourceScopeNames = []struct {
+	scope types.ResourceScope
+	name  string
+}{
+	{types.ResourceScopeTenant, syntax.TARGET_SCOPE_TYPE_TENANT},
+	{types.ResourceScopeManagementGroup, syntax.TARGET_SCOPE_TYPE_MANAGEMENT_GROUP},
+	{types.ResourceScopeSubscription, syntax.TARGET_SCOPE_TYPE_SUBSCRIPTION},
+	{types.ResourceScopeResourceGroup, syntax.TARGET_SCOPE_TYPE_RESOURCE_GROUP},
+	{types.ResourceScopeResource, resourceScopeNameResource},
+	{types.ResourceScopeDesiredStateConfiguration, syntax.TARGET_SCOPE_TYPE_DESIRED_STATE_CONFIGURATION},
+}
+
+func formatResourceScopes(scope types.ResourceScope) []string {
+	var names []string
+	for _, entry := range resourceScopeNames {
+		if scope.Has(entry.scope) {
+			names = append(names, entry.name)
+		}
+	}
+	return names
+}
+
+// getTargetScopeType returns the type of the targetScope value, the union of the names of the target scopes.
+func getTargetScopeType() types.TypeSymbol {
+	var members []types.TypeSymbol
+	for _, entry := range resourceScopeNames {
+		if entry.scope != types.ResourceScopeResource {
+			members = append(members, types.NewStringLiteralType(entry.name))
+		}
+	}
+	return types.CreateUnion(members...)
+}
+
+// getTargetScope returns the scope declared by the targetScope statement of the file. Files without
+// one, or with an invalid one, are deployed at resource group scope.
+func getTargetScope(program *syntax.ProgramSyntax) types.ResourceScope {
+	for _, declaration := range program.Declarations() {
+		targetScope, ok := declaration.(*syntax.TargetScopeSyntax)
+		if !ok {
+			continue
+		}
+		if value, ok := targetScope.Value.(*syntax.StringSyntax); ok {
+			if name, ok := value.TryGetLiteralValue(); ok {
+				for _, entry := range resourceScopeNames {
+					if entry.name == name && entry.scope != types.ResourceScopeResource {
+						return entry.scope
+					}
+				}
+			}
+		}
+		break
+	}
+	return types.ResourceScopeResourceGroup
+}
+
+// getPermittedModuleScopes returns the scopes that modules of a file deployed at the target scope can be deployed at.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Emit/ScopeHelper.cs
+func getPermittedModuleScopes(targetScope types.ResourceScope) types.ResourceScope {
+	switch targetScope {
+	case types.ResourceScopeTenant, types.ResourceScopeManagementGroup:
+		return types.ResourceScopeTenant | types.ResourceScopeManagementGroup | types.ResourceScopeSubscription | types.ResourceScopeResourceGroup
+	}
+	return types.ResourceScopeTenant | types.ResourceScopeSubscription | types.ResourceScopeResourceGroup
+}
+
+// validateScopes checks that resources and modules are deployed at scopes they support, given
+// the target scope of the file and their scope properties.
+func (m *TypeManager) validateScopes() {
+	for _, resource := range m.binder.GetFileSymbol().Resources {
+		m.validateResourceScope(resource)
+	}
+	for _, module := range m.binder.GetFileSymbol().Modules {
+		m.validateModuleScope(module)
+	}
+}
+
+// validateResourceScope checks a top-level resource. Nested resources and resources with a parent
+// property are deployed at the scope of their parent.
+func (m *TypeManager) validateResourceScope(resource *ResourceSymbol) {
+	resourceType, ok := m.getResourceType(resource).(*types.ResourceType)
+	if !ok {
+		return
+	}
+	body := tryGetDeclarationBody(resource.Declaration.Value)
+	if body != nil && body.TryGetProperty(syntax.RESOURCE_PROPERTY_PARENT) != nil {
+		return
+	}
+
+	targetScope := m.binder.GetFileSymbol().TargetScope
+	scope := targetScope
+	span := resource.Declaration.Type.GetSpan()
+	if body != nil {
+		if property := body.TryGetProperty(syntax.RESOURCE_PROPERTY_SCOPE); property != nil {
+			span = property.Value.GetSpan()
+			scopeType := m.GetTypeInfo(property.Value)
+			if _, ok := scopeType.(*types.ResourceType); ok {
+				scope = types.ResourceScopeResource
+			} else if reference := namespaces.TryGetScopeReference(scopeType); reference != types.ResourceScopeNone {
+				// existing resources can be referenced at any scope, but only the file's scope can be deployed to
+				if reference != targetScope && !resource.Declaration.IsExistingResource() {
+					m.addDiagnostic(invalidCrossScopeResourceDeployment(span))
+					return
+				}
+				scope = reference
+			} else {
+				return
+			}
+		}
+	}
+
+	if !resourceType.ValidParentScopes.Has(scope) {
+		m.addDiagnostic(invalidResourceScope(span, scope, resourceType.ValidParentScopes))
+	}
+}
+
+func (m *TypeManager) validateModuleScope(module *ModuleSymbol) {
+	moduleType, ok := m.getModuleType(module.Declaration).(*types.ModuleType)
+	if !ok {
+		return
+	}
+
+	targetScope := m.binder.GetFileSymbol().TargetScope
+	if targetScope == types.ResourceScopeDesiredStateConfiguration {
+		// desired state configurations are not deployed through ARM and have no module scopes
+		return
+	}
+	scope := targetScope
+	span := module.Declaration.Path.GetSpan()
+	if body := tryGetDeclarationBody(module.Declaration.Value); body != nil {
+		if property := body.TryGetProperty(syntax.RESOURCE_PROPERTY_SCOPE); property != nil {
+			span = property.Value.GetSpan()
+			scopeType := m.GetTypeInfo(property.Value)
+			switch scopeType.(type) {
+			case *types.AnyType, *types.ErrorType:
+				return
+			}
+			reference := namespaces.TryGetScopeReference(scopeType)
+			if !getPermittedModuleScopes(targetScope).Has(reference) {
+				m.addDiagnostic(unsupportedModuleScope(span, targetScope))
+				return
+			}
+			scope = reference
+		}
+	}
+
+	if !moduleType.ValidParentScopes.Has(scope) {
+		m.addDiagnostic(invalidModuleScope(span, scope, moduleType.ValidParentScopes))
+	}
+}
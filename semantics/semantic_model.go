@@ -0,0 +1,166 @@
+package semantics
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/syntax"
+	"bicep-go/types"
+	"bicep-go/util"
+)
+
+// ModuleModel is the interface a file exposes to the modules and import statements referencing it.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/ISemanticModel.cs
+type ModuleModel interface {
+	GetTargetScope() types.ResourceScope
+	GetParametersType() *types.ObjectType
+	GetOutputsType() *types.ObjectType
+	GetExports() []*ExportedSymbol
+}
+
+// ModuleLookup resolves the files that module declarations, import statements and functions loading files at
+// compile time reference.
+type ModuleLookup interface {
+	// TryGetModuleModel returns the model of the file referenced by the module declaration,
+	// or a diagnostic explaining why the file cannot be used. Both are nil if the parser already
+	// reported the path as invalid.
+	TryGetModuleModel(declaration *syntax.ModuleDeclarationSyntax) (ModuleModel, *diagnostics.Diagnostic)
+	// TryGetImportedModel is the equivalent of TryGetModuleModel for import statements.
+	TryGetImportedModel(declaration *syntax.CompileTimeImportDeclarationSyntax) (ModuleModel, *diagnostics.Diagnostic)
+	// TryReadFile returns the content of a file loaded at compile time, e.g. by loadTextContent(), whose path is
+	// relative to the file of the program, or a diagnostic at span explaining why it cannot be read.
+	TryReadFile(program *syntax.ProgramSyntax, span *util.TextSpan, filePath string) ([]byte, *diagnostics.Diagnostic)
+}
+
+// SemanticModel holds the binding and type information of a single file.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/SemanticModel.cs
+type SemanticModel struct {
+	Binder       *Binder
+	TypeManager  *TypeManager
+	evaluator    *ConstantEvaluator
+	dependencies *DependencyGraph
+}
+
+// NewSemanticModel binds and type checks a file. Modules and imported symbols are loosely typed if modules is nil.
+func NewSemanticModel(program *syntax.ProgramSyntax, provider types.ResourceTypeProvider, modules ModuleLookup) *SemanticModel {
+	binder := NewBinder(program)
+	return &SemanticModel{
+		Binder:       binder,
+		TypeManager:  NewTypeManager(binder, provider, modules),
+		evaluator:    NewConstantEvaluator(binder, EvaluationFlagsParameterDefaults),
+		dependencies: NewDependencyGraph(binder),
+	}
+}
+
+// GetSymbol returns the symbol a node declares or references, or nil if it neither declares nor references one.
+func (m *SemanticModel) GetSymbol(node syntax.SyntaxBase) Symbol {
+	return m.Binder.GetSymbolInfo(node)
+}
+
+// GetType returns the type of an expression.
+func (m *SemanticModel) GetType(expression syntax.SyntaxBase) types.TypeSymbol {
+	return m.TypeManager.GetTypeInfo(expression)
+}
+
+// GetDeclaredType returns the type a declaration expects its value to have, or the type denoted by a type
+// expression. It returns nil for declarations without a declared type, such as variables.
+func (m *SemanticModel) GetDeclaredType(node syntax.SyntaxBase) types.TypeSymbol {
+	return m.TypeManager.GetDeclaredType(node)
+}
+
+// GetResourceType returns the type of a single instance of the resource, or nil if its type string is not valid.
+func (m *SemanticModel) GetResourceType(resource *ResourceSymbol) *types.ResourceType {
+	resourceType, _ := m.TypeManager.getResourceType(resource).(*types.ResourceType)
+	return resourceType
+}
+
+// GetModuleModel returns the model of the file referenced by a module declaration, or nil if the file
+// cannot be used or modules are not resolved.
+func (m *SemanticModel) GetModuleModel(declaration *syntax.ModuleDeclarationSyntax) ModuleModel {
+	if m.TypeManager.modules == nil {
+		return nil
+	}
+	model, _ := m.TypeManager.modules.TryGetModuleModel(declaration)
+	return model
+}
+
+// GetImportedModel returns the model of the file referenced by an import statement, or nil if the file
+// cannot be used or imports are not resolved.
+func (m *SemanticModel) GetImportedModel(declaration *syntax.CompileTimeImportDeclarationSyntax) ModuleModel {
+	if m.TypeManager.modules == nil {
+		return nil
+	}
+	model, _ := m.TypeManager.modules.TryGetImportedModel(declaration)
+	return model
+}
+
+// GetLoadedFileValue returns the value of a call to a function loading a file at compile time: a string, or the
+// JSON of a loadJsonContent() or loadYamlContent() call as a json.RawMessage. It returns false if the file could
+// not be loaded, or files are not resolved.
+func (m *SemanticModel) GetLoadedFileValue(call syntax.SyntaxBase) (any, bool) {
+	m.TypeManager.GetTypeInfo(call)
+	value, ok := m.TypeManager.loadedFiles[call]
+	return value, ok
+}
+
+// GetDependencyGraph returns the dependencies between the resources and modules of the file.
+func (m *SemanticModel) GetDependencyGraph() *DependencyGraph {
+	return m.dependencies
+}
+
+// GetSymbolType returns the type of a value referencing the symbol.
+func (m *SemanticModel) GetSymbolType(symbol Symbol) types.TypeSymbol {
+	return m.TypeManager.GetSymbolType(symbol)
+}
+
+// FindReferences returns the declaration of the symbol and every node referencing it, in source order.
+func (m *SemanticModel) FindReferences(symbol Symbol) []syntax.SyntaxBase {
+	return m.Binder.FindReferences(symbol)
+}
+
+// GetConstantValue returns the value of an expression if it is known at compile time, taking parameters to have
+// their default values. Use FormatConstantValue to show it.
+func (m *SemanticModel) GetConstantValue(expression syntax.SyntaxBase) (any, bool) {
+	return m.evaluator.TryEvaluate(expression)
+}
+
+// GetDeclarations returns the top-level declared symbols of the file in source order.
+func (m *SemanticModel) GetDeclarations() []DeclaredSymbol {
+	return m.Binder.GetFileSymbol().Declarations
+}
+
+// GetDiagnostics returns the diagnostics of the binder and the type manager, sorted by position.
+func (m *SemanticModel) GetDiagnostics() []*diagnostics.Diagnostic {
+	var all []*diagnostics.Diagnostic
+	all = append(all, m.Binder.GetDiagnostics()...)
+	all = append(all, m.TypeManager.GetDiagnostics()...)
+	all = append(all, m.dependencies.GetDiagnostics()...)
+	diagnostics.Sort(all)
+	return all
+}
+
+func (m *SemanticModel) GetTargetScope() types.ResourceScope {
+	return m.Binder.GetFileSymbol().TargetScope
+}
+
+// GetParametersType returns the type of the params object of a module referencing the file.
+// Parameters without a default value that are not nullable are required.
+func (m *SemanticModel) GetParametersType() *types.ObjectType {
+	var properties []*types.TypeProperty
+	for _, parameter := range m.Binder.GetFileSymbol().Parameters {
+		parameterType := m.TypeManager.GetDeclaredType(parameter.Declaration)
+		flags := types.TypePropertyFlagsNone
+		if parameter.Declaration.DefaultValue() == nil && !types.IsNullable(parameterType) {
+			flags = types.TypePropertyFlagsRequired
+		}
+		properties = append(properties, types.NewTypeProperty(parameter.GetName(), parameterType, flags))
+	}
+	return types.NewObjectType(syntax.MODULE_PROPERTY_PARAMS, properties, nil)
+}
+
+// GetOutputsType returns the type of the outputs of a module referencing the file.
+func (m *SemanticModel) GetOutputsType() *types.ObjectType {
+	var properties []*types.TypeProperty
+	for _, output := range m.Binder.GetFileSymbol().Outputs {
+		properties = append(properties, types.NewTypeProperty(output.GetName(), m.TypeManager.GetDeclaredType(output.Declaration), types.TypePropertyFlagsRequired|types.TypePropertyFlagsReadOnly))
+	}
+	return types.NewObjectType(syntax.MODULE_PROPERTY_OUTPUTS, properties, nil)
+}
@@ -0,0 +1,76 @@
+package semantics
+
+import (
+	"bicep-go/parser"
+	"bicep-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const semanticModelText = `param prefix string
+var name = '${prefix}-app'
+@export()
+type config = {
+  name: string
+}
+output upper string = toUpper(name)
+output names string[] = map([prefix], p => '${p}-${name}')
+`
+
+func TestSemanticModelQueries(t *testing.T) {
+	program := parser.New(semanticModelText).Program()
+	model := NewSemanticModel(program, types.NewGenericResourceTypeProvider(), nil)
+	require.Empty(t, model.GetDiagnostics())
+
+	declarations := model.GetDeclarations()
+	require.Len(t, declarations, 5)
+
+	prefix := declarations[0]
+	require.Same(t, prefix, model.GetSymbol(prefix.GetDeclaringSyntax()))
+	require.Equal(t, "string", model.GetSymbolType(prefix).GetName())
+
+	name := declarations[1].(*VariableSymbol)
+	require.Equal(t, "string", model.GetType(name.Declaration.Value).GetName())
+	require.Nil(t, model.GetDeclaredType(name.Declaration))
+
+	config := declarations[2].(*TypeAliasSymbol)
+	require.Equal(t, "{ name: string }", model.GetDeclaredType(config.Declaration).GetName())
+
+	exports := model.GetExports()
+	require.Len(t, exports, 1)
+	require.Equal(t, "config", exports[0].Name)
+
+	upper := declarations[3].(*OutputSymbol)
+	require.Equal(t, "string", model.GetDeclaredType(upper.Declaration).GetName())
+	require.Same(t, upper, model.GetSymbol(upper.Declaration))
+}
+
+func TestSemanticModelFindReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		find     func(model *SemanticModel) Symbol
+		expected []string
+	}{
+		{"parameter", func(model *SemanticModel) Symbol { return model.GetDeclarations()[0] }, []string{"[0:19]", "[34:40]", "[155:161]"}},
+		{"variable", func(model *SemanticModel) Symbol { return model.GetDeclarations()[1] }, []string{"[20:46]", "[120:124]", "[177:181]"}},
+		{"unreferenced type", func(model *SemanticModel) Symbol { return model.GetDeclarations()[2] }, []string{"[47:89]"}},
+		{"output", func(model *SemanticModel) Symbol { return model.GetDeclarations()[3] }, []string{"[90:125]"}},
+		{"lambda variable", func(model *SemanticModel) Symbol {
+			return model.GetSymbol(findVariableAccess(model.Binder.GetFileSymbol().Program, "p", 0))
+		}, []string{"[164:165]", "[172:173]"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program := parser.New(semanticModelText).Program()
+			model := NewSemanticModel(program, types.NewGenericResourceTypeProvider(), nil)
+
+			var spans []string
+			for _, reference := range model.FindReferences(tt.find(model)) {
+				spans = append(spans, reference.GetSpan().ToString())
+			}
+			require.Equal(t, tt.expected, spans)
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package semantics
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/namespaces"
+	"bicep-go/syntax"
+)
+
+type SymbolKind int
+
+const (
+	SymbolKindError SymbolKind = iota
+	SymbolKindFile
+	SymbolKindMetadata
+	SymbolKindParameter
+	SymbolKindVariable
+	SymbolKindResource
+	SymbolKindModule
+	SymbolKindOutput
+	SymbolKindTypeAlias
+	SymbolKindDeclaredFunction
+	SymbolKindImported
+	SymbolKindWildcardImport
+	SymbolKindLocal
+	SymbolKindNamespace
+	SymbolKindFunction
+	SymbolKindDecorator
+	SymbolKindAmbientType
+)
+
+// Symbol is the result of binding a name.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Semantics/Symbol.cs
+type Symbol interface {
+	GetName() string
+	GetKind() SymbolKind
+}
+
+// DeclaredSymbol is a symbol introduced by a declaration in the file, as opposed to a built-in.
+type DeclaredSymbol interface {
+	Symbol
+	GetNameSyntax() *syntax.IdentifierSyntax
+	GetDeclaringSyntax() syntax.SyntaxBase
+}
+
+type declaredSymbol struct {
+	NameSyntax *syntax.IdentifierSyntax
+}
+
+func (s *declaredSymbol) GetName() string {
+	return s.NameSyntax.IdentifierName()
+}
+
+func (s *declaredSymbol) GetNameSyntax() *syntax.IdentifierSyntax {
+	return s.NameSyntax
+}
+
+type MetadataSymbol struct {
+	declaredSymbol
+	Declaration *syntax.MetadataDeclarationSyntax
+}
+
+func (s *MetadataSymbol) GetKind() SymbolKind {
+	return SymbolKindMetadata
+}
+
+func (s *MetadataSymbol) GetDeclaringSyntax() syntax.SyntaxBase {
+	return s.Declaration
+}
+
+type ParameterSymbol struct {
+	declaredSymbol
+	Declaration *syntax.ParameterDeclarationSyntax
+}
+
+func (s *ParameterSymbol) GetKind() SymbolKind {
+	return SymbolKindParameter
+}
+
+func (s *ParameterSymbol) GetDeclaringSyntax() syntax.SyntaxBase {
+	return s.Declaration
+}
+
+type VariableSymbol struct {
+	declaredSymbol
+	Declaration *syntax.VariableDeclarationSyntax
+}
+
+func (s *VariableSymbol) GetKind() SymbolKind {
+	return SymbolKindVariable
+}
+
+func (s *VariableSymbol) GetDeclaringSyntax() syntax.SyntaxBase {
+	return s.Declaration
+}
+
+type ResourceSymbol struct {
+	declaredSymbol
+	Declaration *syntax.ResourceDeclarationSyntax
+	// Parent is the resource whose body declares this resource, or nil for top-level resources.
+	Parent *ResourceSymbol
+	// NestedResources are the resources declared in the body of this resource.
+	NestedResources []*ResourceSymbol
+}
+
+func (s *ResourceSymbol) GetKind() SymbolKind {
+	return SymbolKindResource
+}
+
+func (s *ResourceSymbol) GetDeclaringSyntax() syntax.SyntaxBase {
+	return s.Declaration
+}
+
+func (s *ResourceSymbol) TryGetNestedResource(name string) *ResourceSymbol {
+	for _, nested := range s.NestedResources {
+		if nested.GetName() == name {
+			return nested
+		}
+	}
+	return nil
+}
+
+type ModuleSymbol struct {
+	declaredSymbol
+	Declaration *syntax.ModuleDeclarationSyntax
+}
+
+func (s *ModuleSymbol) GetKind() SymbolKind {
+	return SymbolKindModule
+}
+
+func (s *ModuleSymbol) GetDeclaringSyntax() syntax.SyntaxBase {
+	return s.Declaration
+}
+
+type OutputSymbol struct {
+	declaredSymbol
+	Declaration *syntax.OutputDeclarationSyntax
+}
+
+func (s *OutputSymbol) GetKind() SymbolKind {
+	return SymbolKindOutput
+}
+
+func (s *OutputSymbol) GetDeclaringSyntax() syntax.SyntaxBase {
+	return s.Declaration
+}
+
+type TypeAliasSymbol struct {
+	declaredSymbol
+	Declaration *syntax.TypeDeclarationSyntax
+}
+
+func (s *TypeAliasSymbol) GetKind() SymbolKind {
+	return SymbolKindTypeAlias
+}
+
+func (s *TypeAliasSymbol) GetDeclaringSyntax() syntax.SyntaxBase {
+	return s.Declaration
+}
+
+// DeclaredFunctionSymbol is a user-defined function, e.g. func name(a string) string => a.
+type DeclaredFunctionSymbol struct {
+	declaredSymbol
+	Declaration *syntax.FunctionDeclarationSyntax
+}
+
+func (s *DeclaredFunctionSymbol) GetKind() SymbolKind {
+	return SymbolKindDeclaredFunction
+}
+
+func (s *DeclaredFunctionSymbol) GetDeclaringSyntax() syntax.SyntaxBase {
+	return s.Declaration
+}
+
+// ImportedSymbol is a type, variable or function imported by name from another file, e.g. b in import {a as b}.
+// Whether it is a type or a value is only known once the imported file is resolved.
+type ImportedSymbol struct {
+	declaredSymbol
+	Declaration *syntax.CompileTimeImportDeclarationSyntax
+	Item        *syntax.ImportedSymbolsListItemSyntax
+}
+
+func (s *ImportedSymbol) GetKind() SymbolKind {
+	return SymbolKindImported
+}
+
+func (s *ImportedSymbol) GetDeclaringSyntax() syntax.SyntaxBase {
+	return s.Item
+}
+
+// GetOriginalSymbolName returns the name the symbol is exported under by the imported file.
+func (s *ImportedSymbol) GetOriginalSymbolName() string {
+	return s.Item.OriginalSymbolName.IdentifierName()
+}
+
+// WildcardImportSymbol is the namespace holding every symbol exported by another file, e.g. lib in import * as lib.
+type WildcardImportSymbol struct {
+	declaredSymbol
+	Declaration *syntax.CompileTimeImportDeclarationSyntax
+	Wildcard    *syntax.WildcardImportSyntax
+}
+
+func (s *WildcardImportSymbol) GetKind() SymbolKind {
+	return SymbolKindWildcardImport
+}
+
+func (s *WildcardImportSymbol) GetDeclaringSyntax() syntax.SyntaxBase {
+	return s.Wildcard
+}
+
+type LocalKind int
+
+const (
+	LocalKindForItem LocalKind = iota
+	LocalKindForIndex
+	LocalKindLambdaItem
+	LocalKindFunctionParameter
+)
+
+// LocalVariableSymbol is a loop item or index variable, a lambda parameter or a parameter of a user-defined function.
+type LocalVariableSymbol struct {
+	declaredSymbol
+	// Declaration is a LocalVariableSyntax, or a TypedLocalVariableSyntax for parameters of user-defined functions.
+	Declaration syntax.SyntaxBase
+	LocalKind   LocalKind
+}
+
+func (s *LocalVariableSymbol) GetKind() SymbolKind {
+	return SymbolKindLocal
+}
+
+func (s *LocalVariableSymbol) GetDeclaringSyntax() syntax.SyntaxBase {
+	return s.Declaration
+}
+
+// NamespaceSymbol is a namespace implicitly imported into the file, e.g. sys or az.
+type NamespaceSymbol struct {
+	Namespace *namespaces.Namespace
+}
+
+func (s *NamespaceSymbol) GetName() string {
+	return s.Namespace.Name
+}
+
+func (s *NamespaceSymbol) GetKind() SymbolKind {
+	return SymbolKindNamespace
+}
+
+// FunctionSymbol is a built-in function of a namespace.
+type FunctionSymbol struct {
+	Function  *namespaces.Function
+	Namespace *NamespaceSymbol
+	// Name is the name the function was called with, which differs from the function name for wildcard functions.
+	Name string
+}
+
+func (s *FunctionSymbol) GetName() string {
+	return s.Name
+}
+
+func (s *FunctionSymbol) GetKind() SymbolKind {
+	return SymbolKindFunction
+}
+
+type DecoratorSymbol struct {
+	Decorator *namespaces.Decorator
+	Namespace *NamespaceSymbol
+}
+
+func (s *DecoratorSymbol) GetName() string {
+	return s.Decorator.Name
+}
+
+func (s *DecoratorSymbol) GetKind() SymbolKind {
+	return SymbolKindDecorator
+}
+
+// AmbientTypeSymbol is a built-in type of a namespace, e.g. string.
+type AmbientTypeSymbol struct {
+	Name      string
+	Namespace *NamespaceSymbol
+}
+
+func (s *AmbientTypeSymbol) GetName() string {
+	return s.Name
+}
+
+func (s *AmbientTypeSymbol) GetKind() SymbolKind {
+	return SymbolKindAmbientType
+}
+
+// ErrorSymbol is bound to names that could not be resolved.
+type ErrorSymbol struct {
+	Diagnostic *diagnostics.Diagnostic
+}
+
+func (s *ErrorSymbol) GetName() string {
+	return "<error>"
+}
+
+func (s *ErrorSymbol) GetKind() SymbolKind {
+	return SymbolKindError
+}
@@ -0,0 +1,844 @@
+package semantics
+
+import (
+	"bicep-go/namespaces"
+	"bicep-go/syntax"
+	"bicep-go/types"
+	"fmt"
+	"math"
+)
+
+// computeType infers the type of an expression, reporting invalid operands, accesses and loops.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/TypeSystem/TypeAssignmentVisitor.cs
+func (m *TypeManager) computeType(node syntax.SyntaxBase) types.TypeSymbol {
+	switch node := node.(type) {
+	case *syntax.StringSyntax:
+		for _, expression := range node.Expressions {
+			m.GetTypeInfo(expression)
+		}
+		if value, ok := node.TryGetLiteralValue(); ok {
+			return types.NewStringLiteralType(value)
+		}
+		return types.String
+
+	case *syntax.IntegerLiteralSyntax:
+		if node.Value <= math.MaxInt64 {
+			return types.NewIntegerLiteralType(int64(node.Value))
+		}
+		if isNegatedMinInt64(m.binder, node) {
+			return types.Int
+		}
+		m.addDiagnostic(invalidInteger(node.GetSpan()))
+		return types.Error
+
+	case *syntax.BooleanLiteralSyntax:
+		return types.NewBooleanLiteralType(node.Value)
+
+	case *syntax.NullLiteralSyntax:
+		return types.Null
+
+	case *syntax.ArraySyntax:
+		var items []types.TypeSymbol
+		for _, item := range node.Items {
+			items = append(items, m.GetTypeInfo(item.Value))
+		}
+		return types.NewTupleType(items)
+
+	case *syntax.ObjectSyntax:
+		return m.getObjectType(node)
+
+	case *syntax.ParenthesizedExpressionSyntax:
+		return m.GetTypeInfo(node.Expression)
+
+	case *syntax.FunctionArgumentSyntax:
+		return m.GetTypeInfo(node.Expression)
+
+	case *syntax.VariableAccessSyntax:
+		symbol := m.binder.GetSymbolInfo(node)
+		if imported, ok := symbol.(*ImportedSymbol); ok {
+			if export := m.tryGetImportedExport(imported); export != nil && export.Kind != ExportKindVariable {
+				m.addDiagnostic(symbolicNameIsNotAVariableOrParameter(node.Name.GetSpan(), imported.GetName()))
+				return types.Error
+			}
+		}
+		m.validateCollectionReference(node, symbol)
+		return m.narrowType(node, m.GetSymbolType(symbol))
+
+	case *syntax.FunctionCallSyntax:
+		argumentTypes := m.getArgumentTypes(node.Arguments)
+		switch symbol := m.binder.GetSymbolInfo(node).(type) {
+		case *FunctionSymbol:
+			returnType := m.resolveOverloads(symbol.Function.Overloads, node.Name, node.Arguments, argumentTypes)
+			m.validateFileLoadArguments(node, symbol.Function, node.Arguments)
+			return returnType
+		case *DeclaredFunctionSymbol:
+			if overload := m.getDeclaredFunctionOverload(symbol); overload != nil {
+				return m.resolveOverloads([]*namespaces.FunctionOverload{overload}, node.Name, node.Arguments, argumentTypes)
+			}
+		case *ImportedSymbol:
+			return m.getImportedFunctionCallType(node, symbol, argumentTypes)
+		}
+		return types.Error
+
+	case *syntax.InstanceFunctionCallSyntax:
+		return m.getInstanceFunctionCallType(node)
+
+	case *syntax.PropertyAccessSyntax:
+		baseType := m.GetTypeInfo(node.BaseExpression)
+		if !node.PropertyName.IsValid() {
+			return types.Error
+		}
+		safe := node.IsSafeAccess() || isShortCircuited(node.BaseExpression)
+		baseType = m.dereference(node.BaseExpression, baseType, safe)
+		propertyType := m.getPropertyType(baseType, node.PropertyName.IdentifierName(), node.PropertyName)
+		if safe {
+			return makeNullable(propertyType)
+		}
+		return m.narrowType(node, propertyType)
+
+	case *syntax.ArrayAccessSyntax:
+		baseType := m.GetTypeInfo(node.BaseExpression)
+		safe := node.IsSafeAccess() || isShortCircuited(node.BaseExpression)
+		baseType = m.dereference(node.BaseExpression, baseType, safe)
+		itemType := m.getIndexedType(baseType, node.IndexExpression)
+		if safe {
+			return makeNullable(itemType)
+		}
+		return itemType
+
+	case *syntax.ResourceAccessSyntax:
+		baseType := m.GetTypeInfo(node.BaseExpression)
+		if symbol, ok := m.binder.GetSymbolInfo(node).(*ResourceSymbol); ok {
+			return m.GetSymbolType(symbol)
+		}
+		switch baseType.(type) {
+		case *types.ErrorType, *types.ResourceType:
+			// missing nested resources are reported by the binder
+		default:
+			m.addDiagnostic(nestedResourceAccessNotSupported(node.DoubleColon.GetSpan(), baseType))
+		}
+		return types.Error
+
+	case *syntax.NonNullAssertionSyntax:
+		return types.RemoveNullability(m.GetTypeInfo(node.BaseExpression))
+
+	case *syntax.UnaryOperationSyntax:
+		return m.getUnaryOperationType(node)
+
+	case *syntax.BinaryOperationSyntax:
+		return m.getBinaryOperationType(node)
+
+	case *syntax.TernaryOperationSyntax:
+		conditionType := m.GetTypeInfo(node.ConditionExpression)
+		if !types.AreTypesAssignable(conditionType, types.Bool) {
+			m.addDiagnostic(valueTypeMismatch(node.ConditionExpression.GetSpan(), types.Bool))
+		}
+		return types.CreateUnion(m.GetTypeInfo(node.TrueExpression), m.GetTypeInfo(node.FalseExpression))
+
+	case *syntax.ForSyntax:
+		iterableType := m.GetTypeInfo(node.Expression)
+		if _, ok := getItemType(iterableType).(*types.ErrorType); ok {
+			if _, ok := iterableType.(*types.ErrorType); !ok {
+				m.addDiagnostic(loopArrayExpressionTypeMismatch(node.Expression.GetSpan(), iterableType))
+			}
+		}
+		return types.NewArrayType(m.GetTypeInfo(node.Body))
+
+	case *syntax.IfConditionSyntax:
+		conditionType := m.GetTypeInfo(node.ConditionExpression)
+		if !types.AreTypesAssignable(conditionType, types.Bool) {
+			m.addDiagnostic(valueTypeMismatch(node.ConditionExpression.GetSpan(), types.Bool))
+		}
+		return m.GetTypeInfo(node.Body)
+
+	case *syntax.LambdaSyntax:
+		return m.getLambdaType(node)
+	}
+
+	return types.Error
+}
+
+// makeNullable adds null to the type of a safe access, unless the type is unknown.
+func makeNullable(typeSymbol types.TypeSymbol) types.TypeSymbol {
+	switch typeSymbol.(type) {
+	case *types.AnyType, *types.ErrorType:
+		return typeSymbol
+	}
+	return types.CreateNullable(typeSymbol)
+}
+
+// dereference returns the type of the base of an access expression without null, warning if the base may be
+// null and the access is not safe.
+func (m *TypeManager) dereference(base syntax.SyntaxBase, baseType types.TypeSymbol, safe bool) types.TypeSymbol {
+	if !isNullableUnion(baseType) {
+		return baseType
+	}
+	if !safe {
+		m.addDiagnostic(dereferenceOfPossiblyNullReference(base.GetSpan(), baseType))
+	}
+	return types.RemoveNullability(baseType)
+}
+
+// isShortCircuited reports whether an access expression follows a safe access in the same chain, e.g. a.?b.c,
+// which evaluates to null as a whole if the safe access does.
+func isShortCircuited(base syntax.SyntaxBase) bool {
+	for {
+		switch access := base.(type) {
+		case *syntax.PropertyAccessSyntax:
+			if access.IsSafeAccess() {
+				return true
+			}
+			base = access.BaseExpression
+		case *syntax.ArrayAccessSyntax:
+			if access.IsSafeAccess() {
+				return true
+			}
+			base = access.BaseExpression
+		default:
+			return false
+		}
+	}
+}
+
+// narrowType narrows the type of a reference by the conditions of the enclosing ternaries. A reference checked
+// against null loses null, e.g. p in p != null ? p.name : 'none', and a reference to a discriminated union whose
+// discriminator is checked becomes the matching variants, e.g. p in p.kind == 'a' ? p.size : 0.
+func (m *TypeManager) narrowType(node syntax.SyntaxBase, typeSymbol types.TypeSymbol) types.TypeSymbol {
+	if !isNullableUnion(typeSymbol) && !isDiscriminatedObject(typeSymbol) {
+		return typeSymbol
+	}
+
+	child := node
+	for _, ancestor := range m.binder.GetHierarchy().GetAncestors(node) {
+		if ternary, ok := ancestor.(*syntax.TernaryOperationSyntax); ok {
+			switch child {
+			case ternary.TrueExpression:
+				typeSymbol = m.narrowTypeByCondition(ternary.ConditionExpression, node, typeSymbol, syntax.BinaryOperatorEquals, syntax.BinaryOperatorNotEquals, syntax.BinaryOperatorLogicalAnd)
+			case ternary.FalseExpression:
+				typeSymbol = m.narrowTypeByCondition(ternary.ConditionExpression, node, typeSymbol, syntax.BinaryOperatorNotEquals, syntax.BinaryOperatorEquals, syntax.BinaryOperatorLogicalOr)
+			}
+		}
+		child = ancestor
+	}
+	return typeSymbol
+}
+
+// narrowTypeByCondition narrows the type of the reference by a condition that holds. A comparison using the
+// equality operator selects a value and one using the inequality operator excludes it, possibly as one of several
+// conditions joined by the junction operator.
+func (m *TypeManager) narrowTypeByCondition(condition syntax.SyntaxBase, reference syntax.SyntaxBase, typeSymbol types.TypeSymbol, equality syntax.BinaryOperator, inequality syntax.BinaryOperator, junction syntax.BinaryOperator) types.TypeSymbol {
+	if isNullableUnion(typeSymbol) {
+		excludesNull := false
+		forEachComparison(condition, inequality, junction, func(operand syntax.SyntaxBase, other syntax.SyntaxBase) {
+			if _, ok := other.(*syntax.NullLiteralSyntax); ok && m.isSameReference(operand, reference) {
+				excludesNull = true
+			}
+		})
+		if excludesNull {
+			typeSymbol = types.RemoveNullability(typeSymbol)
+		}
+	}
+
+	discriminatedType, ok := typeSymbol.(*types.DiscriminatedObjectType)
+	if !ok {
+		return typeSymbol
+	}
+
+	// getVariant returns the variant selected by comparing the discriminator of the reference with a literal
+	getVariant := func(operand syntax.SyntaxBase, other syntax.SyntaxBase) *types.ObjectType {
+		access, ok := operand.(*syntax.PropertyAccessSyntax)
+		if !ok || !access.PropertyName.IsValid() || access.PropertyName.IdentifierName() != discriminatedType.DiscriminatorKey || !m.isSameReference(access.BaseExpression, reference) {
+			return nil
+		}
+		if value, ok := m.GetTypeInfo(other).(*types.StringLiteralType); ok {
+			return discriminatedType.TryGetMember(value.Value)
+		}
+		return nil
+	}
+
+	var selected *types.ObjectType
+	forEachComparison(condition, equality, junction, func(operand syntax.SyntaxBase, other syntax.SyntaxBase) {
+		if variant := getVariant(operand, other); variant != nil {
+			selected = variant
+		}
+	})
+	if selected != nil {
+		return selected
+	}
+
+	excluded := map[*types.ObjectType]bool{}
+	forEachComparison(condition, inequality, junction, func(operand syntax.SyntaxBase, other syntax.SyntaxBase) {
+		if variant := getVariant(operand, other); variant != nil {
+			excluded[variant] = true
+		}
+	})
+	var remaining []*types.ObjectType
+	for _, member := range discriminatedType.Members {
+		if !excluded[member] {
+			remaining = append(remaining, member)
+		}
+	}
+	switch {
+	case len(excluded) == 0 || len(remaining) == 0:
+		return typeSymbol
+	case len(remaining) == 1:
+		return remaining[0]
+	}
+	return types.NewDiscriminatedObjectType(discriminatedType.DiscriminatorKey, remaining)
+}
+
+// forEachComparison calls visit for each comparison using the operator in a condition, following conditions
+// joined by the junction operator. Each comparison is visited with its operands in both orders.
+func forEachComparison(condition syntax.SyntaxBase, comparison syntax.BinaryOperator, junction syntax.BinaryOperator, visit func(operand syntax.SyntaxBase, other syntax.SyntaxBase)) {
+	switch condition := condition.(type) {
+	case *syntax.ParenthesizedExpressionSyntax:
+		forEachComparison(condition.Expression, comparison, junction, visit)
+	case *syntax.BinaryOperationSyntax:
+		switch condition.Operator {
+		case junction:
+			forEachComparison(condition.LeftExpression, comparison, junction, visit)
+			forEachComparison(condition.RightExpression, comparison, junction, visit)
+		case comparison:
+			visit(condition.LeftExpression, condition.RightExpression)
+			visit(condition.RightExpression, condition.LeftExpression)
+		}
+	}
+}
+
+// isSameReference reports whether two expressions access the same symbol through the same properties.
+func (m *TypeManager) isSameReference(a syntax.SyntaxBase, b syntax.SyntaxBase) bool {
+	if parenthesized, ok := a.(*syntax.ParenthesizedExpressionSyntax); ok {
+		return m.isSameReference(parenthesized.Expression, b)
+	}
+	switch a := a.(type) {
+	case *syntax.VariableAccessSyntax:
+		b, ok := b.(*syntax.VariableAccessSyntax)
+		if !ok {
+			return false
+		}
+		symbol := m.binder.GetSymbolInfo(a)
+		return symbol != nil && symbol == m.binder.GetSymbolInfo(b)
+	case *syntax.PropertyAccessSyntax:
+		b, ok := b.(*syntax.PropertyAccessSyntax)
+		return ok && a.PropertyName.IsValid() && b.PropertyName.IsValid() &&
+			a.PropertyName.IdentifierName() == b.PropertyName.IdentifierName() && m.isSameReference(a.BaseExpression, b.BaseExpression)
+	}
+	return false
+}
+
+func (m *TypeManager) getObjectType(node *syntax.ObjectSyntax) types.TypeSymbol {
+	var properties []*types.TypeProperty
+	var additionalPropertiesType types.TypeSymbol
+	seen := map[string]bool{}
+
+	for _, property := range node.Properties() {
+		valueType := m.GetTypeInfo(property.Value)
+
+		name, ok := property.TryGetKeyText()
+		if !ok {
+			// the names of interpolated keys are only known at deployment
+			if key, isString := property.Key.(*syntax.StringSyntax); isString {
+				m.GetTypeInfo(key)
+				additionalPropertiesType = types.Any
+			}
+			continue
+		}
+
+		if seen[name] {
+			m.addDiagnostic(propertyMultipleDeclarations(property.Key.GetSpan(), name))
+			continue
+		}
+		seen[name] = true
+		properties = append(properties, types.NewTypeProperty(name, valueType, types.TypePropertyFlagsRequired))
+	}
+
+	return types.NewObjectType("object", properties, additionalPropertiesType)
+}
+
+// getPropertyType returns the type of a property of a value of the base type, reporting unknown properties.
+func (m *TypeManager) getPropertyType(baseType types.TypeSymbol, name string, nameSyntax syntax.SyntaxBase) types.TypeSymbol {
+	switch baseType := baseType.(type) {
+	case *types.AnyType, *types.ErrorType:
+		return baseType
+	case *types.ResourceType:
+		return m.getObjectPropertyType(baseType, baseType.Body, name, nameSyntax)
+	case *types.ModuleType:
+		return m.getObjectPropertyType(baseType, baseType.Body, name, nameSyntax)
+	case *types.ObjectType:
+		return m.getObjectPropertyType(baseType, baseType, name, nameSyntax)
+	case *types.UnionType:
+		return m.getUnionPropertyType(baseType, baseType.Members, name, nameSyntax)
+	case *types.DiscriminatedObjectType:
+		members := make([]types.TypeSymbol, 0, len(baseType.Members))
+		for _, member := range baseType.Members {
+			members = append(members, member)
+		}
+		return m.getUnionPropertyType(baseType, members, name, nameSyntax)
+	}
+
+	m.addDiagnostic(objectRequiredForPropertyAccess(nameSyntax.GetSpan(), baseType))
+	return types.Error
+}
+
+// getUnionPropertyType returns the union of the types of a property that every member of a union must have.
+func (m *TypeManager) getUnionPropertyType(baseType types.TypeSymbol, members []types.TypeSymbol, name string, nameSyntax syntax.SyntaxBase) types.TypeSymbol {
+	var memberTypes []types.TypeSymbol
+	for _, member := range members {
+		memberType, ok := tryGetPropertyType(member, name)
+		if !ok {
+			m.addDiagnostic(unknownProperty(nameSyntax.GetSpan(), baseType, name))
+			return types.Error
+		}
+		memberTypes = append(memberTypes, memberType)
+	}
+	return types.CreateUnion(memberTypes...)
+}
+
+func (m *TypeManager) getObjectPropertyType(baseType types.TypeSymbol, objectType *types.ObjectType, name string, nameSyntax syntax.SyntaxBase) types.TypeSymbol {
+	if property := objectType.TryGetProperty(name); property != nil {
+		if property.Flags&types.TypePropertyFlagsWriteOnly != 0 {
+			m.addDiagnostic(writeOnlyProperty(nameSyntax.GetSpan(), baseType, name))
+			return types.Error
+		}
+		return getReadPropertyType(baseType, property)
+	}
+	if objectType.AdditionalPropertiesType != nil {
+		return objectType.AdditionalPropertiesType
+	}
+
+	available := propertyNames(objectType, types.TypePropertyFlagsWriteOnly)
+	if len(available) == 0 {
+		m.addDiagnostic(unknownProperty(nameSyntax.GetSpan(), baseType, name))
+	} else {
+		m.addDiagnostic(unknownPropertyWithAvailableProperties(nameSyntax.GetSpan(), baseType, name, available))
+	}
+	return types.Error
+}
+
+// getReadPropertyType returns the type of reading a property. Optional properties of objects may be absent,
+// which reads as null. The bodies of resources and modules are typed by the properties they are declared with.
+func getReadPropertyType(baseType types.TypeSymbol, property *types.TypeProperty) types.TypeSymbol {
+	if _, ok := baseType.(*types.ObjectType); ok && !property.IsRequired() {
+		return makeNullable(property.Type)
+	}
+	return property.Type
+}
+
+// tryGetPropertyType looks up a readable property without reporting anything.
+func tryGetPropertyType(baseType types.TypeSymbol, name string) (types.TypeSymbol, bool) {
+	var objectType *types.ObjectType
+	switch baseType := baseType.(type) {
+	case *types.AnyType, *types.ErrorType:
+		return baseType, true
+	case *types.ResourceType:
+		objectType = baseType.Body
+	case *types.ModuleType:
+		objectType = baseType.Body
+	case *types.ObjectType:
+		objectType = baseType
+	default:
+		return nil, false
+	}
+
+	if property := objectType.TryGetProperty(name); property != nil && property.Flags&types.TypePropertyFlagsWriteOnly == 0 {
+		return getReadPropertyType(baseType, property), true
+	}
+	if objectType.AdditionalPropertiesType != nil {
+		return objectType.AdditionalPropertiesType, true
+	}
+	return nil, false
+}
+
+func (m *TypeManager) getIndexedType(baseType types.TypeSymbol, indexExpression syntax.SyntaxBase) types.TypeSymbol {
+	indexType := m.GetTypeInfo(indexExpression)
+	if _, ok := indexType.(*types.ErrorType); ok {
+		return types.Error
+	}
+
+	switch baseType := baseType.(type) {
+	case *types.AnyType, *types.ErrorType:
+		return baseType
+
+	case *types.ArrayType:
+		if !types.AreTypesAssignable(indexType, types.Int) {
+			m.addDiagnostic(arraysRequireIntegerIndex(indexExpression.GetSpan(), indexType))
+			return types.Error
+		}
+		return baseType.Item
+
+	case *types.TupleType:
+		if !types.AreTypesAssignable(indexType, types.Int) {
+			m.addDiagnostic(arraysRequireIntegerIndex(indexExpression.GetSpan(), indexType))
+			return types.Error
+		}
+		if index, ok := indexType.(*types.IntegerLiteralType); ok {
+			if index.Value < 0 || index.Value >= int64(len(baseType.Items)) {
+				m.addDiagnostic(indexOutOfBounds(indexExpression.GetSpan(), index.Value, baseType, len(baseType.Items)-1))
+				return types.Error
+			}
+			return baseType.Items[index.Value]
+		}
+		return baseType.Item()
+
+	case *types.ObjectType, *types.DiscriminatedObjectType, *types.ResourceType, *types.ModuleType:
+		if !types.AreTypesAssignable(indexType, types.String) {
+			m.addDiagnostic(objectsRequireStringIndex(indexExpression.GetSpan(), indexType))
+			return types.Error
+		}
+		if name, ok := indexType.(*types.StringLiteralType); ok {
+			return m.getPropertyType(baseType, name.Value, indexExpression)
+		}
+		return types.Any
+
+	case *types.UnionType:
+		// unions of arrays and objects are only known at deployment
+		return types.Any
+	}
+
+	m.addDiagnostic(indexerRequiresObjectOrArray(indexExpression.GetSpan(), baseType))
+	return types.Error
+}
+
+// getInstanceFunctionCallType types calls qualified by a namespace or a resource.
+func (m *TypeManager) getInstanceFunctionCallType(node *syntax.InstanceFunctionCallSyntax) types.TypeSymbol {
+	baseType := m.GetTypeInfo(node.BaseExpression)
+	argumentTypes := m.getArgumentTypes(node.Arguments)
+
+	switch symbol := m.binder.GetSymbolInfo(node).(type) {
+	case *FunctionSymbol:
+		returnType := m.resolveOverloads(symbol.Function.Overloads, node.Name, node.Arguments, argumentTypes)
+		m.validateFileLoadArguments(node, symbol.Function, node.Arguments)
+		return returnType
+	case *ErrorSymbol:
+		return types.Error
+	}
+	if wildcard, ok := m.binder.GetSymbolInfo(node.BaseExpression).(*WildcardImportSymbol); ok {
+		return m.getWildcardFunctionCallType(node, wildcard, argumentTypes)
+	}
+
+	switch baseType.(type) {
+	case *types.AnyType, *types.ErrorType:
+		return baseType
+	case *types.ResourceType:
+		// resource functions such as listKeys() are typed by the function library
+		return types.Any
+	}
+
+	if node.Name.IsValid() {
+		m.addDiagnostic(functionNotFoundOnType(node.Name.GetSpan(), baseType, node.Name.IdentifierName()))
+	}
+	return types.Error
+}
+
+// validateCollectionReference reports references to every instance of a resource or module loop, which templates
+// only support as dependencies. Single instances are referenced with an index, e.g. storage[0].
+func (m *TypeManager) validateCollectionReference(node *syntax.VariableAccessSyntax, symbol Symbol) {
+	var value syntax.SyntaxBase
+	switch symbol := symbol.(type) {
+	case *ResourceSymbol:
+		value = symbol.Declaration.Value
+	case *ModuleSymbol:
+		value = symbol.Declaration.Value
+	}
+	if _, ok := value.(*syntax.ForSyntax); !ok {
+		return
+	}
+
+	switch parent := m.binder.GetParent(node).(type) {
+	case *syntax.ArrayAccessSyntax:
+		if parent.BaseExpression == node {
+			return
+		}
+	case *syntax.ArrayItemSyntax:
+		if property, ok := m.binder.GetParent(m.binder.GetParent(parent)).(*syntax.ObjectPropertySyntax); ok {
+			if name, ok := property.TryGetKeyText(); ok && name == syntax.RESOURCE_PROPERTY_DEPENDS_ON {
+				return
+			}
+		}
+	}
+	m.addDiagnostic(directAccessToCollectionNotSupported(node.GetSpan()))
+}
+
+// validateFileLoadArguments checks that the arguments of functions reading files at compile time, such as the
+// path of loadJsonContent(), are compile-time constants, and loads the file if they are.
+func (m *TypeManager) validateFileLoadArguments(call syntax.SyntaxBase, function *namespaces.Function, arguments []*syntax.FunctionArgumentSyntax) {
+	if !function.Overloads[0].HasFlag(namespaces.FunctionFlagsFileLoad) {
+		return
+	}
+	valid := len(arguments) > 0
+	var values []string
+	for _, argument := range arguments {
+		if _, ok := m.GetTypeInfo(argument).(*types.ErrorType); ok {
+			valid = false
+			continue
+		}
+		value, ok := m.evaluator.TryEvaluate(argument.Expression)
+		if !ok {
+			m.addDiagnostic(compileTimeConstantRequired(argument.GetSpan()))
+			valid = false
+			continue
+		}
+		text, ok := value.(string)
+		valid = valid && ok
+		values = append(values, text)
+	}
+	if valid {
+		m.loadFile(call, function, arguments, values)
+	}
+}
+
+// getDeclaredFunctionOverload returns the signature of a user-defined function as an overload, so that calls
+// to it are checked like calls to built-in functions.
+func (m *TypeManager) getDeclaredFunctionOverload(symbol *DeclaredFunctionSymbol) *namespaces.FunctionOverload {
+	lambdaType, ok := m.GetDeclaredType(symbol.Declaration).(*types.LambdaType)
+	if !ok {
+		return nil
+	}
+
+	var parameterNames []string
+	for _, variable := range symbol.Declaration.TryGetLambda().GetLocalVariables() {
+		parameterNames = append(parameterNames, variable.Name.IdentifierName())
+	}
+	return newLambdaOverload(symbol.GetName(), parameterNames, lambdaType)
+}
+
+// newLambdaOverload returns an overload with a required parameter for every argument of the lambda type.
+// Parameters without a name are named after their position.
+func newLambdaOverload(name string, parameterNames []string, lambdaType *types.LambdaType) *namespaces.FunctionOverload {
+	builder := namespaces.NewFunctionOverloadBuilder(name)
+	for i, argumentType := range lambdaType.ArgumentTypes {
+		parameterName := fmt.Sprintf("arg%d", i)
+		if i < len(parameterNames) {
+			parameterName = parameterNames[i]
+		}
+		builder.WithRequiredParameter(parameterName, argumentType, "")
+	}
+	return builder.WithReturnType(lambdaType.ReturnType).Build()
+}
+
+// getLambdaType types the arguments of a lambda after the parameter of the function it is passed to, and its
+// return type after its body.
+func (m *TypeManager) getLambdaType(node *syntax.LambdaSyntax) types.TypeSymbol {
+	expectedType, isArgument := m.getExpectedLambdaType(node)
+	bodyType := m.GetTypeInfo(node.Body)
+	if !isArgument {
+		m.addDiagnostic(lambdaFunctionsOnlyValidInFunctionArguments(node.GetSpan()))
+		return types.Error
+	}
+
+	variables := node.GetLocalVariables()
+	if expectedType == nil {
+		argumentTypes := make([]types.TypeSymbol, len(variables))
+		for i := range variables {
+			argumentTypes[i] = types.Any
+		}
+		return types.NewLambdaType(argumentTypes, bodyType)
+	}
+	if len(variables) < expectedType.MinimumArgumentCount() || len(variables) > expectedType.MaximumArgumentCount() {
+		m.addDiagnostic(lambdaExpectedArgCountMismatch(node.VariableSection.GetSpan(), expectedType, len(variables)))
+		return types.Error
+	}
+
+	argumentTypes := make([]types.TypeSymbol, 0, len(variables))
+	for i := range variables {
+		argumentTypes = append(argumentTypes, expectedType.GetArgumentType(i))
+	}
+	return types.NewLambdaType(argumentTypes, bodyType)
+}
+
+// getExpectedLambdaType returns the lambda type the parameter a lambda is passed to expects, calculated from
+// the other arguments of the call. It returns nil if the parameter does not take lambdas, and false if the
+// lambda is not a function argument at all.
+func (m *TypeManager) getExpectedLambdaType(node *syntax.LambdaSyntax) (*types.LambdaType, bool) {
+	argument, ok := m.binder.GetHierarchy().GetParent(node).(*syntax.FunctionArgumentSyntax)
+	if !ok {
+		return nil, false
+	}
+
+	call := m.binder.GetHierarchy().GetParent(argument)
+	var arguments []*syntax.FunctionArgumentSyntax
+	switch call := call.(type) {
+	case *syntax.FunctionCallSyntax:
+		arguments = call.Arguments
+	case *syntax.InstanceFunctionCallSyntax:
+		arguments = call.Arguments
+	}
+	symbol, ok := m.binder.GetSymbolInfo(call).(*FunctionSymbol)
+	if !ok {
+		return nil, true
+	}
+
+	index := -1
+	// lambdas are typed after the other arguments, so they are left out to avoid cycles
+	argumentTypes := make([]types.TypeSymbol, 0, len(arguments))
+	for i, other := range arguments {
+		if other == argument {
+			index = i
+		}
+		if _, isLambda := other.Expression.(*syntax.LambdaSyntax); isLambda {
+			argumentTypes = append(argumentTypes, types.Any)
+		} else {
+			argumentTypes = append(argumentTypes, m.GetTypeInfo(other))
+		}
+	}
+
+	for _, overload := range symbol.Function.Overloads {
+		if lambdaType, ok := overload.GetCalculatedParameterType(index, argumentTypes).(*types.LambdaType); ok {
+			return lambdaType, true
+		}
+	}
+	return nil, true
+}
+
+func (m *TypeManager) getArgumentTypes(arguments []*syntax.FunctionArgumentSyntax) []types.TypeSymbol {
+	argumentTypes := make([]types.TypeSymbol, 0, len(arguments))
+	for _, argument := range arguments {
+		argumentTypes = append(argumentTypes, m.GetTypeInfo(argument))
+	}
+	return argumentTypes
+}
+
+// resolveOverloads resolves the overloads of a built-in function or decorator that match the arguments and
+// returns the union of their return types. Arguments with errors are not reported again.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/TypeSystem/FunctionResolver.cs
+func (m *TypeManager) resolveOverloads(overloads []*namespaces.FunctionOverload, name *syntax.IdentifierSyntax, arguments []*syntax.FunctionArgumentSyntax, argumentTypes []types.TypeSymbol) types.TypeSymbol {
+	for _, argumentType := range argumentTypes {
+		if _, ok := argumentType.(*types.ErrorType); ok {
+			return types.Error
+		}
+	}
+
+	var countMatches []*namespaces.FunctionOverload
+	for _, overload := range overloads {
+		maximum := overload.MaximumArgumentCount()
+		if len(arguments) >= overload.MinimumArgumentCount() && (maximum < 0 || len(arguments) <= maximum) {
+			countMatches = append(countMatches, overload)
+		}
+	}
+	if len(countMatches) == 0 {
+		minimum, maximum := getArgumentCountRange(overloads)
+		m.addDiagnostic(argumentCountMismatch(name.GetSpan(), len(arguments), minimum, maximum))
+		return types.Error
+	}
+
+	var returnTypes []types.TypeSymbol
+	for _, overload := range countMatches {
+		if findArgumentMismatch(overload, argumentTypes) < 0 {
+			returnTypes = append(returnTypes, overload.GetReturnType(argumentTypes))
+		}
+	}
+	if len(returnTypes) > 0 {
+		return types.CreateUnion(returnTypes...)
+	}
+
+	if len(countMatches) == 1 {
+		index := findArgumentMismatch(countMatches[0], argumentTypes)
+		m.addDiagnostic(argumentTypeMismatch(arguments[index].GetSpan(), argumentTypes[index], countMatches[0].GetParameterType(index)))
+	} else {
+		m.addDiagnostic(cannotResolveFunctionOverload(name.GetSpan(), countMatches))
+	}
+	return types.Error
+}
+
+// findArgumentMismatch returns the index of the first argument that the overload does not accept, or -1.
+func findArgumentMismatch(overload *namespaces.FunctionOverload, argumentTypes []types.TypeSymbol) int {
+	for i, argumentType := range argumentTypes {
+		if !types.AreTypesAssignable(argumentType, overload.GetParameterType(i)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// getArgumentCountRange returns the range of argument counts accepted by any of the overloads.
+// The maximum is -1 if the number of arguments is unlimited.
+func getArgumentCountRange(overloads []*namespaces.FunctionOverload) (int, int) {
+	minimum, maximum := math.MaxInt, 0
+	for _, overload := range overloads {
+		minimum = min(minimum, overload.MinimumArgumentCount())
+		if overload.MaximumArgumentCount() < 0 || maximum < 0 {
+			maximum = -1
+		} else {
+			maximum = max(maximum, overload.MaximumArgumentCount())
+		}
+	}
+	return minimum, maximum
+}
+
+func (m *TypeManager) getUnaryOperationType(node *syntax.UnaryOperationSyntax) types.TypeSymbol {
+	operandType := m.GetTypeInfo(node.Expression)
+	if _, ok := operandType.(*types.ErrorType); ok {
+		return types.Error
+	}
+
+	switch node.Operator {
+	case syntax.UnaryOperatorNot:
+		if literal, ok := operandType.(*types.BooleanLiteralType); ok {
+			return types.NewBooleanLiteralType(!literal.Value)
+		}
+		if types.AreTypesAssignable(operandType, types.Bool) {
+			return types.Bool
+		}
+	case syntax.UnaryOperatorMinus:
+		if literal, ok := node.Expression.(*syntax.IntegerLiteralSyntax); ok && literal.Value == -math.MinInt64 {
+			return types.NewIntegerLiteralType(math.MinInt64)
+		}
+		if literal, ok := operandType.(*types.IntegerLiteralType); ok && literal.Value != math.MinInt64 {
+			return types.NewIntegerLiteralType(-literal.Value)
+		}
+		if types.AreTypesAssignable(operandType, types.Int) {
+			return types.Int
+		}
+	}
+
+	m.addDiagnostic(unaryOperatorInvalidType(node.GetSpan(), syntax.GetUnaryOperatorText(node.Operator), operandType))
+	return types.Error
+}
+
+func (m *TypeManager) getBinaryOperationType(node *syntax.BinaryOperationSyntax) types.TypeSymbol {
+	leftType := m.GetTypeInfo(node.LeftExpression)
+	rightType := m.GetTypeInfo(node.RightExpression)
+	if _, ok := leftType.(*types.ErrorType); ok {
+		return types.Error
+	}
+	if _, ok := rightType.(*types.ErrorType); ok {
+		return types.Error
+	}
+
+	both := func(operandType types.TypeSymbol) bool {
+		return types.AreTypesAssignable(leftType, operandType) && types.AreTypesAssignable(rightType, operandType)
+	}
+
+	switch node.Operator {
+	case syntax.BinaryOperatorLogicalOr, syntax.BinaryOperatorLogicalAnd:
+		if both(types.Bool) {
+			return types.Bool
+		}
+	case syntax.BinaryOperatorEquals, syntax.BinaryOperatorNotEquals:
+		return types.Bool
+	case syntax.BinaryOperatorEqualsInsensitive, syntax.BinaryOperatorNotEqualsInsensitive:
+		if both(types.String) {
+			return types.Bool
+		}
+	case syntax.BinaryOperatorLessThan, syntax.BinaryOperatorLessThanOrEqual, syntax.BinaryOperatorGreaterThan, syntax.BinaryOperatorGreaterThanOrEqual:
+		if both(types.Int) || both(types.String) {
+			return types.Bool
+		}
+	case syntax.BinaryOperatorAdd, syntax.BinaryOperatorSubtract, syntax.BinaryOperatorMultiply, syntax.BinaryOperatorDivide, syntax.BinaryOperatorModulo:
+		if both(types.Int) {
+			return types.Int
+		}
+	case syntax.BinaryOperatorCoalesce:
+		return types.CreateUnion(types.RemoveNullability(leftType), rightType)
+	}
+
+	m.addDiagnostic(binaryOperatorInvalidType(node.GetSpan(), syntax.GetBinaryOperatorText(node.Operator), leftType, rightType))
+	return types.Error
+}
+
+// isNegatedMinInt64 returns whether an integer literal is the 9223372036854775808 of -9223372036854775808, the only
+// literal outside the range of int64 that is valid, as the operand of a unary minus.
+func isNegatedMinInt64(binder *Binder, literal *syntax.IntegerLiteralSyntax) bool {
+	unary, ok := binder.GetParent(literal).(*syntax.UnaryOperationSyntax)
+	return ok && unary.Operator == syntax.UnaryOperatorMinus && literal.Value == -math.MinInt64
+}
@@ -0,0 +1,551 @@
+package semantics
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/namespaces"
+	"bicep-go/syntax"
+	"bicep-go/types"
+	"sort"
+)
+
+// TypeManager infers the type of every expression in a file, evaluates type expressions,
+// and checks that the values of declarations match the types they are assigned to.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/TypeSystem/TypeManager.cs
+type TypeManager struct {
+	binder      *Binder
+	provider    types.ResourceTypeProvider
+	modules     ModuleLookup
+	diagnostics []*diagnostics.Diagnostic
+
+	typeInfo      map[syntax.SyntaxBase]types.TypeSymbol
+	declaredTypes map[syntax.SyntaxBase]types.TypeSymbol
+	resourceTypes map[*ResourceSymbol]types.TypeSymbol
+	moduleTypes   map[*syntax.ModuleDeclarationSyntax]types.TypeSymbol
+	// importedModels caches the models of imported files, which are nil for files that cannot be used.
+	importedModels map[*syntax.CompileTimeImportDeclarationSyntax]ModuleModel
+	// loadedFiles are the values of the calls to functions loading files at compile time.
+	loadedFiles map[syntax.SyntaxBase]any
+	// inProgress guards against cycles between declarations, which are reported by the cycle checker.
+	inProgress map[syntax.SyntaxBase]bool
+	evaluator  *ConstantEvaluator
+}
+
+// NewTypeManager type checks the file of the binder. Modules and imported symbols are loosely typed if modules is nil.
+func NewTypeManager(binder *Binder, provider types.ResourceTypeProvider, modules ModuleLookup) *TypeManager {
+	m := &TypeManager{
+		binder:         binder,
+		provider:       provider,
+		modules:        modules,
+		typeInfo:       map[syntax.SyntaxBase]types.TypeSymbol{},
+		declaredTypes:  map[syntax.SyntaxBase]types.TypeSymbol{},
+		resourceTypes:  map[*ResourceSymbol]types.TypeSymbol{},
+		moduleTypes:    map[*syntax.ModuleDeclarationSyntax]types.TypeSymbol{},
+		importedModels: map[*syntax.CompileTimeImportDeclarationSyntax]ModuleModel{},
+		loadedFiles:    map[syntax.SyntaxBase]any{},
+		inProgress:     map[syntax.SyntaxBase]bool{},
+		evaluator:      NewConstantEvaluator(binder, EvaluationFlagsDefault),
+	}
+
+	m.checkFile()
+	diagnostics.Sort(m.diagnostics)
+	return m
+}
+
+func (m *TypeManager) GetDiagnostics() []*diagnostics.Diagnostic {
+	return m.diagnostics
+}
+
+func (m *TypeManager) addDiagnostic(diagnostic *diagnostics.Diagnostic) {
+	m.diagnostics = append(m.diagnostics, diagnostic)
+}
+
+// GetTypeInfo returns the inferred type of an expression.
+func (m *TypeManager) GetTypeInfo(node syntax.SyntaxBase) types.TypeSymbol {
+	if typeSymbol, ok := m.typeInfo[node]; ok {
+		return typeSymbol
+	}
+	if m.inProgress[node] {
+		return types.Error
+	}
+
+	m.inProgress[node] = true
+	typeSymbol := m.computeType(node)
+	delete(m.inProgress, node)
+
+	m.typeInfo[node] = typeSymbol
+	return typeSymbol
+}
+
+// GetDeclaredType returns the type a declaration expects its value to have, or the type
+// denoted by a type expression. It returns nil for declarations without a declared type.
+func (m *TypeManager) GetDeclaredType(node syntax.SyntaxBase) types.TypeSymbol {
+	if typeSymbol, ok := m.declaredTypes[node]; ok {
+		return typeSymbol
+	}
+	if m.inProgress[node] {
+		return types.Error
+	}
+
+	m.inProgress[node] = true
+	typeSymbol := m.computeDeclaredType(node)
+	delete(m.inProgress, node)
+
+	m.declaredTypes[node] = typeSymbol
+	return typeSymbol
+}
+
+func (m *TypeManager) computeDeclaredType(node syntax.SyntaxBase) types.TypeSymbol {
+	switch node := node.(type) {
+	case *syntax.ParameterDeclarationSyntax:
+		declaredType := m.getDecoratedType(node, m.GetDeclaredType(node.Type))
+		if allowedType := m.tryGetAllowedType(node, declaredType); allowedType != nil {
+			return allowedType
+		}
+		return declaredType
+	case *syntax.OutputDeclarationSyntax:
+		return m.getDecoratedType(node, m.GetDeclaredType(node.Type))
+	case *syntax.TypeDeclarationSyntax:
+		return m.getDecoratedType(node, m.GetDeclaredType(node.Value))
+	case *syntax.ResourceDeclarationSyntax:
+		if resource := m.binder.TryGetResourceSymbol(node); resource != nil {
+			return m.GetSymbolType(resource)
+		}
+		return nil
+	case *syntax.ModuleDeclarationSyntax:
+		return m.wrapInLoop(node.Value, m.getModuleType(node))
+	case *syntax.TargetScopeSyntax:
+		return getTargetScopeType()
+	case *syntax.FunctionDeclarationSyntax:
+		lambda := node.TryGetLambda()
+		if lambda == nil {
+			return types.Error
+		}
+		var argumentTypes []types.TypeSymbol
+		for _, variable := range lambda.GetLocalVariables() {
+			argumentTypes = append(argumentTypes, m.GetDeclaredType(variable.Type))
+		}
+		return types.NewLambdaType(argumentTypes, m.GetDeclaredType(lambda.ReturnType))
+	case *syntax.MetadataDeclarationSyntax, *syntax.VariableDeclarationSyntax:
+		return nil
+	}
+	return m.getTypeFromTypeSyntax(node)
+}
+
+// GetSymbolType returns the type of a value that references the symbol.
+func (m *TypeManager) GetSymbolType(symbol Symbol) types.TypeSymbol {
+	switch symbol := symbol.(type) {
+	case *ParameterSymbol:
+		return m.GetDeclaredType(symbol.Declaration)
+	case *VariableSymbol:
+		return m.GetTypeInfo(symbol.Declaration.Value)
+	case *ResourceSymbol:
+		return m.wrapInLoop(symbol.Declaration.Value, m.getResourceType(symbol))
+	case *ModuleSymbol:
+		return m.GetDeclaredType(symbol.Declaration)
+	case *DeclaredFunctionSymbol:
+		return m.GetDeclaredType(symbol.Declaration)
+	case *ImportedSymbol:
+		return m.getImportedSymbolType(symbol)
+	case *WildcardImportSymbol:
+		return m.getWildcardImportType(symbol, ExportKindVariable)
+	case *LocalVariableSymbol:
+		return m.getLocalVariableType(symbol)
+	case *NamespaceSymbol:
+		return types.Any
+	}
+	return types.Error
+}
+
+// wrapInLoop returns an array of the type for resources and modules declared with a loop.
+func (m *TypeManager) wrapInLoop(value syntax.SyntaxBase, typeSymbol types.TypeSymbol) types.TypeSymbol {
+	if _, ok := value.(*syntax.ForSyntax); ok {
+		return types.NewArrayType(typeSymbol)
+	}
+	return typeSymbol
+}
+
+// getResourceType returns the type of a single instance of the resource.
+func (m *TypeManager) getResourceType(symbol *ResourceSymbol) types.TypeSymbol {
+	if typeSymbol, ok := m.resourceTypes[symbol]; ok {
+		return typeSymbol
+	}
+
+	typeSymbol := m.computeResourceType(symbol)
+	m.resourceTypes[symbol] = typeSymbol
+	return typeSymbol
+}
+
+func (m *TypeManager) computeResourceType(symbol *ResourceSymbol) types.TypeSymbol {
+	declaration := symbol.Declaration
+	typeString, ok := declaration.TypeString()
+	if !ok {
+		m.addDiagnostic(invalidResourceType(declaration.Type.GetSpan()))
+		return types.Error
+	}
+
+	var reference *types.ResourceTypeReference
+	if symbol.Parent == nil {
+		reference = types.TryParseResourceTypeReference(typeString)
+	} else if parent, ok := m.getResourceType(symbol.Parent).(*types.ResourceType); ok {
+		reference = parent.TypeReference.TryCombineChildTypeReference(typeString)
+	} else {
+		return types.Error
+	}
+	if reference == nil {
+		m.addDiagnostic(invalidResourceType(declaration.Type.GetSpan()))
+		return types.Error
+	}
+
+	resourceType := m.getResourceTypeForReference(reference, declaration.Type)
+	if declaration.IsExistingResource() {
+		existing := types.NewResourceType(resourceType.TypeReference, types.CreateExistingResourceBody(resourceType.Body))
+		existing.ValidParentScopes = resourceType.ValidParentScopes
+		return existing
+	}
+	return resourceType
+}
+
+func (m *TypeManager) getResourceTypeForReference(reference *types.ResourceTypeReference, typeSyntax syntax.SyntaxBase) *types.ResourceType {
+	resourceType, err := m.provider.TryGetResourceType(reference)
+	if err != nil {
+		m.addDiagnostic(resourceTypeUnloadable(typeSyntax.GetSpan(), reference, err))
+		return types.NewGenericResourceType(reference)
+	}
+	if resourceType != nil {
+		return resourceType
+	}
+	if apiVersions := m.provider.GetApiVersions(reference.Type); len(apiVersions) > 0 {
+		m.addDiagnostic(resourceTypeApiVersionUnavailable(typeSyntax.GetSpan(), reference, apiVersions))
+	} else {
+		m.addDiagnostic(resourceTypesUnavailable(typeSyntax.GetSpan(), reference))
+	}
+	return types.NewGenericResourceType(reference)
+}
+
+// getModuleType returns the type of a single instance of the module, typed by the parameters, outputs
+// and target scope of the referenced file. Without a module lookup, parameters and outputs are loosely typed.
+func (m *TypeManager) getModuleType(declaration *syntax.ModuleDeclarationSyntax) types.TypeSymbol {
+	if typeSymbol, ok := m.moduleTypes[declaration]; ok {
+		return typeSymbol
+	}
+
+	typeSymbol := m.computeModuleType(declaration)
+	m.moduleTypes[declaration] = typeSymbol
+	return typeSymbol
+}
+
+func (m *TypeManager) computeModuleType(declaration *syntax.ModuleDeclarationSyntax) types.TypeSymbol {
+	if m.modules == nil {
+		path, ok := declaration.TryGetPath()
+		if !ok {
+			return types.Error
+		}
+		return types.NewModuleType(path, types.Object, types.Object)
+	}
+
+	model, diagnostic := m.modules.TryGetModuleModel(declaration)
+	if model == nil {
+		if diagnostic != nil {
+			m.addDiagnostic(diagnostic)
+		}
+		return types.Error
+	}
+	path, _ := declaration.TryGetPath()
+	moduleType := types.NewModuleType(path, model.GetParametersType(), model.GetOutputsType())
+	moduleType.ValidParentScopes = model.GetTargetScope()
+	return moduleType
+}
+
+func (m *TypeManager) getLocalVariableType(symbol *LocalVariableSymbol) types.TypeSymbol {
+	switch symbol.LocalKind {
+	case LocalKindForIndex:
+		return types.Int
+	case LocalKindFunctionParameter:
+		return m.GetDeclaredType(symbol.Declaration.(*syntax.TypedLocalVariableSyntax).Type)
+	case LocalKindForItem:
+		for _, ancestor := range m.binder.GetHierarchy().GetAncestors(symbol.Declaration) {
+			if loop, ok := ancestor.(*syntax.ForSyntax); ok {
+				return getItemType(m.GetTypeInfo(loop.Expression))
+			}
+		}
+	case LocalKindLambdaItem:
+		for _, ancestor := range m.binder.GetHierarchy().GetAncestors(symbol.Declaration) {
+			lambda, ok := ancestor.(*syntax.LambdaSyntax)
+			if !ok {
+				continue
+			}
+			expectedType, _ := m.getExpectedLambdaType(lambda)
+			if expectedType == nil {
+				return types.Any
+			}
+			for i, variable := range lambda.GetLocalVariables() {
+				if variable == symbol.Declaration {
+					if argumentType := expectedType.GetArgumentType(i); argumentType != nil {
+						return argumentType
+					}
+				}
+			}
+			return types.Any
+		}
+	}
+	return types.Any
+}
+
+// getItemType returns the type of the items of an array, or an error for anything but an array.
+func getItemType(arrayType types.TypeSymbol) types.TypeSymbol {
+	switch arrayType := arrayType.(type) {
+	case *types.AnyType:
+		return types.Any
+	case *types.ArrayType:
+		return arrayType.Item
+	case *types.TupleType:
+		return arrayType.Item()
+	case *types.UnionType:
+		var items []types.TypeSymbol
+		for _, member := range arrayType.Members {
+			items = append(items, getItemType(member))
+		}
+		return types.CreateUnion(items...)
+	}
+	return types.Error
+}
+
+func (m *TypeManager) getTypeFromTypeSyntax(node syntax.SyntaxBase) types.TypeSymbol {
+	switch node := node.(type) {
+	case *syntax.TypeVariableAccessSyntax, *syntax.TypePropertyAccessSyntax:
+		switch symbol := m.binder.GetSymbolInfo(node).(type) {
+		case *AmbientTypeSymbol:
+			return getAmbientType(symbol.Name)
+		case *TypeAliasSymbol:
+			return m.GetDeclaredType(symbol.Declaration)
+		case *ImportedSymbol:
+			return m.getImportedTypeType(node, symbol)
+		case *WildcardImportSymbol:
+			return m.getWildcardImportType(symbol, ExportKindType)
+		}
+		if access, ok := node.(*syntax.TypePropertyAccessSyntax); ok && m.binder.GetSymbolInfo(node) == nil {
+			return m.getTypePropertyType(access)
+		}
+		return types.Error
+
+	case *syntax.ResourceTypeSyntax:
+		typeString, ok := node.TypeString()
+		if !ok {
+			return types.Error
+		}
+		reference := types.TryParseResourceTypeReference(typeString)
+		if reference == nil {
+			m.addDiagnostic(invalidResourceType(node.Type.GetSpan()))
+			return types.Error
+		}
+		return m.getResourceTypeForReference(reference, node.Type)
+
+	case *syntax.ArrayTypeSyntax:
+		return types.NewArrayType(m.GetDeclaredType(node.Item))
+
+	case *syntax.NullableTypeSyntax:
+		return types.CreateNullable(m.GetDeclaredType(node.Base))
+
+	case *syntax.UnionTypeSyntax:
+		var members []types.TypeSymbol
+		for _, member := range node.Members {
+			members = append(members, m.GetDeclaredType(member))
+		}
+		return types.CreateUnion(members...)
+
+	case *syntax.ObjectTypeSyntax:
+		var properties []*types.TypeProperty
+		for _, property := range node.Properties() {
+			name, ok := property.TryGetKeyText()
+			if !ok {
+				continue
+			}
+			propertyType := m.getDecoratedType(property, m.GetDeclaredType(property.Value))
+			flags := types.TypePropertyFlagsRequired
+			if property.IsOptional() || types.IsNullable(propertyType) {
+				flags = types.TypePropertyFlagsNone
+			}
+			properties = append(properties, types.NewTypeProperty(name, propertyType, flags))
+		}
+
+		var additionalPropertiesType types.TypeSymbol
+		if additionalProperties := node.AdditionalProperties(); additionalProperties != nil {
+			additionalPropertiesType = m.getDecoratedType(additionalProperties, m.GetDeclaredType(additionalProperties.Value))
+		}
+		return types.NewObjectType(types.FormatObjectTypeName(properties, additionalPropertiesType), properties, additionalPropertiesType)
+
+	case *syntax.TupleTypeSyntax:
+		var items []types.TypeSymbol
+		for _, item := range node.Items {
+			items = append(items, m.getDecoratedType(item, m.GetDeclaredType(item.Value)))
+		}
+		return types.NewTupleType(items)
+
+	case *syntax.ParenthesizedExpressionSyntax:
+		return m.GetDeclaredType(node.Expression)
+
+	case *syntax.StringSyntax:
+		if value, ok := node.TryGetLiteralValue(); ok {
+			return types.NewStringLiteralType(value)
+		}
+		return types.String
+
+	case *syntax.IntegerLiteralSyntax, *syntax.UnaryOperationSyntax, *syntax.BooleanLiteralSyntax, *syntax.NullLiteralSyntax:
+		// literal types are the types of the equivalent literal expressions
+		return m.GetTypeInfo(node)
+	}
+
+	return types.Error
+}
+
+// getTypePropertyType resolves a property of a user-defined object type, e.g. myType.name.
+func (m *TypeManager) getTypePropertyType(node *syntax.TypePropertyAccessSyntax) types.TypeSymbol {
+	baseType := m.GetDeclaredType(node.BaseExpression)
+	if !node.PropertyName.IsValid() {
+		return types.Error
+	}
+	return m.getPropertyType(baseType, node.PropertyName.IdentifierName(), node.PropertyName)
+}
+
+func getAmbientType(name string) types.TypeSymbol {
+	switch name {
+	case syntax.TYPE_NAME_STRING:
+		return types.String
+	case syntax.TYPE_NAME_INT:
+		return types.Int
+	case syntax.TYPE_NAME_BOOL:
+		return types.Bool
+	case syntax.TYPE_OBJECT:
+		return types.Object
+	case syntax.TYPE_ARRAY:
+		return types.Array
+	}
+	return types.Error
+}
+
+// checkFile computes the type of every expression and checks every declaration against its declared type.
+func (m *TypeManager) checkFile() {
+	program := m.binder.GetFileSymbol().Program
+
+	for _, declaration := range program.Declarations() {
+		switch declaration := declaration.(type) {
+		case *syntax.TargetScopeSyntax:
+			m.validateAssignment(declaration.Value, m.GetDeclaredType(declaration), false, expectedValueTypeMismatch)
+		case *syntax.MetadataDeclarationSyntax:
+			m.GetTypeInfo(declaration.Value)
+		case *syntax.ParameterDeclarationSyntax:
+			declaredType := m.GetDeclaredType(declaration)
+			if defaultValue := declaration.DefaultValue(); defaultValue != nil {
+				m.validateParameterDefault(defaultValue, declaredType)
+			}
+		case *syntax.VariableDeclarationSyntax:
+			m.GetTypeInfo(declaration.Value)
+		case *syntax.ModuleDeclarationSyntax:
+			if moduleType, ok := m.getModuleType(declaration).(*types.ModuleType); ok {
+				m.validateDeclarationBody(declaration.Value, moduleType.Body, syntax.KEYWORD_MODULE)
+			} else {
+				m.GetTypeInfo(declaration.Value)
+			}
+		case *syntax.OutputDeclarationSyntax:
+			m.validateAssignment(declaration.Value, m.GetDeclaredType(declaration), false, outputTypeMismatch)
+		case *syntax.TypeDeclarationSyntax:
+			m.GetDeclaredType(declaration)
+		case *syntax.FunctionDeclarationSyntax:
+			m.checkFunction(declaration)
+		}
+	}
+
+	m.validateImports()
+	m.validateExports()
+
+	for _, resource := range m.binder.GetFileSymbol().AllResources() {
+		if resourceType, ok := m.getResourceType(resource).(*types.ResourceType); ok {
+			m.validateDeclarationBody(resource.Declaration.Value, resourceType.Body, syntax.KEYWORD_RESOURCE)
+		} else {
+			m.GetTypeInfo(resource.Declaration.Value)
+		}
+	}
+
+	m.validateScopes()
+	m.validateDecorators(program)
+}
+
+// validateParameterDefault checks the default value of a parameter against its declared type. Values that are
+// not assignable by their type, e.g. toLower('X') for a parameter narrowed by @allowed to 'x', are accepted if
+// their folded value is.
+func (m *TypeManager) validateParameterDefault(defaultValue syntax.SyntaxBase, declaredType types.TypeSymbol) {
+	if !types.AreTypesAssignable(m.GetTypeInfo(defaultValue), declaredType) {
+		if constant, ok := m.evaluator.TryEvaluate(defaultValue); ok {
+			if constantType := getConstantType(constant); constantType != nil && types.AreTypesAssignable(constantType, declaredType) {
+				return
+			}
+		}
+	}
+	m.validateAssignment(defaultValue, declaredType, false, parameterTypeMismatch)
+}
+
+// checkFunction checks the body of a user-defined function against its return type. The body is evaluated at
+// the start of the deployment, so it cannot call functions that must be inlined where they are used.
+func (m *TypeManager) checkFunction(declaration *syntax.FunctionDeclarationSyntax) {
+	lambda := declaration.TryGetLambda()
+	if lambda == nil {
+		return
+	}
+	if lambdaType, ok := m.GetDeclaredType(declaration).(*types.LambdaType); ok {
+		m.validateAssignment(lambda.Body, lambdaType.ReturnType, false, expectedValueTypeMismatch)
+	}
+
+	syntax.Inspect(lambda.Body, func(node syntax.SyntaxBase) bool {
+		var name *syntax.IdentifierSyntax
+		switch node := node.(type) {
+		case *syntax.FunctionCallSyntax:
+			name = node.Name
+		case *syntax.InstanceFunctionCallSyntax:
+			name = node.Name
+		default:
+			return true
+		}
+		if symbol, ok := m.binder.GetSymbolInfo(node).(*FunctionSymbol); ok {
+			for _, overload := range symbol.Function.Overloads {
+				if overload.HasFlag(namespaces.FunctionFlagsRequiresInlining) {
+					m.addDiagnostic(functionValueRequiredAtDeploymentStart(name.GetSpan()))
+					break
+				}
+			}
+		}
+		return true
+	})
+}
+
+// validateDeclarationBody checks the body of a resource or module, looking through loops and conditions.
+func (m *TypeManager) validateDeclarationBody(value syntax.SyntaxBase, bodyType *types.ObjectType, blockName string) {
+	m.GetTypeInfo(value)
+
+	if body := tryGetDeclarationBody(value); body != nil {
+		m.validateObject(body, bodyType, bodyType.WarnOnTypeMismatch(), blockName)
+	}
+}
+
+func tryGetDeclarationBody(value syntax.SyntaxBase) *syntax.ObjectSyntax {
+	switch value := value.(type) {
+	case *syntax.ObjectSyntax:
+		return value
+	case *syntax.IfConditionSyntax:
+		return tryGetDeclarationBody(value.Body)
+	case *syntax.ForSyntax:
+		return tryGetDeclarationBody(value.Body)
+	}
+	return nil
+}
+
+// propertyNames returns the names of the properties that can be read or assigned, sorted alphabetically.
+func propertyNames(objectType *types.ObjectType, excludeFlags types.TypePropertyFlags) []string {
+	var names []string
+	for _, property := range objectType.Properties {
+		if property.Flags&excludeFlags == 0 {
+			names = append(names, property.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
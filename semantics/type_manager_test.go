@@ -0,0 +1,316 @@
+package semantics
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/parser"
+	"bicep-go/syntax"
+	"bicep-go/types"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func checkText(t *testing.T, text string) (*Binder, *TypeManager) {
+	p := parser.New(text)
+	program := p.Program()
+	require.Empty(t, p.GetDiagnostics())
+
+	binder := NewBinder(program)
+	require.Empty(t, binder.GetDiagnostics())
+	return binder, NewTypeManager(binder, types.NewGenericResourceTypeProvider(), nil)
+}
+
+func formatDiagnostics(diagnostics []*diagnostics.Diagnostic) []string {
+	var formatted []string
+	for _, diagnostic := range diagnostics {
+		formatted = append(formatted, diagnostic.ToString())
+	}
+	return formatted
+}
+
+func TestTypeManagerDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"valid defaults", "param s string = 'a'\nparam i int = -1\nparam b bool = !true\nparam o object = {}\nparam a array = []\n", nil},
+		{"default mismatch", "param s string = 1\n", []string{"[17:18] Error BCP027: The parameter expects a default value of type \"string\" but provided value is of type \"1\"."}},
+		{"literal union", "param size 'small' | 'large' = 'medium'\n", []string{"[31:39] Error BCP027: The parameter expects a default value of type \"'small' | 'large'\" but provided value is of type \"'medium'\"."}},
+		{"typed array", "param names string[] = ['a', 1]\n", []string{"[29:30] Error BCP034: The enclosing array expected an item of type \"string\", but the provided item was of type \"1\"."}},
+		{"nullable", "param s string? = null\n", nil},
+		{"not nullable", "param s string = null\n", []string{"[17:21] Error BCP027: The parameter expects a default value of type \"string\" but provided value is of type \"null\"."}},
+		{"output mismatch", "output o int = 'a'\n", []string{"[15:18] Error BCP026: The output expects a value of type \"int\" but the provided value is of type \"'a'\"."}},
+		{"object type", "type t = {\n  name: string\n  size?: int\n}\nparam p t = {\n  name: 'a'\n}\n", nil},
+		{"missing property", "type t = {\n  name: string\n}\nparam p t = {}\n", []string{"[40:41] Error BCP035: The specified \"object\" declaration is missing the following required properties: \"name\"."}},
+		{"property mismatch", "type t = {\n  name: string\n}\nparam p t = {\n  name: 1\n}\n", []string{"[50:51] Error BCP036: The property \"name\" expected a value of type \"string\" but the provided value is of type \"1\"."}},
+		{"additional properties", "type t = {\n  *: int\n}\nparam p t = {\n  a: 1\n  b: 'x'\n}\n", []string{"[48:51] Error BCP036: The property \"b\" expected a value of type \"int\" but the provided value is of type \"'x'\"."}},
+		{"disallowed property", "type t = {\n  name: string\n}\nparam p t = {\n  name: 'a'\n  other: 1\n}\n", []string{"[56:61] Error BCP037: The property \"other\" is not allowed on objects of type \"{ name: string }\". Permissible properties include \"name\"."}},
+		{"sealed type", "@sealed()\ntype closed = {\n  a: int\n}\nparam p closed = {\n  a: 1\n  b: 2\n}\n", []string{"[65:66] Error BCP037: The property \"b\" is not allowed on objects of type \"{ a: int }\". Permissible properties include \"a\"."}},
+		{"tuple", "param p [string, int] = ['a', 1]\n", nil},
+		{"type alias reference", "type names = string[]\nparam p names = ['a']\noutput o string = p[0]\n", nil},
+		{"duplicate property", "var o = {\n  a: 1\n  a: 2\n}\n", []string{"[19:20] Error BCP025: The property \"a\" is declared multiple times in this object. Remove or rename the duplicate properties."}},
+		{"unknown property", "var o = {\n  a: 1\n}\nvar b = o.b\n", []string{"[29:30] Error BCP053: The type \"object\" does not contain property \"b\". Available properties include \"a\"."}},
+		{"property of string", "var s = 'a'\nvar b = s.length\n", []string{"[22:28] Error BCP055: Cannot access properties of type \"'a'\". An \"object\" type is required."}},
+		{"safe access", "param o { a: string }?\noutput a string? = o.?a\n", nil},
+		{"array index", "var a = [1, 2]\nvar b = a['x']\n", []string{"[25:28] Error BCP074: Indexing over arrays requires an index of type \"int\" but the provided index was of type \"'x'\"."}},
+		{"tuple index out of bounds", "var a = [1, 2]\nvar b = a[2]\n", []string{"[25:26] Error BCP311: The provided index value of \"2\" is not valid for type \"[1, 2]\". Indexes for this type must be between 0 and 1."}},
+		{"index over int", "var a = 1\nvar b = a[0]\n", []string{"[20:21] Error BCP076: Cannot index over expression of type \"1\". Arrays or objects are required."}},
+		{"binary operator", "var a = 1 + 'b'\n", []string{"[8:15] Error BCP045: Cannot apply operator \"+\" to operands of type \"1\" and \"'b'\"."}},
+		{"integer out of range", "var a = 9223372036854775808\nvar b = 99999999999999999999\n", []string{
+			"[8:27] Error BCP010: Expected a valid 64-bit signed integer.",
+			"[36:56] Error BCP010: Expected a valid 64-bit signed integer.",
+		}},
+		{"minimum integer", "var a = -9223372036854775808\n", nil},
+		{"unary operator", "var a = !1\n", []string{"[8:10] Error BCP044: Cannot apply operator \"!\" to operand of type \"1\"."}},
+		{"ternary condition", "var a = 1 ? 'a' : 'b'\n", []string{"[8:9] Error BCP046: Expected a value of type \"bool\"."}},
+		{"ternary branches", "param p bool\noutput o string = p ? 'a' : 1\n", []string{"[41:42] Error BCP026: The output expects a value of type \"string\" but the provided value is of type \"1\"."}},
+		{"coalesce", "param p string?\noutput o string = p ?? 'default'\n", nil},
+		{"nullable assignment", "param p string?\noutput o string = p\n", []string{"[34:35] Warning BCP321: Expected a value of type \"string\" but the provided value is of type \"string | null\". If you know the value will not be null, use a non-null assertion operator to inform the compiler that the value will not be null."}},
+		{"non-null assertion", "param p string?\noutput o string = p!\n", nil},
+		{"nullable dereference", "param p { name: string }?\noutput o string = p.name\n", []string{"[44:45] Warning BCP318: The value of type \"{ name: string } | null\" may be null at the start of the deployment, which would cause this access expression (and the overall expression) to fail with a null dereference error. If you do not know whether the value will be null and the template would handle a null value for the overall expression, use a `.?` (safe dereference) operator to short-circuit the access expression if the base expression's value is null. If you know the value will not be null, use a non-null assertion operator to inform the compiler that the value will not be null."}},
+		{"nullable index", "param p string[]?\noutput o string = p[0]\n", []string{"[36:37] Warning BCP318: The value of type \"string[] | null\" may be null at the start of the deployment, which would cause this access expression (and the overall expression) to fail with a null dereference error. If you do not know whether the value will be null and the template would handle a null value for the overall expression, use a `.?` (safe dereference) operator to short-circuit the access expression if the base expression's value is null. If you know the value will not be null, use a non-null assertion operator to inform the compiler that the value will not be null."}},
+		{"optional property dereference", "param p { settings?: { name: string } }\noutput o string = p.settings.name\n", []string{"[58:68] Warning BCP318: The value of type \"{ name: string } | null\" may be null at the start of the deployment, which would cause this access expression (and the overall expression) to fail with a null dereference error. If you do not know whether the value will be null and the template would handle a null value for the overall expression, use a `.?` (safe dereference) operator to short-circuit the access expression if the base expression's value is null. If you know the value will not be null, use a non-null assertion operator to inform the compiler that the value will not be null."}},
+		{"safe dereference chain", "param p { settings?: { name: string } }\noutput o string? = p.?settings.name\n", nil},
+		{"narrowed by ternary", "param p { name: string }?\noutput o string = p != null ? p.name : 'none'\n", nil},
+		{"narrowed by null on the left", "param p string?\noutput o string = null != p ? p : 'none'\n", nil},
+		{"narrowed in else branch", "param p string?\noutput o string = p == null ? 'none' : p\n", nil},
+		{"narrowed by conjunction", "param p { settings?: { name: string } }\noutput o string = p.settings != null && true ? p.settings.name : 'none'\n", nil},
+		{"not narrowed in other branch", "param p string?\noutput o string = p != null ? 'none' : p\n", []string{"[55:56] Warning BCP321: Expected a value of type \"string\" but the provided value is of type \"string | null\". If you know the value will not be null, use a non-null assertion operator to inform the compiler that the value will not be null."}},
+		{"existing resource property", "resource r 'A.B/c@2020-01-01' existing = {\n  name: 'r'\n}\noutput o string = r.properties.name\n", []string{"[75:87] Warning BCP318: The value of type \"object | null\" may be null at the start of the deployment, which would cause this access expression (and the overall expression) to fail with a null dereference error. If you do not know whether the value will be null and the template would handle a null value for the overall expression, use a `.?` (safe dereference) operator to short-circuit the access expression if the base expression's value is null. If you know the value will not be null, use a non-null assertion operator to inform the compiler that the value will not be null."}},
+		{"loop over int", "var a = [for x in 1: x]\n", []string{"[18:19] Error BCP137: Loop expected an expression of type \"array\" but the provided value is of type \"1\"."}},
+		{"loop item type", "param names string[]\noutput o int[] = [for name in names: name]\n", []string{"[58:62] Error BCP034: The enclosing array expected an item of type \"int\", but the provided item was of type \"string\"."}},
+		{"loop index type", "output o int[] = [for (x, i) in ['a']: i]\n", nil},
+		{"resource", "resource r 'Microsoft.Storage/storageAccounts@2023-01-01' = {\n  name: 'r'\n  location: 'west'\n}\noutput id string = r.id\n", nil},
+		{"invalid resource type", "resource r 'storage' = {\n  name: 'r'\n}\n", []string{"[11:20] Error BCP029: The resource type is not valid. Specify a valid resource type of format \"<types>@<apiVersion>\"."}},
+		{"resource missing name", "resource r 'A.B/c@2020-01-01' = {\n}\n", []string{"[32:33] Warning BCP035: The specified \"resource\" declaration is missing the following required properties: \"name\"."}},
+		{"resource read-only property", "resource r 'A.B/c@2020-01-01' = {\n  name: 'r'\n  id: 'x'\n}\n", []string{"[48:50] Warning BCP073: The property \"id\" is read-only. Expressions cannot be assigned to read-only properties."}},
+		{"resource write-only property", "resource r 'A.B/c@2020-01-01' = {\n  name: 'r'\n}\nvar d = r.dependsOn\n", []string{"[58:67] Error BCP077: The property \"dependsOn\" on type \"A.B/c@2020-01-01\" is write-only. Write-only properties cannot be accessed."}},
+		{"resource loop", "resource r 'A.B/c@2020-01-01' = [for i in range(0, 2): {\n  name: string(i)\n}]\noutput id string = r[0].id\n", nil},
+		{"resource loop reference", "resource r 'A.B/c@2020-01-01' = [for i in range(0, 2): {\n  name: string(i)\n}]\noutput names array = map(r, x => x.name)\n", []string{"[103:104] Error BCP144: Directly referencing a resource or module collection is not currently supported here. Apply an array indexer to the expression."}},
+		{"resource loop dependency", "resource r 'A.B/c@2020-01-01' = [for i in range(0, 2): {\n  name: string(i)\n}]\nresource d 'A.B/c@2020-01-01' = {\n  name: 'd'\n  dependsOn: [\n    r\n  ]\n}\n", nil},
+		{"nested resource of resource loop", "resource p 'A.B/c@2020-01-01' = [for name in ['a']: {\n  name: name\n  resource c 'd' = {\n    name: 'c'\n  }\n}]\noutput t string = p::c.type\n", []string{"[127:128] Error BCP144: Directly referencing a resource or module collection is not currently supported here. Apply an array indexer to the expression."}},
+		{"module loop reference", "module m 'm.bicep' = [for i in range(0, 2): {\n  name: string(i)\n}]\noutput modules array = m\n", []string{"[90:91] Error BCP144: Directly referencing a resource or module collection is not currently supported here. Apply an array indexer to the expression."}},
+		{"nested resource type", "resource p 'A.B/c@2020-01-01' = {\n  name: 'p'\n  resource c 'd' = {\n    name: 'c'\n  }\n}\noutput t string = p::c.type\n", nil},
+		{"nested resource of loop instance", "resource p 'A.B/c@2020-01-01' = [for name in ['a']: {\n  name: name\n  resource c 'd' = {\n    name: 'c'\n  }\n}]\noutput t int = p[0]::c.type\n", []string{"[124:136] Error BCP026: The output expects a value of type \"int\" but the provided value is of type \"string\"."}},
+		{"resource parameter", "param r resource 'A.B/c@2020-01-01'\noutput id string = r.id\n", nil},
+		{"existing resource", "resource r 'A.B/c@2020-01-01' existing = {\n  name: 'r'\n  location: 'x'\n}\n", []string{"[57:65] Warning BCP073: The property \"location\" is read-only. Expressions cannot be assigned to read-only properties."}},
+		{"module", "module m 'm.bicep' = {\n  name: 'm'\n  params: {\n    a: 1\n  }\n}\noutput o string = m.outputs.x\n", nil},
+		{"module unknown property", "module m 'm.bicep' = {\n  name: 'm'\n  foo: 1\n}\n", []string{"[37:40] Error BCP037: The property \"foo\" is not allowed on objects of type \"m.bicep\". Permissible properties include \"dependsOn\", \"name\", \"params\", \"scope\"."}},
+		{"argument count", "var a = length()\n", []string{"[8:14] Error BCP071: Expected 1 argument, but got 0."}},
+		{"argument count range", "var a = substring('a')\n", []string{"[8:17] Error BCP071: Expected 2 to 3 arguments, but got 1."}},
+		{"variable argument count", "var a = resourceId('a')\n", []string{"[8:18] Error BCP071: Expected at least 2 arguments, but got 1."}},
+		{"argument type", "var a = toLower(1)\n", []string{"[16:17] Error BCP070: Argument of type \"1\" is not assignable to parameter of type \"string\"."}},
+		{"no matching overload", "var a = sys.contains(1, 'a')\n", []string{"[12:20] Error BCP048: Cannot resolve function overload. Candidate overloads are: contains(object: object, propertyName: string): bool, contains(array: array, itemToFind: any): bool, contains(string: string, itemToFind: string): bool."}},
+		{"function return type", "output o int = toUpper('a')\n", []string{"[15:27] Error BCP026: The output expects a value of type \"int\" but the provided value is of type \"string\"."}},
+		{"resource condition", "resource r 'A.B/c@2020-01-01' = if ('a') {\n  name: 'r'\n}\n", []string{"[35:40] Error BCP046: Expected a value of type \"bool\"."}},
+		{"function", "func greet(name string, n int) string => '${name}-${n}'\noutput o string = greet('a', 1)\n", nil},
+		{"function body mismatch", "func f(a string) int => a\n", []string{"[24:25] Error BCP033: Expected a value of type \"int\" but the provided value is of type \"string\"."}},
+		{"function argument type", "func f(a string) string => a\nvar v = f(1)\n", []string{"[39:40] Error BCP070: Argument of type \"1\" is not assignable to parameter of type \"string\"."}},
+		{"function argument count", "func f(a string) string => a\nvar v = f()\n", []string{"[37:38] Error BCP071: Expected 1 argument, but got 0."}},
+		{"function call return type", "func f() string => 'a'\noutput o int = f()\n", []string{"[38:41] Error BCP026: The output expects a value of type \"int\" but the provided value is of type \"string\"."}},
+		{"function with type alias", "type pair = [string, int]\nfunc first(p pair) string => p[0]\noutput o string = first(['a', 1])\n", nil},
+		{"lambda item type", "param names string[]\noutput o int[] = filter(names, name => name == 'a')\n", []string{"[38:72] Error BCP026: The output expects a value of type \"int[]\" but the provided value is of type \"string[]\"."}},
+		{"lambda return type", "param names string[]\nvar v = filter(names, name => name)\n", []string{"[43:55] Error BCP070: Argument of type \"(string) => string\" is not assignable to parameter of type \"(any, [int]) => bool\"."}},
+		{"lambda property access", "param items { name: string }[]\nvar v = map(items, item => item.size)\n", []string{"[63:67] Error BCP053: The type \"{ name: string }\" does not contain property \"size\". Available properties include \"name\"."}},
+		{"lambda with index", "param names string[]\noutput o string[] = map(names, (name, i) => '${i}-${name}')\n", nil},
+		{"lambda argument count", "param names string[]\nvar v = sort(names, a => true)\n", []string{"[41:42] Error BCP244: Expected lambda expression of type \"(string, string) => bool\" with 2 arguments but received 1 arguments."}},
+		{"lambda argument count range", "param names string[]\nvar v = filter(names, (a, b, c) => true)\n", []string{"[43:52] Error BCP244: Expected lambda expression of type \"(string, [int]) => bool\" with between 1 and 2 arguments but received 3 arguments."}},
+		{"lambda as value", "var v = x => x\n", []string{"[8:14] Error BCP242: Lambda functions may only be specified directly as function arguments."}},
+		{"lambda in parentheses", "var v = map([1], (x => x))\n", []string{"[18:24] Error BCP242: Lambda functions may only be specified directly as function arguments."}},
+		{"lambda to non-lambda parameter", "var v = concat(x => x)\n", []string{"[8:14] Error BCP048: Cannot resolve function overload. Candidate overloads are: concat(... : array): array, concat(... : string | int | bool): string."}},
+		{"non-lambda to lambda parameter", "var v = map([1], 1)\n", []string{"[17:18] Error BCP070: Argument of type \"1\" is not assignable to parameter of type \"(any, [int]) => any\"."}},
+		{"reduce", "output o int = reduce([1, 2], 0, (acc, cur) => acc + cur)\n", nil},
+		{"discriminated object", "@discriminator('kind')\ntype t = {\n  kind: 'a'\n  size: int\n} | {\n  kind: 'b'\n  name: string\n}\nparam p t = {\n  kind: 'a'\n  size: 1\n}\n", nil},
+		{"discriminated object mismatch", "@discriminator('kind')\ntype t = {\n  kind: 'a'\n  size: int\n} | {\n  kind: 'b'\n  name: string\n}\nparam p t = {\n  kind: 'a'\n  name: 'n'\n}\n", []string{"[105:106] Error BCP035: The specified \"object\" declaration is missing the following required properties: \"size\".", "[121:125] Error BCP037: The property \"name\" is not allowed on objects of type \"{ kind: 'a', size: int }\". Permissible properties include \"kind\", \"size\"."}},
+		{"discriminated object missing discriminator", "@discriminator('kind')\ntype t = {\n  kind: 'a'\n  size: int\n} | {\n  kind: 'b'\n  name: string\n}\nparam p t = {\n  size: 1\n}\n", []string{"[105:106] Error BCP078: The property \"kind\" requires a value of type \"'a' | 'b'\", but none was supplied."}},
+		{"discriminated object unknown discriminator", "@discriminator('kind')\ntype t = {\n  kind: 'a'\n  size: int\n} | {\n  kind: 'b'\n  name: string\n}\nparam p t = {\n  kind: 'c'\n}\n", []string{"[115:118] Error BCP036: The property \"kind\" expected a value of type \"'a' | 'b'\" but the provided value is of type \"'c'\"."}},
+		{"discriminated property access", "@discriminator('kind')\ntype t = {\n  kind: 'a'\n  size: int\n} | {\n  kind: 'b'\n  name: string\n}\nparam p t\noutput o string = p.name\n", []string{"[123:127] Error BCP052: The type \"{ kind: 'a', size: int } | { kind: 'b', name: string }\" does not contain property \"name\"."}},
+		{"discriminated narrowing", "@discriminator('kind')\ntype t = {\n  kind: 'a'\n  size: int\n} | {\n  kind: 'b'\n  name: string\n}\nparam p t\noutput o string = p.kind == 'b' ? p.name : string(p.size)\n", nil},
+		{"discriminated narrowing both branches", "@discriminator('kind')\ntype t = {\n  kind: 'a'\n  size: int\n} | {\n  kind: 'b'\n  name: string\n}\nparam p t\noutput o string = p.kind != 'a' ? p.name : string(p.size)\n", nil},
+		{"function calling reference", "func f(id string) object => reference(id)\n", []string{"[28:37] Error BCP341: This expression is being used inside a function declaration, which requires a value that can be calculated at the start of the deployment."}},
+		{"file load constant path", "var name = 'script'\nvar v = loadTextContent('${name}.sh')\n", nil},
+		{"file load non-constant path", "param name string\nvar v = loadTextContent('${name}.sh')\n", []string{"[42:54] Error BCP032: The value must be a compile-time constant."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, typeManager := checkText(t, tt.input)
+			require.Equal(t, tt.expected, formatDiagnostics(typeManager.GetDiagnostics()))
+		})
+	}
+}
+
+func TestTypeManagerInference(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"'a'", "'a'"},
+		{"'a${p}'", "string"},
+		{"-3", "-3"},
+		{"-9223372036854775808", "-9223372036854775808"},
+		{"true", "true"},
+		{"null", "null"},
+		{"[1, 'a']", "[1, 'a']"},
+		{"{ a: 1 }", "object"},
+		{"{ a: 1 }.a", "1"},
+		{"p", "string"},
+		{"n", "string | null"},
+		{"n!", "string"},
+		{"n ?? 1", "string | 1"},
+		{"p == 'a'", "bool"},
+		{"1 + 2", "int"},
+		{"true ? 'a' : 1", "'a' | 1"},
+		{"[for x in names: x]", "string[]"},
+		{"names[0]", "string"},
+		{"concat('a')", "string"},
+		{"concat(names, names)", "string[]"},
+		{"sys.length([1, 2])", "2"},
+		{"resourceGroup().location", "string"},
+		{"az.subscription('s').tenantId", "string"},
+		{"any(1)", "any"},
+		{"o.a", "string"},
+		{"o.?a", "string | null"},
+		{"{ a: { b: 1 } }.?a.b", "1 | null"},
+		{"n != null ? n : 'a'", "string | 'a'"},
+		{"resourceGroup().tags", "object | null"},
+		{"map(names, name => length(name))", "int[]"},
+		{"map(names, (name, i) => i)", "int[]"},
+		{"filter(names, name => startsWith(name, 'a'))", "string[]"},
+		{"sort(names, (a, b) => a < b)", "string[]"},
+		{"toObject(names, name => name)", "object"},
+		{"toObject(names, name => name, name => length(name))", "object"},
+		{"mapValues(o, value => toUpper(value))", "object"},
+		{"reduce(names, '', (acc, name) => concat(acc, name))", "string"},
+		{"groupBy(names, name => substring(name, 0, 1))", "object"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			input := "param p string\nparam n string?\nparam names string[]\nparam o { a: string }\nvar v = " + tt.expression + "\n"
+			binder, typeManager := checkText(t, input)
+			require.Empty(t, typeManager.GetDiagnostics())
+
+			variable := binder.GetFileSymbol().Variables[0]
+			require.Equal(t, tt.expected, typeManager.GetSymbolType(variable).GetName())
+		})
+	}
+}
+
+func TestTypeManagerDeclaredTypes(t *testing.T) {
+	input := `type size = 'small' | 'large'
+type config = {
+  name: string
+  size: size?
+  tags: {
+    *: string
+  }
+}
+param p config[]
+`
+	binder, typeManager := checkText(t, input)
+	require.Empty(t, typeManager.GetDiagnostics())
+
+	parameter := binder.GetFileSymbol().Parameters[0]
+	declaredType := typeManager.GetDeclaredType(parameter.Declaration)
+	require.Equal(t, "{ name: string, size?: 'small' | 'large' | null, tags: { *: string } }[]", declaredType.GetName())
+
+	config := declaredType.(*types.ArrayType).Item.(*types.ObjectType)
+	require.True(t, config.TryGetProperty("name").IsRequired())
+	require.False(t, config.TryGetProperty("size").IsRequired())
+
+	var access *syntax.TypeVariableAccessSyntax
+	syntax.Inspect(parameter.Declaration, func(node syntax.SyntaxBase) bool {
+		if node, ok := node.(*syntax.TypeVariableAccessSyntax); ok {
+			access = node
+		}
+		return true
+	})
+	require.Same(t, config, typeManager.GetDeclaredType(access))
+}
+
+func TestTypeManagerDecorators(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"valid", "@minLength(1)\n@maxLength(3)\nparam s string = 'ab'\n@minValue(1)\n@maxValue(5)\nparam i int = 3\n@secure()\nparam o object\n@allowed([\n  'a'\n  'b'\n])\nparam a string = 'a'\n", nil},
+		{"length of int", "@minLength(1)\nparam i int\n", []string{"[1:13] Error BCP124: The decorator \"minLength\" can only be attached to targets of type \"string | array\", but the target has type \"int\"."}},
+		{"value of string", "@maxValue(1)\nparam s string\n", []string{"[1:12] Error BCP124: The decorator \"maxValue\" can only be attached to targets of type \"int\", but the target has type \"string\"."}},
+		{"secure array", "@secure()\nparam a array\n", []string{"[1:9] Error BCP124: The decorator \"secure\" can only be attached to targets of type \"string | object\", but the target has type \"array\"."}},
+		{"secure output", "@secure()\noutput o string = 'a'\n", nil},
+		{"type member", "type t = {\n  @minLength(1)\n  name: string\n  @maxValue(1)\n  size: string\n}\n", []string{"[45:56] Error BCP124: The decorator \"maxValue\" can only be attached to targets of type \"int\", but the target has type \"string\"."}},
+		{"argument type", "@minLength('a')\nparam s string\n", []string{"[11:14] Error BCP070: Argument of type \"'a'\" is not assignable to parameter of type \"int\"."}},
+		{"allowed type mismatch", "@allowed([\n  1\n])\nparam s string\n", []string{"[13:14] Error BCP034: The enclosing array expected an item of type \"string\", but the provided item was of type \"1\"."}},
+		{"allowed empty", "@allowed([])\nparam s string\n", []string{"[9:11] Error BCP099: The \"allowed\" array must contain one or more items."}},
+		{"allowed default", "@allowed([\n  'a'\n  'b'\n])\nparam s string = 'c'\n", []string{"[43:46] Error BCP027: The parameter expects a default value of type \"'a' | 'b'\" but provided value is of type \"'c'\"."}},
+		{"allowed folded default", "@allowed([\n  'x'\n])\nparam q string = toLower('X')\n@allowed([\n  'x'\n])\nparam r string = toLower('Y')\n", []string{"[87:99] Error BCP027: The parameter expects a default value of type \"'x'\" but provided value is of type \"string\"."}},
+		{"allowed folded array", "@allowed([\n  'a'\n  'b'\n])\nparam s string[] = [\n  toLower('A')\n]\n", nil},
+		{"allowed array", "@allowed([\n  'a'\n  'b'\n])\nparam s string[] = [\n  'a'\n]\noutput o 'a'[] = s\n", []string{"[72:73] Error BCP026: The output expects a value of type \"'a'[]\" but the provided value is of type \"('a' | 'b')[]\"."}},
+		{"allowed on type", "@allowed(['a'])\ntype t = string\n", []string{"[1:15] Error BCP297: Function \"allowed\" cannot be used as a type decorator."}},
+		{"batch size on parameter", "@batchSize(1)\nparam p int\n", []string{"[1:13] Error BCP125: Function \"batchSize\" cannot be used as a parameter decorator."}},
+		{"batch size without loop", "@batchSize(1)\nresource r 'A.B/c@2020-01-01' = {\n  name: 'r'\n}\n", []string{"[1:13] Error BCP155: The decorator \"batchSize\" can only be attached to resource or module collections."}},
+		{"batch size too small", "@batchSize(0)\nresource r 'A.B/c@2020-01-01' = [for i in range(0, 2): {\n  name: string(i)\n}]\n", []string{"[11:12] Error BCP154: Expected a batch size of at least 1 but the specified value was \"0\"."}},
+		{"batch size on module loop", "@batchSize(2)\nmodule m 'm.bicep' = [for i in range(0, 2): {\n  name: string(i)\n}]\n", nil},
+		{"discriminator", "@discriminator('kind')\ntype t = {\n  kind: 'a'\n} | {\n  kind: 'b'\n}\n", nil},
+		{"discriminator not required", "@discriminator('kind')\ntype t = {\n  kind: 'a'\n} | {\n  kind: 'b'?\n}\n", []string{"[15:21] Error BCP364: The property \"kind\" must be a required string literal on all union member types."}},
+		{"discriminator not literal", "@discriminator('kind')\ntype t = {\n  kind: 'a'\n} | {\n  kind: string\n}\n", []string{"[15:21] Error BCP364: The property \"kind\" must be a required string literal on all union member types."}},
+		{"discriminator duplicated", "@discriminator('kind')\ntype t = {\n  kind: 'a'\n} | {\n  kind: 'a'\n  size: int\n}\n", []string{"[15:21] Error BCP365: The value \"a\" for discriminator property \"kind\" is duplicated across multiple union member types. The value must be unique across all union member types."}},
+		{"discriminator nested", "type a = {\n  kind: 'a'\n}\n@discriminator('kind')\ntype ab = a | {\n  kind: 'b'\n}\n@discriminator('kind')\ntype abc = ab | {\n  kind: 'c'\n}\nparam p abc = {\n  kind: 'c'\n}\n", nil},
+		{"discriminator on parameter", "@discriminator('kind')\nparam p {\n  kind: 'a'\n} | {\n  kind: 'a'\n}\n", []string{"[15:21] Error BCP365: The value \"a\" for discriminator property \"kind\" is duplicated across multiple union member types. The value must be unique across all union member types."}},
+		{"discriminator on primitives", "@discriminator('kind')\ntype t = string | int\n", []string{"[1:22] Error BCP363: The \"discriminator\" decorator can only be applied to object-only union types with unique member types."}},
+		{"exported function", "@export()\n@description('d')\nfunc f() int => 1\n", nil},
+		{"function decorator", "@secure()\nfunc f() string => 'a'\n", []string{"[1:9] Error BCP130: Decorators are not allowed here."}},
+		{"variable decorator", "@secure()\nvar v = 'a'\n", []string{"[1:9] Error BCP126: Function \"secure\" cannot be used as a variable decorator."}},
+		{"target scope", "@description('x')\ntargetScope = 'resourceGroup'\n", []string{"[1:17] Error BCP130: Decorators are not allowed here."}},
+		{"duplicate", "@description('a')\n@description('b')\nparam p string\n", []string{"[19:35] Error BCP166: Duplicate \"description\" decorator."}},
+		{"default constraints", "@maxLength(2)\nparam s string = 'abc'\n@minLength(2)\nparam a array = [\n  1\n]\n@maxValue(2)\nparam i int = 3\n@minValue(2)\nparam j int = -1\n", []string{
+			"[31:36] Error BCP332: The provided value (whose length will always be greater than or equal to 3) is too long to assign to a target for which the maximum allowable length is 2.",
+			"[67:74] Error BCP333: The provided value (whose length will always be less than or equal to 1) is too short to assign to a target for which the minimum allowable length is 2.",
+			"[102:103] Error BCP327: The provided value (which will always be greater than or equal to 3) is too large to assign to a target for which the maximum allowable value is 2.",
+			"[131:133] Error BCP328: The provided value (which will always be less than or equal to -1) is too small to assign to a target for which the minimum allowable value is 2.",
+		}},
+		{"folded default constraints", "var suffix = 'bc'\n@maxLength(2)\nparam s string = 'a${suffix}'\n@maxValue(2)\nparam i int = 1 + 2\n", []string{
+			"[49:61] Error BCP332: The provided value (whose length will always be greater than or equal to 3) is too long to assign to a target for which the maximum allowable length is 2.",
+			"[89:94] Error BCP327: The provided value (which will always be greater than or equal to 3) is too large to assign to a target for which the maximum allowable value is 2.",
+		}},
+		{"overflowing default", "@maxValue(10)\nparam p int = 9223372036854775807 + 1\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, typeManager := checkText(t, tt.input)
+			require.Equal(t, tt.expected, formatDiagnostics(typeManager.GetDiagnostics()))
+		})
+	}
+}
+
+func TestTypeManagerScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"target scope", "targetScope = 'subscription'\nvar rg = resourceGroup('rg')\n", nil},
+		{"invalid target scope", "targetScope = 'foo'\n", []string{"[14:19] Error BCP033: Expected a value of type \"'tenant' | 'managementGroup' | 'subscription' | 'resourceGroup' | 'desiredStateConfiguration'\" but the provided value is of type \"'foo'\"."}},
+		{"current resource group at subscription scope", "targetScope = 'subscription'\nvar rg = resourceGroup()\n", []string{"[38:51] Error BCP071: Expected 1 to 2 arguments, but got 0."}},
+		{"module scope", "targetScope = 'subscription'\nmodule m 'm.bicep' = {\n  name: 'm'\n  scope: resourceGroup('rg')\n}\n", nil},
+		{"module at management group", "targetScope = 'tenant'\nmodule m 'm.bicep' = {\n  name: 'm'\n  scope: managementGroup('mg')\n}\n", nil},
+		{"module resource scope", "resource r 'A.B/c@2020-01-01' = {\n  name: 'r'\n}\nmodule m 'm.bicep' = {\n  name: 'm'\n  scope: r\n}\n", []string{"[92:93] Error BCP116: Unsupported scope for module deployment in a \"resourceGroup\" target scope. Omit this property to inherit the current scope, or specify a valid scope. Permissible scopes include current resource group: resourceGroup(), named resource group in same subscription: resourceGroup(<name>), named resource group in a different subscription: resourceGroup(<subId>, <name>), current subscription: subscription(), named subscription: subscription(<subId>) or tenant: tenant()."}},
+		{"module resource group scope", "targetScope = 'subscription'\nresource rg 'Microsoft.Resources/resourceGroups@2022-09-01' = {\n  name: 'rg'\n  location: 'westus'\n}\nmodule m 'm.bicep' = {\n  name: 'm'\n  scope: rg\n}\n", nil},
+		{"module management group scope", "targetScope = 'tenant'\nresource group 'Microsoft.Management/managementGroups@2021-04-01' existing = {\n  name: 'group'\n}\nmodule m 'm.bicep' = {\n  name: 'm'\n  scope: group\n}\n", nil},
+		{"module string scope", "targetScope = 'subscription'\nmodule m 'm.bicep' = {\n  name: 'm'\n  scope: 'x'\n}\n", []string{"[73:76] Error BCP115: Unsupported scope for module deployment in a \"subscription\" target scope. Omit this property to inherit the current scope, or specify a valid scope. Permissible scopes include current subscription: subscription(), named subscription: subscription(<subId>), named resource group in same subscription: resourceGroup(<name>), named resource group in different subscription: resourceGroup(<subId>, <name>), or tenant: tenant()."}},
+		{"resource at other scope", "targetScope = 'subscription'\nresource r 'A.B/c@2020-01-01' = {\n  name: 'r'\n  scope: resourceGroup('rg')\n}\n", []string{"[84:103] Error BCP139: A resource's scope must match the scope of the Bicep file for it to be deployable. You must use modules to deploy resources to a different scope."}},
+		{"existing resource at other scope", "targetScope = 'subscription'\nresource r 'A.B/c@2020-01-01' existing = {\n  name: 'r'\n  scope: resourceGroup('rg')\n}\n", nil},
+		{"extension resource", "resource r 'A.B/c@2020-01-01' = {\n  name: 'r'\n}\nresource e 'A.B/d@2020-01-01' = {\n  name: 'e'\n  scope: r\n}\n", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, typeManager := checkText(t, tt.input)
+			require.Equal(t, tt.expected, formatDiagnostics(typeManager.GetDiagnostics()))
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package semantics
+
+import (
+	"bicep-go/diagnostics"
+	"bicep-go/syntax"
+	"bicep-go/types"
+	"bicep-go/util"
+	"sort"
+)
+
+// mismatchBuilder creates the diagnostic reported when a value is not assignable to the expected type.
+type mismatchBuilder func(span *util.TextSpan, expectedType types.TypeSymbol, actualType types.TypeSymbol) *diagnostics.Diagnostic
+
+func (m *TypeManager) addMismatch(diagnostic *diagnostics.Diagnostic, warn bool) {
+	if warn {
+		diagnostic.Level = diagnostics.DiagnosticLevelWarning
+	}
+	m.addDiagnostic(diagnostic)
+}
+
+// validateAssignment checks that an expression can be assigned to the target type. Mismatches in object
+// literals, array literals, loops and ternaries are reported at the innermost mismatching expression.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/TypeSystem/TypeValidator.cs
+func (m *TypeManager) validateAssignment(expression syntax.SyntaxBase, targetType types.TypeSymbol, warn bool, mismatch mismatchBuilder) {
+	actualType := m.GetTypeInfo(expression)
+
+	switch expression := expression.(type) {
+	case *syntax.ParenthesizedExpressionSyntax:
+		m.validateAssignment(expression.Expression, targetType, warn, mismatch)
+		return
+
+	case *syntax.ObjectSyntax:
+		if objectType := tryGetObjectTarget(targetType); objectType != nil {
+			m.validateObject(expression, objectType, warn || objectType.WarnOnTypeMismatch(), "object")
+			return
+		}
+		if discriminatedType, ok := types.RemoveNullability(targetType).(*types.DiscriminatedObjectType); ok {
+			m.validateDiscriminatedObject(expression, discriminatedType, warn)
+			return
+		}
+
+	case *syntax.ArraySyntax:
+		switch arrayType := types.RemoveNullability(targetType).(type) {
+		case *types.ArrayType:
+			for _, item := range expression.Items {
+				m.validateAssignment(item.Value, arrayType.Item, warn, arrayTypeMismatch)
+			}
+			return
+		case *types.TupleType:
+			if len(arrayType.Items) == len(expression.Items) {
+				for i, item := range expression.Items {
+					m.validateAssignment(item.Value, arrayType.Items[i], warn, arrayTypeMismatch)
+				}
+				return
+			}
+		}
+
+	case *syntax.ForSyntax:
+		if arrayType, ok := types.RemoveNullability(targetType).(*types.ArrayType); ok {
+			body := expression.Body
+			if condition, ok := body.(*syntax.IfConditionSyntax); ok {
+				body = condition.Body
+			}
+			m.validateAssignment(body, arrayType.Item, warn, arrayTypeMismatch)
+			return
+		}
+
+	case *syntax.TernaryOperationSyntax:
+		m.validateAssignment(expression.TrueExpression, targetType, warn, mismatch)
+		m.validateAssignment(expression.FalseExpression, targetType, warn, mismatch)
+		return
+	}
+
+	if !types.AreTypesAssignable(actualType, targetType) {
+		if isNullableUnion(actualType) && types.AreTypesAssignable(types.RemoveNullability(actualType), targetType) {
+			m.addDiagnostic(possibleNullReferenceAssignment(expression.GetSpan(), targetType, actualType))
+			return
+		}
+		m.addMismatch(mismatch(expression.GetSpan(), targetType, actualType), warn)
+	}
+}
+
+// isNullableUnion reports whether a type is a union of null and other types, which is only null if the value is.
+func isNullableUnion(typeSymbol types.TypeSymbol) bool {
+	_, ok := typeSymbol.(*types.UnionType)
+	return ok && types.IsNullable(typeSymbol)
+}
+
+// tryGetObjectTarget returns the object type an object literal is checked against property by property.
+// Unions of several object types are left to the assignability check.
+func tryGetObjectTarget(targetType types.TypeSymbol) *types.ObjectType {
+	objectType, _ := types.RemoveNullability(targetType).(*types.ObjectType)
+	return objectType
+}
+
+// validateDiscriminatedObject checks an object literal against the variant of a discriminated union selected by
+// the value of its discriminator property.
+func (m *TypeManager) validateDiscriminatedObject(object *syntax.ObjectSyntax, discriminatedType *types.DiscriminatedObjectType, warn bool) {
+	key := discriminatedType.DiscriminatorKey
+	discriminator := object.TryGetProperty(key)
+	if discriminator == nil {
+		m.addMismatch(missingDiscriminatorProperty(object.OpenBrace.GetSpan(), key, discriminatedType.GetDiscriminatorType()), warn)
+		return
+	}
+
+	if value, ok := m.GetTypeInfo(discriminator.Value).(*types.StringLiteralType); ok {
+		if variant := discriminatedType.TryGetMember(value.Value); variant != nil {
+			m.validateObject(object, variant, warn, "object")
+			return
+		}
+	}
+	m.validateAssignment(discriminator.Value, discriminatedType.GetDiscriminatorType(), warn, func(span *util.TextSpan, expectedType types.TypeSymbol, actualType types.TypeSymbol) *diagnostics.Diagnostic {
+		return propertyTypeMismatch(span, key, expectedType, actualType)
+	})
+}
+
+// validateObject checks the properties of an object literal against an object type.
+func (m *TypeManager) validateObject(object *syntax.ObjectSyntax, objectType *types.ObjectType, warn bool, blockName string) {
+	present := map[string]bool{}
+
+	for _, property := range object.Properties() {
+		name, ok := property.TryGetKeyText()
+		if !ok {
+			if objectType.AdditionalPropertiesType != nil {
+				m.validateAssignment(property.Value, objectType.AdditionalPropertiesType, warn, expectedValueTypeMismatch)
+			}
+			continue
+		}
+		present[name] = true
+
+		propertyMismatch := func(span *util.TextSpan, expectedType types.TypeSymbol, actualType types.TypeSymbol) *diagnostics.Diagnostic {
+			return propertyTypeMismatch(span, name, expectedType, actualType)
+		}
+
+		if declared := objectType.TryGetProperty(name); declared != nil {
+			if declared.IsReadOnly() {
+				m.addMismatch(propertyReadOnly(property.Key.GetSpan(), name), warn)
+				continue
+			}
+			m.validateAssignment(property.Value, declared.Type, warn, propertyMismatch)
+			continue
+		}
+
+		if objectType.AdditionalPropertiesType != nil {
+			m.validateAssignment(property.Value, objectType.AdditionalPropertiesType, warn, propertyMismatch)
+			continue
+		}
+
+		// properties missing from provider types are warnings, as those types may be incomplete
+		m.addMismatch(disallowedProperty(property.Key.GetSpan(), name, objectType, propertyNames(objectType, types.TypePropertyFlagsReadOnly)), warn)
+	}
+
+	var missing []string
+	for _, property := range objectType.Properties {
+		if property.IsRequired() && !property.IsReadOnly() && !present[property.Name] {
+			missing = append(missing, property.Name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		m.addMismatch(missingRequiredProperties(object.OpenBrace.GetSpan(), blockName, missing), warn)
+	}
+}
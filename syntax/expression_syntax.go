@@ -0,0 +1,626 @@
+package syntax
+
+import (
+	"bicep-go/token"
+	"bicep-go/util"
+)
+
+type VariableAccessSyntax struct {
+	Name *IdentifierSyntax
+}
+
+func NewVariableAccessSyntax(name *IdentifierSyntax) *VariableAccessSyntax {
+	return &VariableAccessSyntax{
+		Name: name,
+	}
+}
+
+func (s *VariableAccessSyntax) GetSpan() *util.TextSpan {
+	return s.Name.GetSpan()
+}
+
+type StringSyntax struct {
+	StringTokens  []*token.Token
+	Expressions   []SyntaxBase
+	SegmentValues []string
+}
+
+func NewStringSyntax(stringTokens []*token.Token, expressions []SyntaxBase, segmentValues []string) *StringSyntax {
+	return &StringSyntax{
+		StringTokens:  stringTokens,
+		Expressions:   expressions,
+		SegmentValues: segmentValues,
+	}
+}
+
+func (s *StringSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.StringTokens[0], s.StringTokens[len(s.StringTokens)-1])
+}
+
+func (s *StringSyntax) IsInterpolated() bool {
+	return len(s.Expressions) > 0
+}
+
+func (s *StringSyntax) TryGetLiteralValue() (string, bool) {
+	if s.IsInterpolated() || len(s.SegmentValues) != 1 {
+		return "", false
+	}
+	return s.SegmentValues[0], true
+}
+
+type IntegerLiteralSyntax struct {
+	Literal *token.Token
+	Value   uint64
+}
+
+func NewIntegerLiteralSyntax(literal *token.Token, value uint64) *IntegerLiteralSyntax {
+	return &IntegerLiteralSyntax{
+		Literal: literal,
+		Value:   value,
+	}
+}
+
+func (s *IntegerLiteralSyntax) GetSpan() *util.TextSpan {
+	return s.Literal.GetSpan()
+}
+
+type BooleanLiteralSyntax struct {
+	Literal *token.Token
+	Value   bool
+}
+
+func NewBooleanLiteralSyntax(literal *token.Token, value bool) *BooleanLiteralSyntax {
+	return &BooleanLiteralSyntax{
+		Literal: literal,
+		Value:   value,
+	}
+}
+
+func (s *BooleanLiteralSyntax) GetSpan() *util.TextSpan {
+	return s.Literal.GetSpan()
+}
+
+type NullLiteralSyntax struct {
+	NullKeyword *token.Token
+}
+
+func NewNullLiteralSyntax(nullKeyword *token.Token) *NullLiteralSyntax {
+	return &NullLiteralSyntax{
+		NullKeyword: nullKeyword,
+	}
+}
+
+func (s *NullLiteralSyntax) GetSpan() *util.TextSpan {
+	return s.NullKeyword.GetSpan()
+}
+
+type ArraySyntax struct {
+	OpenBracket  *token.Token
+	Items        []*ArrayItemSyntax
+	CloseBracket SyntaxBase
+}
+
+func NewArraySyntax(openBracket *token.Token, items []*ArrayItemSyntax, closeBracket SyntaxBase) *ArraySyntax {
+	return &ArraySyntax{
+		OpenBracket:  openBracket,
+		Items:        items,
+		CloseBracket: closeBracket,
+	}
+}
+
+func (s *ArraySyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.OpenBracket, s.CloseBracket)
+}
+
+type ArrayItemSyntax struct {
+	Value SyntaxBase
+}
+
+func NewArrayItemSyntax(value SyntaxBase) *ArrayItemSyntax {
+	return &ArrayItemSyntax{
+		Value: value,
+	}
+}
+
+func (s *ArrayItemSyntax) GetSpan() *util.TextSpan {
+	return s.Value.GetSpan()
+}
+
+type ObjectSyntax struct {
+	OpenBrace *token.Token
+	// Children holds ObjectPropertySyntax, nested ResourceDeclarationSyntax and SkippedTriviaSyntax nodes.
+	Children   []SyntaxBase
+	CloseBrace SyntaxBase
+}
+
+func NewObjectSyntax(openBrace *token.Token, children []SyntaxBase, closeBrace SyntaxBase) *ObjectSyntax {
+	return &ObjectSyntax{
+		OpenBrace:  openBrace,
+		Children:   children,
+		CloseBrace: closeBrace,
+	}
+}
+
+func (s *ObjectSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.OpenBrace, s.CloseBrace)
+}
+
+func (s *ObjectSyntax) Properties() []*ObjectPropertySyntax {
+	var properties []*ObjectPropertySyntax
+	for _, child := range s.Children {
+		if property, ok := child.(*ObjectPropertySyntax); ok {
+			properties = append(properties, property)
+		}
+	}
+	return properties
+}
+
+func (s *ObjectSyntax) Resources() []*ResourceDeclarationSyntax {
+	var resources []*ResourceDeclarationSyntax
+	for _, child := range s.Children {
+		if resource, ok := child.(*ResourceDeclarationSyntax); ok {
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// TryGetProperty returns the first property with the given key, or nil if there is none.
+func (s *ObjectSyntax) TryGetProperty(key string) *ObjectPropertySyntax {
+	for _, property := range s.Properties() {
+		if name, ok := property.TryGetKeyText(); ok && name == key {
+			return property
+		}
+	}
+	return nil
+}
+
+type ObjectPropertySyntax struct {
+	// Key is an IdentifierSyntax, a StringSyntax or a SkippedTriviaSyntax.
+	Key   SyntaxBase
+	Colon SyntaxBase
+	Value SyntaxBase
+}
+
+func NewObjectPropertySyntax(key SyntaxBase, colon SyntaxBase, value SyntaxBase) *ObjectPropertySyntax {
+	return &ObjectPropertySyntax{
+		Key:   key,
+		Colon: colon,
+		Value: value,
+	}
+}
+
+func (s *ObjectPropertySyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.Key, s.Value)
+}
+
+func (s *ObjectPropertySyntax) TryGetKeyText() (string, bool) {
+	return tryGetKeyText(s.Key)
+}
+
+func tryGetKeyText(key SyntaxBase) (string, bool) {
+	switch key := key.(type) {
+	case *IdentifierSyntax:
+		return key.IdentifierName(), key.IsValid()
+	case *StringSyntax:
+		return key.TryGetLiteralValue()
+	}
+	return "", false
+}
+
+type ParenthesizedExpressionSyntax struct {
+	OpenParen  *token.Token
+	Expression SyntaxBase
+	CloseParen SyntaxBase
+}
+
+func NewParenthesizedExpressionSyntax(openParen *token.Token, expression SyntaxBase, closeParen SyntaxBase) *ParenthesizedExpressionSyntax {
+	return &ParenthesizedExpressionSyntax{
+		OpenParen:  openParen,
+		Expression: expression,
+		CloseParen: closeParen,
+	}
+}
+
+func (s *ParenthesizedExpressionSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.OpenParen, s.CloseParen)
+}
+
+type FunctionArgumentSyntax struct {
+	Expression SyntaxBase
+}
+
+func NewFunctionArgumentSyntax(expression SyntaxBase) *FunctionArgumentSyntax {
+	return &FunctionArgumentSyntax{
+		Expression: expression,
+	}
+}
+
+func (s *FunctionArgumentSyntax) GetSpan() *util.TextSpan {
+	return s.Expression.GetSpan()
+}
+
+type FunctionCallSyntax struct {
+	Name       *IdentifierSyntax
+	OpenParen  *token.Token
+	Arguments  []*FunctionArgumentSyntax
+	CloseParen SyntaxBase
+}
+
+func NewFunctionCallSyntax(name *IdentifierSyntax, openParen *token.Token, arguments []*FunctionArgumentSyntax, closeParen SyntaxBase) *FunctionCallSyntax {
+	return &FunctionCallSyntax{
+		Name:       name,
+		OpenParen:  openParen,
+		Arguments:  arguments,
+		CloseParen: closeParen,
+	}
+}
+
+func (s *FunctionCallSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.Name, s.CloseParen)
+}
+
+// InstanceFunctionCallSyntax is a call qualified by a namespace or a resource, e.g. sys.concat() or stg.listKeys().
+type InstanceFunctionCallSyntax struct {
+	BaseExpression SyntaxBase
+	Dot            *token.Token
+	Name           *IdentifierSyntax
+	OpenParen      *token.Token
+	Arguments      []*FunctionArgumentSyntax
+	CloseParen     SyntaxBase
+}
+
+func NewInstanceFunctionCallSyntax(baseExpression SyntaxBase, dot *token.Token, name *IdentifierSyntax, openParen *token.Token, arguments []*FunctionArgumentSyntax, closeParen SyntaxBase) *InstanceFunctionCallSyntax {
+	return &InstanceFunctionCallSyntax{
+		BaseExpression: baseExpression,
+		Dot:            dot,
+		Name:           name,
+		OpenParen:      openParen,
+		Arguments:      arguments,
+		CloseParen:     closeParen,
+	}
+}
+
+func (s *InstanceFunctionCallSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.BaseExpression, s.CloseParen)
+}
+
+type PropertyAccessSyntax struct {
+	BaseExpression   SyntaxBase
+	Dot              *token.Token
+	SafeAccessMarker *token.Token
+	PropertyName     *IdentifierSyntax
+}
+
+func NewPropertyAccessSyntax(baseExpression SyntaxBase, dot *token.Token, safeAccessMarker *token.Token, propertyName *IdentifierSyntax) *PropertyAccessSyntax {
+	return &PropertyAccessSyntax{
+		BaseExpression:   baseExpression,
+		Dot:              dot,
+		SafeAccessMarker: safeAccessMarker,
+		PropertyName:     propertyName,
+	}
+}
+
+func (s *PropertyAccessSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.BaseExpression, s.PropertyName)
+}
+
+func (s *PropertyAccessSyntax) IsSafeAccess() bool {
+	return s.SafeAccessMarker != nil
+}
+
+type ArrayAccessSyntax struct {
+	BaseExpression   SyntaxBase
+	OpenSquare       *token.Token
+	SafeAccessMarker *token.Token
+	IndexExpression  SyntaxBase
+	CloseSquare      SyntaxBase
+}
+
+func NewArrayAccessSyntax(baseExpression SyntaxBase, openSquare *token.Token, safeAccessMarker *token.Token, indexExpression SyntaxBase, closeSquare SyntaxBase) *ArrayAccessSyntax {
+	return &ArrayAccessSyntax{
+		BaseExpression:   baseExpression,
+		OpenSquare:       openSquare,
+		SafeAccessMarker: safeAccessMarker,
+		IndexExpression:  indexExpression,
+		CloseSquare:      closeSquare,
+	}
+}
+
+func (s *ArrayAccessSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.BaseExpression, s.CloseSquare)
+}
+
+func (s *ArrayAccessSyntax) IsSafeAccess() bool {
+	return s.SafeAccessMarker != nil
+}
+
+// ResourceAccessSyntax is a reference to a nested resource, e.g. vnet::subnet.
+type ResourceAccessSyntax struct {
+	BaseExpression SyntaxBase
+	DoubleColon    *token.Token
+	ResourceName   *IdentifierSyntax
+}
+
+func NewResourceAccessSyntax(baseExpression SyntaxBase, doubleColon *token.Token, resourceName *IdentifierSyntax) *ResourceAccessSyntax {
+	return &ResourceAccessSyntax{
+		BaseExpression: baseExpression,
+		DoubleColon:    doubleColon,
+		ResourceName:   resourceName,
+	}
+}
+
+func (s *ResourceAccessSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.BaseExpression, s.ResourceName)
+}
+
+type NonNullAssertionSyntax struct {
+	BaseExpression    SyntaxBase
+	AssertionOperator *token.Token
+}
+
+func NewNonNullAssertionSyntax(baseExpression SyntaxBase, assertionOperator *token.Token) *NonNullAssertionSyntax {
+	return &NonNullAssertionSyntax{
+		BaseExpression:    baseExpression,
+		AssertionOperator: assertionOperator,
+	}
+}
+
+func (s *NonNullAssertionSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.BaseExpression, s.AssertionOperator)
+}
+
+type UnaryOperationSyntax struct {
+	OperatorToken *token.Token
+	Expression    SyntaxBase
+	Operator      UnaryOperator
+}
+
+func NewUnaryOperationSyntax(operatorToken *token.Token, expression SyntaxBase, operator UnaryOperator) *UnaryOperationSyntax {
+	return &UnaryOperationSyntax{
+		OperatorToken: operatorToken,
+		Expression:    expression,
+		Operator:      operator,
+	}
+}
+
+func (s *UnaryOperationSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.OperatorToken, s.Expression)
+}
+
+type BinaryOperationSyntax struct {
+	LeftExpression  SyntaxBase
+	OperatorToken   *token.Token
+	RightExpression SyntaxBase
+	Operator        BinaryOperator
+}
+
+func NewBinaryOperationSyntax(leftExpression SyntaxBase, operatorToken *token.Token, rightExpression SyntaxBase, operator BinaryOperator) *BinaryOperationSyntax {
+	return &BinaryOperationSyntax{
+		LeftExpression:  leftExpression,
+		OperatorToken:   operatorToken,
+		RightExpression: rightExpression,
+		Operator:        operator,
+	}
+}
+
+func (s *BinaryOperationSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.LeftExpression, s.RightExpression)
+}
+
+type TernaryOperationSyntax struct {
+	ConditionExpression SyntaxBase
+	Question            *token.Token
+	TrueExpression      SyntaxBase
+	Colon               SyntaxBase
+	FalseExpression     SyntaxBase
+}
+
+func NewTernaryOperationSyntax(conditionExpression SyntaxBase, question *token.Token, trueExpression SyntaxBase, colon SyntaxBase, falseExpression SyntaxBase) *TernaryOperationSyntax {
+	return &TernaryOperationSyntax{
+		ConditionExpression: conditionExpression,
+		Question:            question,
+		TrueExpression:      trueExpression,
+		Colon:               colon,
+		FalseExpression:     falseExpression,
+	}
+}
+
+func (s *TernaryOperationSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.ConditionExpression, s.FalseExpression)
+}
+
+type LocalVariableSyntax struct {
+	Name *IdentifierSyntax
+}
+
+func NewLocalVariableSyntax(name *IdentifierSyntax) *LocalVariableSyntax {
+	return &LocalVariableSyntax{
+		Name: name,
+	}
+}
+
+func (s *LocalVariableSyntax) GetSpan() *util.TextSpan {
+	return s.Name.GetSpan()
+}
+
+// VariableBlockSyntax is a parenthesized list of local variables, e.g. (item, index).
+type VariableBlockSyntax struct {
+	OpenParen  *token.Token
+	Arguments  []*LocalVariableSyntax
+	CloseParen SyntaxBase
+}
+
+func NewVariableBlockSyntax(openParen *token.Token, arguments []*LocalVariableSyntax, closeParen SyntaxBase) *VariableBlockSyntax {
+	return &VariableBlockSyntax{
+		OpenParen:  openParen,
+		Arguments:  arguments,
+		CloseParen: closeParen,
+	}
+}
+
+func (s *VariableBlockSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.OpenParen, s.CloseParen)
+}
+
+type LambdaSyntax struct {
+	// VariableSection is a LocalVariableSyntax or a VariableBlockSyntax.
+	VariableSection SyntaxBase
+	Arrow           *token.Token
+	Body            SyntaxBase
+}
+
+func NewLambdaSyntax(variableSection SyntaxBase, arrow *token.Token, body SyntaxBase) *LambdaSyntax {
+	return &LambdaSyntax{
+		VariableSection: variableSection,
+		Arrow:           arrow,
+		Body:            body,
+	}
+}
+
+func (s *LambdaSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.VariableSection, s.Body)
+}
+
+func (s *LambdaSyntax) GetLocalVariables() []*LocalVariableSyntax {
+	return getLocalVariables(s.VariableSection)
+}
+
+func getLocalVariables(variableSection SyntaxBase) []*LocalVariableSyntax {
+	switch variableSection := variableSection.(type) {
+	case *LocalVariableSyntax:
+		return []*LocalVariableSyntax{variableSection}
+	case *VariableBlockSyntax:
+		return variableSection.Arguments
+	}
+	return nil
+}
+
+// TypedLocalVariableSyntax is a parameter of a user-defined function, e.g. name string.
+type TypedLocalVariableSyntax struct {
+	Name *IdentifierSyntax
+	Type SyntaxBase
+}
+
+func NewTypedLocalVariableSyntax(name *IdentifierSyntax, typeSyntax SyntaxBase) *TypedLocalVariableSyntax {
+	return &TypedLocalVariableSyntax{
+		Name: name,
+		Type: typeSyntax,
+	}
+}
+
+func (s *TypedLocalVariableSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.Name, s.Type)
+}
+
+// TypedVariableBlockSyntax is the parenthesized parameter list of a user-defined function, e.g. (a string, b int).
+type TypedVariableBlockSyntax struct {
+	OpenParen  *token.Token
+	Arguments  []*TypedLocalVariableSyntax
+	CloseParen SyntaxBase
+}
+
+func NewTypedVariableBlockSyntax(openParen *token.Token, arguments []*TypedLocalVariableSyntax, closeParen SyntaxBase) *TypedVariableBlockSyntax {
+	return &TypedVariableBlockSyntax{
+		OpenParen:  openParen,
+		Arguments:  arguments,
+		CloseParen: closeParen,
+	}
+}
+
+func (s *TypedVariableBlockSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.OpenParen, s.CloseParen)
+}
+
+// TypedLambdaSyntax is the signature and body of a user-defined function, e.g. (a string) string => a.
+type TypedLambdaSyntax struct {
+	VariableSection *TypedVariableBlockSyntax
+	ReturnType      SyntaxBase
+	Arrow           SyntaxBase
+	Body            SyntaxBase
+}
+
+func NewTypedLambdaSyntax(variableSection *TypedVariableBlockSyntax, returnType SyntaxBase, arrow SyntaxBase, body SyntaxBase) *TypedLambdaSyntax {
+	return &TypedLambdaSyntax{
+		VariableSection: variableSection,
+		ReturnType:      returnType,
+		Arrow:           arrow,
+		Body:            body,
+	}
+}
+
+func (s *TypedLambdaSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.VariableSection, s.Body)
+}
+
+func (s *TypedLambdaSyntax) GetLocalVariables() []*TypedLocalVariableSyntax {
+	return s.VariableSection.Arguments
+}
+
+// ForSyntax is a for-expression, e.g. [for (item, index) in items: body].
+type ForSyntax struct {
+	OpenSquare *token.Token
+	ForKeyword *token.Token
+	// VariableSection is a LocalVariableSyntax, a VariableBlockSyntax or a SkippedTriviaSyntax.
+	VariableSection SyntaxBase
+	InKeyword       SyntaxBase
+	Expression      SyntaxBase
+	Colon           SyntaxBase
+	// Body is an expression, or an IfConditionSyntax for filtered resource and module loops.
+	Body        SyntaxBase
+	CloseSquare SyntaxBase
+}
+
+func NewForSyntax(openSquare *token.Token, forKeyword *token.Token, variableSection SyntaxBase, inKeyword SyntaxBase, expression SyntaxBase, colon SyntaxBase, body SyntaxBase, closeSquare SyntaxBase) *ForSyntax {
+	return &ForSyntax{
+		OpenSquare:      openSquare,
+		ForKeyword:      forKeyword,
+		VariableSection: variableSection,
+		InKeyword:       inKeyword,
+		Expression:      expression,
+		Colon:           colon,
+		Body:            body,
+		CloseSquare:     closeSquare,
+	}
+}
+
+func (s *ForSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.OpenSquare, s.CloseSquare)
+}
+
+// ItemVariable returns the loop item variable, or nil if it is missing.
+func (s *ForSyntax) ItemVariable() *LocalVariableSyntax {
+	variables := getLocalVariables(s.VariableSection)
+	if len(variables) > 0 {
+		return variables[0]
+	}
+	return nil
+}
+
+// IndexVariable returns the loop index variable, or nil if the loop does not declare one.
+func (s *ForSyntax) IndexVariable() *LocalVariableSyntax {
+	variables := getLocalVariables(s.VariableSection)
+	if len(variables) > 1 {
+		return variables[1]
+	}
+	return nil
+}
+
+// IfConditionSyntax is a condition on a resource or module body, e.g. if (deploy) { ... }.
+type IfConditionSyntax struct {
+	Keyword             *token.Token
+	ConditionExpression SyntaxBase
+	Body                SyntaxBase
+}
+
+func NewIfConditionSyntax(keyword *token.Token, conditionExpression SyntaxBase, body SyntaxBase) *IfConditionSyntax {
+	return &IfConditionSyntax{
+		Keyword:             keyword,
+		ConditionExpression: conditionExpression,
+		Body:                body,
+	}
+}
+
+func (s *IfConditionSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.Keyword, s.Body)
+}
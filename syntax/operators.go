@@ -0,0 +1,63 @@
+package syntax
+
+type UnaryOperator int
+
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Syntax/UnaryOperator.cs
+const (
+	UnaryOperatorNot UnaryOperator = iota
+	UnaryOperatorMinus
+)
+
+type BinaryOperator int
+
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Syntax/BinaryOperator.cs
+const (
+	BinaryOperatorLogicalOr BinaryOperator = iota
+	BinaryOperatorLogicalAnd
+	BinaryOperatorEquals
+	BinaryOperatorNotEquals
+	BinaryOperatorEqualsInsensitive
+	BinaryOperatorNotEqualsInsensitive
+	BinaryOperatorLessThan
+	BinaryOperatorLessThanOrEqual
+	BinaryOperatorGreaterThan
+	BinaryOperatorGreaterThanOrEqual
+	BinaryOperatorAdd
+	BinaryOperatorSubtract
+	BinaryOperatorMultiply
+	BinaryOperatorDivide
+	BinaryOperatorModulo
+	BinaryOperatorCoalesce
+)
+
+var unaryOperatorToText = map[UnaryOperator]string{
+	UnaryOperatorNot:   "!",
+	UnaryOperatorMinus: "-",
+}
+
+var binaryOperatorToText = map[BinaryOperator]string{
+	BinaryOperatorLogicalOr:            "||",
+	BinaryOperatorLogicalAnd:           "&&",
+	BinaryOperatorEquals:               "==",
+	BinaryOperatorNotEquals:            "!=",
+	BinaryOperatorEqualsInsensitive:    "=~",
+	BinaryOperatorNotEqualsInsensitive: "!~",
+	BinaryOperatorLessThan:             "<",
+	BinaryOperatorLessThanOrEqual:      "<=",
+	BinaryOperatorGreaterThan:          ">",
+	BinaryOperatorGreaterThanOrEqual:   ">=",
+	BinaryOperatorAdd:                  "+",
+	BinaryOperatorSubtract:             "-",
+	BinaryOperatorMultiply:             "*",
+	BinaryOperatorDivide:               "/",
+	BinaryOperatorModulo:               "%",
+	BinaryOperatorCoalesce:             "??",
+}
+
+func GetUnaryOperatorText(operator UnaryOperator) string {
+	return unaryOperatorToText[operator]
+}
+
+func GetBinaryOperatorText(operator BinaryOperator) string {
+	return binaryOperatorToText[operator]
+}
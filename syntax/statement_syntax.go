@@ -0,0 +1,513 @@
+package syntax
+
+import (
+	"bicep-go/token"
+	"bicep-go/util"
+)
+
+type ProgramSyntax struct {
+	Children  []SyntaxBase
+	EndOfFile *token.Token
+}
+
+func NewProgramSyntax(children []SyntaxBase, endOfFile *token.Token) *ProgramSyntax {
+	return &ProgramSyntax{
+		Children:  children,
+		EndOfFile: endOfFile,
+	}
+}
+
+func (s *ProgramSyntax) GetSpan() *util.TextSpan {
+	if len(s.Children) > 0 {
+		return spanBetween(s.Children[0], s.EndOfFile)
+	}
+	return s.EndOfFile.GetSpan()
+}
+
+// Declarations returns the statements of the program, skipping new lines.
+func (s *ProgramSyntax) Declarations() []SyntaxBase {
+	var declarations []SyntaxBase
+	for _, child := range s.Children {
+		if _, ok := child.(*token.Token); !ok {
+			declarations = append(declarations, child)
+		}
+	}
+	return declarations
+}
+
+type TargetScopeSyntax struct {
+	decorableSyntax
+	Keyword    *token.Token
+	Assignment SyntaxBase
+	Value      SyntaxBase
+}
+
+func NewTargetScopeSyntax(leadingNodes []SyntaxBase, keyword *token.Token, assignment SyntaxBase, value SyntaxBase) *TargetScopeSyntax {
+	return &TargetScopeSyntax{
+		decorableSyntax: decorableSyntax{LeadingNodes: leadingNodes},
+		Keyword:         keyword,
+		Assignment:      assignment,
+		Value:           value,
+	}
+}
+
+func (s *TargetScopeSyntax) GetSpan() *util.TextSpan {
+	return s.spanFrom(s.Keyword, s.Value)
+}
+
+type MetadataDeclarationSyntax struct {
+	decorableSyntax
+	Keyword    *token.Token
+	Name       *IdentifierSyntax
+	Assignment SyntaxBase
+	Value      SyntaxBase
+}
+
+func NewMetadataDeclarationSyntax(leadingNodes []SyntaxBase, keyword *token.Token, name *IdentifierSyntax, assignment SyntaxBase, value SyntaxBase) *MetadataDeclarationSyntax {
+	return &MetadataDeclarationSyntax{
+		decorableSyntax: decorableSyntax{LeadingNodes: leadingNodes},
+		Keyword:         keyword,
+		Name:            name,
+		Assignment:      assignment,
+		Value:           value,
+	}
+}
+
+func (s *MetadataDeclarationSyntax) GetSpan() *util.TextSpan {
+	return s.spanFrom(s.Keyword, s.Value)
+}
+
+func (s *MetadataDeclarationSyntax) GetName() *IdentifierSyntax {
+	return s.Name
+}
+
+type ParameterDeclarationSyntax struct {
+	decorableSyntax
+	Keyword *token.Token
+	Name    *IdentifierSyntax
+	Type    SyntaxBase
+	// Modifier is nil or a ParameterDefaultValueSyntax.
+	Modifier SyntaxBase
+}
+
+func NewParameterDeclarationSyntax(leadingNodes []SyntaxBase, keyword *token.Token, name *IdentifierSyntax, typeSyntax SyntaxBase, modifier SyntaxBase) *ParameterDeclarationSyntax {
+	return &ParameterDeclarationSyntax{
+		decorableSyntax: decorableSyntax{LeadingNodes: leadingNodes},
+		Keyword:         keyword,
+		Name:            name,
+		Type:            typeSyntax,
+		Modifier:        modifier,
+	}
+}
+
+func (s *ParameterDeclarationSyntax) GetSpan() *util.TextSpan {
+	if s.Modifier != nil {
+		return s.spanFrom(s.Keyword, s.Modifier)
+	}
+	return s.spanFrom(s.Keyword, s.Type)
+}
+
+func (s *ParameterDeclarationSyntax) GetName() *IdentifierSyntax {
+	return s.Name
+}
+
+// DefaultValue returns the default value expression, or nil if the parameter has none.
+func (s *ParameterDeclarationSyntax) DefaultValue() SyntaxBase {
+	if modifier, ok := s.Modifier.(*ParameterDefaultValueSyntax); ok {
+		return modifier.DefaultValue
+	}
+	return nil
+}
+
+type ParameterDefaultValueSyntax struct {
+	AssignmentToken *token.Token
+	DefaultValue    SyntaxBase
+}
+
+func NewParameterDefaultValueSyntax(assignmentToken *token.Token, defaultValue SyntaxBase) *ParameterDefaultValueSyntax {
+	return &ParameterDefaultValueSyntax{
+		AssignmentToken: assignmentToken,
+		DefaultValue:    defaultValue,
+	}
+}
+
+func (s *ParameterDefaultValueSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.AssignmentToken, s.DefaultValue)
+}
+
+type VariableDeclarationSyntax struct {
+	decorableSyntax
+	Keyword    *token.Token
+	Name       *IdentifierSyntax
+	Assignment SyntaxBase
+	Value      SyntaxBase
+}
+
+func NewVariableDeclarationSyntax(leadingNodes []SyntaxBase, keyword *token.Token, name *IdentifierSyntax, assignment SyntaxBase, value SyntaxBase) *VariableDeclarationSyntax {
+	return &VariableDeclarationSyntax{
+		decorableSyntax: decorableSyntax{LeadingNodes: leadingNodes},
+		Keyword:         keyword,
+		Name:            name,
+		Assignment:      assignment,
+		Value:           value,
+	}
+}
+
+func (s *VariableDeclarationSyntax) GetSpan() *util.TextSpan {
+	return s.spanFrom(s.Keyword, s.Value)
+}
+
+func (s *VariableDeclarationSyntax) GetName() *IdentifierSyntax {
+	return s.Name
+}
+
+type ResourceDeclarationSyntax struct {
+	decorableSyntax
+	Keyword         *token.Token
+	Name            *IdentifierSyntax
+	Type            SyntaxBase
+	ExistingKeyword *token.Token
+	Assignment      SyntaxBase
+	// Value is an ObjectSyntax, IfConditionSyntax or ForSyntax.
+	Value SyntaxBase
+}
+
+func NewResourceDeclarationSyntax(leadingNodes []SyntaxBase, keyword *token.Token, name *IdentifierSyntax, typeSyntax SyntaxBase, existingKeyword *token.Token, assignment SyntaxBase, value SyntaxBase) *ResourceDeclarationSyntax {
+	return &ResourceDeclarationSyntax{
+		decorableSyntax: decorableSyntax{LeadingNodes: leadingNodes},
+		Keyword:         keyword,
+		Name:            name,
+		Type:            typeSyntax,
+		ExistingKeyword: existingKeyword,
+		Assignment:      assignment,
+		Value:           value,
+	}
+}
+
+func (s *ResourceDeclarationSyntax) GetSpan() *util.TextSpan {
+	return s.spanFrom(s.Keyword, s.Value)
+}
+
+func (s *ResourceDeclarationSyntax) GetName() *IdentifierSyntax {
+	return s.Name
+}
+
+func (s *ResourceDeclarationSyntax) IsExistingResource() bool {
+	return s.ExistingKeyword != nil
+}
+
+// TypeString returns the literal resource type string, e.g. 'Microsoft.Storage/storageAccounts@2023-01-01'.
+func (s *ResourceDeclarationSyntax) TypeString() (string, bool) {
+	if typeString, ok := s.Type.(*StringSyntax); ok {
+		return typeString.TryGetLiteralValue()
+	}
+	return "", false
+}
+
+// TryGetBody returns the object body of the resource, looking through loops and conditions.
+func (s *ResourceDeclarationSyntax) TryGetBody() *ObjectSyntax {
+	return tryGetBody(s.Value)
+}
+
+type ModuleDeclarationSyntax struct {
+	decorableSyntax
+	Keyword    *token.Token
+	Name       *IdentifierSyntax
+	Path       SyntaxBase
+	Assignment SyntaxBase
+	// Value is an ObjectSyntax, IfConditionSyntax or ForSyntax.
+	Value SyntaxBase
+}
+
+func NewModuleDeclarationSyntax(leadingNodes []SyntaxBase, keyword *token.Token, name *IdentifierSyntax, path SyntaxBase, assignment SyntaxBase, value SyntaxBase) *ModuleDeclarationSyntax {
+	return &ModuleDeclarationSyntax{
+		decorableSyntax: decorableSyntax{LeadingNodes: leadingNodes},
+		Keyword:         keyword,
+		Name:            name,
+		Path:            path,
+		Assignment:      assignment,
+		Value:           value,
+	}
+}
+
+func (s *ModuleDeclarationSyntax) GetSpan() *util.TextSpan {
+	return s.spanFrom(s.Keyword, s.Value)
+}
+
+func (s *ModuleDeclarationSyntax) GetName() *IdentifierSyntax {
+	return s.Name
+}
+
+func (s *ModuleDeclarationSyntax) TryGetPath() (string, bool) {
+	if path, ok := s.Path.(*StringSyntax); ok {
+		return path.TryGetLiteralValue()
+	}
+	return "", false
+}
+
+// TryGetBody returns the object body of the module, looking through loops and conditions.
+func (s *ModuleDeclarationSyntax) TryGetBody() *ObjectSyntax {
+	return tryGetBody(s.Value)
+}
+
+func tryGetBody(value SyntaxBase) *ObjectSyntax {
+	switch value := value.(type) {
+	case *ObjectSyntax:
+		return value
+	case *IfConditionSyntax:
+		return tryGetBody(value.Body)
+	case *ForSyntax:
+		return tryGetBody(value.Body)
+	}
+	return nil
+}
+
+type OutputDeclarationSyntax struct {
+	decorableSyntax
+	Keyword    *token.Token
+	Name       *IdentifierSyntax
+	Type       SyntaxBase
+	Assignment SyntaxBase
+	Value      SyntaxBase
+}
+
+func NewOutputDeclarationSyntax(leadingNodes []SyntaxBase, keyword *token.Token, name *IdentifierSyntax, typeSyntax SyntaxBase, assignment SyntaxBase, value SyntaxBase) *OutputDeclarationSyntax {
+	return &OutputDeclarationSyntax{
+		decorableSyntax: decorableSyntax{LeadingNodes: leadingNodes},
+		Keyword:         keyword,
+		Name:            name,
+		Type:            typeSyntax,
+		Assignment:      assignment,
+		Value:           value,
+	}
+}
+
+func (s *OutputDeclarationSyntax) GetSpan() *util.TextSpan {
+	return s.spanFrom(s.Keyword, s.Value)
+}
+
+func (s *OutputDeclarationSyntax) GetName() *IdentifierSyntax {
+	return s.Name
+}
+
+type TypeDeclarationSyntax struct {
+	decorableSyntax
+	Keyword    *token.Token
+	Name       *IdentifierSyntax
+	Assignment SyntaxBase
+	Value      SyntaxBase
+}
+
+func NewTypeDeclarationSyntax(leadingNodes []SyntaxBase, keyword *token.Token, name *IdentifierSyntax, assignment SyntaxBase, value SyntaxBase) *TypeDeclarationSyntax {
+	return &TypeDeclarationSyntax{
+		decorableSyntax: decorableSyntax{LeadingNodes: leadingNodes},
+		Keyword:         keyword,
+		Name:            name,
+		Assignment:      assignment,
+		Value:           value,
+	}
+}
+
+func (s *TypeDeclarationSyntax) GetSpan() *util.TextSpan {
+	return s.spanFrom(s.Keyword, s.Value)
+}
+
+func (s *TypeDeclarationSyntax) GetName() *IdentifierSyntax {
+	return s.Name
+}
+
+// FunctionDeclarationSyntax is a user-defined function, e.g. func name(a string) string => a.
+type FunctionDeclarationSyntax struct {
+	decorableSyntax
+	Keyword *token.Token
+	Name    *IdentifierSyntax
+	// Lambda is a TypedLambdaSyntax, or a SkippedTriviaSyntax if it could not be parsed.
+	Lambda SyntaxBase
+}
+
+func NewFunctionDeclarationSyntax(leadingNodes []SyntaxBase, keyword *token.Token, name *IdentifierSyntax, lambda SyntaxBase) *FunctionDeclarationSyntax {
+	return &FunctionDeclarationSyntax{
+		decorableSyntax: decorableSyntax{LeadingNodes: leadingNodes},
+		Keyword:         keyword,
+		Name:            name,
+		Lambda:          lambda,
+	}
+}
+
+func (s *FunctionDeclarationSyntax) GetSpan() *util.TextSpan {
+	return s.spanFrom(s.Keyword, s.Lambda)
+}
+
+func (s *FunctionDeclarationSyntax) GetName() *IdentifierSyntax {
+	return s.Name
+}
+
+// TryGetLambda returns the lambda of the function, or nil if it could not be parsed.
+func (s *FunctionDeclarationSyntax) TryGetLambda() *TypedLambdaSyntax {
+	lambda, _ := s.Lambda.(*TypedLambdaSyntax)
+	return lambda
+}
+
+// CompileTimeImportDeclarationSyntax imports exported types, variables and functions from another file,
+// e.g. import {a, b as c} from 'lib.bicep' or import * as lib from 'lib.bicep'.
+type CompileTimeImportDeclarationSyntax struct {
+	decorableSyntax
+	Keyword *token.Token
+	// ImportExpression is an ImportedSymbolsListSyntax, a WildcardImportSyntax, or a SkippedTriviaSyntax
+	// if it could not be parsed.
+	ImportExpression SyntaxBase
+	// FromClause is a CompileTimeImportFromClauseSyntax, or a SkippedTriviaSyntax if it could not be parsed.
+	FromClause SyntaxBase
+}
+
+func NewCompileTimeImportDeclarationSyntax(leadingNodes []SyntaxBase, keyword *token.Token, importExpression SyntaxBase, fromClause SyntaxBase) *CompileTimeImportDeclarationSyntax {
+	return &CompileTimeImportDeclarationSyntax{
+		decorableSyntax:  decorableSyntax{LeadingNodes: leadingNodes},
+		Keyword:          keyword,
+		ImportExpression: importExpression,
+		FromClause:       fromClause,
+	}
+}
+
+func (s *CompileTimeImportDeclarationSyntax) GetSpan() *util.TextSpan {
+	return s.spanFrom(s.Keyword, s.FromClause)
+}
+
+// TryGetPathSyntax returns the path the symbols are imported from, or nil if it could not be parsed.
+func (s *CompileTimeImportDeclarationSyntax) TryGetPathSyntax() SyntaxBase {
+	if fromClause, ok := s.FromClause.(*CompileTimeImportFromClauseSyntax); ok {
+		return fromClause.Path
+	}
+	return nil
+}
+
+func (s *CompileTimeImportDeclarationSyntax) TryGetPath() (string, bool) {
+	if path, ok := s.TryGetPathSyntax().(*StringSyntax); ok {
+		return path.TryGetLiteralValue()
+	}
+	return "", false
+}
+
+// ImportedSymbolsListSyntax is the list of symbols imported by name, e.g. {a, b as c}.
+type ImportedSymbolsListSyntax struct {
+	OpenBrace       *token.Token
+	ImportedSymbols []*ImportedSymbolsListItemSyntax
+	CloseBrace      SyntaxBase
+}
+
+func NewImportedSymbolsListSyntax(openBrace *token.Token, importedSymbols []*ImportedSymbolsListItemSyntax, closeBrace SyntaxBase) *ImportedSymbolsListSyntax {
+	return &ImportedSymbolsListSyntax{
+		OpenBrace:       openBrace,
+		ImportedSymbols: importedSymbols,
+		CloseBrace:      closeBrace,
+	}
+}
+
+func (s *ImportedSymbolsListSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.OpenBrace, s.CloseBrace)
+}
+
+// ImportedSymbolsListItemSyntax is a symbol imported by name, optionally under an alias.
+type ImportedSymbolsListItemSyntax struct {
+	OriginalSymbolName *IdentifierSyntax
+	// AsClause is nil when the symbol is imported under its original name.
+	AsClause *AliasAsClauseSyntax
+}
+
+func NewImportedSymbolsListItemSyntax(originalSymbolName *IdentifierSyntax, asClause *AliasAsClauseSyntax) *ImportedSymbolsListItemSyntax {
+	return &ImportedSymbolsListItemSyntax{
+		OriginalSymbolName: originalSymbolName,
+		AsClause:           asClause,
+	}
+}
+
+func (s *ImportedSymbolsListItemSyntax) GetSpan() *util.TextSpan {
+	if s.AsClause != nil {
+		return spanBetween(s.OriginalSymbolName, s.AsClause)
+	}
+	return s.OriginalSymbolName.GetSpan()
+}
+
+// GetName returns the name the symbol is imported under.
+func (s *ImportedSymbolsListItemSyntax) GetName() *IdentifierSyntax {
+	if s.AsClause != nil {
+		if alias, ok := s.AsClause.Alias.(*IdentifierSyntax); ok {
+			return alias
+		}
+	}
+	return s.OriginalSymbolName
+}
+
+// WildcardImportSyntax imports every exported symbol under a namespace, e.g. * as lib.
+type WildcardImportSyntax struct {
+	Wildcard      *token.Token
+	AliasAsClause SyntaxBase
+}
+
+func NewWildcardImportSyntax(wildcard *token.Token, aliasAsClause SyntaxBase) *WildcardImportSyntax {
+	return &WildcardImportSyntax{
+		Wildcard:      wildcard,
+		AliasAsClause: aliasAsClause,
+	}
+}
+
+func (s *WildcardImportSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.Wildcard, s.AliasAsClause)
+}
+
+// GetName returns the namespace the symbols are imported under, or nil if it could not be parsed.
+func (s *WildcardImportSyntax) GetName() *IdentifierSyntax {
+	if asClause, ok := s.AliasAsClause.(*AliasAsClauseSyntax); ok {
+		if alias, ok := asClause.Alias.(*IdentifierSyntax); ok {
+			return alias
+		}
+	}
+	return nil
+}
+
+type AliasAsClauseSyntax struct {
+	Keyword *token.Token
+	Alias   SyntaxBase
+}
+
+func NewAliasAsClauseSyntax(keyword *token.Token, alias SyntaxBase) *AliasAsClauseSyntax {
+	return &AliasAsClauseSyntax{
+		Keyword: keyword,
+		Alias:   alias,
+	}
+}
+
+func (s *AliasAsClauseSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.Keyword, s.Alias)
+}
+
+type CompileTimeImportFromClauseSyntax struct {
+	Keyword *token.Token
+	Path    SyntaxBase
+}
+
+func NewCompileTimeImportFromClauseSyntax(keyword *token.Token, path SyntaxBase) *CompileTimeImportFromClauseSyntax {
+	return &CompileTimeImportFromClauseSyntax{
+		Keyword: keyword,
+		Path:    path,
+	}
+}
+
+func (s *CompileTimeImportFromClauseSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.Keyword, s.Path)
+}
+
+// MissingDeclarationSyntax holds decorators that are not followed by a declaration.
+type MissingDeclarationSyntax struct {
+	decorableSyntax
+}
+
+func NewMissingDeclarationSyntax(leadingNodes []SyntaxBase) *MissingDeclarationSyntax {
+	return &MissingDeclarationSyntax{
+		decorableSyntax: decorableSyntax{LeadingNodes: leadingNodes},
+	}
+}
+
+func (s *MissingDeclarationSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.LeadingNodes[0], s.LeadingNodes[len(s.LeadingNodes)-1])
+}
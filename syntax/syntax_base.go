@@ -0,0 +1,139 @@
+package syntax
+
+import (
+	"bicep-go/token"
+	"bicep-go/util"
+)
+
+// SyntaxBase is implemented by every node of the syntax tree, including tokens.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Syntax/SyntaxBase.cs
+type SyntaxBase interface {
+	GetSpan() *util.TextSpan
+}
+
+func spanBetween(first SyntaxBase, last SyntaxBase) *util.TextSpan {
+	return first.GetSpan().Between(last.GetSpan())
+}
+
+// SkippedTriviaSyntax holds tokens that the parser could not make sense of.
+// It also stands in for missing tokens, in which case Elements is empty.
+type SkippedTriviaSyntax struct {
+	Span         *util.TextSpan
+	Elements     []SyntaxBase
+	ErrorMessage string
+}
+
+func NewSkippedTriviaSyntax(span *util.TextSpan, elements []SyntaxBase, errorMessage string) *SkippedTriviaSyntax {
+	return &SkippedTriviaSyntax{
+		Span:         span,
+		Elements:     elements,
+		ErrorMessage: errorMessage,
+	}
+}
+
+func (s *SkippedTriviaSyntax) GetSpan() *util.TextSpan {
+	return s.Span
+}
+
+// IdentifierSyntax is a name. The child is a token, or a SkippedTriviaSyntax if the name is missing.
+type IdentifierSyntax struct {
+	Child SyntaxBase
+}
+
+func NewIdentifierSyntax(child SyntaxBase) *IdentifierSyntax {
+	return &IdentifierSyntax{
+		Child: child,
+	}
+}
+
+func (s *IdentifierSyntax) GetSpan() *util.TextSpan {
+	return s.Child.GetSpan()
+}
+
+func (s *IdentifierSyntax) IsValid() bool {
+	_, ok := s.Child.(*token.Token)
+	return ok
+}
+
+func (s *IdentifierSyntax) IdentifierName() string {
+	if tok, ok := s.Child.(*token.Token); ok {
+		return tok.Literal
+	}
+	return MISSING_NAME
+}
+
+// decorableSyntax carries the decorators and new lines preceding a declaration.
+type decorableSyntax struct {
+	LeadingNodes []SyntaxBase
+}
+
+func (s *decorableSyntax) GetLeadingNodes() []SyntaxBase {
+	return s.LeadingNodes
+}
+
+func (s *decorableSyntax) Decorators() []*DecoratorSyntax {
+	var decorators []*DecoratorSyntax
+	for _, node := range s.LeadingNodes {
+		if decorator, ok := node.(*DecoratorSyntax); ok {
+			decorators = append(decorators, decorator)
+		}
+	}
+	return decorators
+}
+
+func (s *decorableSyntax) spanFrom(keyword SyntaxBase, last SyntaxBase) *util.TextSpan {
+	if len(s.LeadingNodes) > 0 {
+		return spanBetween(s.LeadingNodes[0], last)
+	}
+	return spanBetween(keyword, last)
+}
+
+// DecorableSyntax is implemented by statements and type members that accept decorators.
+type DecorableSyntax interface {
+	SyntaxBase
+	GetLeadingNodes() []SyntaxBase
+	Decorators() []*DecoratorSyntax
+}
+
+// NamedDeclarationSyntax is implemented by top-level declarations that introduce a symbol.
+type NamedDeclarationSyntax interface {
+	DecorableSyntax
+	GetName() *IdentifierSyntax
+}
+
+type DecoratorSyntax struct {
+	At         *token.Token
+	Expression SyntaxBase
+}
+
+func NewDecoratorSyntax(at *token.Token, expression SyntaxBase) *DecoratorSyntax {
+	return &DecoratorSyntax{
+		At:         at,
+		Expression: expression,
+	}
+}
+
+func (s *DecoratorSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.At, s.Expression)
+}
+
+// Name returns the name of the decorator function, without any namespace qualifier.
+func (s *DecoratorSyntax) Name() string {
+	switch expression := s.Expression.(type) {
+	case *FunctionCallSyntax:
+		return expression.Name.IdentifierName()
+	case *InstanceFunctionCallSyntax:
+		return expression.Name.IdentifierName()
+	}
+	return MISSING_NAME
+}
+
+func (s *DecoratorSyntax) Arguments() []*FunctionArgumentSyntax {
+	switch expression := s.Expression.(type) {
+	case *FunctionCallSyntax:
+		return expression.Arguments
+	case *InstanceFunctionCallSyntax:
+		return expression.Arguments
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package syntax
+
+// SyntaxHierarchy records the parent of every node in a syntax tree.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Syntax/SyntaxHierarchy.cs
+type SyntaxHierarchy struct {
+	parents map[SyntaxBase]SyntaxBase
+}
+
+func NewSyntaxHierarchy(root SyntaxBase) *SyntaxHierarchy {
+	hierarchy := &SyntaxHierarchy{
+		parents: map[SyntaxBase]SyntaxBase{},
+	}
+	hierarchy.addChildren(root)
+	return hierarchy
+}
+
+func (h *SyntaxHierarchy) addChildren(node SyntaxBase) {
+	for _, child := range GetChildren(node) {
+		h.parents[child] = node
+		h.addChildren(child)
+	}
+}
+
+// GetParent returns the parent of a node, or nil for the root.
+func (h *SyntaxHierarchy) GetParent(node SyntaxBase) SyntaxBase {
+	return h.parents[node]
+}
+
+// GetAncestors returns the ancestors of a node, starting with its parent.
+func (h *SyntaxHierarchy) GetAncestors(node SyntaxBase) []SyntaxBase {
+	var ancestors []SyntaxBase
+	for parent := h.GetParent(node); parent != nil; parent = h.GetParent(parent) {
+		ancestors = append(ancestors, parent)
+	}
+	return ancestors
+}
+
+// IsDescendant reports whether node is contained in ancestor. A node is not its own descendant.
+func (h *SyntaxHierarchy) IsDescendant(node SyntaxBase, ancestor SyntaxBase) bool {
+	for parent := h.GetParent(node); parent != nil; parent = h.GetParent(parent) {
+		if parent == ancestor {
+			return true
+		}
+	}
+	return false
+}
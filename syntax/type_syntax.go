@@ -0,0 +1,235 @@
+package syntax
+
+import (
+	"bicep-go/token"
+	"bicep-go/util"
+)
+
+// TypeVariableAccessSyntax is a reference to a named type, e.g. string or a user-defined type.
+type TypeVariableAccessSyntax struct {
+	Name *IdentifierSyntax
+}
+
+func NewTypeVariableAccessSyntax(name *IdentifierSyntax) *TypeVariableAccessSyntax {
+	return &TypeVariableAccessSyntax{
+		Name: name,
+	}
+}
+
+func (s *TypeVariableAccessSyntax) GetSpan() *util.TextSpan {
+	return s.Name.GetSpan()
+}
+
+// TypePropertyAccessSyntax is a reference to a type through a namespace, e.g. sys.string.
+type TypePropertyAccessSyntax struct {
+	BaseExpression SyntaxBase
+	Dot            *token.Token
+	PropertyName   *IdentifierSyntax
+}
+
+func NewTypePropertyAccessSyntax(baseExpression SyntaxBase, dot *token.Token, propertyName *IdentifierSyntax) *TypePropertyAccessSyntax {
+	return &TypePropertyAccessSyntax{
+		BaseExpression: baseExpression,
+		Dot:            dot,
+		PropertyName:   propertyName,
+	}
+}
+
+func (s *TypePropertyAccessSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.BaseExpression, s.PropertyName)
+}
+
+// ResourceTypeSyntax is a resource-typed reference, e.g. resource 'Microsoft.Storage/storageAccounts@2023-01-01'.
+type ResourceTypeSyntax struct {
+	Keyword *token.Token
+	Type    SyntaxBase
+}
+
+func NewResourceTypeSyntax(keyword *token.Token, typeSyntax SyntaxBase) *ResourceTypeSyntax {
+	return &ResourceTypeSyntax{
+		Keyword: keyword,
+		Type:    typeSyntax,
+	}
+}
+
+func (s *ResourceTypeSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.Keyword, s.Type)
+}
+
+func (s *ResourceTypeSyntax) TypeString() (string, bool) {
+	if typeString, ok := s.Type.(*StringSyntax); ok {
+		return typeString.TryGetLiteralValue()
+	}
+	return "", false
+}
+
+type ArrayTypeSyntax struct {
+	Item         SyntaxBase
+	OpenBracket  *token.Token
+	CloseBracket SyntaxBase
+}
+
+func NewArrayTypeSyntax(item SyntaxBase, openBracket *token.Token, closeBracket SyntaxBase) *ArrayTypeSyntax {
+	return &ArrayTypeSyntax{
+		Item:         item,
+		OpenBracket:  openBracket,
+		CloseBracket: closeBracket,
+	}
+}
+
+func (s *ArrayTypeSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.Item, s.CloseBracket)
+}
+
+type NullableTypeSyntax struct {
+	Base              SyntaxBase
+	NullabilityMarker *token.Token
+}
+
+func NewNullableTypeSyntax(base SyntaxBase, nullabilityMarker *token.Token) *NullableTypeSyntax {
+	return &NullableTypeSyntax{
+		Base:              base,
+		NullabilityMarker: nullabilityMarker,
+	}
+}
+
+func (s *NullableTypeSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.Base, s.NullabilityMarker)
+}
+
+type UnionTypeSyntax struct {
+	Members []SyntaxBase
+}
+
+func NewUnionTypeSyntax(members []SyntaxBase) *UnionTypeSyntax {
+	return &UnionTypeSyntax{
+		Members: members,
+	}
+}
+
+func (s *UnionTypeSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.Members[0], s.Members[len(s.Members)-1])
+}
+
+type ObjectTypeSyntax struct {
+	OpenBrace *token.Token
+	// Children holds ObjectTypePropertySyntax, ObjectTypeAdditionalPropertiesSyntax and SkippedTriviaSyntax nodes.
+	Children   []SyntaxBase
+	CloseBrace SyntaxBase
+}
+
+func NewObjectTypeSyntax(openBrace *token.Token, children []SyntaxBase, closeBrace SyntaxBase) *ObjectTypeSyntax {
+	return &ObjectTypeSyntax{
+		OpenBrace:  openBrace,
+		Children:   children,
+		CloseBrace: closeBrace,
+	}
+}
+
+func (s *ObjectTypeSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.OpenBrace, s.CloseBrace)
+}
+
+func (s *ObjectTypeSyntax) Properties() []*ObjectTypePropertySyntax {
+	var properties []*ObjectTypePropertySyntax
+	for _, child := range s.Children {
+		if property, ok := child.(*ObjectTypePropertySyntax); ok {
+			properties = append(properties, property)
+		}
+	}
+	return properties
+}
+
+// AdditionalProperties returns the `*: type` member, or nil if there is none.
+func (s *ObjectTypeSyntax) AdditionalProperties() *ObjectTypeAdditionalPropertiesSyntax {
+	for _, child := range s.Children {
+		if additionalProperties, ok := child.(*ObjectTypeAdditionalPropertiesSyntax); ok {
+			return additionalProperties
+		}
+	}
+	return nil
+}
+
+type ObjectTypePropertySyntax struct {
+	decorableSyntax
+	Key               SyntaxBase
+	OptionalityMarker *token.Token
+	Colon             SyntaxBase
+	Value             SyntaxBase
+}
+
+func NewObjectTypePropertySyntax(leadingNodes []SyntaxBase, key SyntaxBase, optionalityMarker *token.Token, colon SyntaxBase, value SyntaxBase) *ObjectTypePropertySyntax {
+	return &ObjectTypePropertySyntax{
+		decorableSyntax:   decorableSyntax{LeadingNodes: leadingNodes},
+		Key:               key,
+		OptionalityMarker: optionalityMarker,
+		Colon:             colon,
+		Value:             value,
+	}
+}
+
+func (s *ObjectTypePropertySyntax) GetSpan() *util.TextSpan {
+	return s.spanFrom(s.Key, s.Value)
+}
+
+func (s *ObjectTypePropertySyntax) TryGetKeyText() (string, bool) {
+	return tryGetKeyText(s.Key)
+}
+
+func (s *ObjectTypePropertySyntax) IsOptional() bool {
+	return s.OptionalityMarker != nil
+}
+
+type ObjectTypeAdditionalPropertiesSyntax struct {
+	decorableSyntax
+	Asterisk *token.Token
+	Colon    SyntaxBase
+	Value    SyntaxBase
+}
+
+func NewObjectTypeAdditionalPropertiesSyntax(leadingNodes []SyntaxBase, asterisk *token.Token, colon SyntaxBase, value SyntaxBase) *ObjectTypeAdditionalPropertiesSyntax {
+	return &ObjectTypeAdditionalPropertiesSyntax{
+		decorableSyntax: decorableSyntax{LeadingNodes: leadingNodes},
+		Asterisk:        asterisk,
+		Colon:           colon,
+		Value:           value,
+	}
+}
+
+func (s *ObjectTypeAdditionalPropertiesSyntax) GetSpan() *util.TextSpan {
+	return s.spanFrom(s.Asterisk, s.Value)
+}
+
+type TupleTypeSyntax struct {
+	OpenBracket  *token.Token
+	Items        []*TupleTypeItemSyntax
+	CloseBracket SyntaxBase
+}
+
+func NewTupleTypeSyntax(openBracket *token.Token, items []*TupleTypeItemSyntax, closeBracket SyntaxBase) *TupleTypeSyntax {
+	return &TupleTypeSyntax{
+		OpenBracket:  openBracket,
+		Items:        items,
+		CloseBracket: closeBracket,
+	}
+}
+
+func (s *TupleTypeSyntax) GetSpan() *util.TextSpan {
+	return spanBetween(s.OpenBracket, s.CloseBracket)
+}
+
+type TupleTypeItemSyntax struct {
+	decorableSyntax
+	Value SyntaxBase
+}
+
+func NewTupleTypeItemSyntax(leadingNodes []SyntaxBase, value SyntaxBase) *TupleTypeItemSyntax {
+	return &TupleTypeItemSyntax{
+		decorableSyntax: decorableSyntax{LeadingNodes: leadingNodes},
+		Value:           value,
+	}
+}
+
+func (s *TupleTypeItemSyntax) GetSpan() *util.TextSpan {
+	return s.spanFrom(s.Value, s.Value)
+}
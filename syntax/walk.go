@@ -0,0 +1,246 @@
+package syntax
+
+import "bicep-go/token"
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children
+// of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node SyntaxBase) (w Visitor)
+}
+
+// Walk traverses a syntax tree in depth-first order, visiting children in source order.
+func Walk(v Visitor, node SyntaxBase) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	for _, child := range GetChildren(node) {
+		Walk(v, child)
+	}
+
+	v.Visit(nil)
+}
+
+type inspector func(SyntaxBase) bool
+
+func (f inspector) Visit(node SyntaxBase) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses a syntax tree in depth-first order, calling f(node) for each node.
+// If f returns true, Inspect invokes f recursively for each of the children of node,
+// followed by a call of f(nil).
+func Inspect(node SyntaxBase, f func(SyntaxBase) bool) {
+	Walk(inspector(f), node)
+}
+
+// GetChildren returns the direct children of a node in source order. Tokens are included.
+func GetChildren(node SyntaxBase) []SyntaxBase {
+	var children []SyntaxBase
+	add := func(nodes ...SyntaxBase) {
+		children = append(children, nodes...)
+	}
+	addToken := func(tok *token.Token) {
+		if tok != nil {
+			children = append(children, tok)
+		}
+	}
+	addOptional := func(node SyntaxBase) {
+		if node != nil {
+			children = append(children, node)
+		}
+	}
+
+	switch n := node.(type) {
+	case *token.Token:
+	case *SkippedTriviaSyntax:
+		add(n.Elements...)
+	case *IdentifierSyntax:
+		add(n.Child)
+	case *ProgramSyntax:
+		add(n.Children...)
+		add(n.EndOfFile)
+	case *DecoratorSyntax:
+		add(n.At, n.Expression)
+	case *TargetScopeSyntax:
+		add(n.LeadingNodes...)
+		add(n.Keyword, n.Assignment, n.Value)
+	case *MetadataDeclarationSyntax:
+		add(n.LeadingNodes...)
+		add(n.Keyword, n.Name, n.Assignment, n.Value)
+	case *ParameterDeclarationSyntax:
+		add(n.LeadingNodes...)
+		add(n.Keyword, n.Name, n.Type)
+		addOptional(n.Modifier)
+	case *ParameterDefaultValueSyntax:
+		add(n.AssignmentToken, n.DefaultValue)
+	case *VariableDeclarationSyntax:
+		add(n.LeadingNodes...)
+		add(n.Keyword, n.Name, n.Assignment, n.Value)
+	case *ResourceDeclarationSyntax:
+		add(n.LeadingNodes...)
+		add(n.Keyword, n.Name, n.Type)
+		addToken(n.ExistingKeyword)
+		add(n.Assignment, n.Value)
+	case *ModuleDeclarationSyntax:
+		add(n.LeadingNodes...)
+		add(n.Keyword, n.Name, n.Path, n.Assignment, n.Value)
+	case *OutputDeclarationSyntax:
+		add(n.LeadingNodes...)
+		add(n.Keyword, n.Name, n.Type, n.Assignment, n.Value)
+	case *TypeDeclarationSyntax:
+		add(n.LeadingNodes...)
+		add(n.Keyword, n.Name, n.Assignment, n.Value)
+	case *FunctionDeclarationSyntax:
+		add(n.LeadingNodes...)
+		add(n.Keyword, n.Name, n.Lambda)
+	case *CompileTimeImportDeclarationSyntax:
+		add(n.LeadingNodes...)
+		add(n.Keyword, n.ImportExpression, n.FromClause)
+	case *ImportedSymbolsListSyntax:
+		add(n.OpenBrace)
+		for _, item := range n.ImportedSymbols {
+			add(item)
+		}
+		add(n.CloseBrace)
+	case *ImportedSymbolsListItemSyntax:
+		add(n.OriginalSymbolName)
+		if n.AsClause != nil {
+			add(n.AsClause)
+		}
+	case *WildcardImportSyntax:
+		add(n.Wildcard, n.AliasAsClause)
+	case *AliasAsClauseSyntax:
+		add(n.Keyword, n.Alias)
+	case *CompileTimeImportFromClauseSyntax:
+		add(n.Keyword, n.Path)
+	case *MissingDeclarationSyntax:
+		add(n.LeadingNodes...)
+	case *VariableAccessSyntax:
+		add(n.Name)
+	case *StringSyntax:
+		for i, tok := range n.StringTokens {
+			add(tok)
+			if i < len(n.Expressions) {
+				add(n.Expressions[i])
+			}
+		}
+	case *IntegerLiteralSyntax:
+		add(n.Literal)
+	case *BooleanLiteralSyntax:
+		add(n.Literal)
+	case *NullLiteralSyntax:
+		add(n.NullKeyword)
+	case *ArraySyntax:
+		add(n.OpenBracket)
+		for _, item := range n.Items {
+			add(item)
+		}
+		add(n.CloseBracket)
+	case *ArrayItemSyntax:
+		add(n.Value)
+	case *ObjectSyntax:
+		add(n.OpenBrace)
+		add(n.Children...)
+		add(n.CloseBrace)
+	case *ObjectPropertySyntax:
+		add(n.Key, n.Colon, n.Value)
+	case *ParenthesizedExpressionSyntax:
+		add(n.OpenParen, n.Expression, n.CloseParen)
+	case *FunctionArgumentSyntax:
+		add(n.Expression)
+	case *FunctionCallSyntax:
+		add(n.Name, n.OpenParen)
+		for _, argument := range n.Arguments {
+			add(argument)
+		}
+		add(n.CloseParen)
+	case *InstanceFunctionCallSyntax:
+		add(n.BaseExpression, n.Dot, n.Name, n.OpenParen)
+		for _, argument := range n.Arguments {
+			add(argument)
+		}
+		add(n.CloseParen)
+	case *PropertyAccessSyntax:
+		add(n.BaseExpression, n.Dot)
+		addToken(n.SafeAccessMarker)
+		add(n.PropertyName)
+	case *ArrayAccessSyntax:
+		add(n.BaseExpression, n.OpenSquare)
+		addToken(n.SafeAccessMarker)
+		add(n.IndexExpression, n.CloseSquare)
+	case *ResourceAccessSyntax:
+		add(n.BaseExpression, n.DoubleColon, n.ResourceName)
+	case *NonNullAssertionSyntax:
+		add(n.BaseExpression, n.AssertionOperator)
+	case *UnaryOperationSyntax:
+		add(n.OperatorToken, n.Expression)
+	case *BinaryOperationSyntax:
+		add(n.LeftExpression, n.OperatorToken, n.RightExpression)
+	case *TernaryOperationSyntax:
+		add(n.ConditionExpression, n.Question, n.TrueExpression, n.Colon, n.FalseExpression)
+	case *LocalVariableSyntax:
+		add(n.Name)
+	case *VariableBlockSyntax:
+		add(n.OpenParen)
+		for _, argument := range n.Arguments {
+			add(argument)
+		}
+		add(n.CloseParen)
+	case *LambdaSyntax:
+		add(n.VariableSection, n.Arrow, n.Body)
+	case *TypedLocalVariableSyntax:
+		add(n.Name, n.Type)
+	case *TypedVariableBlockSyntax:
+		add(n.OpenParen)
+		for _, argument := range n.Arguments {
+			add(argument)
+		}
+		add(n.CloseParen)
+	case *TypedLambdaSyntax:
+		add(n.VariableSection, n.ReturnType, n.Arrow, n.Body)
+	case *ForSyntax:
+		add(n.OpenSquare, n.ForKeyword, n.VariableSection, n.InKeyword, n.Expression, n.Colon, n.Body, n.CloseSquare)
+	case *IfConditionSyntax:
+		add(n.Keyword, n.ConditionExpression, n.Body)
+	case *TypeVariableAccessSyntax:
+		add(n.Name)
+	case *TypePropertyAccessSyntax:
+		add(n.BaseExpression, n.Dot, n.PropertyName)
+	case *ResourceTypeSyntax:
+		add(n.Keyword, n.Type)
+	case *ArrayTypeSyntax:
+		add(n.Item, n.OpenBracket, n.CloseBracket)
+	case *NullableTypeSyntax:
+		add(n.Base, n.NullabilityMarker)
+	case *UnionTypeSyntax:
+		add(n.Members...)
+	case *ObjectTypeSyntax:
+		add(n.OpenBrace)
+		add(n.Children...)
+		add(n.CloseBrace)
+	case *ObjectTypePropertySyntax:
+		add(n.LeadingNodes...)
+		add(n.Key)
+		addToken(n.OptionalityMarker)
+		add(n.Colon, n.Value)
+	case *ObjectTypeAdditionalPropertiesSyntax:
+		add(n.LeadingNodes...)
+		add(n.Asterisk, n.Colon, n.Value)
+	case *TupleTypeSyntax:
+		add(n.OpenBracket)
+		for _, item := range n.Items {
+			add(item)
+		}
+		add(n.CloseBracket)
+	case *TupleTypeItemSyntax:
+		add(n.LeadingNodes...)
+		add(n.Value)
+	}
+
+	return children
+}
@@ -1,11 +1,15 @@
 package token
 
-import "fmt"
+import (
+	"bicep-go/util"
+	"fmt"
+)
 
 type Token struct {
 	Type           TokenType
 	Literal        string
 	Line           int
+	Span           *util.TextSpan
 	leadingTrivia  []*Trivia
 	trailingTrivia []*Trivia
 }
@@ -13,6 +17,7 @@ type Token struct {
 func NewToken(
 	tokenType TokenType,
 	literal string,
+	span *util.TextSpan,
 	leadingTrivia []*Trivia,
 	trailingTrivia []*Trivia,
 ) *Token {
@@ -21,11 +26,44 @@ func NewToken(
 		Type:           tokenType,
 		Literal:        literal,
 		Line:           0,
+		Span:           span,
 		leadingTrivia:  leadingTrivia,
 		trailingTrivia: trailingTrivia,
 	}
 }
 
+func (tok *Token) GetSpan() *util.TextSpan {
+	return tok.Span
+}
+
+func (tok *Token) GetLeadingTrivia() []*Trivia {
+	return tok.leadingTrivia
+}
+
+func (tok *Token) GetTrailingTrivia() []*Trivia {
+	return tok.trailingTrivia
+}
+
+// GetFullEnd returns the offset just past the token's trailing trivia.
+func (tok *Token) GetFullEnd() int {
+	if len(tok.trailingTrivia) > 0 {
+		last := tok.trailingTrivia[len(tok.trailingTrivia)-1].Span
+		return last.Position + last.Length
+	}
+	return tok.Span.Position + tok.Span.Length
+}
+
+// Shift moves the token and its trivia by the given number of characters.
+func (tok *Token) Shift(delta int) {
+	tok.Span.Position += delta
+	for _, trivia := range tok.leadingTrivia {
+		trivia.Span.Position += delta
+	}
+	for _, trivia := range tok.trailingTrivia {
+		trivia.Span.Position += delta
+	}
+}
+
 func (tok *Token) ToString() string {
 	return fmt.Sprintf("Type: %s, Literal: %s", GetTokenText(tok.Type), tok.Literal)
 }
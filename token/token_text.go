@@ -13,7 +13,7 @@ var tokenTypeToText = map[TokenType]string{
 	TokenTypeQuestion:             "?",
 	TokenTypeColon:                ":",
 	TokenTypeSemicolon:            ";",
-	TokenTypeAssignment:           ":",
+	TokenTypeAssignment:           "=",
 	TokenTypePlus:                 "+",
 	TokenTypeMinus:                "-",
 	TokenTypeAsterisk:             "*",
@@ -23,17 +23,17 @@ var tokenTypeToText = map[TokenType]string{
 	TokenTypeLessThan:             "<",
 	TokenTypeGreaterThan:          ">",
 	TokenTypePipe:                 "|",
-	TokenTypeLessThanOrEqual:      "<:",
-	TokenTypeGreaterThanOrEqual:   ">:",
-	TokenTypeEquals:               "::",
-	TokenTypeNotEquals:            "!:",
-	TokenTypeEqualsInsensitive:    ":~",
+	TokenTypeLessThanOrEqual:      "<=",
+	TokenTypeGreaterThanOrEqual:   ">=",
+	TokenTypeEquals:               "==",
+	TokenTypeNotEquals:            "!=",
+	TokenTypeEqualsInsensitive:    "=~",
 	TokenTypeNotEqualsInsensitive: "!~",
 	TokenTypeLogicalAnd:           "&&",
 	TokenTypeLogicalOr:            "||",
 	TokenTypeDoubleQuestion:       "??",
 	TokenTypeDoubleColon:          "::",
-	TokenTypeArrow:                ":>",
+	TokenTypeArrow:                "=>",
 	TokenTypeTrueKeyword:          "true",
 	TokenTypeFalseKeyword:         "false",
 	TokenTypeNullKeyword:          "null",
@@ -0,0 +1,196 @@
+package types
+
+// AreTypesAssignable reports whether a value of the source type can be assigned to the target type.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/TypeSystem/TypeValidator.cs
+func AreTypesAssignable(source TypeSymbol, target TypeSymbol) bool {
+	switch source.(type) {
+	case *AnyType, *ErrorType, *NeverType:
+		return true
+	}
+
+	switch target := target.(type) {
+	case *AnyType, *ErrorType:
+		return true
+	case *UnionType:
+		if sourceUnion, ok := source.(*UnionType); ok {
+			return allAssignable(sourceUnion.Members, target)
+		}
+		for _, member := range target.Members {
+			if AreTypesAssignable(source, member) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch source := source.(type) {
+	case *UnionType:
+		return allAssignable(source.Members, target)
+	case *DiscriminatedObjectType:
+		for _, member := range source.Members {
+			if !AreTypesAssignable(member, target) {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch target := target.(type) {
+	case *NullType:
+		_, ok := source.(*NullType)
+		return ok
+
+	case *PrimitiveType:
+		switch source := source.(type) {
+		case *PrimitiveType:
+			return source.Name == target.Name
+		case *StringLiteralType:
+			return target == String
+		case *IntegerLiteralType:
+			return target == Int
+		case *BooleanLiteralType:
+			return target == Bool
+		}
+		return false
+
+	case *StringLiteralType:
+		source, ok := source.(*StringLiteralType)
+		return ok && source.Value == target.Value
+
+	case *IntegerLiteralType:
+		source, ok := source.(*IntegerLiteralType)
+		return ok && source.Value == target.Value
+
+	case *BooleanLiteralType:
+		source, ok := source.(*BooleanLiteralType)
+		return ok && source.Value == target.Value
+
+	case *ArrayType:
+		switch source := source.(type) {
+		case *ArrayType:
+			return AreTypesAssignable(source.Item, target.Item)
+		case *TupleType:
+			return allAssignable(source.Items, target.Item)
+		}
+		return false
+
+	case *TupleType:
+		switch source := source.(type) {
+		case *TupleType:
+			if len(source.Items) != len(target.Items) {
+				return false
+			}
+			for i, item := range source.Items {
+				if !AreTypesAssignable(item, target.Items[i]) {
+					return false
+				}
+			}
+			return true
+		case *ArrayType:
+			// the length of an array is not known until deployment
+			for _, item := range target.Items {
+				if !AreTypesAssignable(source.Item, item) {
+					return false
+				}
+			}
+			return true
+		}
+		return false
+
+	case *ObjectType:
+		source, ok := source.(*ObjectType)
+		return ok && isObjectAssignable(source, target)
+
+	case *ResourceType:
+		source, ok := source.(*ResourceType)
+		return ok && source.TypeReference.Equals(target.TypeReference)
+
+	case *ModuleType:
+		source, ok := source.(*ModuleType)
+		return ok && source.Name == target.Name
+
+	case *DiscriminatedObjectType:
+		source, ok := source.(*ObjectType)
+		if !ok {
+			return false
+		}
+		// an object is assignable if it is assignable to the variant its discriminator selects, or to any
+		// variant if the value of its discriminator is not known
+		if property := source.TryGetProperty(target.DiscriminatorKey); property != nil {
+			if literal, ok := property.Type.(*StringLiteralType); ok {
+				member := target.TryGetMember(literal.Value)
+				return member != nil && isObjectAssignable(source, member)
+			}
+		}
+		for _, member := range target.Members {
+			if isObjectAssignable(source, member) {
+				return true
+			}
+		}
+		return false
+
+	case *LambdaType:
+		source, ok := source.(*LambdaType)
+		return ok && isLambdaAssignable(source, target)
+	}
+
+	return false
+}
+
+// isLambdaAssignable checks that the source lambda takes an argument count the target accepts, that the
+// arguments the target passes are accepted by the source, and that the source returns what the target expects.
+func isLambdaAssignable(source *LambdaType, target *LambdaType) bool {
+	count := source.MaximumArgumentCount()
+	if count < target.MinimumArgumentCount() || count > target.MaximumArgumentCount() {
+		return false
+	}
+	for i := 0; i < count; i++ {
+		if !AreTypesAssignable(target.GetArgumentType(i), source.GetArgumentType(i)) {
+			return false
+		}
+	}
+	return AreTypesAssignable(source.ReturnType, target.ReturnType)
+}
+
+func allAssignable(sources []TypeSymbol, target TypeSymbol) bool {
+	for _, source := range sources {
+		if !AreTypesAssignable(source, target) {
+			return false
+		}
+	}
+	return true
+}
+
+// isObjectAssignable checks the properties of the source against the target. A source that allows
+// additional properties of any type may still provide the required properties, so only declared
+// properties of the source are checked.
+func isObjectAssignable(source *ObjectType, target *ObjectType) bool {
+	_, sourceIsOpen := source.AdditionalPropertiesType.(*AnyType)
+
+	for _, targetProperty := range target.Properties {
+		sourceProperty := source.TryGetProperty(targetProperty.Name)
+		if sourceProperty == nil {
+			if targetProperty.IsRequired() && !sourceIsOpen {
+				return false
+			}
+			continue
+		}
+		if !AreTypesAssignable(sourceProperty.Type, targetProperty.Type) {
+			return false
+		}
+	}
+
+	for _, sourceProperty := range source.Properties {
+		if target.TryGetProperty(sourceProperty.Name) != nil {
+			continue
+		}
+		if target.AdditionalPropertiesType != nil && !AreTypesAssignable(sourceProperty.Type, target.AdditionalPropertiesType) {
+			return false
+		}
+	}
+
+	if source.AdditionalPropertiesType != nil && target.AdditionalPropertiesType != nil {
+		return AreTypesAssignable(source.AdditionalPropertiesType, target.AdditionalPropertiesType)
+	}
+	return true
+}
@@ -0,0 +1,24 @@
+package types
+
+// ResourceScope is a set of scopes at which a deployment or resource can be targeted.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/TypeSystem/ResourceScope.cs
+type ResourceScope int
+
+const (
+	ResourceScopeNone   ResourceScope = 0
+	ResourceScopeTenant ResourceScope = 1 << iota
+	ResourceScopeManagementGroup
+	ResourceScopeSubscription
+	ResourceScopeResourceGroup
+	// ResourceScopeResource is the scope of extension resources, which are deployed onto another resource.
+	ResourceScopeResource
+	ResourceScopeDesiredStateConfiguration
+
+	ResourceScopeAll = ResourceScopeTenant | ResourceScopeManagementGroup | ResourceScopeSubscription |
+		ResourceScopeResourceGroup | ResourceScopeResource | ResourceScopeDesiredStateConfiguration
+)
+
+// Has reports whether the set shares any scope with the other set.
+func (s ResourceScope) Has(scope ResourceScope) bool {
+	return s&scope != 0
+}
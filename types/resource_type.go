@@ -0,0 +1,199 @@
+package types
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	resourceTypePattern        = regexp.MustCompile(`(?i)^([a-z0-9][a-z0-9.]*)((?:/[a-z0-9\-]+)+)@(\d{4}-\d{2}-\d{2}(?:-[a-z0-9]+)?)$`)
+	resourceTypeSegmentPattern = regexp.MustCompile(`(?i)^([a-z0-9\-]+)(?:@(\d{4}-\d{2}-\d{2}(?:-[a-z0-9]+)?))?$`)
+)
+
+// ResourceTypeReference is a resource type with its API version, e.g. Microsoft.Storage/storageAccounts@2023-01-01.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Resources/ResourceTypeReference.cs
+type ResourceTypeReference struct {
+	Type       string
+	ApiVersion string
+}
+
+func NewResourceTypeReference(typeName string, apiVersion string) *ResourceTypeReference {
+	return &ResourceTypeReference{
+		Type:       typeName,
+		ApiVersion: apiVersion,
+	}
+}
+
+// TryParseResourceTypeReference parses a fully-qualified resource type string, returning nil if it is not valid.
+func TryParseResourceTypeReference(text string) *ResourceTypeReference {
+	match := resourceTypePattern.FindStringSubmatch(text)
+	if match == nil {
+		return nil
+	}
+	return NewResourceTypeReference(match[1]+match[2], match[3])
+}
+
+// TryCombineChildTypeReference resolves the type string of a nested resource against the type of its parent.
+// The child type is either fully-qualified, or a single type segment with an optional API version, e.g. 'subnets'.
+func (r *ResourceTypeReference) TryCombineChildTypeReference(childText string) *ResourceTypeReference {
+	if child := TryParseResourceTypeReference(childText); child != nil {
+		return child
+	}
+
+	match := resourceTypeSegmentPattern.FindStringSubmatch(childText)
+	if match == nil {
+		return nil
+	}
+
+	apiVersion := r.ApiVersion
+	if match[2] != "" {
+		apiVersion = match[2]
+	}
+	return NewResourceTypeReference(r.Type+"/"+match[1], apiVersion)
+}
+
+func (r *ResourceTypeReference) FormatName() string {
+	return r.Type + "@" + r.ApiVersion
+}
+
+// Equals compares resource types case-insensitively, as ARM does.
+func (r *ResourceTypeReference) Equals(other *ResourceTypeReference) bool {
+	return strings.EqualFold(r.Type, other.Type) && strings.EqualFold(r.ApiVersion, other.ApiVersion)
+}
+
+// ResourceType is the type of a resource declaration. Body is the type of the declaration's object body.
+type ResourceType struct {
+	TypeReference *ResourceTypeReference
+	Body          *ObjectType
+	// ValidParentScopes are the scopes the resource can be deployed at. NewResourceType allows all of them.
+	ValidParentScopes ResourceScope
+}
+
+func NewResourceType(typeReference *ResourceTypeReference, body *ObjectType) *ResourceType {
+	return &ResourceType{
+		TypeReference:     typeReference,
+		Body:              body,
+		ValidParentScopes: ResourceScopeAll,
+	}
+}
+
+func (t *ResourceType) GetName() string {
+	return t.TypeReference.FormatName()
+}
+
+// ModuleType is the type of a module declaration. Body is the type of the declaration's object body.
+type ModuleType struct {
+	Name string
+	Body *ObjectType
+	// ValidParentScopes are the scopes the module can be deployed at, given by the target scope of the module file.
+	ValidParentScopes ResourceScope
+}
+
+func (t *ModuleType) GetName() string {
+	return t.Name
+}
+
+// ResourceTypeProvider supplies the body types of resources.
+type ResourceTypeProvider interface {
+	// TryGetResourceType returns nil if the provider has no type for the reference, and an error if it has one
+	// that cannot be loaded.
+	TryGetResourceType(reference *ResourceTypeReference) (*ResourceType, error)
+	// GetApiVersions returns the API versions the provider has types for, or nil if it does not know the resource type.
+	GetApiVersions(typeName string) []string
+}
+
+type genericResourceTypeProvider struct{}
+
+// NewGenericResourceTypeProvider returns a provider that accepts every resource type with a loosely typed body.
+func NewGenericResourceTypeProvider() ResourceTypeProvider {
+	return &genericResourceTypeProvider{}
+}
+
+func (p *genericResourceTypeProvider) TryGetResourceType(reference *ResourceTypeReference) (*ResourceType, error) {
+	return NewGenericResourceType(reference), nil
+}
+
+func (p *genericResourceTypeProvider) GetApiVersions(typeName string) []string {
+	return nil
+}
+
+// NewGenericResourceType returns a resource type whose body accepts any properties besides the common ones.
+func NewGenericResourceType(reference *ResourceTypeReference) *ResourceType {
+	return NewResourceType(reference, CreateResourceBody(reference.FormatName(), []*TypeProperty{
+		NewTypeProperty("name", String, TypePropertyFlagsRequired),
+		NewTypeProperty("location", String, TypePropertyFlagsNone),
+		NewTypeProperty("tags", Object, TypePropertyFlagsNone),
+		NewTypeProperty("properties", Object, TypePropertyFlagsNone),
+	}, Any))
+}
+
+// CreateResourceBody adds the properties shared by all resources to the properties of a resource type.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/LanguageConstants.cs
+func CreateResourceBody(name string, properties []*TypeProperty, additionalPropertiesType TypeSymbol) *ObjectType {
+	common := []*TypeProperty{
+		NewTypeProperty("id", String, TypePropertyFlagsReadOnly),
+		NewTypeProperty("type", String, TypePropertyFlagsReadOnly),
+		NewTypeProperty("apiVersion", String, TypePropertyFlagsReadOnly),
+		NewTypeProperty("scope", Any, TypePropertyFlagsWriteOnly),
+		NewTypeProperty("parent", Any, TypePropertyFlagsWriteOnly),
+		NewTypeProperty("dependsOn", Array, TypePropertyFlagsWriteOnly),
+	}
+
+	body := NewObjectType(name, nil, additionalPropertiesType)
+	for _, property := range append(properties, common...) {
+		if body.TryGetProperty(property.Name) == nil {
+			body.Properties = append(body.Properties, property)
+		}
+	}
+	body.ValidationFlags = ValidationFlagsWarnOnTypeMismatch
+	return body
+}
+
+// CreateExistingResourceBody returns the body of an existing resource, in which only the name, scope,
+// parent and dependencies can be assigned and every other property is read-only.
+func CreateExistingResourceBody(body *ObjectType) *ObjectType {
+	existing := NewObjectType(body.Name, nil, body.AdditionalPropertiesType)
+	existing.ValidationFlags = body.ValidationFlags
+	for _, property := range body.Properties {
+		switch property.Name {
+		case "name":
+			existing.Properties = append(existing.Properties, property)
+		case "scope", "parent", "dependsOn":
+			existing.Properties = append(existing.Properties, NewTypeProperty(property.Name, property.Type, TypePropertyFlagsWriteOnly))
+		default:
+			flags := property.Flags&^(TypePropertyFlagsRequired|TypePropertyFlagsWriteOnly) | TypePropertyFlagsReadOnly
+			propertyType := property.Type
+			// optional properties may not be set on the existing resource
+			if property.Flags&(TypePropertyFlagsRequired|TypePropertyFlagsReadOnly) == 0 {
+				propertyType = CreateNullable(propertyType)
+			}
+			existing.Properties = append(existing.Properties, NewTypeProperty(property.Name, propertyType, flags))
+		}
+	}
+	return existing
+}
+
+// NewModuleType returns the type of a module with the given parameters and outputs.
+// The params property is required if any of the parameters is.
+func NewModuleType(name string, paramsType TypeSymbol, outputsType TypeSymbol) *ModuleType {
+	paramsFlags := TypePropertyFlagsNone
+	if paramsObject, ok := paramsType.(*ObjectType); ok {
+		for _, property := range paramsObject.Properties {
+			if property.IsRequired() {
+				paramsFlags = TypePropertyFlagsRequired
+			}
+		}
+	}
+
+	return &ModuleType{
+		Name:              name,
+		ValidParentScopes: ResourceScopeAll,
+		Body: NewObjectType(name, []*TypeProperty{
+			NewTypeProperty("name", String, TypePropertyFlagsNone),
+			NewTypeProperty("params", paramsType, paramsFlags),
+			NewTypeProperty("scope", Any, TypePropertyFlagsWriteOnly),
+			NewTypeProperty("dependsOn", Array, TypePropertyFlagsWriteOnly),
+			NewTypeProperty("outputs", outputsType, TypePropertyFlagsReadOnly),
+		}, nil),
+	}
+}
@@ -0,0 +1,333 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TypeSymbol is the type of an expression or the type a declaration expects.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/TypeSystem/TypeSymbol.cs
+type TypeSymbol interface {
+	GetName() string
+}
+
+type ValidationFlags int
+
+const (
+	ValidationFlagsDefault ValidationFlags = 0
+	// ValidationFlagsWarnOnTypeMismatch reports mismatches against the type as warnings,
+	// for types that may be inaccurate such as resource bodies.
+	ValidationFlagsWarnOnTypeMismatch ValidationFlags = 1 << iota
+)
+
+// AnyType accepts and is accepted by every type.
+type AnyType struct{}
+
+func (t *AnyType) GetName() string {
+	return "any"
+}
+
+// ErrorType is the type of an expression that has errors. It is assignable both ways
+// so that a single error is not reported again by every expression using it.
+type ErrorType struct{}
+
+func (t *ErrorType) GetName() string {
+	return "error"
+}
+
+// NeverType is the type of an expression that can have no value, e.g. an empty union.
+type NeverType struct{}
+
+func (t *NeverType) GetName() string {
+	return "never"
+}
+
+type NullType struct{}
+
+func (t *NullType) GetName() string {
+	return "null"
+}
+
+// PrimitiveType is one of string, int or bool.
+type PrimitiveType struct {
+	Name string
+}
+
+func (t *PrimitiveType) GetName() string {
+	return t.Name
+}
+
+type StringLiteralType struct {
+	Value string
+}
+
+func NewStringLiteralType(value string) *StringLiteralType {
+	return &StringLiteralType{Value: value}
+}
+
+func (t *StringLiteralType) GetName() string {
+	return "'" + EscapeStringLiteral(t.Value) + "'"
+}
+
+// EscapeStringLiteral escapes a string so that it can be written between single quotes.
+func EscapeStringLiteral(value string) string {
+	replacer := strings.NewReplacer("\\", "\\\\", "'", "\\'", "\n", "\\n", "\r", "\\r", "\t", "\\t", "${", "\\${")
+	return replacer.Replace(value)
+}
+
+type IntegerLiteralType struct {
+	Value int64
+}
+
+func NewIntegerLiteralType(value int64) *IntegerLiteralType {
+	return &IntegerLiteralType{Value: value}
+}
+
+func (t *IntegerLiteralType) GetName() string {
+	return strconv.FormatInt(t.Value, 10)
+}
+
+type BooleanLiteralType struct {
+	Value bool
+}
+
+func NewBooleanLiteralType(value bool) *BooleanLiteralType {
+	return &BooleanLiteralType{Value: value}
+}
+
+func (t *BooleanLiteralType) GetName() string {
+	return strconv.FormatBool(t.Value)
+}
+
+// ArrayType is an array whose items are all of the item type.
+type ArrayType struct {
+	Item TypeSymbol
+}
+
+func NewArrayType(item TypeSymbol) *ArrayType {
+	return &ArrayType{Item: item}
+}
+
+func (t *ArrayType) GetName() string {
+	switch t.Item.(type) {
+	case *AnyType:
+		return "array"
+	case *UnionType:
+		return "(" + t.Item.GetName() + ")[]"
+	}
+	return t.Item.GetName() + "[]"
+}
+
+// TupleType is an array of fixed length with a type per item.
+type TupleType struct {
+	Items []TypeSymbol
+}
+
+func NewTupleType(items []TypeSymbol) *TupleType {
+	return &TupleType{Items: items}
+}
+
+func (t *TupleType) GetName() string {
+	names := make([]string, 0, len(t.Items))
+	for _, item := range t.Items {
+		names = append(names, item.GetName())
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+// Item returns the union of the item types.
+func (t *TupleType) Item() TypeSymbol {
+	return CreateUnion(t.Items...)
+}
+
+// LambdaType is the type of a lambda or a user-defined function, e.g. (string, int) => string.
+// Lambdas passed to functions such as map() may leave out the trailing OptionalArgumentTypes, e.g. the index.
+type LambdaType struct {
+	ArgumentTypes         []TypeSymbol
+	OptionalArgumentTypes []TypeSymbol
+	ReturnType            TypeSymbol
+}
+
+func NewLambdaType(argumentTypes []TypeSymbol, returnType TypeSymbol) *LambdaType {
+	return &LambdaType{ArgumentTypes: argumentTypes, ReturnType: returnType}
+}
+
+func (t *LambdaType) GetName() string {
+	names := make([]string, 0, len(t.ArgumentTypes)+len(t.OptionalArgumentTypes))
+	for _, argumentType := range t.ArgumentTypes {
+		names = append(names, argumentType.GetName())
+	}
+	for _, argumentType := range t.OptionalArgumentTypes {
+		names = append(names, "["+argumentType.GetName()+"]")
+	}
+	return "(" + strings.Join(names, ", ") + ") => " + t.ReturnType.GetName()
+}
+
+func (t *LambdaType) MinimumArgumentCount() int {
+	return len(t.ArgumentTypes)
+}
+
+func (t *LambdaType) MaximumArgumentCount() int {
+	return len(t.ArgumentTypes) + len(t.OptionalArgumentTypes)
+}
+
+// GetArgumentType returns the type of the argument at the index, or nil if the lambda takes fewer arguments.
+func (t *LambdaType) GetArgumentType(index int) TypeSymbol {
+	if index < len(t.ArgumentTypes) {
+		return t.ArgumentTypes[index]
+	}
+	if index < t.MaximumArgumentCount() {
+		return t.OptionalArgumentTypes[index-len(t.ArgumentTypes)]
+	}
+	return nil
+}
+
+type TypePropertyFlags int
+
+const (
+	TypePropertyFlagsNone     TypePropertyFlags = 0
+	TypePropertyFlagsRequired TypePropertyFlags = 1 << iota
+	TypePropertyFlagsReadOnly
+	TypePropertyFlagsWriteOnly
+)
+
+type TypeProperty struct {
+	Name        string
+	Type        TypeSymbol
+	Flags       TypePropertyFlags
+	Description string
+}
+
+func NewTypeProperty(name string, typeSymbol TypeSymbol, flags TypePropertyFlags) *TypeProperty {
+	return &TypeProperty{
+		Name:  name,
+		Type:  typeSymbol,
+		Flags: flags,
+	}
+}
+
+func (p *TypeProperty) IsRequired() bool {
+	return p.Flags&TypePropertyFlagsRequired != 0
+}
+
+func (p *TypeProperty) IsReadOnly() bool {
+	return p.Flags&TypePropertyFlagsReadOnly != 0
+}
+
+// ObjectType is an object with named properties. Properties that are not declared must match
+// AdditionalPropertiesType, and are not allowed at all when it is nil.
+type ObjectType struct {
+	Name                     string
+	Properties               []*TypeProperty
+	AdditionalPropertiesType TypeSymbol
+	ValidationFlags          ValidationFlags
+}
+
+func NewObjectType(name string, properties []*TypeProperty, additionalPropertiesType TypeSymbol) *ObjectType {
+	return &ObjectType{
+		Name:                     name,
+		Properties:               properties,
+		AdditionalPropertiesType: additionalPropertiesType,
+	}
+}
+
+func (t *ObjectType) GetName() string {
+	return t.Name
+}
+
+func (t *ObjectType) TryGetProperty(name string) *TypeProperty {
+	for _, property := range t.Properties {
+		if property.Name == name {
+			return property
+		}
+	}
+	return nil
+}
+
+func (t *ObjectType) WarnOnTypeMismatch() bool {
+	return t.ValidationFlags&ValidationFlagsWarnOnTypeMismatch != 0
+}
+
+// FormatObjectTypeName renders the name of an object type from its properties, e.g. { name: string, size?: int }.
+func FormatObjectTypeName(properties []*TypeProperty, additionalPropertiesType TypeSymbol) string {
+	var members []string
+	for _, property := range properties {
+		optionality := ""
+		if !property.IsRequired() {
+			optionality = "?"
+		}
+		members = append(members, fmt.Sprintf("%s%s: %s", property.Name, optionality, property.Type.GetName()))
+	}
+	if additionalPropertiesType != nil {
+		members = append(members, "*: "+additionalPropertiesType.GetName())
+	}
+	if len(members) == 0 {
+		return "{ }"
+	}
+	return "{ " + strings.Join(members, ", ") + " }"
+}
+
+// UnionType is a value of any of the member types. Use CreateUnion to build normalized unions.
+type UnionType struct {
+	Members []TypeSymbol
+}
+
+func (t *UnionType) GetName() string {
+	names := make([]string, 0, len(t.Members))
+	for _, member := range t.Members {
+		names = append(names, member.GetName())
+	}
+	return strings.Join(names, " | ")
+}
+
+// DiscriminatedObjectType is a union of object types that are told apart by the string literal value of a
+// shared property, e.g. a union of { kind: 'a', ... } and { kind: 'b', ... } discriminated by kind.
+type DiscriminatedObjectType struct {
+	DiscriminatorKey string
+	// Members are the variants in declaration order. Each has a required string literal discriminator property.
+	Members []*ObjectType
+}
+
+func NewDiscriminatedObjectType(discriminatorKey string, members []*ObjectType) *DiscriminatedObjectType {
+	return &DiscriminatedObjectType{DiscriminatorKey: discriminatorKey, Members: members}
+}
+
+func (t *DiscriminatedObjectType) GetName() string {
+	names := make([]string, 0, len(t.Members))
+	for _, member := range t.Members {
+		names = append(names, member.GetName())
+	}
+	return strings.Join(names, " | ")
+}
+
+// TryGetMember returns the variant whose discriminator property has the given value, or nil if there is none.
+func (t *DiscriminatedObjectType) TryGetMember(discriminatorValue string) *ObjectType {
+	for _, member := range t.Members {
+		if literal, ok := member.TryGetProperty(t.DiscriminatorKey).Type.(*StringLiteralType); ok && literal.Value == discriminatorValue {
+			return member
+		}
+	}
+	return nil
+}
+
+// GetDiscriminatorType returns the union of the discriminator values of the variants.
+func (t *DiscriminatedObjectType) GetDiscriminatorType() TypeSymbol {
+	values := make([]TypeSymbol, 0, len(t.Members))
+	for _, member := range t.Members {
+		values = append(values, member.TryGetProperty(t.DiscriminatorKey).Type)
+	}
+	return CreateUnion(values...)
+}
+
+var (
+	Any    = &AnyType{}
+	Error  = &ErrorType{}
+	Never  = &NeverType{}
+	Null   = &NullType{}
+	String = &PrimitiveType{Name: "string"}
+	Int    = &PrimitiveType{Name: "int"}
+	Bool   = &PrimitiveType{Name: "bool"}
+	Array  = NewArrayType(Any)
+	Object = NewObjectType("object", nil, Any)
+)
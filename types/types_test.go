@@ -0,0 +1,100 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAreTypesAssignable(t *testing.T) {
+	name := NewTypeProperty("name", String, TypePropertyFlagsRequired)
+	size := NewTypeProperty("size", Int, TypePropertyFlagsNone)
+	named := NewObjectType("named", []*TypeProperty{name, size}, nil)
+	small := NewStringLiteralType("small")
+	storage := NewResourceType(NewResourceTypeReference("Microsoft.Storage/storageAccounts", "2023-01-01"), Object)
+	kindA := NewObjectType("a", []*TypeProperty{NewTypeProperty("kind", NewStringLiteralType("a"), TypePropertyFlagsRequired), size}, nil)
+	kindB := NewObjectType("b", []*TypeProperty{NewTypeProperty("kind", NewStringLiteralType("b"), TypePropertyFlagsRequired), name}, nil)
+	discriminated := NewDiscriminatedObjectType("kind", []*ObjectType{kindA, kindB})
+	predicate := &LambdaType{ArgumentTypes: []TypeSymbol{String}, OptionalArgumentTypes: []TypeSymbol{Int}, ReturnType: Bool}
+
+	tests := []struct {
+		name     string
+		source   TypeSymbol
+		target   TypeSymbol
+		expected bool
+	}{
+		{"any to string", Any, String, true},
+		{"string to any", String, Any, true},
+		{"error to int", Error, Int, true},
+		{"literal to primitive", small, String, true},
+		{"primitive to literal", String, small, false},
+		{"literal to same literal", small, NewStringLiteralType("small"), true},
+		{"literal to other literal", small, NewStringLiteralType("large"), false},
+		{"int to string", Int, String, false},
+		{"int literal to int", NewIntegerLiteralType(1), Int, true},
+		{"bool literal to bool", NewBooleanLiteralType(true), Bool, true},
+		{"null to nullable", Null, CreateNullable(String), true},
+		{"null to string", Null, String, false},
+		{"nullable to string", CreateNullable(String), String, false},
+		{"literal to union", small, CreateUnion(small, NewStringLiteralType("large")), true},
+		{"union to wider union", CreateUnion(small, Null), CreateUnion(String, Null), true},
+		{"typed array to array", NewArrayType(String), Array, true},
+		{"typed array mismatch", NewArrayType(String), NewArrayType(Int), false},
+		{"tuple to typed array", NewTupleType([]TypeSymbol{small, String}), NewArrayType(String), true},
+		{"tuple length mismatch", NewTupleType([]TypeSymbol{String}), NewTupleType([]TypeSymbol{String, String}), false},
+		{"object to object", named, Object, true},
+		{"open object to named", Object, named, true},
+		{"missing required property", NewObjectType("object", []*TypeProperty{size}, nil), named, false},
+		{"optional property", NewObjectType("object", []*TypeProperty{name}, nil), named, true},
+		{"property mismatch", NewObjectType("object", []*TypeProperty{NewTypeProperty("name", Int, TypePropertyFlagsRequired)}, nil), named, false},
+		{"additional property mismatch", NewObjectType("object", []*TypeProperty{name}, nil), NewObjectType("ints", nil, Int), false},
+		{"resource to same resource", storage, NewResourceType(NewResourceTypeReference("microsoft.storage/storageAccounts", "2023-01-01"), Object), true},
+		{"resource to other resource", storage, NewResourceType(NewResourceTypeReference("Microsoft.Storage/storageAccounts", "2022-01-01"), Object), false},
+		{"resource to object", storage, Object, false},
+		{"variant to discriminated", kindB, discriminated, true},
+		{"object to selected variant", NewObjectType("object", []*TypeProperty{NewTypeProperty("kind", NewStringLiteralType("a"), TypePropertyFlagsRequired), size}, nil), discriminated, true},
+		{"object mismatching selected variant", NewObjectType("object", []*TypeProperty{NewTypeProperty("kind", NewStringLiteralType("a"), TypePropertyFlagsRequired), name, NewTypeProperty("size", String, TypePropertyFlagsRequired)}, nil), discriminated, false},
+		{"object without discriminator", NewObjectType("object", []*TypeProperty{name}, nil), discriminated, false},
+		{"open object to discriminated", Object, discriminated, true},
+		{"discriminated to object", discriminated, Object, true},
+		{"discriminated to variant", discriminated, kindA, false},
+		{"lambda to lambda", NewLambdaType([]TypeSymbol{String}, NewBooleanLiteralType(true)), predicate, true},
+		{"lambda with optional argument", NewLambdaType([]TypeSymbol{String, Int}, Bool), predicate, true},
+		{"lambda with too many arguments", NewLambdaType([]TypeSymbol{String, Int, Int}, Bool), predicate, false},
+		{"lambda argument mismatch", NewLambdaType([]TypeSymbol{Int}, Bool), predicate, false},
+		{"lambda return mismatch", NewLambdaType([]TypeSymbol{String}, String), predicate, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, AreTypesAssignable(tt.source, tt.target))
+		})
+	}
+}
+
+func TestCreateUnion(t *testing.T) {
+	small := NewStringLiteralType("small")
+	large := NewStringLiteralType("large")
+
+	require.Equal(t, Never, CreateUnion())
+	require.Equal(t, small, CreateUnion(small, NewStringLiteralType("small")))
+	require.Equal(t, Any, CreateUnion(small, Any))
+	require.Equal(t, Error, CreateUnion(small, Error))
+	require.Equal(t, "'small' | 'large' | null", CreateUnion(CreateUnion(small, large), Null, small).GetName())
+	require.Equal(t, "'small' | 'large'", RemoveNullability(CreateNullable(CreateUnion(small, large))).GetName())
+	require.Equal(t, "(string | int)[]", NewArrayType(CreateUnion(String, Int)).GetName())
+}
+
+func TestResourceTypeReference(t *testing.T) {
+	reference := TryParseResourceTypeReference("Microsoft.Network/virtualNetworks@2023-04-01")
+	require.Equal(t, "Microsoft.Network/virtualNetworks", reference.Type)
+	require.Equal(t, "2023-04-01", reference.ApiVersion)
+
+	require.Nil(t, TryParseResourceTypeReference("Microsoft.Network/virtualNetworks"))
+	require.Nil(t, TryParseResourceTypeReference("Microsoft.Network@2023-04-01"))
+	require.NotNil(t, TryParseResourceTypeReference("Microsoft.Network/virtualNetworks@2023-04-01-preview"))
+
+	require.Equal(t, "Microsoft.Network/virtualNetworks/subnets@2023-04-01", reference.TryCombineChildTypeReference("subnets").FormatName())
+	require.Equal(t, "Microsoft.Network/virtualNetworks/subnets@2022-01-01", reference.TryCombineChildTypeReference("subnets@2022-01-01").FormatName())
+	require.Nil(t, reference.TryCombineChildTypeReference("sub/nets"))
+}
@@ -0,0 +1,95 @@
+package types
+
+// CreateUnion builds a union of the given types. Nested unions are flattened, duplicates are removed,
+// and a union containing any or an error is any or an error. It returns never for no types and the
+// type itself for a single one.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/TypeSystem/TypeHelper.cs
+func CreateUnion(members ...TypeSymbol) TypeSymbol {
+	for _, member := range members {
+		if _, ok := member.(*ErrorType); ok {
+			return Error
+		}
+	}
+
+	var flattened []TypeSymbol
+	seen := map[any]bool{}
+
+	var add func(member TypeSymbol) bool
+	add = func(member TypeSymbol) bool {
+		switch member := member.(type) {
+		case *AnyType:
+			return false
+		case *NeverType:
+			return true
+		case *UnionType:
+			for _, nested := range member.Members {
+				if !add(nested) {
+					return false
+				}
+			}
+			return true
+		}
+
+		// object types share names such as "object", so they are only deduplicated by identity
+		var key any = member.GetName()
+		switch member.(type) {
+		case *ObjectType, *DiscriminatedObjectType:
+			key = member
+		}
+		if !seen[key] {
+			seen[key] = true
+			flattened = append(flattened, member)
+		}
+		return true
+	}
+
+	for _, member := range members {
+		if !add(member) {
+			return Any
+		}
+	}
+
+	switch len(flattened) {
+	case 0:
+		return Never
+	case 1:
+		return flattened[0]
+	}
+	return &UnionType{Members: flattened}
+}
+
+// IsNullable reports whether null is assignable to the type.
+func IsNullable(typeSymbol TypeSymbol) bool {
+	switch typeSymbol := typeSymbol.(type) {
+	case *NullType:
+		return true
+	case *UnionType:
+		for _, member := range typeSymbol.Members {
+			if IsNullable(member) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CreateNullable returns the union of the type and null.
+func CreateNullable(typeSymbol TypeSymbol) TypeSymbol {
+	return CreateUnion(typeSymbol, Null)
+}
+
+// RemoveNullability returns the type without null. It returns the type itself when it is not nullable.
+func RemoveNullability(typeSymbol TypeSymbol) TypeSymbol {
+	union, ok := typeSymbol.(*UnionType)
+	if !ok {
+		return typeSymbol
+	}
+
+	var members []TypeSymbol
+	for _, member := range union.Members {
+		if _, ok := member.(*NullType); !ok {
+			members = append(members, member)
+		}
+	}
+	return CreateUnion(members...)
+}
@@ -0,0 +1,19 @@
+package util
+
+import "io/fs"
+
+// DisplayPathFS is a file system whose files are shown to users with other paths than their names, e.g. with the
+// paths of the operating system. Messages name the files of such file systems by their display paths.
+type DisplayPathFS interface {
+	fs.FS
+	DisplayPath(name string) string
+}
+
+// GetDisplayPath returns the path a file of the file system is shown with, which is its name unless the file system
+// implements DisplayPathFS.
+func GetDisplayPath(fsys fs.FS, name string) string {
+	if displayFS, ok := fsys.(DisplayPathFS); ok {
+		return displayFS.DisplayPath(name)
+	}
+	return name
+}
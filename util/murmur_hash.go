@@ -0,0 +1,80 @@
+package util
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// ComputeMurmurHash64 computes the 64-bit MurmurHash3 variant ARM uses for template hashes and uniqueString(): two
+// 32-bit lanes over 8-byte blocks, with the second lane in the high half of the result.
+func ComputeMurmurHash64(data []byte) uint64 {
+	const (
+		c1 = 0x239b961b
+		c2 = 0xab0e9789
+	)
+	var h1, h2 uint32
+	length := len(data)
+	index := 0
+	for ; index+8 <= length; index += 8 {
+		k1 := binary.LittleEndian.Uint32(data[index:])
+		k2 := binary.LittleEndian.Uint32(data[index+4:])
+
+		k1 *= c1
+		k1 = bits.RotateLeft32(k1, 15)
+		k1 *= c2
+		h1 ^= k1
+		h1 = bits.RotateLeft32(h1, 19)
+		h1 += h2
+		h1 = h1*5 + 0x561ccd1b
+
+		k2 *= c2
+		k2 = bits.RotateLeft32(k2, 17)
+		k2 *= c1
+		h2 ^= k2
+		h2 = bits.RotateLeft32(h2, 13)
+		h2 += h1
+		h2 = h2*5 + 0x0bcaa747
+	}
+
+	if tail := data[index:]; len(tail) > 0 {
+		var k1 uint32
+		for i := min(len(tail), 4) - 1; i >= 0; i-- {
+			k1 = k1<<8 | uint32(tail[i])
+		}
+		k1 *= c1
+		k1 = bits.RotateLeft32(k1, 15)
+		k1 *= c2
+		h1 ^= k1
+
+		if len(tail) > 4 {
+			var k2 uint32
+			for i := len(tail) - 1; i >= 4; i-- {
+				k2 = k2<<8 | uint32(tail[i])
+			}
+			k2 *= c2
+			k2 = bits.RotateLeft32(k2, 17)
+			k2 *= c1
+			h2 ^= k2
+		}
+	}
+
+	h1 ^= uint32(length)
+	h2 ^= uint32(length)
+	h1 += h2
+	h2 += h1
+	h1 = fmix32(h1)
+	h2 = fmix32(h2)
+	h1 += h2
+	h2 += h1
+	return uint64(h2)<<32 | uint64(h1)
+}
+
+// fmix32 is the finalization mix of MurmurHash3, which forces all bits of a lane to avalanche.
+func fmix32(h uint32) uint32 {
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
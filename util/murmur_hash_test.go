@@ -0,0 +1,24 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeMurmurHash64(t *testing.T) {
+	for _, tc := range []struct {
+		data     string
+		expected uint64
+	}{
+		{"", 0},
+		{"a", 2686549150780078204},
+		{"abcd", 15372358952837647232},
+		{"abcde", 16732853381781808622},
+		{"abcdefg", 6596867396500806095},
+		{"abcdefgh", 9789183536796037058},
+		{"abcdefghijk", 14695319728199611614},
+	} {
+		require.Equal(t, tc.expected, ComputeMurmurHash64([]byte(tc.data)), tc.data)
+	}
+}
@@ -0,0 +1,30 @@
+package util
+
+import "sort"
+
+// ComputeLineStarts returns the offsets at which the lines of a text start. Lines end with \n, \r\n or \r.
+// https://github.com/Azure/bicep/blob/main/src/Bicep.Core/Text/TextCoordinateConverter.cs
+func ComputeLineStarts(text string) []int {
+	lineStarts := []int{0}
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				i++
+			}
+			lineStarts = append(lineStarts, i+1)
+		case '\n':
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	return lineStarts
+}
+
+// GetPosition returns the zero-based line and column of an offset, given the line starts of its text.
+func GetPosition(lineStarts []int, offset int) (int, int) {
+	line := sort.Search(len(lineStarts), func(i int) bool { return lineStarts[i] > offset }) - 1
+	if line < 0 {
+		line = 0
+	}
+	return line, offset - lineStarts[line]
+}
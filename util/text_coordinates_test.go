@@ -0,0 +1,40 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeLineStarts(t *testing.T) {
+	for _, tc := range []struct {
+		text     string
+		expected []int
+	}{
+		{"", []int{0}},
+		{"abc", []int{0}},
+		{"a\nbc\n", []int{0, 2, 5}},
+		{"a\r\nb\rc", []int{0, 3, 5}},
+	} {
+		require.Equal(t, tc.expected, ComputeLineStarts(tc.text))
+	}
+}
+
+func TestGetPosition(t *testing.T) {
+	lineStarts := ComputeLineStarts("param a int\nvar b = a\n")
+	for _, tc := range []struct {
+		offset         int
+		expectedLine   int
+		expectedColumn int
+	}{
+		{0, 0, 0},
+		{6, 0, 6},
+		{12, 1, 0},
+		{20, 1, 8},
+		{22, 2, 0},
+	} {
+		line, column := GetPosition(lineStarts, tc.offset)
+		require.Equal(t, tc.expectedLine, line)
+		require.Equal(t, tc.expectedColumn, column)
+	}
+}
@@ -3,8 +3,8 @@ package util
 import "fmt"
 
 type TextSpan struct {
-	Position int
-	Length   int
+	Position int `json:"position"`
+	Length   int `json:"length"`
 }
 
 func NewTextSpan(position int, length int) *TextSpan {